@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <node-id>",
+	Short: "Write the files in a response's code blocks to the working directory",
+	Long: `Write the code blocks in a node that name a file to that file, relative to the working directory,
+after showing a diff of every change and asking for confirmation.
+
+A block names its file in its fence (` + "```go title=main.go, ```go:main.go or ```main.go" + `), on a line
+just before it (such as **main.go** or ` + "`main.go`:" + `), or in a comment on its first line (// main.go).
+Blocks in diff or patch format are applied as unified diffs to the files they name, so a response
+can change part of a file. Blocks that don't name a file are skipped.
+
+Files outside the working directory are never written. Use --dry-run to only show the changes, and
+--yes to apply them without asking.`,
+	Example: `  bai apply 3f2a9c1b
+  bai apply 3f2a9c1b --dry-run
+  bai apply 3f2a9c1b --index 2 --yes`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		index, err := cmd.Flags().GetInt("index")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get index flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dir flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get yes flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		node, err := newSession(database).Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		blocks := render.CodeBlocks(node.Content)
+		if len(blocks) == 0 {
+			fmt.Printf("\033[90mℹ️  Node %s has no code blocks.\033[0m\n", shortID(node.ID))
+			return
+		}
+		if index != 0 {
+			if index < 1 || index > len(blocks) {
+				fmt.Printf("\033[31m❌ --index must be between 1 and %d.\033[0m\n", len(blocks))
+				os.Exit(1)
+			}
+			blocks = blocks[index-1 : index]
+		}
+
+		changes, err := planChanges(blocks, dir)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(changes) == 0 {
+			fmt.Println("\033[90mℹ️  Nothing to apply: no code block names a file that it would change.\033[0m")
+			return
+		}
+
+		for _, change := range changes {
+			printFileChange(change)
+		}
+		if dryRun {
+			fmt.Printf("\n\033[90mℹ️  Dry run: %d file(s) would be changed.\033[0m\n", len(changes))
+			return
+		}
+		if !confirmApply(fmt.Sprintf("Apply changes to %d file(s)?", len(changes)), yes) {
+			fmt.Println("\033[90mApply cancelled.\033[0m")
+			return
+		}
+
+		for _, change := range changes {
+			if err := change.write(); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("\033[32m✅ Applied changes to %d file(s).\033[0m\n", len(changes))
+	},
+}
+
+// fileChange is the new content of one file, as planned by 'bai apply'
+type fileChange struct {
+	path    string // Path shown to the user, relative to the working directory
+	target  string // Path on disk
+	old     string
+	new     string
+	exists  bool
+	deleted bool
+}
+
+// write makes the change on disk
+func (c fileChange) write() error {
+	if c.deleted {
+		if err := os.Remove(c.target); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", c.path, err)
+		}
+		fmt.Printf("🗑️  Deleted \033[90m%s\033[0m\n", c.path)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", c.path, err)
+	}
+	if err := os.WriteFile(c.target, []byte(withNewline(c.new)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", c.path, err)
+	}
+	fmt.Printf("📄 Wrote \033[90m%s\033[0m\n", c.path)
+	return nil
+}
+
+// printFileChange shows a change as a diff against the file's current content
+func printFileChange(c fileChange) {
+	switch {
+	case c.deleted:
+		fmt.Printf("\n\033[1m🗑️  %s\033[0m \033[90m(delete)\033[0m\n", c.path)
+	case !c.exists:
+		fmt.Printf("\n\033[1m📄 %s\033[0m \033[90m(new file)\033[0m\n", c.path)
+	default:
+		fmt.Printf("\n\033[1m📝 %s\033[0m\n", c.path)
+	}
+	printUnifiedDiff(diffLines(splitFileLines(c.old), splitFileLines(c.new)), 3)
+}
+
+// planChanges works out the file changes made by the blocks, relative to dir. Several blocks for
+// the same file apply in order, so a later patch sees an earlier block's content.
+func planChanges(blocks []render.CodeBlock, dir string) ([]fileChange, error) {
+	var order []string
+	changes := make(map[string]*fileChange)
+
+	// load returns the planned change for a path, starting from the file on disk
+	load := func(path string) (*fileChange, error) {
+		rel, target, err := resolveApplyPath(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		if change, ok := changes[rel]; ok {
+			return change, nil
+		}
+
+		change := &fileChange{path: rel, target: target}
+		data, err := os.ReadFile(target)
+		switch {
+		case err == nil:
+			change.exists = true
+			change.old = strings.TrimSuffix(string(data), "\n")
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		change.new = change.old
+		changes[rel] = change
+		order = append(order, rel)
+		return change, nil
+	}
+
+	for i, block := range blocks {
+		if isPatch(block) {
+			patches, err := parsePatch(block.Code)
+			if err != nil {
+				return nil, fmt.Errorf("code block %d: %w", i+1, err)
+			}
+			for _, patch := range patches {
+				path := patch.newPath
+				if path == "" {
+					path = patch.oldPath
+				}
+				change, err := load(path)
+				if err != nil {
+					return nil, fmt.Errorf("code block %d: %w", i+1, err)
+				}
+				if patch.newPath == "" {
+					change.deleted = true
+					change.new = ""
+					continue
+				}
+				if change.new, err = patch.apply(change.new); err != nil {
+					return nil, fmt.Errorf("code block %d: %s: %w", i+1, path, err)
+				}
+			}
+			continue
+		}
+
+		path := blockPath(block)
+		if path == "" {
+			continue
+		}
+		change, err := load(path)
+		if err != nil {
+			return nil, fmt.Errorf("code block %d: %w", i+1, err)
+		}
+		change.new = strings.TrimSuffix(block.Code, "\n")
+		change.deleted = false
+	}
+
+	var result []fileChange
+	for _, rel := range order {
+		change := changes[rel]
+		if change.deleted && !change.exists {
+			continue
+		}
+		if !change.deleted && change.exists && change.new == change.old {
+			continue
+		}
+		result = append(result, *change)
+	}
+	return result, nil
+}
+
+// resolveApplyPath checks that a path named by a response stays inside dir, returning it cleaned
+// and joined to dir
+func resolveApplyPath(dir, path string) (string, string, error) {
+	rel := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("refusing to write %s, which is outside the working directory", path)
+	}
+	return rel, filepath.Join(dir, rel), nil
+}
+
+// splitFileLines splits file content into lines for diffing, treating empty content as no lines
+func splitFileLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+var (
+	// infoPath matches a path given as an attribute in a fence's info string
+	infoPath = regexp.MustCompile(`\b(?:title|file|filename|path)=["']?([^"'\s]+)`)
+	// commentPath matches a first line that is only a comment naming a file
+	commentPath = regexp.MustCompile(`^\s*(?://|#|--|;|/\*|<!--)\s*(?:(?i:file(?:name)?|path)\s*:\s*)?(\S+?)\s*(?:\*/|-->)?\s*$`)
+	// looksLikePath matches a file name with an extension, optionally in directories
+	looksLikePath = regexp.MustCompile(`^(?:[\w.-]+/)*[\w-][\w.-]*\.[A-Za-z0-9]+$|^(?:[\w.-]+/)*(?:Makefile|Dockerfile|Procfile)$`)
+)
+
+// blockPath returns the file a code block names, or an empty string if it names none
+func blockPath(block render.CodeBlock) string {
+	if m := infoPath.FindStringSubmatch(block.Info); m != nil {
+		return m[1]
+	}
+	if _, path, ok := strings.Cut(block.Language, ":"); ok && looksLikePath.MatchString(path) {
+		return path
+	}
+	if block.Language != "" && looksLikePath.MatchString(block.Language) {
+		if _, known := codeExtensions[strings.ToLower(block.Language)]; !known {
+			return block.Language
+		}
+	}
+
+	if caption := captionPath(block.Caption); caption != "" {
+		return caption
+	}
+
+	firstLine, _, _ := strings.Cut(block.Code, "\n")
+	if m := commentPath.FindStringSubmatch(firstLine); m != nil && looksLikePath.MatchString(m[1]) {
+		return m[1]
+	}
+	return ""
+}
+
+// captionPath returns the file named by a line such as "**main.go**", "`main.go`:" or
+// "File: main.go" before a code block, or an empty string if it names none
+func captionPath(caption string) string {
+	caption = strings.TrimLeft(caption, "#>*_ ")
+	caption = strings.TrimRight(caption, ":*_ ")
+	for _, prefix := range []string{"File:", "file:", "Filename:", "filename:", "Path:", "path:"} {
+		caption = strings.TrimSpace(strings.TrimPrefix(caption, prefix))
+	}
+	caption = strings.Trim(caption, "`*_")
+	if looksLikePath.MatchString(caption) {
+		return caption
+	}
+	return ""
+}
+
+// isPatch reports whether a code block holds a unified diff
+func isPatch(block render.CodeBlock) bool {
+	switch strings.ToLower(block.Language) {
+	case "diff", "patch", "udiff":
+		return strings.Contains(block.Code, "@@")
+	}
+	return false
+}
+
+// filePatch is the part of a unified diff that changes one file
+type filePatch struct {
+	oldPath string // Empty for a new file
+	newPath string // Empty for a deleted file
+	hunks   []patchHunk
+}
+
+// patchHunk is one @@ section of a unified diff
+type patchHunk struct {
+	oldStart int // 1-based line the hunk starts at in the old file, or 0 if unknown
+	lines    []diffOp
+}
+
+var hunkHeader = regexp.MustCompile(`^@@\s*(?:-(\d+)(?:,\d+)?\s*\+\d+(?:,\d+)?\s*)?@@`)
+
+// parsePatch parses a unified diff, such as 'git diff' or 'diff -u' prints
+func parsePatch(text string) ([]filePatch, error) {
+	var patches []filePatch
+	var current *filePatch
+	var hunk *patchHunk
+
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- ") && (hunk == nil || isFileHeader(line)):
+			patches = append(patches, filePatch{oldPath: patchPath(line[4:], "a/")})
+			current, hunk = &patches[len(patches)-1], nil
+		case strings.HasPrefix(line, "+++ ") && current != nil && hunk == nil:
+			current.newPath = patchPath(line[4:], "b/")
+		case strings.HasPrefix(line, "@@"):
+			if current == nil {
+				return nil, errors.New("diff has a hunk before any --- and +++ file header")
+			}
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			current.hunks = append(current.hunks, patchHunk{oldStart: start})
+			hunk = &current.hunks[len(current.hunks)-1]
+		case hunk == nil || strings.HasPrefix(line, `\`):
+			// Headers such as "diff --git" and "index", and "\ No newline at end of file"
+		case line == "":
+			// Blank context lines often lose their leading space when pasted
+			hunk.lines = append(hunk.lines, diffOp{' ', ""})
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.lines = append(hunk.lines, diffOp{line[0], line[1:]})
+		default:
+			hunk = nil
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil, errors.New("diff has no --- and +++ file headers")
+	}
+	for _, patch := range patches {
+		if patch.oldPath == "" && patch.newPath == "" {
+			return nil, errors.New("diff has a file header without a path")
+		}
+	}
+	return patches, nil
+}
+
+// isFileHeader reports whether a "--- " line inside a hunk is really the next file's header
+// rather than a removed line starting with "--"
+func isFileHeader(line string) bool {
+	path := strings.TrimSpace(line[4:])
+	return strings.HasPrefix(path, "a/") || path == "/dev/null"
+}
+
+// patchPath extracts the path from a --- or +++ header, dropping git's a/ or b/ prefix and any
+// timestamp; /dev/null becomes an empty path
+func patchPath(header, prefix string) string {
+	path, _, _ := strings.Cut(strings.TrimSpace(header), "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// apply applies the patch's hunks to content. Hunks are matched by their context and removed
+// lines, looking outward from the line numbers they give, since responses often get those wrong.
+func (p filePatch) apply(content string) (string, error) {
+	lines := splitFileLines(content)
+	offset, end := 0, 0 // How far hunks have moved from their line numbers, and where the last one ended
+	for n, hunk := range p.hunks {
+		var before, after []string
+		for _, op := range hunk.lines {
+			if op.kind != '+' {
+				before = append(before, op.line)
+			}
+			if op.kind != '-' {
+				after = append(after, op.line)
+			}
+		}
+
+		expected := end
+		if hunk.oldStart > 0 {
+			expected = hunk.oldStart - 1 + offset
+		}
+		at := findLines(lines, before, expected)
+		if at < 0 {
+			// A hunk whose result is already there was applied before, so there's nothing to do
+			if done := findLines(lines, after, expected); done >= 0 && len(after) > 0 {
+				end = done + len(after)
+				continue
+			}
+			return "", fmt.Errorf("hunk %d does not apply: its context wasn't found", n+1)
+		}
+
+		updated := append([]string{}, lines[:at]...)
+		updated = append(updated, after...)
+		updated = append(updated, lines[at+len(before):]...)
+		lines = updated
+		end = at + len(after)
+		if hunk.oldStart > 0 {
+			offset = end - (hunk.oldStart - 1 + len(before))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// findLines returns where want occurs in lines, searching outward from near, or -1 if it doesn't.
+// Trailing whitespace is ignored when comparing lines.
+func findLines(lines, want []string, near int) int {
+	matches := func(at int) bool {
+		if at < 0 || at+len(want) > len(lines) {
+			return false
+		}
+		for i, line := range want {
+			if strings.TrimRight(lines[at+i], " \t\r") != strings.TrimRight(line, " \t\r") {
+				return false
+			}
+		}
+		return true
+	}
+
+	if near < 0 {
+		near = 0
+	}
+	if near > len(lines) {
+		near = len(lines)
+	}
+	for distance := 0; distance <= len(lines); distance++ {
+		if matches(near - distance) {
+			return near - distance
+		}
+		if distance > 0 && matches(near+distance) {
+			return near + distance
+		}
+	}
+	return -1
+}
+
+// confirmApply asks the user to confirm applying changes and returns true if they agreed
+// With --yes it always agrees; without a terminal to ask on, the command exits instead
+func confirmApply(prompt string, yes bool) bool {
+	if yes {
+		return true
+	}
+	if !isTerminal(os.Stdin) {
+		fmt.Printf("\n\033[31m❌ Refusing to apply changes without a terminal to confirm on. Pass --yes to apply non-interactively.\033[0m\n")
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n\033[33m%s\033[0m \033[1m(y/N):\033[0m ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to read input: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().IntP("index", "n", 0, "Only apply the nth code block, counting from 1")
+	applyCmd.Flags().StringP("dir", "C", ".", "Directory that file paths are relative to")
+	applyCmd.Flags().Bool("dry-run", false, "Show the changes without prompting or writing anything")
+	applyCmd.Flags().BoolP("yes", "y", false, "Apply the changes without asking for confirmation")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Ask a list of prompts as separate branches of the current conversation",
+	Long: `Ask each prompt in a file as its own branch below the current working node, generating the
+model's responses concurrently. The current working node stays where it is. How many responses are
+generated at once is set by --concurrency or the generate.concurrency setting, and
+generate.provider_limits caps the requests sent to any one provider; in a terminal a progress bar
+tracks the batch.
+
+Each non-blank line of the input is a prompt. In a .csv file the first row names the columns and
+each following row is a prompt, taken from the "prompt" column if there is one, or otherwise written
+out as "column: value" lines. Use --input - to read prompts from stdin.`,
+	Example: `  bai batch --input prompts.txt --llm gpt-4o
+  bai batch --input cases.csv --concurrency 8`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input, err := cmd.Flags().GetString("input")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get input flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get concurrency flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if concurrency < 0 {
+			fmt.Printf("\033[31m❌ --concurrency can't be negative.\033[0m\n")
+			os.Exit(1)
+		}
+
+		prompts, err := bonsai.ReadBatchInputs(input)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(prompts) == 0 {
+			fmt.Printf("\033[90mℹ️  No prompts found in %s.\033[0m\n", input)
+			return
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		if err := limitContextFromFlags(cmd, session); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		parent, err := session.Current()
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if llmModel == "" {
+			if inherited, err := session.InheritedModel(parent); err == nil && inherited == "" {
+				fmt.Printf("\033[33m⚠️  No model given or inherited; adding prompts without responses.\033[0m\n")
+			}
+		}
+		fmt.Printf("📦 Running %d prompt(s) below \033[33m%s\033[0m...\n", len(prompts), parent.ID)
+
+		progress := newProgressLine("📦")
+		done, failed := 0, 0
+		started := time.Now()
+		results := session.Batch(context.Background(), parent, prompts, bonsai.BatchOptions{
+			Model:       llmModel,
+			Concurrency: concurrency,
+			Timeout:     session.Timeout(),
+			OnResult: func(result bonsai.BatchResult) {
+				done++
+				prompt := truncateContent(strings.ReplaceAll(prompts[result.Index], "\n", " "), 50)
+				switch {
+				case result.Err != nil:
+					failed++
+					progress.Printf("[%d/%d] \033[31m❌ %s: %v\033[0m\n", done, len(prompts), prompt, result.Err)
+				case result.Response != nil:
+					progress.Printf("[%d/%d] ✅ \033[33m%s\033[0m %s\n", done, len(prompts), result.Response.ID, prompt)
+				default:
+					progress.Printf("[%d/%d] ✅ \033[33m%s\033[0m %s\n", done, len(prompts), result.Message.ID, prompt)
+				}
+			},
+			OnProgress: progress.Update,
+		})
+		progress.Done()
+
+		created := 0
+		for _, result := range results {
+			if result.Message != nil {
+				created++
+			}
+		}
+		fmt.Printf("\n🌿 \033[32mCreated %d branch(es)\033[0m", created)
+		if failed > 0 {
+			fmt.Printf(", \033[31m%d failed\033[0m", failed)
+		}
+		fmt.Printf(". Current working node unchanged: \033[33m%s\033[0m\n", parent.ID)
+		notifyIfSlowOrWarn(database, started, "bai batch finished", fmt.Sprintf("%d of %d prompt(s) answered", len(prompts)-failed, len(prompts)))
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringP("input", "i", "", "File of prompts, one per line or CSV row (- for stdin)")
+	batchCmd.Flags().StringP("llm", "l", "", "LLM model to use (defaults to the current node's model)")
+	batchCmd.Flags().IntP("concurrency", "c", 0, "Number of responses to generate at once (defaults to generate.concurrency)")
+	addContextFlags(batchCmd)
+	batchCmd.MarkFlagRequired("input")
+}
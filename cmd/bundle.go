@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Pack subtrees into files to send to other people",
+	Long: `Pack a subtree into a single compressed .bonsai file that can be emailed or copied to a teammate,
+who imports it into their own garden with 'bai bundle apply'.
+
+A bundle holds the branch from the root down to the bundled node, so the subtree keeps its context,
+and everything below that node. Nodes keep their IDs, so applying a bundle again, or a newer bundle
+of the same subtree, only adds what's new.`,
+	Example: `  bai bundle create 3f2a9c1b kyoto.bonsai
+  bai bundle apply kyoto.bonsai`,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <node-id> <file>",
+	Short: "Pack a node, its branch and its subtree into a bundle file",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		node, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		bundle, err := session.NewBundle(node.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		path := args[1]
+		file, err := os.Create(path)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to create %s: %v\033[0m\n", path, err)
+			os.Exit(1)
+		}
+		if err := bundle.Write(file); err != nil {
+			file.Close()
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if err := file.Close(); err != nil {
+			fmt.Printf("\033[31m❌ Failed to write %s: %v\033[0m\n", path, err)
+			os.Exit(1)
+		}
+
+		size := int64(0)
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		}
+		fmt.Printf("📦 Bundled %d node(s) from \033[33m%s\033[0m into \033[90m%s\033[0m (%s)\n", len(bundle.Nodes), shortID(node.ID), path, formatBytes(size))
+	},
+}
+
+var bundleApplyCmd = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Import the nodes in a bundle file into your garden",
+	Long: `Import the nodes in a bundle file into your garden. Nodes you already have are kept; a node that was
+changed both in your garden and in the bundle is a conflict, and is skipped unless --force is given.
+The current working node stays where it is: check out the bundled node to continue from it.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var opts bonsai.SyncOptions
+		var err error
+		if opts.Force, err = cmd.Flags().GetBool("force"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get force flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if opts.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		bundle, err := bonsai.ReadBundle(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %s: %v\033[0m\n", args[0], err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		title, _, _ := strings.Cut(strings.TrimSpace(bundle.Root().Content), "\n")
+		fmt.Printf("📦 Bundle of \033[1m%s\033[0m \033[90m(%d node(s), created %s)\033[0m\n", truncateContent(title, 60), len(bundle.Nodes), time.Unix(bundle.CreatedAt, 0).Format("2006-01-02 15:04"))
+
+		result, err := newSession(database).ApplyBundle(context.Background(), bundle, opts)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		for _, conflict := range result.Conflicts {
+			fmt.Printf("\033[33m⚠️  Conflict on node %s: %s differs from your copy\033[0m\n", shortID(conflict.ID), strings.Join(conflict.Fields, ", "))
+		}
+		verb := "Imported"
+		if opts.DryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("\033[32m✅ %s %d new and %d updated node(s)\033[0m \033[90m(%d already in your garden)\033[0m\n", verb, len(result.Added), len(result.Updated), result.UpToDate)
+		if len(result.Conflicts) > 0 {
+			fmt.Printf("\033[33m%d conflicting node(s) skipped. Rerun with --force to replace your versions.\033[0m\n", len(result.Conflicts))
+		}
+		if !opts.DryRun {
+			fmt.Printf("\033[90mContinue from the bundled node with 'bai checkout %s'.\033[0m\n", shortID(bundle.Node))
+			if len(result.Conflicts) > 0 {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleApplyCmd)
+	bundleApplyCmd.Flags().Bool("force", false, "Replace your versions of conflicting nodes with the bundle's")
+	bundleApplyCmd.Flags().Bool("dry-run", false, "Show what would be imported without importing anything")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Show whether LLM responses are cached and how many are stored",
+	Long: `Show whether LLM responses are cached and how many are stored.
+
+With the cache on, a generation whose model, parameters and full conversation history match an
+earlier one is answered from the cache instead of the model, so re-running experiments and replays
+returns instantly and costs nothing. Turn it on with 'bai config set cache.responses on' and
+bypass it for a single command with --no-cache.`,
+	Example: `  bai config set cache.responses on
+  bai cache
+  bai replay 3f2a9c1b --llm gpt-4 --no-cache
+  bai cache clear`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		enabled, err := database.ResponseCacheEnabled()
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to read %s: %v\033[0m\n", db.ResponseCacheConfigKey, err)
+			os.Exit(1)
+		}
+		count, err := database.CountCachedResponses()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if enabled {
+			fmt.Printf("💾 Response cache is \033[32mon\033[0m with \033[33m%d\033[0m cached response(s)\n", count)
+		} else {
+			fmt.Printf("💾 Response cache is \033[90moff\033[0m with \033[33m%d\033[0m cached response(s)\n", count)
+			fmt.Printf("\033[90mTurn it on with 'bai config set %s on'\033[0m\n", db.ResponseCacheConfigKey)
+		}
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached LLM response",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		cleared, err := database.ClearResponseCache()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🧹 Cleared \033[33m%d\033[0m cached response(s)\n", cleared)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -10,11 +10,20 @@ import (
 var checkoutCmd = &cobra.Command{
 	Use:   "checkout <node-id>",
 	Short: "Jump to a different node in the conversation tree",
-	Long:  `Jumps to a different node in the conversation tree.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Jumps to a different node in the conversation tree.
+
+Each tree remembers its own working position. Checking out the root of a different tree
+restores your last position in that tree; use --exact to go to the root itself.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		nodeID := args[0]
 
+		exact, err := cmd.Flags().GetBool("exact")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get exact flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Initialize database
 		database, err := initializeDatabase(false)
 		if err != nil {
@@ -23,43 +32,28 @@ var checkoutCmd = &cobra.Command{
 		}
 		defer database.Close()
 
-		// Check if the node exists
-		node, err := database.GetNodeByID(nodeID)
+		// Abbreviated node IDs such as those shown by 'bai recent' are expanded
+		result, err := newSession(database).Checkout(nodeID, exact)
 		if err != nil {
 			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
-		// Get current working node for comparison
-		currentNodeID, err := database.GetCurrentNode()
-		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to get current node: %v\033[0m\n", err)
-			os.Exit(1)
+		node := result.Node
+		if result.Restored {
+			fmt.Printf("🔖 Restoring your last position in this tree (use --exact to go to the root)\n")
 		}
-
-		// Check if we're already on this node
-		if currentNodeID != nil && *currentNodeID == nodeID {
-			fmt.Printf("📍 Already on node \033[33m%s\033[0m\n", nodeID)
+		if result.AlreadyCurrent {
+			fmt.Printf("📍 Already on node \033[33m%s\033[0m\n", node.ID)
 			return
 		}
 
-		// Set the new current node
-		if err := database.SetCurrentNode(nodeID); err != nil {
-			fmt.Printf("\033[31m❌ Failed to set current node: %v\033[0m\n", err)
-			os.Exit(1)
-		}
-
-		fmt.Printf("📍 \033[32mMoved to node:\033[0m \033[33m%s\033[0m\n", nodeID)
-		var typeIcon string
-		if node.Type == "user" {
-			typeIcon = "👤"
-		} else {
-			typeIcon = "🤖"
-		}
-		fmt.Printf("%s Type: \033[90m%s\033[0m\n", typeIcon, node.Type)
+		fmt.Printf("📍 \033[32mMoved to node:\033[0m \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("%s Type: \033[90m%s\033[0m\n", nodeTypeIcon(node.Type), node.Type)
 		if node.Model != nil {
 			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
 		}
+		printAuthor(node)
 		if node.Parent != nil {
 			fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
 		}
@@ -69,4 +63,5 @@ var checkoutCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(checkoutCmd)
+	checkoutCmd.Flags().BoolP("exact", "e", false, "Check out the given node even if it's a root with a remembered position")
 }
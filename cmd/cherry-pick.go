@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/aarose/bonsai/pkg/hooks"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +25,13 @@ var cherryPickCmd = &cobra.Command{
 		}
 		defer database.Close()
 
+		// Expand abbreviated node IDs such as those shown by 'bai recent'
+		sourceNodeID, err = database.ResolveNodeID(sourceNodeID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Get current working node
 		currentNodeID, err := database.GetCurrentNode()
 		if err != nil {
@@ -59,13 +67,7 @@ var cherryPickCmd = &cobra.Command{
 
 			fmt.Printf("🍒 \033[32mCherry-picked node:\033[0m \033[33m%s\033[0m\n", sourceNodeID)
 			fmt.Printf("✨ \033[32mCreated new node with ID:\033[0m \033[33m%s\033[0m\n", duplicateNode.ID)
-			var typeIcon string
-			if duplicateNode.Type == "user" {
-				typeIcon = "👤"
-			} else {
-				typeIcon = "🤖"
-			}
-			fmt.Printf("%s Type: \033[90m%s\033[0m\n", typeIcon, duplicateNode.Type)
+			fmt.Printf("%s Type: \033[90m%s\033[0m\n", nodeTypeIcon(duplicateNode.Type), duplicateNode.Type)
 
 			fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *currentNodeID)
 		if duplicateNode.Model != nil {
@@ -81,6 +83,8 @@ var cherryPickCmd = &cobra.Command{
 			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", content)
 
 			fmt.Printf("\n\033[32m✓ Successfully cherry-picked content from \033[33m%s\033[32m to new node \033[33m%s\033[0m\n", sourceNodeID, duplicateNode.ID)
+
+		fireHook(database, hooks.NodeCreated, duplicateNode)
 	},
 }
 
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <node-id>",
+	Short: "Copy a branch into a brand-new independent seed",
+	Long: `Copies the branch from the root down to the given node into a brand-new root tree, so an
+experiment can be forked without bloating the original conversation. Use --subtree to also copy
+everything below the node. The copy of the given node becomes the current working node.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nodeID := args[0]
+
+		includeSubtree, err := cmd.Flags().GetBool("subtree")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get subtree flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Expand abbreviated node IDs such as those shown by 'bai recent'
+		nodeID, err = database.ResolveNodeID(nodeID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		clonedNode, count, err := database.CloneBranch(nodeID, includeSubtree)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to clone branch: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if err := database.SetCurrentNode(clonedNode.ID); err != nil {
+			fmt.Printf("\033[33m⚠️  Cloned branch but failed to set current node: %v\033[0m\n", err)
+		}
+
+		fmt.Printf("🌱 \033[32mCloned %d node(s) from \033[33m%s\033[32m into a new seed\033[0m\n", count, nodeID)
+		fmt.Printf("📍 Current working node: \033[33m%s\033[0m\n", clonedNode.ID)
+		if clonedNode.Model != nil {
+			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *clonedNode.Model)
+		}
+		fmt.Printf("💬 Message: \033[90m%s\033[0m\n", truncateContent(clonedNode.Content, 100))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+	cloneCmd.Flags().BoolP("subtree", "s", false, "Also copy all descendants of the node")
+}
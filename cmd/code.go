@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// codeExtensions maps code block languages to file extensions for 'bai code --out <dir>'
+var codeExtensions = map[string]string{
+	"go": "go", "python": "py", "py": "py", "javascript": "js", "js": "js", "typescript": "ts", "ts": "ts",
+	"jsx": "jsx", "tsx": "tsx", "bash": "sh", "sh": "sh", "shell": "sh", "zsh": "sh", "rust": "rs", "rs": "rs",
+	"c": "c", "cpp": "cpp", "c++": "cpp", "java": "java", "csharp": "cs", "cs": "cs", "kotlin": "kt",
+	"swift": "swift", "ruby": "rb", "rb": "rb", "php": "php", "sql": "sql", "json": "json", "yaml": "yaml",
+	"yml": "yaml", "toml": "toml", "html": "html", "css": "css", "markdown": "md", "md": "md",
+}
+
+var codeCmd = &cobra.Command{
+	Use:   "code <node-id>",
+	Short: "Extract the code blocks from a node",
+	Long: `Print the fenced code blocks in a node's content without the surrounding text, or write them to
+files with --out. Use --index to pick a single block, counting from 1.
+
+With --out, a single block is written to the given file. Several blocks are written into the given
+directory as block-1.go, block-2.py and so on, named after their language.`,
+	Example: `  bai code 3f2a9c1b
+  bai code 3f2a9c1b --index 2 --out main.go
+  bai code 3f2a9c1b --out snippets/`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		index, err := cmd.Flags().GetInt("index")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get index flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		out, err := cmd.Flags().GetString("out")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get out flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		node, err := newSession(database).Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		blocks := render.CodeBlocks(node.Content)
+		if len(blocks) == 0 {
+			fmt.Fprintf(os.Stderr, "\033[90mℹ️  Node %s has no code blocks.\033[0m\n", shortID(node.ID))
+			os.Exit(1)
+		}
+		if index != 0 {
+			if index < 1 || index > len(blocks) {
+				fmt.Printf("\033[31m❌ --index must be between 1 and %d.\033[0m\n", len(blocks))
+				os.Exit(1)
+			}
+			blocks = blocks[index-1 : index]
+		}
+
+		if out == "" {
+			// Print only the code, so it can be piped or redirected
+			for i, block := range blocks {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Println(block.Code)
+			}
+			return
+		}
+
+		if err := writeCodeBlocks(blocks, out, index); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// writeCodeBlocks writes a single block to the file at out, or several into the directory at out
+func writeCodeBlocks(blocks []render.CodeBlock, out string, index int) error {
+	info, err := os.Stat(out)
+	isDir := err == nil && info.IsDir()
+	if len(blocks) == 1 && !isDir && !strings.HasSuffix(out, string(os.PathSeparator)) {
+		if err := os.WriteFile(out, []byte(withNewline(blocks[0].Code)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out, err)
+		}
+		fmt.Printf("📄 Wrote \033[90m%s\033[0m\n", out)
+		return nil
+	}
+
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", out, err)
+	}
+	for i, block := range blocks {
+		number := i + 1
+		if index != 0 {
+			number = index
+		}
+		ext, ok := codeExtensions[strings.ToLower(block.Language)]
+		if !ok {
+			ext = "txt"
+		}
+
+		path := filepath.Join(out, fmt.Sprintf("block-%d.%s", number, ext))
+		if err := os.WriteFile(path, []byte(withNewline(block.Code)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("📄 Wrote \033[90m%s\033[0m\n", path)
+	}
+	return nil
+}
+
+// withNewline ends text with a newline, as files conventionally are
+func withNewline(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
+
+func init() {
+	rootCmd.AddCommand(codeCmd)
+	codeCmd.Flags().IntP("index", "n", 0, "Only extract the nth code block, counting from 1")
+	codeCmd.Flags().StringP("out", "o", "", "Write the code to this file, or directory for several blocks")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var compressCmd = &cobra.Command{
+	Use:   "compress",
+	Short: "Compress large node content, such as pasted documents and long generations",
+	Long: `Compress the content of every node of at least --min-size bytes with gzip, and of new nodes from
+then on, shrinking gardens that contain pasted documents or long generations.
+
+Compression is transparent: nodes read, search and export the same as before. Content that doesn't
+shrink is stored as it is. Encrypted databases can't be compressed. Run again with a different
+--min-size to change the threshold, or use --off to store everything uncompressed again.`,
+	Example: `  bai compress
+  bai compress --min-size 1024
+  bai compress --off`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		off, err := cmd.Flags().GetBool("off")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get off flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		minSize, err := cmd.Flags().GetInt("min-size")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get min-size flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		sizeBefore, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if off {
+			if !database.IsCompressed() {
+				fmt.Println("📄 The database isn't compressed.")
+				return
+			}
+			if err := database.DisableCompression(); err != nil {
+				fmt.Printf("\033[31m❌ Failed to turn off compression: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("\033[32m📄 Every node is stored uncompressed again\033[0m")
+			return
+		}
+
+		if database.CompressMinSize() == minSize {
+			fmt.Printf("🗜️  Content of %s or more is already compressed.\n", formatBytes(int64(minSize)))
+		} else if err := database.EnableCompression(minSize); err != nil {
+			fmt.Printf("\033[31m❌ Failed to compress the database: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		stats, err := database.GetCompressionStats()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		sizeAfter, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[32m🗜️  %d piece(s) of content compressed from %s to %s\033[0m\n", stats.Contents, formatBytes(stats.OriginalBytes), formatBytes(stats.CompressedBytes))
+		fmt.Printf("💾 Database size: %s → %s\n", formatBytes(sizeBefore), formatBytes(sizeAfter))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compressCmd)
+	compressCmd.Flags().Bool("off", false, "Store the content of every node uncompressed again")
+	compressCmd.Flags().Int("min-size", db.DefaultCompressMinSize, "Compress content of at least this many bytes")
+}
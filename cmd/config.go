@@ -0,0 +1,513 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/aarose/bonsai/pkg/redact"
+	"github.com/aarose/bonsai/pkg/share"
+	"github.com/aarose/bonsai/web"
+	"github.com/spf13/cobra"
+)
+
+// configKey describes a setting that can be changed with bai config
+type configKey struct {
+	description string
+	validate    func(value string) error
+}
+
+// configKeys lists every supported setting, keyed by name
+var configKeys = map[string]configKey{
+	"gc.max_age_days": {
+		description: "Trees untouched for this many days are removed by 'bai gc' (0 disables)",
+		validate:    validateNonNegativeInt,
+	},
+	"gc.action": {
+		description: "What 'bai gc' does with expired trees: archive (export to ~/.bonsai/archive, then delete) or delete",
+		validate:    validateOneOf("archive", "delete"),
+	},
+	"gc.max_db_size_mb": {
+		description: "'bai gc' removes the least recently active trees until the database is under this size (0 disables)",
+		validate:    validateNonNegativeInt,
+	},
+	evalJudgeConfigKey: {
+		description: "Model 'bai eval' asks to judge comparisons when --judge isn't given",
+	},
+	gitCaptureConfigKey: {
+		description: "Whether new nodes record the git repository, branch and commit of the directory bai runs in: on or off",
+		validate:    validateOneOf("on", "off"),
+	},
+	shareServiceConfigKey: {
+		description: "Where 'bai share' uploads branches: gist (the default) or the URL of a paste service",
+		validate:    validateShareService,
+	},
+	syncRemoteConfigKey: {
+		description: "Remote 'bai push' and 'bai pull' use when none is given: a database path, bai serve URL, or webdav:// or s3:// snapshot URL",
+	},
+	db.RedactOnCreateConfigKey: {
+		description: "Whether secrets matching the redaction rules are scrubbed from new nodes before they're stored: on or off",
+		validate:    validateOneOf("on", "off"),
+	},
+	db.RedactOnExportConfigKey: {
+		description: "Whether secrets are scrubbed from branches shared with 'bai share' and bundles: on or off",
+		validate:    validateOneOf("on", "off"),
+	},
+	db.RedactDisabledConfigKey: {
+		description: "Comma-separated built-in redaction rules to turn off, e.g. email (see 'bai redact rule list')",
+		validate:    validateBuiltinRules,
+	},
+	db.TemperatureConfigKey: {
+		description: "Sampling temperature responses are generated with, e.g. 0 for the most deterministic (the provider's default if unset)",
+		validate:    validateTemperature,
+	},
+	db.MaxTokensConfigKey: {
+		description: "Longest response to ask models for, in tokens (1000 if unset)",
+		validate:    validatePositiveInt,
+	},
+	db.ModelConfigKey: {
+		description: "Model that responds when no --llm is given and neither the branch nor its tree has one, e.g. to 'bai seed' (none if unset; --no-llm skips it)",
+	},
+	db.IncludeNotesConfigKey: {
+		description: "Whether notes added with 'bai note' are sent to models along with the rest of the conversation: on or off (off if unset)",
+		validate:    validateOneOf("on", "off"),
+	},
+	db.SystemConfigKey: {
+		description: "System prompt sent ahead of every conversation; each response records a hash of the one it used",
+	},
+	concurrencyConfigKey: {
+		description: "How many requests 'bai batch' and 'bai experiment' make at once unless --concurrency is given (4 if unset)",
+		validate:    validatePositiveInt,
+	},
+	providerLimitsConfigKey: {
+		description: "Most requests sent to each provider at once, as provider=N pairs, e.g. anthropic=2,openai=8 (unlimited if unset)",
+		validate:    validateProviderLimits,
+	},
+	db.ProviderRulesConfigKey: {
+		description: "Providers serving models by name prefix, as prefix=provider pairs, e.g. llama=gateway,mistral=ollama; the longest matching prefix wins (built-in naming if unset)",
+		validate:    validateProviderRules,
+	},
+	db.TimeoutConfigKey: {
+		description: "Longest each LLM request may take, including streaming the response, e.g. 5m (2m if unset; --timeout overrides it)",
+		validate:    validatePositiveDuration,
+	},
+	db.ConnectTimeoutConfigKey: {
+		description: "Longest connecting to a model's provider may take before the request fails, e.g. 15s (10s if unset)",
+		validate:    validatePositiveDuration,
+	},
+	db.ResponseCacheConfigKey: {
+		description: "Whether identical generations are answered from a cache of earlier responses instead of the model: on or off (--no-cache bypasses it)",
+		validate:    validateOneOf("on", "off"),
+	},
+	db.MaxContentSizeConfigKey: {
+		description: "Largest message or response a node may hold, in bytes; larger ones are refused (1048576 if unset, 0 for no limit)",
+		validate:    validateNonNegativeInt,
+	},
+	db.AuthorConfigKey: {
+		description: "Name new nodes are attributed to, shown in log, checkout and the web UI (defaults to your login name)",
+	},
+	bonsai.InboxConfigKey: {
+		description: "Node 'bai quick' adds questions below (an \"Inbox\" tree, created on first use, if unset)",
+	},
+	notifyAfterConfigKey: {
+		description: "Show a desktop notification when 'bai batch', 'bai experiment', 'bai run' or a scheduled prompt takes at least this long, e.g. 30s (off if unset)",
+		validate:    validatePositiveDuration,
+	},
+	web.CORSOriginsConfigKey: {
+		description: "Comma-separated origins allowed to call the 'bai visualize' API from other sites, e.g. http://localhost:5173 (* allows any)",
+		validate:    validateOrigins,
+	},
+	web.IngestTokenConfigKey: {
+		description: "Token tools send as a bearer token to log transcripts through POST /api/ingest on 'bai serve', e.g. from openssl rand -hex 24 (ingestion is off if unset, unless the server has --token)",
+	},
+	hooks.ConfigKey(hooks.NodeCreated): {
+		description: "Shell command run when a user node is added; it receives the node as JSON on stdin",
+	},
+	hooks.ConfigKey(hooks.ResponseReceived): {
+		description: "Shell command run when an LLM response is stored; it receives the response node as JSON on stdin",
+	},
+	hooks.ConfigKey(hooks.Prune): {
+		description: "Shell command run after a node is pruned; it receives the pruned node as JSON on stdin",
+	},
+}
+
+// treeConfigKeys lists the settings a single tree can set with --tree, keyed by name
+var treeConfigKeys = map[string]configKey{
+	db.TreeModelSetting: {
+		description: "Model new messages in the tree use when none is given and their parent has none",
+	},
+	db.TreeTemperatureSetting: {
+		description: "Sampling temperature responses in the tree are generated with, overriding " + db.TemperatureConfigKey,
+		validate:    validateTemperature,
+	},
+	db.TreeMaxTokensSetting: {
+		description: "Longest response to ask models for in the tree, in tokens, overriding " + db.MaxTokensConfigKey,
+		validate:    validatePositiveInt,
+	},
+	db.TreeSystemSetting: {
+		description: "System prompt sent ahead of conversations in the tree, overriding " + db.SystemConfigKey,
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change bai settings",
+	Long: `View and change bai settings. Settings are stored in the Bonsai database alongside your trees.
+
+With --tree, view and change the settings of a single conversation tree instead, given the ID of any
+node in it: its default model, temperature, max_tokens and system prompt. They override the global
+settings for that tree and are stored on its root node, so they travel with it.`,
+	Example: `  bai config set generate.temperature 0.7
+  bai config set --tree 3f2a9c1b model claude-3-5-sonnet-20241022
+  bai config list --tree 3f2a9c1b`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := args[0]
+		tree := treeFlag(cmd)
+		requireConfigKey(key, tree != "")
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		var value *string
+		if tree != "" {
+			settings := requireTreeSettings(database, tree)
+			if v, ok := settings[key]; ok {
+				value = &v
+			}
+		} else if value, err = database.GetConfigValue(key); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if value == nil {
+			fmt.Printf("\033[90mℹ️  %s is not set.\033[0m\n", key)
+			return
+		}
+		fmt.Println(*value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		key, value := args[0], args[1]
+		tree := treeFlag(cmd)
+		definition := requireConfigKey(key, tree != "")
+
+		if definition.validate != nil {
+			if err := definition.validate(value); err != nil {
+				fmt.Printf("\033[31m❌ Invalid value for %s: %v\033[0m\n", key, err)
+				os.Exit(1)
+			}
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if tree != "" {
+			rootID := requireTreeRoot(database, tree)
+			if err := database.SetTreeSetting(rootID, key, value); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("⚙️  \033[32mSet\033[0m %s = \033[33m%s\033[0m for tree \033[33m%s\033[0m\n", key, value, shortID(rootID))
+			return
+		}
+
+		if err := database.SetConfigValue(key, value); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⚙️  \033[32mSet\033[0m %s = \033[33m%s\033[0m\n", key, value)
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Reset a setting to its default",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := args[0]
+		tree := treeFlag(cmd)
+		requireConfigKey(key, tree != "")
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if tree != "" {
+			rootID := requireTreeRoot(database, tree)
+			if err := database.UnsetTreeSetting(rootID, key); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("⚙️  \033[32mUnset\033[0m %s for tree \033[33m%s\033[0m\n", key, shortID(rootID))
+			return
+		}
+
+		if err := database.DeleteConfigValue(key); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⚙️  \033[32mUnset\033[0m %s\n", key)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings and their current values",
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		tree := treeFlag(cmd)
+		definitions := configKeys
+		var settings map[string]string
+		if tree != "" {
+			definitions = treeConfigKeys
+			settings = requireTreeSettings(database, tree)
+		}
+
+		for _, key := range sortedKeys(definitions) {
+			var value *string
+			if tree != "" {
+				if v, ok := settings[key]; ok {
+					value = &v
+				}
+			} else if value, err = database.GetConfigValue(key); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+
+			if value != nil {
+				fmt.Printf("%s = \033[33m%s\033[0m\n", key, *value)
+			} else {
+				fmt.Printf("%s \033[90m(not set)\033[0m\n", key)
+			}
+			fmt.Printf("  \033[90m%s\033[0m\n", definitions[key].description)
+		}
+	},
+}
+
+// requireConfigKey returns the definition of a setting, or of a tree's setting with tree, exiting if
+// the key is unknown
+func requireConfigKey(key string, tree bool) configKey {
+	if tree {
+		definition, ok := treeConfigKeys[key]
+		if !ok {
+			fmt.Printf("\033[31m❌ Unknown tree setting: %s. Trees can set %s.\033[0m\n", key, strings.Join(sortedKeys(treeConfigKeys), ", "))
+			os.Exit(1)
+		}
+		return definition
+	}
+
+	definition, ok := configKeys[key]
+	if !ok {
+		fmt.Printf("\033[31m❌ Unknown setting: %s. Use 'bai config list' to see available settings.\033[0m\n", key)
+		os.Exit(1)
+	}
+	return definition
+}
+
+// sortedKeys returns the names of the given settings in order
+func sortedKeys(definitions map[string]configKey) []string {
+	keys := make([]string, 0, len(definitions))
+	for key := range definitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// treeFlag returns the node given with --tree, or "" for the global settings
+func treeFlag(cmd *cobra.Command) string {
+	tree, err := cmd.Flags().GetString("tree")
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get tree flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	return tree
+}
+
+// requireTreeRoot returns the ID of the root of the tree holding a node, given by full or
+// abbreviated ID, exiting if there's no such node
+func requireTreeRoot(database *db.Database, nodeID string) string {
+	nodeID, err := database.ResolveNodeID(nodeID)
+	if err != nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	rootID, err := database.GetRootID(nodeID)
+	if err != nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	return rootID
+}
+
+// requireTreeSettings returns the settings of the tree holding a node, exiting if there's no such node
+func requireTreeSettings(database *db.Database, nodeID string) map[string]string {
+	_, settings, err := database.GetTreeSettings(requireTreeRoot(database, nodeID))
+	if err != nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	return settings
+}
+
+// validateNonNegativeInt checks that a value is a whole number of zero or more
+func validateNonNegativeInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("must be a whole number of 0 or more")
+	}
+	return nil
+}
+
+// validatePositiveInt checks that a value is a whole number of one or more
+func validatePositiveInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("must be a whole number of 1 or more")
+	}
+	return nil
+}
+
+// validatePositiveDuration checks that a value is a duration longer than zero, such as 90s or 5m
+func validatePositiveDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("must be a duration longer than zero, such as 90s or 5m")
+	}
+	return nil
+}
+
+// validateTemperature accepts a sampling temperature between 0 and 2
+func validateTemperature(value string) error {
+	t, err := strconv.ParseFloat(value, 64)
+	if err != nil || t < 0 || t > 2 {
+		return fmt.Errorf("must be a number from 0 to 2")
+	}
+	return nil
+}
+
+// validateProviderLimits accepts comma-separated provider=N pairs
+func validateProviderLimits(value string) error {
+	_, err := llm.ParseProviderLimits(value)
+	return err
+}
+
+// validateProviderRules accepts comma-separated prefix=provider pairs
+func validateProviderRules(value string) error {
+	_, err := llm.ParseProviderRules(value)
+	return err
+}
+
+// validateOrigins accepts a comma-separated list of CORS origins
+func validateOrigins(value string) error {
+	for _, origin := range web.ParseOrigins(value) {
+		if err := web.ValidateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateShareService accepts gist or an http(s) paste service URL
+func validateShareService(value string) error {
+	if value == share.GistService || strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return nil
+	}
+	return fmt.Errorf("must be %s or an http(s) URL", share.GistService)
+}
+
+// validateBuiltinRules accepts a comma-separated list of built-in redaction rule names
+func validateBuiltinRules(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" && !redact.IsBuiltin(name) {
+			return fmt.Errorf("%s isn't a built-in rule", name)
+		}
+	}
+	return nil
+}
+
+// validateOneOf returns a validator that only accepts the given values
+func validateOneOf(allowed ...string) func(string) error {
+	return func(value string) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", allowed)
+	}
+}
+
+// configString returns a setting's value, or fallback if it isn't set
+func configString(database *db.Database, key, fallback string) (string, error) {
+	value, err := database.GetConfigValue(key)
+	if err != nil {
+		return "", err
+	}
+	if value == nil || *value == "" {
+		return fallback, nil
+	}
+	return *value, nil
+}
+
+// configInt returns a setting's value as an integer, or fallback if it isn't set
+func configInt(database *db.Database, key string, fallback int) (int, error) {
+	value, err := configString(database, key, "")
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q is not a number", key, value)
+	}
+	return n, nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.PersistentFlags().String("tree", "", "View or change the settings of the tree holding this node instead of the global ones")
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var cronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Add prompts to trees on a schedule",
+	Long: `Schedule recurring prompts, such as a weekly review or a daily summary. Each time a schedule comes
+due, its prompt is added as a new branch below its node and the model's reply is generated, with the
+branch above as context. The current working node isn't moved.
+
+Schedules are cron expressions in local time: minute, hour, day of month, month and day of week, as
+in "0 9 * * 1" for 9am every Monday, or @hourly, @daily, @weekly, @monthly or @yearly. They're run
+by 'bai serve' while it's up; runs that come due while it's down are skipped.`,
+	Example: `  # Ask for a weekly review of a tree every Monday at 9am
+  bai template save weekly-review "Summarize what we decided this week about {{topic}}"
+  bai cron add "0 9 * * 1" --tree 3f2a9c1b --template weekly-review --var topic=pricing
+
+  # Or give the prompt itself
+  bai cron add @daily --tree 3f2a9c1b --prompt "Any updates on this?" --llm gpt-4o
+
+  bai cron list
+  bai cron run 7c21
+  bai cron remove 7c21`,
+}
+
+var cronAddCmd = &cobra.Command{
+	Use:   "add <schedule>",
+	Short: "Schedule a prompt",
+	Long: `Schedule a prompt, given with --prompt or rendered from a saved template with --template and --var.
+Each run adds it below the node given with --tree, or the current working node. The model is taken
+from --llm, or inherited like any new message's.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		schedule := &bonsai.Schedule{Spec: args[0]}
+		var err error
+		if schedule.NodeID, err = cmd.Flags().GetString("tree"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get tree flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if schedule.Template, err = cmd.Flags().GetString("template"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get template flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if schedule.Prompt, err = cmd.Flags().GetString("prompt"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get prompt flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if schedule.Model, err = cmd.Flags().GetString("llm"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		assignments, err := cmd.Flags().GetStringArray("var")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get var flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		for _, assignment := range assignments {
+			key, value, ok := strings.Cut(assignment, "=")
+			if !ok || key == "" {
+				fmt.Printf("\033[31m❌ Invalid --var %q: expected name=value\033[0m\n", assignment)
+				os.Exit(1)
+			}
+			if schedule.Vars == nil {
+				schedule.Vars = make(map[string]string)
+			}
+			schedule.Vars[key] = value
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		if schedule.NodeID == "" {
+			current, err := session.Current()
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v; pass --tree to choose the node to add the prompt below\033[0m\n", err)
+				os.Exit(1)
+			}
+			schedule.NodeID = current.ID
+		}
+		if err := session.AddSchedule(schedule); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⏰ \033[32mScheduled\033[0m \033[33m%s\033[0m below \033[33m%s\033[0m\n", shortID(schedule.ID), shortID(schedule.NodeID))
+		fmt.Printf("   Next run: \033[90m%s\033[0m\n", bonsai.NextRun(schedule, time.Now()).Format("Mon 2006-01-02 15:04"))
+		fmt.Println("   \033[90mSchedules run while 'bai serve' is up.\033[0m")
+	},
+}
+
+var cronListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled prompts with their next and latest runs",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		schedules, err := database.GetSchedules()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(schedules) == 0 {
+			fmt.Println("⏰ No scheduled prompts. Add one with 'bai cron add'.")
+			return
+		}
+
+		fmt.Printf("⏰ Found %d scheduled prompt(s):\n\n", len(schedules))
+		for _, schedule := range schedules {
+			fmt.Printf("• \033[33m%s\033[0m \033[36m%s\033[0m below \033[33m%s\033[0m\n", shortID(schedule.ID), schedule.Spec, shortID(schedule.NodeID))
+			fmt.Printf("  💬 \033[90m%s\033[0m\n", describeSchedulePrompt(schedule))
+			if schedule.Model != "" {
+				fmt.Printf("  🧠 Model: \033[35m%s\033[0m\n", schedule.Model)
+			}
+			fmt.Printf("  ⏭️  Next run: \033[90m%s\033[0m\n", bonsai.NextRun(schedule, time.Now()).Format("Mon 2006-01-02 15:04"))
+			if schedule.LastRun != 0 {
+				fmt.Printf("  🕒 Last run: \033[90m%s\033[0m", formatAge(schedule.LastRun))
+				if schedule.LastNode != "" {
+					fmt.Printf(" \033[90madded\033[0m \033[33m%s\033[0m", shortID(schedule.LastNode))
+				}
+				fmt.Println()
+			}
+			if schedule.LastError != "" {
+				fmt.Printf("  \033[31m❌ %s\033[0m\n", schedule.LastError)
+			}
+		}
+	},
+}
+
+// describeSchedulePrompt summarizes what a schedule adds on one line
+func describeSchedulePrompt(schedule *db.Schedule) string {
+	if schedule.Template == "" {
+		return truncateContent(strings.Join(strings.Fields(schedule.Prompt), " "), 60)
+	}
+	description := "template " + schedule.Template
+	for key, value := range schedule.Vars {
+		description += fmt.Sprintf(" %s=%s", key, truncateContent(value, 20))
+	}
+	return description
+}
+
+var cronRemoveCmd = &cobra.Command{
+	Use:   "remove <schedule-id>",
+	Short: "Remove a scheduled prompt",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		schedule, err := database.GetSchedule(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if _, err := database.DeleteSchedule(schedule.ID); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  \033[32mRemoved schedule\033[0m \033[33m%s\033[0m\n", shortID(schedule.ID))
+	},
+}
+
+var cronRunCmd = &cobra.Command{
+	Use:   "run <schedule-id>",
+	Short: "Run a scheduled prompt now, to try it out",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		schedule, err := database.GetSchedule(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if _, err := database.ClaimScheduleRun(schedule.ID, time.Now()); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		turn, err := newSession(database).RunSchedule(cmd.Context(), schedule)
+		if turn != nil {
+			fmt.Printf("🔄 \033[32mAdded\033[0m \033[33m%s\033[0m below \033[33m%s\033[0m\n", turn.Message.ID, shortID(schedule.NodeID))
+			if turn.Response != nil {
+				fmt.Printf("🤖 \033[32mResponse:\033[0m \033[33m%s\033[0m\n%s\n", turn.Response.ID, turn.Response.Content)
+			}
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runSchedules runs the scheduled prompts of the database at dbPath until ctx is cancelled, logging each run
+func runSchedules(ctx context.Context, dbPath string) error {
+	database, err := db.NewDatabase(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database for schedules: %w", err)
+	}
+	defer database.Close()
+	if err := database.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize database for schedules: %w", err)
+	}
+
+	return newSession(database).RunScheduler(ctx, bonsai.SchedulerOptions{
+		OnRun: func(schedule *bonsai.Schedule, turn *bonsai.Turn, err error) {
+			switch {
+			case schedule == nil:
+				log.Printf("Error reading schedules: %v", err)
+			case err != nil:
+				log.Printf("Scheduled prompt %s failed: %v", shortID(schedule.ID), err)
+			default:
+				log.Printf("Scheduled prompt %s added %s", shortID(schedule.ID), shortID(turn.Message.ID))
+			}
+			if turn == nil {
+				return
+			}
+			// The prompt node is stored as a run starts, so it dates the generation
+			message := fmt.Sprintf("Schedule %s added %s", shortID(schedule.ID), shortID(turn.Message.ID))
+			if err != nil {
+				message = fmt.Sprintf("Schedule %s failed: %v", shortID(schedule.ID), err)
+			}
+			if err := notifyIfSlow(database, time.Unix(turn.Message.CreatedAt, 0), "bai scheduled prompt finished", message); err != nil {
+				log.Printf("Couldn't show a notification: %v", err)
+			}
+		},
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.AddCommand(cronAddCmd, cronListCmd, cronRemoveCmd, cronRunCmd)
+
+	cronAddCmd.Flags().String("tree", "", "Node to add the prompt below on each run (defaults to the current working node)")
+	cronAddCmd.Flags().String("template", "", "Saved template to render the prompt from")
+	cronAddCmd.Flags().StringArray("var", nil, "Value of a template variable, as name=value (repeatable)")
+	cronAddCmd.Flags().String("prompt", "", "The prompt to add, instead of a template")
+	cronAddCmd.Flags().StringP("llm", "l", "", "LLM model to answer with (defaults to the node's model)")
+	cronAddCmd.MarkFlagsMutuallyExclusive("template", "prompt")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var dedupContentCmd = &cobra.Command{
+	Use:   "dedup-content",
+	Short: "Store content shared by several nodes only once",
+	Long: `Store the content of nodes that share it, such as cherry-picked nodes and cloned or forked
+branches, only once, with each node referring to the shared copy. New nodes are stored the same way
+from then on. Content shorter than a few hundred bytes stays in its node, since a reference would
+take up as much space.
+
+Nothing changes for any command: nodes read the same as before. Encrypted databases can't be
+deduplicated. Use --off to store a copy in every node again. To clean up branches that repeat
+each other, see 'bai dedupe'.`,
+	Example: `  bai dedup-content
+  bai dedup-content --off`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		off, err := cmd.Flags().GetBool("off")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get off flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		sizeBefore, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if off {
+			if !database.IsDeduplicated() {
+				fmt.Println("📄 The database isn't deduplicated.")
+				return
+			}
+			if err := database.DisableDedup(); err != nil {
+				fmt.Printf("\033[31m❌ Failed to turn off deduplication: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("\033[32m📄 Every node stores its own copy of its content again\033[0m")
+			return
+		}
+
+		if database.IsDeduplicated() {
+			fmt.Println("📦 The database is already deduplicated.")
+		} else if err := database.EnableDedup(); err != nil {
+			fmt.Printf("\033[31m❌ Failed to deduplicate the database: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		stats, err := database.GetDedupStats()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		sizeAfter, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[32m📦 %d node(s) share %d stored piece(s) of content, saving %s\033[0m\n", stats.References, stats.Contents, formatBytes(stats.SavedBytes))
+		fmt.Printf("💾 Database size: %s → %s\n", formatBytes(sizeBefore), formatBytes(sizeAfter))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupContentCmd)
+	dedupContentCmd.Flags().Bool("off", false, "Store a copy of the content in every node again")
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [node-id]",
+	Short: "Find nearly identical sibling branches and prune or merge them",
+	Long: `Find sibling branches that start with nearly the same node, as heavy use of regenerate, replay
+and compare leaves behind, and clean them up. Similarity is the share of three-word runs two sibling
+nodes have in common; --threshold sets how similar counts as a duplicate. Only the siblings themselves
+are compared, not what followed them.
+
+Of each set of duplicates the branch with the most nodes is kept, then the oldest. For each duplicate
+you're asked whether to:
+
+  prune  delete the duplicate branch and everything in it
+  merge  delete only its first node, moving any follow-ups below the branch that's kept
+  skip   leave it alone
+
+Pass --prune or --merge to do the same to every duplicate without asking. Without a terminal to ask
+on, and without either flag, duplicates are only listed. If the current working node is removed, it
+moves to the branch that's kept.
+
+Searches the tree holding the current working node, the subtree of the given node, or with --all every
+tree. This is unrelated to 'bai dedup-content', which stores identical content only once without
+changing any branches.`,
+	Example: `  bai dedupe
+  bai dedupe 3f2a9c1b --threshold 0.8
+  bai dedupe --all --merge`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get all flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		threshold, err := cmd.Flags().GetFloat64("threshold")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get threshold flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if threshold <= 0 || threshold > 1 {
+			fmt.Printf("\033[31m❌ --threshold must be above 0 and at most 1.\033[0m\n")
+			os.Exit(1)
+		}
+		pruneAll, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get prune flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		mergeAll, err := cmd.Flags().GetBool("merge")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get merge flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if all && len(args) > 0 {
+			fmt.Printf("\033[31m❌ Pass a node ID or --all, not both.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		var scope []string
+		var scopeName string
+		switch {
+		case all:
+			roots, err := database.GetRootNodes()
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			for _, root := range roots {
+				scope = append(scope, root.ID)
+			}
+			scopeName = "all trees"
+		case len(args) > 0:
+			node, err := session.Node(args[0])
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			scope = []string{node.ID}
+			scopeName = "the subtree of " + shortID(node.ID)
+		default:
+			rootID, err := session.CurrentRootID()
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			if rootID == "" {
+				fmt.Println("🌱 No current working node set. Pass a node ID, or --all to search every tree.")
+				return
+			}
+			scope = []string{rootID}
+			scopeName = "the current tree"
+		}
+
+		duplicates, err := session.FindDuplicateBranches(scope, threshold)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(duplicates) == 0 {
+			fmt.Printf("\033[32m✅ No duplicate branches in %s\033[0m\n", scopeName)
+			return
+		}
+		fmt.Printf("👯 %d duplicate branch(es) in %s:\n", len(duplicates), scopeName)
+
+		interactive := !pruneAll && !mergeAll && isTerminal(os.Stdin)
+		reader := bufio.NewReader(os.Stdin)
+		pruned, merged := 0, 0
+		for _, duplicate := range duplicates {
+			fmt.Println()
+			printDuplicateBranch(duplicate)
+
+			// An earlier prune may have deleted this pair along with its branch
+			if _, err := database.GetNodeByID(duplicate.Duplicate.ID); err != nil {
+				fmt.Println("   \033[90mAlready removed with an earlier branch.\033[0m")
+				continue
+			}
+			if _, err := database.GetNodeByID(duplicate.Keep.ID); err != nil {
+				fmt.Println("   \033[90mThe branch it repeats was removed with an earlier branch.\033[0m")
+				continue
+			}
+
+			action := ""
+			switch {
+			case pruneAll:
+				action = "p"
+			case mergeAll:
+				action = "m"
+			case interactive:
+				action = askDedupeAction(reader)
+			}
+
+			switch action {
+			case "p":
+				result, err := session.PruneDuplicate(duplicate)
+				if err != nil && result == nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+				if err != nil {
+					fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+				}
+				pruned++
+				fmt.Printf("   ✂️  Pruned %d node(s)\n", result.Deleted)
+			case "m":
+				moved, err := session.MergeDuplicate(duplicate)
+				if err != nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+				merged++
+				fmt.Printf("   🔀 Merged into %s, moving %d child(ren)\n", shortID(duplicate.Keep.ID), moved)
+			case "q":
+				fmt.Println("Stopped.")
+				printDedupeSummary(pruned, merged)
+				return
+			}
+		}
+
+		fmt.Println()
+		if !pruneAll && !mergeAll && !interactive {
+			fmt.Println("\033[90mℹ️  Nothing changed. Pass --prune or --merge to clean these up, or run in a terminal to choose for each.\033[0m")
+			return
+		}
+		printDedupeSummary(pruned, merged)
+	},
+}
+
+// askDedupeAction asks what to do with a duplicate until given p, m, s or q, or their full words,
+// returning the letter. An empty answer skips the duplicate and the end of input quits.
+func askDedupeAction(reader *bufio.Reader) string {
+	for {
+		fmt.Printf("   \033[1m[p]rune, [m]erge, [s]kip or [q]uit?\033[0m ")
+		response, err := reader.ReadString('\n')
+		if err == io.EOF {
+			fmt.Println()
+			return "q"
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to read input: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		switch strings.TrimSpace(strings.ToLower(response)) {
+		case "p", "prune":
+			return "p"
+		case "m", "merge":
+			return "m"
+		case "", "s", "skip":
+			return "s"
+		case "q", "quit":
+			return "q"
+		}
+	}
+}
+
+// printDuplicateBranch shows a duplicate branch next to the branch it repeats
+func printDuplicateBranch(d bonsai.DuplicateBranch) {
+	fmt.Printf("   \033[33m%s\033[0m \033[90m(%d node(s))\033[0m %s\n", shortID(d.Duplicate.ID), d.Size, truncateContent(strings.ReplaceAll(d.Duplicate.Content, "\n", " "), 60))
+	fmt.Printf("   \033[90m%.0f%% like\033[0m \033[32m%s\033[0m \033[90m(%d node(s))\033[0m %s\n", d.Similarity*100, shortID(d.Keep.ID), d.KeepSize, truncateContent(strings.ReplaceAll(d.Keep.Content, "\n", " "), 60))
+}
+
+// printDedupeSummary reports how many duplicates were pruned and merged
+func printDedupeSummary(pruned, merged int) {
+	if pruned == 0 && merged == 0 {
+		fmt.Println("\033[90mℹ️  Nothing changed.\033[0m")
+		return
+	}
+	fmt.Printf("\033[32m✅ Pruned %d and merged %d duplicate branch(es)\033[0m\n", pruned, merged)
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+	dedupeCmd.Flags().BoolP("all", "a", false, "Search every tree")
+	dedupeCmd.Flags().Float64("threshold", bonsai.DefaultDuplicateThreshold, "How similar branches must be to count as duplicates, from 0 to 1")
+	dedupeCmd.Flags().Bool("prune", false, "Prune every duplicate without asking")
+	dedupeCmd.Flags().Bool("merge", false, "Merge every duplicate into the branch it repeats without asking")
+	dedupeCmd.MarkFlagsMutuallyExclusive("prune", "merge")
+}
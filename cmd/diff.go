@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <node-a> <node-b>",
+	Short: "Show a colorized diff between two nodes or branches",
+	Long: `Show a colorized line diff between the contents of two nodes.
+
+With --branch, the complete root-to-node transcripts of both nodes are compared instead,
+which is handy for comparing sibling regenerations along with everything that led to them.`,
+	Example: `  # Compare two sibling responses
+  bai diff <node-a> <node-b>
+
+  # Compare the full transcripts leading to each node
+  bai diff <node-a> <node-b> --branch`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		nodeAID, nodeBID := args[0], args[1]
+
+		branchMode, err := cmd.Flags().GetBool("branch")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get branch flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		contextLines, err := cmd.Flags().GetInt("unified")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get unified flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Expand abbreviated node IDs such as those shown by 'bai recent'
+		nodeAID, err = database.ResolveNodeID(nodeAID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		nodeBID, err = database.ResolveNodeID(nodeBID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		var textA, textB string
+		if branchMode {
+			historyA, err := database.GetConversationHistory(nodeAID)
+			if err != nil {
+				fmt.Printf("\033[31m❌ Failed to get branch %s: %v\033[0m\n", nodeAID, err)
+				os.Exit(1)
+			}
+			historyB, err := database.GetConversationHistory(nodeBID)
+			if err != nil {
+				fmt.Printf("\033[31m❌ Failed to get branch %s: %v\033[0m\n", nodeBID, err)
+				os.Exit(1)
+			}
+			textA, textB = formatTranscript(historyA), formatTranscript(historyB)
+		} else {
+			nodeA, err := database.GetNodeByID(nodeAID)
+			if err != nil {
+				fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			nodeB, err := database.GetNodeByID(nodeBID)
+			if err != nil {
+				fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			textA, textB = nodeA.Content, nodeB.Content
+		}
+
+		ops := diffLines(strings.Split(textA, "\n"), strings.Split(textB, "\n"))
+		if !hasChanges(ops) {
+			fmt.Println("\033[90mℹ️  No differences found.\033[0m")
+			return
+		}
+
+		fmt.Printf("\033[1m--- %s\033[0m\n", nodeAID)
+		fmt.Printf("\033[1m+++ %s\033[0m\n", nodeBID)
+		printUnifiedDiff(ops, contextLines)
+	},
+}
+
+// diffOp is a single line in a line-based diff
+type diffOp struct {
+	kind byte // ' ' for unchanged, '-' for removed, '+' for added
+	line string
+}
+
+// diffLines computes a line diff between a and b using the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] holds the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+
+	return ops
+}
+
+// hasChanges reports whether a diff contains any added or removed lines
+func hasChanges(ops []diffOp) bool {
+	for _, op := range ops {
+		if op.kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// printUnifiedDiff prints a colorized diff, keeping only contextLines unchanged lines around each change
+// A negative contextLines prints every line
+func printUnifiedDiff(ops []diffOp, contextLines int) {
+	// Mark which lines are close enough to a change to be shown
+	visible := make([]bool, len(ops))
+	for i, op := range ops {
+		if contextLines < 0 {
+			visible[i] = true
+			continue
+		}
+		if op.kind == ' ' {
+			continue
+		}
+		for k := i - contextLines; k <= i+contextLines; k++ {
+			if k >= 0 && k < len(ops) {
+				visible[k] = true
+			}
+		}
+	}
+
+	skipped := false
+	for i, op := range ops {
+		if !visible[i] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			fmt.Println("\033[36m@@ ... @@\033[0m")
+			skipped = false
+		}
+		switch op.kind {
+		case '-':
+			fmt.Printf("\033[31m-%s\033[0m\n", op.line)
+		case '+':
+			fmt.Printf("\033[32m+%s\033[0m\n", op.line)
+		default:
+			fmt.Printf("\033[90m %s\033[0m\n", op.line)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolP("branch", "b", false, "Diff the entire root-to-node transcripts instead of single nodes")
+	diffCmd.Flags().IntP("unified", "U", 3, "Number of unchanged context lines to show around changes (-1 for all)")
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the content of your conversations in the database file",
+	Long: `Encrypt the content and metadata of every node with AES-256-GCM, so conversation trees can't be read
+from the database file, its backups or synced copies without the key.
+
+The key comes from a passphrase, read from BONSAI_PASSPHRASE or prompted for, or from a key file given
+with --key-file, which is created with a new random key if it doesn't exist. Every bai command then
+needs the same BONSAI_PASSPHRASE set, or the key file, to open the database. Set BONSAI_KEY_FILE if
+the key file moves. There is no way to recover the conversations if the key is lost.
+
+The shape of the trees, models, timestamps, templates and settings stay readable. Searching an
+encrypted database decrypts every node, so it is slower on large gardens. Databases you push to or pull
+from are encrypted or not independently of this one, and are unlocked the same way. Use 'bai decrypt'
+to go back to plaintext.`,
+	Example: `  BONSAI_PASSPHRASE='correct horse battery staple' bai encrypt
+  bai encrypt --key-file ~/.bonsai/key`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		keyFile, err := cmd.Flags().GetString("key-file")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get key-file flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if database.IsEncrypted() {
+			fmt.Println("🔒 The database is already encrypted.")
+			return
+		}
+
+		kdf, secret := db.KDFPassphrase, os.Getenv(db.PassphraseEnv)
+		if keyFile != "" {
+			kdf, secret = db.KDFKeyFile, keyFile
+			created, err := ensureKeyFile(keyFile)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			if created {
+				fmt.Printf("🔑 Created a new key in \033[90m%s\033[0m. Back it up: without it your conversations can't be read.\n", keyFile)
+			}
+		} else if secret == "" {
+			if secret, err = promptNewPassphrase(); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := database.EnableEncryption(kdf, secret); err != nil {
+			fmt.Printf("\033[31m❌ Failed to encrypt the database: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("\033[32m🔒 Encrypted the content of every node\033[0m")
+		if kdf == db.KDFPassphrase {
+			fmt.Printf("\033[90mSet %s to this passphrase to use bai from now on.\033[0m\n", db.PassphraseEnv)
+		}
+	},
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store your conversations in plaintext again",
+	Long: `Decrypt every node encrypted with 'bai encrypt' and stop encrypting new ones. The database has to be
+unlocked with BONSAI_PASSPHRASE or the key file as usual.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if !database.IsEncrypted() {
+			fmt.Println("🔓 The database isn't encrypted.")
+			return
+		}
+
+		if err := database.DisableEncryption(); err != nil {
+			fmt.Printf("\033[31m❌ Failed to decrypt the database: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\033[32m🔓 Decrypted every node; the database is stored in plaintext again\033[0m")
+	},
+}
+
+// ensureKeyFile creates a key file holding a new random key unless the file already exists
+func ensureKeyFile(path string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return false, fmt.Errorf("failed to generate key: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return false, fmt.Errorf("failed to create key file: %w", err)
+	}
+	if _, err := fmt.Fprintln(file, base64.StdEncoding.EncodeToString(key)); err != nil {
+		file.Close()
+		return false, fmt.Errorf("failed to write key file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return false, fmt.Errorf("failed to write key file: %w", err)
+	}
+	return true, nil
+}
+
+// promptNewPassphrase asks for a passphrase twice on the terminal, without echoing it where stty is available
+func promptNewPassphrase() (string, error) {
+	if !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("no terminal to ask for a passphrase on: set %s or pass --key-file", db.PassphraseEnv)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if stty("-echo") == nil {
+		defer stty("echo")
+	}
+
+	fmt.Print("🔑 New passphrase: ")
+	passphrase, err := reader.ReadString('\n')
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	fmt.Print("🔑 Repeat passphrase: ")
+	repeated, err := reader.ReadString('\n')
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	passphrase = strings.TrimRight(passphrase, "\r\n")
+	if passphrase != strings.TrimRight(repeated, "\r\n") {
+		return "", errors.New("the passphrases don't match")
+	}
+	if passphrase == "" {
+		return "", errors.New("the passphrase is empty")
+	}
+	return passphrase, nil
+}
+
+// stty changes a setting of the terminal on stdin
+func stty(setting string) error {
+	command := exec.Command("stty", setting)
+	command.Stdin = os.Stdin
+	return command.Run()
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+	encryptCmd.Flags().String("key-file", "", "Encrypt with the key in this file instead of a passphrase, creating it if needed")
+}
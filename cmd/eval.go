@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+// evalJudgeConfigKey is the setting holding the default judge model for 'bai eval'
+const evalJudgeConfigKey = "eval.judge"
+
+// defaultEvalCriteria is what 'bai eval' compares on unless --criteria is given
+const defaultEvalCriteria = "accuracy,helpfulness,clarity"
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <node-a> <node-b>",
+	Short: "Ask a judge model to compare two responses",
+	Long: `Ask a judge model which of two nodes is better on each of the given criteria, and overall. The
+verdict is stored as an annotation on both nodes, and 'bai stats' totals the wins, losses and ties
+of each model across all evaluations.
+
+The judge is given with --judge, or the eval.judge setting.`,
+	Example: `  bai eval 3f2a9c1b 7d41e0aa --criteria "accuracy,tone" --judge gpt-4o
+  bai config set eval.judge claude-3-5-sonnet`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		judge, err := cmd.Flags().GetString("judge")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get judge flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		criteriaList, err := cmd.Flags().GetString("criteria")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get criteria flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		criteria := bonsai.ParseCriteria(criteriaList)
+		if len(criteria) == 0 {
+			fmt.Printf("\033[31m❌ Give at least one criterion with --criteria.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if judge == "" {
+			value, err := database.GetConfigValue(evalJudgeConfigKey)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			if value == nil || strings.TrimSpace(*value) == "" {
+				fmt.Printf("\033[31m❌ No judge model. Use --judge, or set one with 'bai config set %s <model>'.\033[0m\n", evalJudgeConfigKey)
+				os.Exit(1)
+			}
+			judge = strings.TrimSpace(*value)
+		}
+
+		session := newSession(database)
+		a, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		b, err := session.Node(args[1])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if a.ID == b.ID {
+			fmt.Printf("\033[31m❌ Can't compare a node with itself.\033[0m\n")
+			os.Exit(1)
+		}
+
+		fmt.Printf("⚖️  Asking \033[35m%s\033[0m to compare \033[33m%s\033[0m (A) and \033[33m%s\033[0m (B)...\n", judge, shortID(a.ID), shortID(b.ID))
+
+		ctx, cancel := context.WithTimeout(context.Background(), session.Timeout())
+		defer cancel()
+
+		verdict, err := session.Evaluate(ctx, a, b, judge, criteria)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		for _, criterion := range criteria {
+			fmt.Printf("  %-16s %s\n", criterion, describeWinner(verdict.Winners[criterion], a, b))
+		}
+		fmt.Printf("  \033[1m%-16s %s\033[0m\n", "overall", describeWinner(verdict.Overall, a, b))
+		if verdict.Reason != "" {
+			fmt.Printf("\n💬 \033[90m%s\033[0m\n", verdict.Reason)
+		}
+		fmt.Printf("\n📝 Verdict saved on both nodes.\n")
+	},
+}
+
+// describeWinner names the winning node of a comparison
+func describeWinner(winner string, a, b *bonsai.Node) string {
+	switch winner {
+	case "a":
+		return fmt.Sprintf("A \033[33m%s\033[0m%s", shortID(a.ID), modelSuffix(a))
+	case "b":
+		return fmt.Sprintf("B \033[33m%s\033[0m%s", shortID(b.ID), modelSuffix(b))
+	case "tie":
+		return "tie"
+	default:
+		return "\033[90m(no verdict)\033[0m"
+	}
+}
+
+// modelSuffix formats a node's model for appending to its ID, or "" if it has none
+func modelSuffix(node *bonsai.Node) string {
+	if node.Model == nil || *node.Model == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (\033[35m%s\033[0m)", *node.Model)
+}
+
+func init() {
+	rootCmd.AddCommand(evalCmd)
+	evalCmd.Flags().String("judge", "", "Model that judges the comparison (defaults to the eval.judge setting)")
+	evalCmd.Flags().String("criteria", defaultEvalCriteria, "Comma-separated criteria to compare on")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var excludeCmd = &cobra.Command{
+	Use:   "exclude <node-id>...",
+	Short: "Keep nodes out of what's sent to models",
+	Long: `Mark nodes as excluded from context: they stay in the tree, shown by 'bai log' and 'bai show',
+but are never sent to models, so a message holding a secret or a tangent that's sending the
+conversation off course no longer affects the replies below it. Everything below an excluded node
+is still sent. Pass --undo to include the nodes again.`,
+	Example: `  bai exclude 3f2a9c1b
+  bai exclude --undo 3f2a9c1b`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		undo, err := cmd.Flags().GetBool("undo")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get undo flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		for _, nodeID := range args {
+			node, err := session.Exclude(nodeID, !undo)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+
+			preview := truncateContent(strings.ReplaceAll(node.Content, "\n", " "), 60)
+			if undo {
+				fmt.Printf("✅ \033[32mIncluded\033[0m \033[33m%s\033[0m in context again: \033[90m%s\033[0m\n", shortID(node.ID), preview)
+			} else {
+				fmt.Printf("🚫 \033[32mExcluded\033[0m \033[33m%s\033[0m from context: \033[90m%s\033[0m\n", shortID(node.ID), preview)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(excludeCmd)
+	excludeCmd.Flags().Bool("undo", false, "Include the nodes in context again")
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+var experimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "Compare prompt variants across models as sibling branches",
+	Long: `Run every prompt variant against every model as sibling branches below the current working node,
+then print a Markdown report comparing the responses. The current working node stays where it is.
+
+Variants are given with --prompt, or one per line with --prompts-file. With --judge, another model
+scores each response from 1 to 10 on --criteria, the score is stored with the response, and the
+report ranks variants and models by their average score.
+
+Responses and verdicts are requested concurrently, --concurrency or the generate.concurrency setting
+at a time, with generate.provider_limits capping the requests sent to any one provider; in a
+terminal progress bars track both stages.`,
+	Example: `  bai experiment --prompt "Explain monads" --prompt "Explain monads to a five-year-old" \
+    --llm gpt-4o --llm claude-3-haiku --judge gpt-4o
+  bai experiment --prompts-file variants.txt --llm gpt-4o --llm gpt-4o-mini --report report.md`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		variants, err := cmd.Flags().GetStringArray("prompt")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get prompt flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		promptsFile, err := cmd.Flags().GetString("prompts-file")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get prompts-file flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		models, err := cmd.Flags().GetStringArray("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		judge, err := cmd.Flags().GetString("judge")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get judge flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		criteria, err := cmd.Flags().GetString("criteria")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get criteria flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		reportPath, err := cmd.Flags().GetString("report")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get report flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		concurrency, err := cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get concurrency flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if concurrency < 0 {
+			fmt.Printf("\033[31m❌ --concurrency can't be negative.\033[0m\n")
+			os.Exit(1)
+		}
+
+		if promptsFile != "" {
+			fromFile, err := bonsai.ReadBatchInputs(promptsFile)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			variants = append(variants, fromFile...)
+		}
+		if len(variants) == 0 {
+			fmt.Printf("\033[31m❌ Give at least one prompt variant with --prompt or --prompts-file.\033[0m\n")
+			os.Exit(1)
+		}
+		if len(models) == 0 {
+			fmt.Printf("\033[31m❌ Give at least one model with --llm.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		parent, err := session.Current()
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		total := len(variants) * len(models)
+		fmt.Printf("🧪 Running %d variant(s) × %d model(s) below \033[33m%s\033[0m...\n", len(variants), len(models), parent.ID)
+
+		progress := newProgressLine("🧪")
+		judging := newProgressLine("⚖️ ")
+		done := 0
+		started := time.Now()
+		experiment, err := session.RunExperiment(context.Background(), parent, variants, models, bonsai.ExperimentOptions{
+			Concurrency: concurrency,
+			Timeout:     session.Timeout(),
+			Judge:       judge,
+			Criteria:    criteria,
+			OnResult: func(result bonsai.BatchResult) {
+				done++
+				variant, model := result.Index/len(models)+1, models[result.Index%len(models)]
+				if result.Err != nil {
+					progress.Printf("[%d/%d] \033[31m❌ variant %d × %s: %v\033[0m\n", done, total, variant, model, result.Err)
+					return
+				}
+				progress.Printf("[%d/%d] ✅ variant %d × \033[35m%s\033[0m\n", done, total, variant, model)
+			},
+			OnProgress: func(p llm.Progress) {
+				progress.Update(p)
+				if p.Done == p.Total {
+					progress.Done()
+				}
+			},
+			OnJudgeProgress: judging.Update,
+		})
+		judging.Done()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		notifyIfSlowOrWarn(database, started, "bai experiment finished", fmt.Sprintf("%d variant(s) × %d model(s) run", len(variants), len(models)))
+		if judge != "" {
+			fmt.Printf("⚖️  Judged by \033[35m%s\033[0m\n", judge)
+		}
+
+		report := experiment.Report()
+		if reportPath != "" {
+			if err := os.WriteFile(reportPath, []byte(report), 0644); err != nil {
+				fmt.Printf("\033[31m❌ Failed to write report: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📄 Report written to \033[90m%s\033[0m\n", reportPath)
+		} else {
+			fmt.Printf("\n%s", report)
+		}
+
+		for _, run := range experiment.Runs {
+			if run.Err != nil && run.Response != nil {
+				fmt.Printf("\033[33m⚠️  variant %d × %s: %v\033[0m\n", run.Variant+1, run.Model, run.Err)
+			}
+		}
+		fmt.Printf("\n📍 Current working node unchanged: \033[33m%s\033[0m\n", parent.ID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(experimentCmd)
+	experimentCmd.Flags().StringArrayP("prompt", "p", nil, "Prompt variant to try (repeatable)")
+	experimentCmd.Flags().String("prompts-file", "", "File of prompt variants, one per line (- for stdin)")
+	experimentCmd.Flags().StringArrayP("llm", "l", nil, "Model to try each variant with (repeatable)")
+	experimentCmd.Flags().String("judge", "", "Model that scores each response from 1 to 10")
+	experimentCmd.Flags().String("criteria", bonsai.DefaultJudgeCriteria, "What the judge scores responses on")
+	experimentCmd.Flags().String("report", "", "Write the Markdown report to a file instead of printing it")
+	experimentCmd.Flags().IntP("concurrency", "c", 0, "Number of requests to make at once (defaults to generate.concurrency)")
+}
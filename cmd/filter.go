@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+// addNodeFilterFlags adds the flags read by nodeFilterFromFlags to a listing command
+func addNodeFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("model", "m", "", "Only nodes whose model matches this glob, e.g. 'claude*'")
+	cmd.Flags().StringP("type", "t", "", "Only nodes of this type: user, llm, note or system")
+	cmd.Flags().String("since", "", "Only nodes created since a date (2024-01-01) or that long ago (7d, 12h)")
+	cmd.Flags().String("until", "", "Only nodes created before a date (2024-01-01) or that long ago (7d, 12h)")
+}
+
+// nodeFilterFromFlags reads the flags added by addNodeFilterFlags
+func nodeFilterFromFlags(cmd *cobra.Command) (db.NodeFilter, error) {
+	var filter db.NodeFilter
+	var err error
+	if filter.Model, err = cmd.Flags().GetString("model"); err != nil {
+		return filter, fmt.Errorf("failed to get model flag: %w", err)
+	}
+	if filter.Type, err = cmd.Flags().GetString("type"); err != nil {
+		return filter, fmt.Errorf("failed to get type flag: %w", err)
+	}
+	if filter.Type != "" && !db.IsNodeType(filter.Type) {
+		return filter, fmt.Errorf("--type must be one of %s", strings.Join(db.NodeTypes, ", "))
+	}
+
+	for _, bound := range []struct {
+		flag string
+		time *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value, err := cmd.Flags().GetString(bound.flag)
+		if err != nil {
+			return filter, fmt.Errorf("failed to get %s flag: %w", bound.flag, err)
+		}
+		if value == "" {
+			continue
+		}
+		if *bound.time, err = parseTimeBound(value, time.Now()); err != nil {
+			return filter, fmt.Errorf("invalid --%s: %w", bound.flag, err)
+		}
+	}
+	return filter, nil
+}
+
+// addListFlags adds the flags read by listOptionsFromFlags to a listing command
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sort", db.SortRecent, "Order to list in: recent (latest activity), created (newest first) or size (most nodes)")
+	cmd.Flags().IntP("limit", "n", 0, "Most entries to list (all of them if 0, or 20 per --page)")
+	cmd.Flags().Int("offset", 0, "Entries to skip before listing")
+	cmd.Flags().IntP("page", "p", 0, "Page of entries to list, counting from 1")
+	cmd.MarkFlagsMutuallyExclusive("offset", "page")
+}
+
+// defaultPageSize is how many entries a page of a listing holds when --page is given without --limit
+const defaultPageSize = 20
+
+// listOptionsFromFlags reads the flags added by addListFlags
+func listOptionsFromFlags(cmd *cobra.Command) (db.ListOptions, error) {
+	var opts db.ListOptions
+	var err error
+	if opts.Sort, err = cmd.Flags().GetString("sort"); err != nil {
+		return opts, fmt.Errorf("failed to get sort flag: %w", err)
+	}
+	if opts.Sort != db.SortRecent && opts.Sort != db.SortCreated && opts.Sort != db.SortSize {
+		return opts, fmt.Errorf("--sort must be %s, %s or %s", db.SortRecent, db.SortCreated, db.SortSize)
+	}
+	if opts.Limit, err = cmd.Flags().GetInt("limit"); err != nil {
+		return opts, fmt.Errorf("failed to get limit flag: %w", err)
+	}
+	if opts.Offset, err = cmd.Flags().GetInt("offset"); err != nil {
+		return opts, fmt.Errorf("failed to get offset flag: %w", err)
+	}
+	page, err := cmd.Flags().GetInt("page")
+	if err != nil {
+		return opts, fmt.Errorf("failed to get page flag: %w", err)
+	}
+	if opts.Limit < 0 || opts.Offset < 0 || page < 0 {
+		return opts, fmt.Errorf("--limit, --offset and --page can't be negative")
+	}
+
+	if page > 0 {
+		if opts.Limit == 0 {
+			opts.Limit = defaultPageSize
+		}
+		opts.Offset = (page - 1) * opts.Limit
+	}
+	return opts, nil
+}
+
+// describeListPage says which entries of a listing are shown, e.g. "21-40 of 57", or just the
+// total when all of them are
+func describeListPage(opts db.ListOptions, shown, total int) string {
+	if opts.Offset == 0 && shown == total {
+		return fmt.Sprintf("%d", total)
+	}
+	if shown == 0 {
+		return fmt.Sprintf("none of %d", total)
+	}
+	return fmt.Sprintf("%d-%d of %d", opts.Offset+1, opts.Offset+shown, total)
+}
+
+// parseTimeBound reads a date (2024-01-01), a date and time in RFC 3339, or how long before now,
+// in days (7d) or any Go duration (12h, 90m)
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q isn't a date like 2024-01-01 or an age like 7d", value)
+}
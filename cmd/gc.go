@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up old trees and compact the database",
+	Long: `Apply the retention policy and compact the database.
+
+The retention policy is configured with 'bai config set':
+  gc.max_age_days      remove trees with no new nodes for this many days (0 or unset disables)
+  gc.action            archive (default) exports removed trees to ~/.bonsai/archive before
+                       deleting them; delete removes them outright
+  gc.max_db_size_mb    remove the least recently active trees until the database is under
+                       this size (0 or unset disables)
+
+Archives are plain JSON, so on an encrypted database gc refuses to archive unless
+--plaintext-archive is given; set gc.action to delete to remove trees without archiving them.
+
+The tree containing the current working node is never removed. gc also deletes orphaned
+nodes whose parent no longer exists, and vacuums the SQLite file to reclaim space.`,
+	Example: `  # Archive trees untouched for 90 days, then clean up
+  bai config set gc.max_age_days 90
+  bai gc
+
+  # See what would be removed without changing anything
+  bai gc --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Only one gc may run at a time, and other commands' multi-step operations wait for it
+		unlock, err := database.Lock()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer unlock()
+
+		maxAgeDays, err := configInt(database, "gc.max_age_days", 0)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		maxSizeMB, err := configInt(database, "gc.max_db_size_mb", 0)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		action, err := configString(database, "gc.action", "archive")
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		plaintextArchive, err := cmd.Flags().GetBool("plaintext-archive")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get plaintext-archive flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Never remove the tree the user is currently working in
+		currentRootID, err := newSession(database).CurrentRootID()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		trees, err := database.GetTreeActivity()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		sizeBefore, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("🧹 Running garden cleanup...")
+
+		// Remove trees that have been untouched for longer than the retention period
+		var remaining []*db.TreeActivity
+		removedTrees := 0
+		cutoff := time.Now().AddDate(0, 0, -maxAgeDays).Unix()
+		for _, tree := range trees {
+			expired := maxAgeDays > 0 && tree.LastActivity > 0 && tree.LastActivity < cutoff
+			if !expired || tree.RootID == currentRootID {
+				remaining = append(remaining, tree)
+				continue
+			}
+
+			lastActive := time.Unix(tree.LastActivity, 0).Format("2006-01-02")
+			if dryRun {
+				fmt.Printf("• Would %s tree \033[33m%s\033[0m (%d node(s), last active %s)\n", action, tree.RootID, tree.NodeCount, lastActive)
+				continue
+			}
+			if err := removeTree(database, tree.RootID, action, plaintextArchive); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			removedTrees++
+			fmt.Printf("• %s tree \033[33m%s\033[0m (%d node(s), last active %s)\n", pastTense(action), tree.RootID, tree.NodeCount, lastActive)
+		}
+
+		if dryRun {
+			fmt.Printf("\033[90mℹ️  Dry run - nothing was changed. Database size: %s\033[0m\n", formatBytes(sizeBefore))
+			if maxSizeMB > 0 && sizeBefore > int64(maxSizeMB)<<20 {
+				fmt.Printf("\033[33m⚠️  Database exceeds the %d MB cap; gc would also remove the least recently active trees.\033[0m\n", maxSizeMB)
+			}
+			return
+		}
+
+		orphans, err := database.DeleteOrphanedNodes()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if orphans > 0 {
+			fmt.Printf("• Removed %d orphaned node(s)\n", orphans)
+		}
+
+		if err := database.Vacuum(); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Enforce the size cap, removing the least recently active trees first
+		if maxSizeMB > 0 {
+			sort.SliceStable(remaining, func(i, j int) bool {
+				return remaining[i].LastActivity < remaining[j].LastActivity
+			})
+
+			for _, tree := range remaining {
+				size, err := database.GetFileSize()
+				if err != nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+				if size <= int64(maxSizeMB)<<20 {
+					break
+				}
+				if tree.RootID == currentRootID {
+					continue
+				}
+
+				if err := removeTree(database, tree.RootID, action, plaintextArchive); err != nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+				if err := database.Vacuum(); err != nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+				removedTrees++
+				fmt.Printf("• %s tree \033[33m%s\033[0m (%d node(s)) to stay under %d MB\n", pastTense(action), tree.RootID, tree.NodeCount, maxSizeMB)
+			}
+		}
+
+		sizeAfter, err := database.GetFileSize()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[32m✅ Cleanup complete: removed %d tree(s); database %s → %s\033[0m\n", removedTrees, formatBytes(sizeBefore), formatBytes(sizeAfter))
+	},
+}
+
+// errPlaintextArchive is returned instead of archiving a tree of an encrypted database without --plaintext-archive
+var errPlaintextArchive = errors.New("the database is encrypted but archives are plain JSON; set gc.action to delete, or pass --plaintext-archive to archive anyway")
+
+// removeTree deletes a tree, first exporting it to the archive directory if action is "archive".
+// Trees of an encrypted database are only archived if plaintextArchive is set, since the archive
+// would leave a decrypted copy on disk.
+func removeTree(database *db.Database, rootID, action string, plaintextArchive bool) error {
+	if action == "archive" {
+		if database.IsEncrypted() && !plaintextArchive {
+			return errPlaintextArchive
+		}
+		archiveDir := filepath.Join(filepath.Dir(database.GetPath()), "archive")
+		if err := os.MkdirAll(archiveDir, 0755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+
+		var archive bytes.Buffer
+		if err := newSession(database).Export(&archive, rootID); err != nil {
+			return fmt.Errorf("failed to archive tree %s: %w", rootID, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(archiveDir, rootID+".json"), archive.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write archive for tree %s: %w", rootID, err)
+		}
+	}
+
+	if _, err := database.DeleteNodeAndAllChildren(rootID); err != nil {
+		return fmt.Errorf("failed to delete tree %s: %w", rootID, err)
+	}
+	return nil
+}
+
+// pastTense returns the capitalized past tense of a gc action for display
+func pastTense(action string) string {
+	if action == "archive" {
+		return "Archived"
+	}
+	return "Deleted"
+}
+
+// formatBytes formats a byte count in human-readable units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().Bool("dry-run", false, "Show what would be removed without changing anything")
+	gcCmd.Flags().Bool("plaintext-archive", false, "Archive trees of an encrypted database even though archives aren't encrypted")
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+// timeoutFlag bounds each LLM request, set by the --timeout flag; zero defers to the setting
+var timeoutFlag time.Duration
+
+// Settings for the pool that bounds how many requests fan-out commands like batch and experiment
+// make at once, overall and per provider
+const (
+	concurrencyConfigKey    = "generate.concurrency"
+	providerLimitsConfigKey = "generate.provider_limits"
+)
+
+// noCache bypasses the response cache, set by the --no-cache flag
+var noCache bool
+
+// forceModel sends model names no provider is known to serve as-is, set by the --force-model flag
+var forceModel bool
+
+// addContextFlags adds the flags choosing how much of the branch a command sends to the model
+func addContextFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("context", 0, "Send only the last N turns of the branch, counting the new message (system prompts are always sent)")
+	cmd.Flags().String("context-from", "", "Send the branch only from this node down")
+	cmd.MarkFlagsMutuallyExclusive("context", "context-from")
+}
+
+// limitContextFromFlags makes the session send only the part of each branch the flags added by
+// addContextFlags choose
+func limitContextFromFlags(cmd *cobra.Command, session *bonsai.Session) error {
+	var window bonsai.ContextWindow
+	var err error
+	if window.Turns, err = cmd.Flags().GetInt("context"); err != nil {
+		return fmt.Errorf("failed to get context flag: %w", err)
+	}
+	if cmd.Flags().Changed("context") && window.Turns < 1 {
+		return fmt.Errorf("--context must be at least 1")
+	}
+	from, err := cmd.Flags().GetString("context-from")
+	if err != nil {
+		return fmt.Errorf("failed to get context-from flag: %w", err)
+	}
+	// Resolve the node now, so a mistyped ID fails before any message is added
+	if from != "" {
+		node, err := session.Node(from)
+		if err != nil {
+			return err
+		}
+		window.From = node.ID
+	}
+	session.LimitContext(window)
+	return nil
+}
+
+// generateResponse sends the messages to the given model, with the generation parameters of the tree
+// the response will be stored under parentID in, and returns the response text along with the
+// parameters it was generated with
+func generateResponse(session *bonsai.Session, parentID, model string, messages []llm.Message) (string, *llm.Generation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), session.Timeout())
+	defer cancel()
+
+	spin := startSpinner(model)
+	defer spin.Stop()
+	ctx, generation := llm.WithGeneration(llm.WithStreamHandler(ctx, spin.Chunk))
+	response, err := session.CompleteInTree(ctx, parentID, model, messages)
+	return response, generation, err
+}
+
+// generateChildResponse generates an LLM response to the conversation ending at the given node
+// and stores it as a new child of that node, showing a spinner while the response streams in
+func generateChildResponse(session *bonsai.Session, node *db.Node, model string) (*db.Node, error) {
+	warnAboutGeneration(session, node, model)
+	ctx, cancel := context.WithTimeout(context.Background(), session.Timeout())
+	defer cancel()
+
+	spin := startSpinner(model)
+	llmNode, err := session.Respond(llm.WithStreamHandler(ctx, spin.Chunk), node, model)
+	spin.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Created LLM response node with ID: \033[33m%s\033[0m\n", llmNode.ID)
+	fmt.Printf("🤖 LLM Response: %s\n", renderMarkdown(llmNode.Content))
+
+	return llmNode, nil
+}
+
+// warnAboutGeneration prints a warning for each problem with generating the model's reply to the
+// conversation ending at node that the model's known capabilities reveal, without stopping it
+func warnAboutGeneration(session *bonsai.Session, node *db.Node, model string) {
+	warnings, err := session.CheckGeneration(node, model)
+	if err != nil {
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Printf("\033[33m⚠️  %s\033[0m\n", warning)
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "Search the current branch with a regular expression",
+	Long: `Print the lines of nodes matching a regular expression, with line numbers and surrounding context.
+Unlike 'bai search', which matches words anywhere in the garden, grep matches exact text such as error
+messages, identifiers or paths, and only looks where you point it:
+
+  --branch   the conversation from the root down to the current working node (the default)
+  --subtree  the current working node and everything below it
+  --all      every node in every tree
+
+Patterns use Go's regular expression syntax (RE2).`,
+	Example: `  bai grep "nil pointer dereference"
+  bai grep 'func \w+Handler' --subtree
+  bai grep -i timeout --all -C 2`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		subtree, err := cmd.Flags().GetBool("subtree")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get subtree flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get all flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		ignoreCase, err := cmd.Flags().GetBool("ignore-case")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get ignore-case flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		contextLines, err := cmd.Flags().GetInt("context")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get context flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if contextLines < 0 {
+			fmt.Printf("\033[31m❌ --context can't be negative.\033[0m\n")
+			os.Exit(1)
+		}
+
+		pattern := args[0]
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Invalid pattern: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		var nodes []*db.Node
+		var scope string
+		if all {
+			nodes, err = database.GetAllNodes()
+			scope = "all trees"
+		} else {
+			session := newSession(database)
+			var current *bonsai.Node
+			current, err = session.Current()
+			if errors.Is(err, bonsai.ErrNoCurrentNode) {
+				fmt.Println("🌱 No current working node set. Use 'bai checkout' to pick one, or --all to search every tree.")
+				return
+			}
+			if err == nil && subtree {
+				nodes, err = database.GetNodeAndAllChildren(current.ID)
+				scope = "the subtree of " + shortID(current.ID)
+			} else if err == nil {
+				nodes, err = database.GetConversationHistory(current.ID)
+				scope = "the current branch"
+			}
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		var output strings.Builder
+		matches, matched := 0, 0
+		for _, node := range nodes {
+			count, lines := grepNode(re, node.Content, contextLines)
+			if count == 0 {
+				continue
+			}
+			matches += count
+			matched++
+
+			fmt.Fprintf(&output, "\n%s \033[33m%s\033[0m \033[90m%s\033[0m\n", nodeTypeIcon(node.Type), shortID(node.ID), formatAge(node.CreatedAt))
+			output.WriteString(lines)
+		}
+
+		if matched == 0 {
+			fmt.Printf("\033[90mℹ️  Nothing in %s matches /%s/.\033[0m\n", scope, args[0])
+			return
+		}
+		fmt.Printf("🔎 %d match(es) in %d node(s) in %s for /%s/:\n", matches, matched, scope, args[0])
+		fmt.Print(output.String())
+	},
+}
+
+// grepNode finds the lines of content matching re and renders them with their line numbers, the
+// matches highlighted and up to contextLines lines around each. Runs of lines that aren't adjacent are
+// separated by a dotted line. It returns how many lines matched.
+func grepNode(re *regexp.Regexp, content string, contextLines int) (int, string) {
+	lines := strings.Split(content, "\n")
+
+	matching := make([]bool, len(lines))
+	count := 0
+	for i, line := range lines {
+		if re.MatchString(line) {
+			matching[i] = true
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, ""
+	}
+
+	var b strings.Builder
+	last := -1
+	for i := range lines {
+		shown := false
+		for j := i - contextLines; j <= i+contextLines; j++ {
+			if j >= 0 && j < len(lines) && matching[j] {
+				shown = true
+				break
+			}
+		}
+		if !shown {
+			continue
+		}
+
+		if last >= 0 && i > last+1 {
+			b.WriteString("   \033[90m┈\033[0m\n")
+		}
+		last = i
+
+		if matching[i] {
+			highlighted := re.ReplaceAllStringFunc(lines[i], func(match string) string {
+				return "\033[1;33m" + match + "\033[0m"
+			})
+			fmt.Fprintf(&b, "   \033[36m%4d\033[0m│ %s\n", i+1, highlighted)
+		} else {
+			fmt.Fprintf(&b, "   \033[90m%4d│ %s\033[0m\n", i+1, lines[i])
+		}
+	}
+	return count, b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+	grepCmd.Flags().Bool("branch", false, "Search the conversation from the root to the current working node (the default)")
+	grepCmd.Flags().BoolP("subtree", "s", false, "Search the current working node and everything below it")
+	grepCmd.Flags().BoolP("all", "a", false, "Search every node in every tree")
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Match regardless of case")
+	grepCmd.Flags().IntP("context", "C", 1, "Lines of context to show around each match")
+	grepCmd.MarkFlagsMutuallyExclusive("branch", "subtree", "all")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+// gitCaptureConfigKey is the setting that turns recording the git commit on new nodes on or off
+const gitCaptureConfigKey = "git.capture"
+
+var linkCommitCmd = &cobra.Command{
+	Use:   "link-commit <node-id> [sha]",
+	Short: "Link a git commit to a node",
+	Long: `Link a git commit to a node, so a conversation about code can be traced to the commit that came
+of it. The commit is looked up in the git repository containing the working directory, and defaults
+to HEAD. A node can have any number of linked commits; 'bai log' lists them.
+
+Nodes also record the repository, branch and commit checked out where they were created, when bai
+runs inside a git repository. Turn that off with 'bai config set git.capture off'.`,
+	Example: `  bai link-commit 3f2a9c1b
+  bai link-commit 3f2a9c1b 9e1d4c7`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rev := "HEAD"
+		if len(args) == 2 {
+			rev = args[1]
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		node, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		ref, err := bonsai.ResolveGitRef(".", rev)
+		if errors.Is(err, bonsai.ErrNotGitRepo) {
+			fmt.Println("\033[31m❌ Not inside a git repository. Run 'bai link-commit' from the repository the commit is in.\033[0m")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if err := session.LinkCommit(node, *ref); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔖 Linked commit \033[32m%s\033[0m to node \033[33m%s\033[0m\n", ref, shortID(node.ID))
+	},
+}
+
+// printCommits shows the commit a node was created at and the commits linked to it
+func printCommits(node *db.Node) {
+	if ref := bonsai.CreatedAtCommit(node); ref != nil {
+		fmt.Printf("🌿 Created at: \033[32m%s\033[0m\n", ref)
+	}
+	for _, ref := range bonsai.LinkedCommits(node) {
+		fmt.Printf("🔖 Linked commit: \033[32m%s\033[0m\n", ref)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(linkCommitCmd)
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
 	"github.com/spf13/cobra"
 )
 
@@ -54,17 +56,15 @@ var logCmd = &cobra.Command{
 		}
 
 		fmt.Printf("🪵 Log from current working node: \033[33m%s\033[0m\n", *currentNodeID)
-		var currentTypeIcon string
-		if currentNode.Type == "user" {
-			currentTypeIcon = "👤"
-		} else {
-			currentTypeIcon = "🤖"
-		}
-		fmt.Printf("%s Current node type: \033[90m%s\033[0m\n", currentTypeIcon, currentNode.Type)
+		fmt.Printf("%s Current node type: \033[90m%s\033[0m\n", nodeTypeIcon(currentNode.Type), currentNode.Type)
 		if currentNode.Model != nil {
 			fmt.Printf("🧠 Current node model: \033[35m%s\033[0m\n", *currentNode.Model)
 		}
+		printAuthor(currentNode)
 			fmt.Printf("💬 Current node message: \033[90m%s\033[0m\n", currentNode.Content)
+		printQuotes(currentNode)
+		printCommits(currentNode)
+		printExcluded(currentNode)
 		fmt.Println()
 
 		// Determine how many levels to traverse
@@ -100,16 +100,11 @@ var logCmd = &cobra.Command{
 
 			fmt.Printf("\033[1m%s:\033[0m\n", levelIndicator)
 			fmt.Printf("ID: \033[33m%s\033[0m\n", parent.ID)
-			var typeIcon string
-			if parent.Type == "user" {
-				typeIcon = "👤"
-			} else {
-				typeIcon = "🤖"
-			}
-			fmt.Printf("%s Type: \033[90m%s\033[0m\n", typeIcon, parent.Type)
+			fmt.Printf("%s Type: \033[90m%s\033[0m\n", nodeTypeIcon(parent.Type), parent.Type)
 			if parent.Model != nil {
 				fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *parent.Model)
 			}
+			printAuthor(parent)
 
 			// Show a preview of the content (first 150 characters)
 			content := parent.Content
@@ -119,6 +114,9 @@ var logCmd = &cobra.Command{
 			// Replace newlines with spaces for cleaner display
 			content = strings.ReplaceAll(content, "\n", " ")
 			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", content)
+			printQuotes(parent)
+			printCommits(parent)
+			printExcluded(parent)
 
 			// Add spacing between levels except for the last one
 			if i < len(parentPath)-1 {
@@ -140,6 +138,27 @@ var logCmd = &cobra.Command{
 	},
 }
 
+// printExcluded notes when a node is left out of what's sent to models
+func printExcluded(node *db.Node) {
+	if db.IsExcluded(node) {
+		fmt.Printf("🚫 \033[90mExcluded from context ('bai exclude --undo %s' to include it)\033[0m\n", shortID(node.ID))
+	}
+}
+
+// printQuotes lists the nodes a message quotes, so they can be checked out
+func printQuotes(node *db.Node) {
+	for _, id := range bonsai.Quotes(node) {
+		fmt.Printf("🔗 Quotes: \033[33m%s\033[0m\n", id)
+	}
+}
+
+// printAuthor shows who added a node, if that was recorded
+func printAuthor(node *db.Node) {
+	if node.Author != nil && *node.Author != "" {
+		fmt.Printf("✍️  Author: \033[36m%s\033[0m\n", *node.Author)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(logCmd)
 	logCmd.Flags().IntP("up", "u", 1, "Number of levels to climb up the parent chain")
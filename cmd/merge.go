@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+const mergePrompt = `Below are two alternative branches of the same conversation. Each branch explored the
+discussion in a different direction.
+
+Write a single, self-contained answer that synthesizes the best of both branches: keep
+the most accurate, useful and well-explained points from each, resolve any contradictions,
+and drop anything redundant. Respond with the synthesized answer only.
+
+=== Branch A ===
+%s
+
+=== Branch B ===
+%s`
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <branch-a> <branch-b>",
+	Short: "Merge two branches into a new node with an LLM-synthesized answer",
+	Long: `Sends the transcripts of two branches (root to each given node) to the model and asks it to
+synthesize a single answer combining the best of both. The answer is added as a new child of
+<branch-a>, with both source nodes recorded in its metadata, and becomes the current working node.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		branchAID, branchBID := args[0], args[1]
+
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Expand abbreviated node IDs such as those shown by 'bai recent'
+		branchAID, err = database.ResolveNodeID(branchAID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		branchBID, err = database.ResolveNodeID(branchBID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if branchAID == branchBID {
+			fmt.Printf("\033[31m❌ Cannot merge a branch with itself.\033[0m\n")
+			os.Exit(1)
+		}
+
+		branchA, err := database.GetConversationHistory(branchAID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get branch %s: %v\033[0m\n", branchAID, err)
+			os.Exit(1)
+		}
+
+		branchB, err := database.GetConversationHistory(branchBID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get branch %s: %v\033[0m\n", branchBID, err)
+			os.Exit(1)
+		}
+
+		// Leave out notes unless each branch's tree includes them, as replies do
+		settingsA, err := database.GetTreeGenerationSettings(branchAID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		settingsB, err := database.GetTreeGenerationSettings(branchBID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		branchA, branchB = settingsA.ModelHistory(branchA), settingsB.ModelHistory(branchB)
+
+		// Use the flag if provided, otherwise inherit from either branch
+		model := llmModel
+		for _, tipID := range []string{branchAID, branchBID} {
+			if model != "" {
+				break
+			}
+			if model, err = database.InheritedModel(tipID); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+		if model == "" {
+			fmt.Printf("\033[31m❌ No model found on either branch or its tree. Use --llm to choose one.\033[0m\n")
+			os.Exit(1)
+		}
+
+		fmt.Printf("🔀 Merging \033[33m%s\033[0m and \033[33m%s\033[0m with \033[35m%s\033[0m...\n", branchAID, branchBID, model)
+
+		prompt := fmt.Sprintf(mergePrompt, formatTranscript(branchA), formatTranscript(branchB))
+		response, generation, err := generateResponse(newSession(database), branchAID, model, []llm.Message{{Role: "user", Content: prompt}})
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get LLM response: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		mergedNode, err := database.CreateLLMResponseNode(branchAID, response, model)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to create merged node: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if err := database.SetNodeMetadata(mergedNode.ID, "merged_from", []string{branchAID, branchBID}); err != nil {
+			fmt.Printf("\033[33m⚠️  Created merged node but failed to record its parents: %v\033[0m\n", err)
+		}
+		if err := database.SetNodeMetadata(mergedNode.ID, db.GenerationMetadataKey, generation); err != nil {
+			fmt.Printf("\033[33m⚠️  Created merged node but failed to record its generation parameters: %v\033[0m\n", err)
+		}
+
+		fmt.Printf("✨ \033[32mCreated merged node with ID:\033[0m \033[33m%s\033[0m\n", mergedNode.ID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", branchAID)
+		fmt.Printf("🔗 Merged from: \033[33m%s\033[0m, \033[33m%s\033[0m\n", branchAID, branchBID)
+		fmt.Printf("🤖 LLM Response: %s\n", renderMarkdown(mergedNode.Content))
+
+		fireHook(database, hooks.ResponseReceived, mergedNode)
+	},
+}
+
+// formatTranscript renders a root-to-node chain as a plain-text conversation transcript
+func formatTranscript(history []*db.Node) string {
+	var builder strings.Builder
+	for i, node := range history {
+		if i > 0 {
+			builder.WriteString("\n\n")
+		}
+		role := "User"
+		switch node.Type {
+		case "llm":
+			role = "Assistant"
+		case "note":
+			role = "Note"
+		case "system":
+			role = "System"
+		}
+		builder.WriteString(role + ": " + node.Content)
+	}
+	return builder.String()
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringP("llm", "l", "", "LLM model to use for the synthesis (defaults to the model of either branch)")
+}
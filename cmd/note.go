@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note [node-id] <text>",
+	Short: "Add a note of your own to a conversation",
+	Long: `Add a note below the current working node: an annotation, reminder or bit of context for
+yourself that's kept in the tree but not sent to models. Nothing responds to a note, and replies
+below it carry on the conversation as if it weren't there. Set generate.include_notes to on to send
+notes along with the rest of the conversation, as user messages.
+
+Given a node ID before the text, the note is added below that node instead, without checking it
+out.`,
+	Example: `  bai note "This answer misses the caching layer; try again with more context"
+  bai note 3f2a9c1b "Verified against the docs"`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var parentID string
+		text := args[len(args)-1]
+		if len(args) == 2 {
+			parentID = args[0]
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		node, err := newSession(database).Note(text, parentID)
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("📝 \033[32mCreated note with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
+		fmt.Printf("💬 Note: \033[90m%s\033[0m\n", node.Content)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+}
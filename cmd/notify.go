@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/notify"
+)
+
+// notifyAfterConfigKey is the setting that turns on desktop notifications for long-running
+// generations, holding how long one must take before it's worth a notification
+const notifyAfterConfigKey = "notify.after"
+
+// notifyIfSlow shows a desktop notification if notifications are on and the work begun at started
+// took at least as long as the notify.after setting. It returns why the notification couldn't be
+// shown, if it was due.
+func notifyIfSlow(database *db.Database, started time.Time, title, message string) error {
+	value, err := configString(database, notifyAfterConfigKey, "")
+	if err != nil || value == "" {
+		return err
+	}
+	after, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", notifyAfterConfigKey, err)
+	}
+
+	elapsed := time.Since(started)
+	if elapsed < after {
+		return nil
+	}
+	return notify.Send(title, fmt.Sprintf("%s (took %s)", message, elapsed.Round(time.Second)))
+}
+
+// notifyIfSlowOrWarn is notifyIfSlow for commands, printing a warning if the notification failed
+func notifyIfSlowOrWarn(database *db.Database, started time.Time, title, message string) {
+	if err := notifyIfSlow(database, started, title, message); err != nil {
+		fmt.Printf("\033[33m⚠️  Couldn't show a notification: %v\033[0m\n", err)
+	}
+}
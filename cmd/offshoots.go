@@ -11,8 +11,26 @@ import (
 var offshootsCmd = &cobra.Command{
 	Use:   "offshoots",
 	Short: "List all conversation branches of the current working node",
-	Long:  `List all conversation branches of the current working node. Shows the node ID, type, and a preview of their content.`,
+	Long: `List all conversation branches of the current working node. Shows the node ID, type, and a preview of their content.
+
+Filter the branches by model, type or creation date with --model, --type, --since and --until.
+Branches are listed most recently active first; --sort created lists the newest first and --sort size
+those with the most nodes below them. Use --limit with --page or --offset to list a page at a time.`,
+	Example: `  bai offshoots
+  bai offshoots --type llm --model 'gpt-4*'
+  bai offshoots --sort size --limit 5`,
 	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := nodeFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		opts, err := listOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Initialize database
 		database, err := initializeDatabase(false)
 		if err != nil {
@@ -33,13 +51,21 @@ var offshootsCmd = &cobra.Command{
 			return
 		}
 
-		// Get direct children of the current node
-		children, err := database.GetDirectChildren(*currentNodeID)
+		// Get the page of direct children of the current node matching the filter
+		children, total, err := database.ListChildNodes(*currentNodeID, filter, opts)
 		if err != nil {
 			fmt.Printf("\033[31m❌ Failed to get child nodes: %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
+		if len(children) == 0 && total > 0 {
+			fmt.Printf("🌿 No offshoots on this page; current node \033[33m%s\033[0m has %d in all\n", *currentNodeID, total)
+			return
+		}
+		if len(children) == 0 && !filter.IsZero() {
+			fmt.Printf("🌿 No offshoots of current node \033[33m%s\033[0m match the filter\n", *currentNodeID)
+			return
+		}
 		if len(children) == 0 {
 			fmt.Printf("🌿 No offshoots found for current node: \033[33m%s\033[0m\n", *currentNodeID)
 			return
@@ -68,10 +94,12 @@ var offshootsCmd = &cobra.Command{
 			}
 		}
 
-		fmt.Printf("\n\033[90mTotal: %d child node(s)\033[0m\n", len(children))
+		fmt.Printf("\n\033[90mTotal: %s child node(s)\033[0m\n", describeListPage(opts, len(children), total))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(offshootsCmd)
+	addNodeFilterFlags(offshootsCmd)
+	addListFlags(offshootsCmd)
 }
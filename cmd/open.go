@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/web"
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open <node-id>",
+	Short: "Open the visualization in your browser, focused on a node",
+	Long: `Opens the web visualization in your browser at the permalink for a node, with the tree
+focused on it.
+
+If a visualization server is already running on the port it's reused; otherwise one is
+started, like 'bai visualize', and runs until you press Ctrl+C.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		port, err := cmd.Flags().GetInt("port")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get port flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Expand abbreviated node IDs so the permalink keeps working as the tree grows
+		nodeID, err := database.ResolveNodeID(args[0])
+		database.Close()
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if visualizationRunning(port) {
+			url := fmt.Sprintf("http://localhost:%d/%s", port, web.NodePage(nodeID))
+			if err := web.OpenBrowser(url); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				fmt.Printf("💡 Open \033[36m%s\033[0m instead\n", url)
+				os.Exit(1)
+			}
+			fmt.Printf("🌐 Opened \033[36m%s\033[0m\n", url)
+			return
+		}
+
+		serveVisualization(port, true, web.NodePage(nodeID))
+	},
+}
+
+// visualizationRunning reports whether a bonsai visualization server is answering on the local port
+func visualizationRunning(port int) bool {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://localhost:" + strconv.Itoa(port) + "/api/v1/health")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	// A server started with a token still identifies itself by refusing the request
+	if resp.StatusCode == http.StatusUnauthorized {
+		return strings.Contains(resp.Header.Get("WWW-Authenticate"), `realm="bonsai"`)
+	}
+	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().IntP("port", "p", 8080, "Port the visualization server runs on")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin [node-id]...",
+	Short: "Pin nodes as context sent with every message in the tree",
+	Long: `Pin nodes, such as a spec, requirements or a code snippet, to the tree holding the current working
+node. Their content is sent to the model ahead of every conversation in the tree, whichever branch
+it's on and however far down, as a single message of pinned context. Nodes can be pinned from any
+tree. A pinned node already on the branch being continued is only sent there, and excluded nodes
+aren't sent at all.
+
+Without a node ID, lists the nodes pinned to the current tree. Pass --undo to unpin nodes.`,
+	Example: `  bai pin 3f2a9c1b
+  bai pin
+  bai pin --undo 3f2a9c1b`,
+	Run: func(cmd *cobra.Command, args []string) {
+		undo, err := cmd.Flags().GetBool("undo")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get undo flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if undo && len(args) == 0 {
+			fmt.Printf("\033[31m❌ Pass the nodes to unpin.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		if len(args) == 0 {
+			listPins(session)
+			return
+		}
+
+		for _, nodeID := range args {
+			if undo {
+				id, err := session.Unpin(nodeID)
+				if err != nil {
+					exitPinError(err)
+				}
+				fmt.Printf("✅ \033[32mUnpinned\033[0m \033[33m%s\033[0m\n", shortID(id))
+				continue
+			}
+
+			node, added, err := session.Pin(nodeID)
+			if err != nil {
+				exitPinError(err)
+			}
+			preview := truncateContent(strings.ReplaceAll(node.Content, "\n", " "), 60)
+			if added {
+				fmt.Printf("📌 \033[32mPinned\033[0m \033[33m%s\033[0m: \033[90m%s\033[0m\n", shortID(node.ID), preview)
+			} else {
+				fmt.Printf("\033[90mℹ️  %s is already pinned\033[0m\n", shortID(node.ID))
+			}
+		}
+	},
+}
+
+// listPins shows the nodes pinned to the current tree
+func listPins(session *bonsai.Session) {
+	pinned, err := session.Pinned()
+	if err != nil {
+		exitPinError(err)
+	}
+	if len(pinned) == 0 {
+		fmt.Println("📌 Nothing is pinned to this tree. Pin a node with 'bai pin <node-id>'.")
+		return
+	}
+
+	fmt.Printf("📌 %d node(s) pinned to this tree:\n", len(pinned))
+	for _, pin := range pinned {
+		if pin.Node == nil {
+			fmt.Printf("   \033[33m%s\033[0m \033[90m(deleted; 'bai pin --undo %s' to unpin it)\033[0m\n", shortID(pin.ID), shortID(pin.ID))
+			continue
+		}
+		note := ""
+		if db.IsExcluded(pin.Node) {
+			note = " \033[90m(excluded, not sent)\033[0m"
+		}
+		fmt.Printf("   %s \033[33m%s\033[0m%s %s\n", nodeTypeIcon(pin.Node.Type), shortID(pin.ID), note, truncateContent(strings.ReplaceAll(pin.Node.Content, "\n", " "), 60))
+	}
+}
+
+// exitPinError reports a failure to change or list pins and exits
+func exitPinError(err error) {
+	if errors.Is(err, bonsai.ErrNoCurrentNode) {
+		fmt.Println("🌱 No current working node set. Check out a node in the tree to pin to first.")
+		os.Exit(1)
+	}
+	fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+	os.Exit(1)
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	pinCmd.Flags().Bool("undo", false, "Unpin the nodes")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Save and manage seed presets for starting kinds of trees",
+	Long: `Save and manage seed presets, which standardize how a kind of tree is started with
+'bai seed --preset'. A preset can give the tree a system prompt, a scaffold for the first message,
+a model, a temperature and a max_tokens. The system prompt and parameters become the new tree's
+settings (see 'bai config --tree'), so every reply in it uses them.
+
+The message scaffold is a template: the seed's content fills in {{input}}, or follows the scaffold
+if it has no {{input}}. Any other {{variables}} are asked for when running in a terminal. Presets
+are stored in the Bonsai database.`,
+	Example: `  bai preset save brainstorm --system "You are a creative collaborator. Offer many varied ideas." \
+    --message "Let's brainstorm: {{input}}" --llm gpt-4o --temperature 1.2
+  bai seed --preset brainstorm "names for a coffee shop"
+  bai preset list
+  bai preset delete brainstorm`,
+}
+
+var presetSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save a seed preset, replacing any with the same name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t\n") {
+			fmt.Printf("\033[31m❌ Preset names can't be empty or contain spaces.\033[0m\n")
+			os.Exit(1)
+		}
+
+		preset := &db.Preset{Profile: *profileFromFlags(cmd)}
+		var err error
+		if preset.Message, err = cmd.Flags().GetString("message"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get message flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if *preset == (db.Preset{}) {
+			fmt.Printf("\033[31m❌ A preset needs at least one of --system, --message, --llm, --temperature or --max-tokens.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if err := database.SavePreset(name, preset); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🎛️  \033[32mSaved preset\033[0m \033[33m%s\033[0m\n", name)
+		printPreset(preset)
+	},
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved seed presets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		presets, err := database.GetPresets()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(presets) == 0 {
+			fmt.Println("\033[90mℹ️  No presets saved. Use 'bai preset save <name> --system ...' to add one.\033[0m")
+			return
+		}
+
+		names := make([]string, 0, len(presets))
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("🎛️  Found %d preset(s):\n\n", len(presets))
+		for _, name := range names {
+			fmt.Printf("• \033[33m%s\033[0m\n", name)
+			printPreset(presets[name])
+		}
+	},
+}
+
+var presetDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a seed preset",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		deleted, err := database.DeletePreset(name)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if !deleted {
+			fmt.Printf("\033[31m❌ Preset '%s' not found.\033[0m\n", name)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🗑️  \033[32mDeleted preset\033[0m \033[33m%s\033[0m\n", name)
+	},
+}
+
+// printPreset prints the parts of a preset that are set, indented below its name
+func printPreset(preset *db.Preset) {
+	if preset.Message != "" {
+		fmt.Printf("  💬 Message: \033[90m%s\033[0m\n", truncateContent(strings.ReplaceAll(preset.Message, "\n", " "), 60))
+		if variables := bonsai.TemplateVariables(preset.Message); len(variables) > 0 {
+			fmt.Printf("  🔤 Variables: \033[36m%s\033[0m\n", strings.Join(variables, ", "))
+		}
+	}
+	printProfile(&preset.Profile)
+}
+
+// requirePreset returns a saved preset, exiting if there's no preset with the name
+func requirePreset(database *db.Database, name string) *db.Preset {
+	preset, err := database.GetPreset(name)
+	if err != nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	if preset == nil {
+		fmt.Printf("\033[31m❌ Preset '%s' not found. Use 'bai preset list' to see saved presets.\033[0m\n", name)
+		os.Exit(1)
+	}
+	return preset
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetSaveCmd)
+	presetCmd.AddCommand(presetListCmd)
+	presetCmd.AddCommand(presetDeleteCmd)
+
+	addProfileFlags(presetSaveCmd, "for trees started with the preset")
+	presetSaveCmd.Flags().String("message", "", "Scaffold of the first message; the seed's content fills in {{input}}")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+// profileFlag names the execution profile commands generate with, set by the --profile flag
+var profileFlag string
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List execution profiles, named settings to generate with using --profile",
+	Long: `List execution profiles. A profile is a named set of a model, temperature, max_tokens and
+system prompt that any generating command runs with when given --profile, such as a careful
+"research" profile and a fast, cheap "draft" one.
+
+With a profile, its model answers unless --llm is given, even in branches that have a model of their
+own, and its other settings override the global ones and the tree's for that command. Settings the
+profile doesn't set are left as configured. Profiles are stored in the Bonsai database.`,
+	Example: `  bai profiles save research --llm claude-3-5-sonnet --temperature 0.2 --max-tokens 4000 \
+    --system "Cite sources and flag uncertainty."
+  bai profiles save draft --llm gpt-4o-mini --temperature 1
+  bai "Summarize the tradeoffs" --profile research
+  bai batch --input prompts.txt --profile draft
+  bai profiles
+  bai profiles delete draft`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		profiles, err := database.GetProfiles()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(profiles) == 0 {
+			fmt.Println("\033[90mℹ️  No profiles saved. Use 'bai profiles save <name> --llm ...' to add one.\033[0m")
+			return
+		}
+
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("🎚️  Found %d profile(s):\n\n", len(profiles))
+		for _, name := range names {
+			fmt.Printf("• \033[33m%s\033[0m\n", name)
+			printProfile(profiles[name])
+		}
+	},
+}
+
+var profilesSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save an execution profile, replacing any with the same name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t\n") {
+			fmt.Printf("\033[31m❌ Profile names can't be empty or contain spaces.\033[0m\n")
+			os.Exit(1)
+		}
+
+		profile := profileFromFlags(cmd)
+		if *profile == (db.Profile{}) {
+			fmt.Printf("\033[31m❌ A profile needs at least one of --llm, --temperature, --max-tokens or --system.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if err := database.SaveProfile(name, profile); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🎚️  \033[32mSaved profile\033[0m \033[33m%s\033[0m\n", name)
+		printProfile(profile)
+	},
+}
+
+var profilesDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete an execution profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		deleted, err := database.DeleteProfile(name)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if !deleted {
+			fmt.Printf("\033[31m❌ Profile '%s' not found.\033[0m\n", name)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🗑️  \033[32mDeleted profile\033[0m \033[33m%s\033[0m\n", name)
+	},
+}
+
+// addProfileFlags adds the flags setting the parts of a profile to a command that saves one
+func addProfileFlags(cmd *cobra.Command, purpose string) {
+	cmd.Flags().StringP("llm", "l", "", "Model that responds when no --llm is given")
+	cmd.Flags().String("temperature", "", "Sampling temperature "+purpose+", from 0 to 2")
+	cmd.Flags().Int("max-tokens", 0, "Longest response to ask models for "+purpose+", in tokens")
+	cmd.Flags().String("system", "", "System prompt "+purpose)
+}
+
+// profileFromFlags reads the flags added by addProfileFlags, exiting if one is invalid
+func profileFromFlags(cmd *cobra.Command) *db.Profile {
+	profile := &db.Profile{}
+	var err error
+	if profile.Model, err = cmd.Flags().GetString("llm"); err != nil {
+		fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	if cmd.Flags().Changed("temperature") {
+		temperature, err := cmd.Flags().GetString("temperature")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get temperature flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if err := validateTemperature(temperature); err != nil {
+			fmt.Printf("\033[31m❌ Invalid --temperature: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		value, _ := strconv.ParseFloat(temperature, 64)
+		profile.Temperature = &value
+	}
+	if profile.MaxTokens, err = cmd.Flags().GetInt("max-tokens"); err != nil {
+		fmt.Printf("\033[31m❌ Failed to get max-tokens flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	if profile.MaxTokens < 0 {
+		fmt.Printf("\033[31m❌ Invalid --max-tokens: must be a whole number of 1 or more\033[0m\n")
+		os.Exit(1)
+	}
+	if profile.System, err = cmd.Flags().GetString("system"); err != nil {
+		fmt.Printf("\033[31m❌ Failed to get system flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	return profile
+}
+
+// printProfile prints the parts of a profile that are set, indented below its name
+func printProfile(profile *db.Profile) {
+	if profile.Model != "" {
+		fmt.Printf("  🧠 Model: \033[35m%s\033[0m\n", profile.Model)
+	}
+	if profile.Temperature != nil {
+		fmt.Printf("  🌡️  Temperature: \033[33m%s\033[0m\n", strconv.FormatFloat(*profile.Temperature, 'g', -1, 64))
+	}
+	if profile.MaxTokens > 0 {
+		fmt.Printf("  📏 Max tokens: \033[33m%d\033[0m\n", profile.MaxTokens)
+	}
+	if profile.System != "" {
+		fmt.Printf("  ⚙️  System: \033[90m%s\033[0m\n", truncateContent(strings.ReplaceAll(profile.System, "\n", " "), 60))
+	}
+}
+
+// selectedProfile returns the profile named with --profile, or nil if none was, exiting if there's
+// no profile with the name
+func selectedProfile(database *db.Database) *db.Profile {
+	if profileFlag == "" {
+		return nil
+	}
+	profile, err := database.GetProfile(profileFlag)
+	if err != nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	if profile == nil {
+		fmt.Printf("\033[31m❌ Profile '%s' not found. Use 'bai profiles' to see saved profiles.\033[0m\n", profileFlag)
+		os.Exit(1)
+	}
+	return profile
+}
+
+func init() {
+	rootCmd.AddCommand(profilesCmd)
+	profilesCmd.AddCommand(profilesSaveCmd)
+	profilesCmd.AddCommand(profilesDeleteCmd)
+
+	addProfileFlags(profilesSaveCmd, "for commands run with the profile")
+}
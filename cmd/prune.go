@@ -6,17 +6,40 @@ import (
 	"os"
 	"strings"
 
+	"github.com/aarose/bonsai/db"
 	"github.com/spf13/cobra"
 )
 
 var pruneCmd = &cobra.Command{
 	Use:   "prune <node-id>",
 	Short: "Cut off a branch of the conversation tree, deleting it",
-	Long:  `Cut off a branch of the conversation tree, deleting it. This action cannot be undone.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Cut off a branch of the conversation tree, deleting it. This action cannot be undone.
+
+Use --keep-children to remove only the given node and reattach its children to its parent,
+so a bad intermediate turn can be deleted without losing everything below it.
+
+Pruning asks for confirmation and refuses to run when stdin isn't a terminal, unless --yes
+is passed. Use --dry-run to only show what would be deleted.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		nodeID := args[0]
 
+		keepChildren, err := cmd.Flags().GetBool("keep-children")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get keep-children flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		var opts pruneOptions
+		if opts.dryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if opts.yes, err = cmd.Flags().GetBool("yes"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get yes flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Initialize database
 		database, err := initializeDatabase(false)
 		if err != nil {
@@ -25,6 +48,18 @@ var pruneCmd = &cobra.Command{
 		}
 		defer database.Close()
 
+		// Expand abbreviated node IDs such as those shown by 'bai recent'
+		nodeID, err = database.ResolveNodeID(nodeID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if keepChildren {
+			spliceNode(database, nodeID, opts)
+			return
+		}
+
 		// Get the node to be deleted and preview what will be affected
 		nodesToDelete, err := database.GetNodeAndAllChildren(nodeID)
 		if err != nil {
@@ -46,13 +81,7 @@ var pruneCmd = &cobra.Command{
 			} else { // Root node being deleted
 				indent = "• "
 			}
-			var typeIcon string
-			if node.Type == "user" {
-				typeIcon = "👤"
-			} else {
-				typeIcon = "🤖"
-			}
-			fmt.Printf("%s%s \033[33m%s\033[0m: \033[90m%s\033[0m\n", indent, typeIcon, node.ID, truncateContent(node.Content, 50))
+			fmt.Printf("%s%s \033[33m%s\033[0m: \033[90m%s\033[0m\n", indent, nodeTypeIcon(node.Type), node.ID, truncateContent(node.Content, 50))
 			if node.Model != nil {
 				fmt.Printf("%s🧠 Model: \033[35m%s\033[0m\n", strings.Repeat(" ", len(indent)), *node.Model)
 			}
@@ -65,52 +94,132 @@ var pruneCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		willDeleteCurrent := false
 		if currentNodeID != nil {
 			for _, node := range nodesToDelete {
 				if *currentNodeID == node.ID {
-					willDeleteCurrent = true
 					fmt.Printf("\n\033[33m⚠️  WARNING: This will delete your current working node!\033[0m\n")
 					break
 				}
 			}
 		}
 
-		// Ask for confirmation
-		fmt.Printf("\n\033[33mAre you sure you want to prune these nodes? This cannot be undone.\033[0m \033[1m(y/N):\033[0m ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to read input: %v\033[0m\n", err)
-			os.Exit(1)
+		if opts.dryRun {
+			fmt.Println("\n\033[90mℹ️  Dry run - nothing was deleted.\033[0m")
+			return
 		}
 
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		// Ask for confirmation
+		if !confirmPrune("Are you sure you want to prune these nodes? This cannot be undone.", opts) {
 			fmt.Println("\033[90mPruning cancelled.\033[0m")
 			return
 		}
 
-		// Perform the deletion
-		deletedCount, err := database.DeleteNodeAndAllChildren(nodeID)
-		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to delete nodes: %v\033[0m\n", err)
+		result, err := newSession(database).Prune(nodeID, false)
+		if result == nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
 			os.Exit(1)
 		}
-
-		// Clear current node if it was deleted
-		if willDeleteCurrent {
-			if err := database.ClearCurrentNode(); err != nil {
-				fmt.Printf("\033[33m⚠️  Deleted nodes but failed to clear current node: %v\033[0m\n", err)
-			} else {
-				fmt.Printf("\033[90mCurrent working node has been cleared.\033[0m\n")
-			}
+		if err != nil {
+			fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+		} else if result.CurrentCleared {
+			fmt.Printf("\033[90mCurrent working node has been cleared.\033[0m\n")
 		}
 
-		fmt.Printf("\033[32m✅ Successfully pruned %d node(s) from the Bonsai tree.\033[0m\n", deletedCount)
+		fmt.Printf("\033[32m✅ Successfully pruned %d node(s) from the Bonsai tree.\033[0m\n", result.Deleted)
 	},
 }
 
+// pruneOptions holds the prune command's safety flags
+type pruneOptions struct {
+	dryRun bool // Only show the preview
+	yes    bool // Skip the confirmation prompt
+}
+
+// spliceNode deletes a single node and reattaches its children to the node's parent
+func spliceNode(database *db.Database, nodeID string, opts pruneOptions) {
+	node, err := database.GetNodeByID(nodeID)
+	if err != nil {
+		fmt.Printf("\033[31m❌ Error: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	children, err := database.GetDirectChildren(nodeID)
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get child nodes: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("🪚 \033[33mThis will delete the following node:\033[0m\n\n")
+	fmt.Printf("• %s \033[33m%s\033[0m: \033[90m%s\033[0m\n", nodeTypeIcon(node.Type), node.ID, truncateContent(node.Content, 50))
+
+	if len(children) > 0 {
+		if node.Parent != nil {
+			fmt.Printf("\n🌿 Its %d child node(s) will be reattached to parent \033[33m%s\033[0m\n", len(children), *node.Parent)
+		} else {
+			fmt.Printf("\n🌱 Its %d child node(s) will become new seeds\n", len(children))
+		}
+	}
+
+	currentNodeID, err := database.GetCurrentNode()
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get current node: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	willDeleteCurrent := currentNodeID != nil && *currentNodeID == nodeID
+	if willDeleteCurrent {
+		fmt.Printf("\n\033[33m⚠️  WARNING: This will delete your current working node!\033[0m\n")
+	}
+
+	if opts.dryRun {
+		fmt.Println("\n\033[90mℹ️  Dry run - nothing was deleted.\033[0m")
+		return
+	}
+
+	if !confirmPrune("Are you sure you want to prune this node? This cannot be undone.", opts) {
+		fmt.Println("\033[90mPruning cancelled.\033[0m")
+		return
+	}
+
+	result, err := newSession(database).Prune(nodeID, true)
+	if result == nil {
+		fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+	} else if result.CurrentMovedTo != nil {
+		fmt.Printf("\033[90mCurrent working node moved to parent \033[33m%s\033[0m\n", *result.CurrentMovedTo)
+	} else if result.CurrentCleared {
+		fmt.Printf("\033[90mCurrent working node has been cleared.\033[0m\n")
+	}
+
+	fmt.Printf("\033[32m✅ Successfully pruned node %s and reattached %d child node(s).\033[0m\n", nodeID, result.Reattached)
+}
+
+// confirmPrune asks the user to confirm a prune and returns true if they agreed
+// With --yes it always agrees; without a terminal to ask on, the command exits instead
+func confirmPrune(prompt string, opts pruneOptions) bool {
+	if opts.yes {
+		return true
+	}
+	if !isTerminal(os.Stdin) {
+		fmt.Printf("\n\033[31m❌ Refusing to prune without a terminal to confirm on. Pass --yes to prune non-interactively.\033[0m\n")
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n\033[33m%s\033[0m \033[1m(y/N):\033[0m ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to read input: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
 // truncateContent truncates content to a specified length with ellipsis
 func truncateContent(content string, maxLen int) string {
 	if len(content) <= maxLen {
@@ -121,4 +230,7 @@ func truncateContent(content string, maxLen int) string {
 
 func init() {
 	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolP("keep-children", "k", false, "Delete only this node and reattach its children to its parent")
+	pruneCmd.Flags().Bool("dry-run", false, "Show what would be deleted without prompting or deleting anything")
+	pruneCmd.Flags().BoolP("yes", "y", false, "Prune without asking for confirmation")
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/spf13/cobra"
+)
+
+var quickCmd = &cobra.Command{
+	Use:   "quick <prompt>",
+	Short: "Ask a one-off question in the inbox tree and print only the answer",
+	Long: `Ask a question without leaving your place: the prompt is added as a new branch of the inbox tree,
+the model's answer is generated, and only the answer is printed, as plain text. It's meant for
+launchers such as Raycast and Alfred, and for shell aliases.
+
+The words of the prompt don't need quoting; with none, the prompt is read from stdin. The inbox is
+an "Inbox" tree created on first use, or any node the quick.inbox setting names. The answer comes
+from --llm, or else the model the inbox inherits, such as the generate.model setting. The current
+working node isn't moved. Errors go to stderr with a non-zero exit status, and nothing is printed
+to stdout.`,
+	Example: `  bai quick what is the capital of Australia
+  pbpaste | bai quick --llm gpt-4o-mini
+  alias q='bai quick'
+
+  # Send quick questions to a tree of your own
+  bai config set quick.inbox 3f2a9c1b`,
+	Run: func(cmd *cobra.Command, args []string) {
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to get llm flag: %v\n", err)
+			os.Exit(1)
+		}
+
+		prompt := strings.Join(args, " ")
+		if prompt == "" && !isTerminal(os.Stdin) {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to read prompt from stdin: %v\n", err)
+				os.Exit(1)
+			}
+			prompt = string(data)
+		}
+		if strings.TrimSpace(prompt) == "" {
+			fmt.Fprintln(os.Stderr, "❌ No prompt given: pass it as arguments or on stdin")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Stdout carries only the answer
+		session := newSession(database)
+		session.Hooks().OnError = func(event hooks.Event, err error) {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+
+		turn, err := session.Quick(context.Background(), prompt, llmModel, session.Timeout())
+		if err == nil && turn.Response == nil {
+			err = fmt.Errorf("no answer was generated")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(strings.TrimSpace(turn.Response.Content))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quickCmd)
+	quickCmd.Flags().StringP("llm", "l", "", "LLM model to answer with (defaults to the inbox's model)")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "List recently created or visited nodes across all trees",
+	Long: `List the most recently created or visited nodes across all conversation trees, newest first.
+
+Short IDs are shown so you can quickly resume where you left off with 'bai checkout <short-id>'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get limit flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		nodes, err := database.GetRecentNodes(limit)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(nodes) == 0 {
+			fmt.Println("\033[90mℹ️  No recent activity found.\033[0m")
+			return
+		}
+
+		currentNodeID, err := database.GetCurrentNode()
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get current node: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🕒 Recently active nodes:\n\n")
+		for _, node := range nodes {
+			activity, when := "created", node.CreatedAt
+			if node.VisitedAt > node.CreatedAt {
+				activity, when = "visited", node.VisitedAt
+			}
+
+			typeIcon := nodeTypeIcon(node.Type)
+
+			status := ""
+			if currentNodeID != nil && *currentNodeID == node.ID {
+				status = " \033[32m(current working node)\033[0m"
+			}
+
+			fmt.Printf("%s \033[33m%s\033[0m \033[90m%s %s\033[0m%s\n", typeIcon, shortID(node.ID), activity, formatAge(when), status)
+
+			// Show which tree the node belongs to
+			if history, err := database.GetConversationHistory(node.ID); err == nil && len(history) > 1 {
+				title := strings.ReplaceAll(history[0].Content, "\n", " ")
+				fmt.Printf("   🌳 \033[90m%s\033[0m\n", truncateContent(title, 60))
+			}
+
+			content := strings.ReplaceAll(node.Content, "\n", " ")
+			fmt.Printf("   💬 %s\n", truncateContent(content, 80))
+		}
+	},
+}
+
+// nodeTypeIcon returns the icon shown next to nodes of a type
+func nodeTypeIcon(nodeType string) string {
+	switch nodeType {
+	case "llm":
+		return "🤖"
+	case "note":
+		return "📝"
+	case "system":
+		return "⚙️"
+	default:
+		return "👤"
+	}
+}
+
+// formatAge describes how long ago a Unix timestamp was, e.g. "5m ago" or "3d ago"
+func formatAge(unix int64) string {
+	if unix == 0 {
+		return "at an unknown time"
+	}
+
+	elapsed := time.Since(time.Unix(unix, 0))
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	default:
+		return "on " + time.Unix(unix, 0).Format("2006-01-02")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(recentCmd)
+	recentCmd.Flags().IntP("limit", "n", 10, "Maximum number of nodes to show")
+}
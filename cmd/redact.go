@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/redact"
+	"github.com/spf13/cobra"
+)
+
+var redactCmd = &cobra.Command{
+	Use:   "redact <node-id>",
+	Short: "Scrub API keys, tokens and other secrets from existing nodes",
+	Long: `Scrub secrets matching the redaction rules from a node's content, replacing each with a marker like
+[REDACTED:openai-key]. Use --tree to scrub everything below the node too.
+
+Secrets are also scrubbed from new nodes as they're stored (unless redact.on_create is off), and from
+branches shared with 'bai share' and packed with 'bai bundle' (unless redact.on_export is off), so this
+is mainly for nodes added before a rule existed. Archives written by 'bai gc' keep nodes as stored.
+
+Built-in rules cover common API keys and tokens, private keys and email addresses; turn some off with
+the redact.disabled setting, and add your own with 'bai redact rule add'.`,
+	Example: `  bai redact 3f2a9c1b
+  bai redact 3f2a9c1b --tree --dry-run
+  bai redact rule add internal-host 'https?://[a-z0-9.-]+\.corp\.example\.com'
+  bai redact rule add password 'password\s*[:=]\s*(\S+)'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tree, err := cmd.Flags().GetBool("tree")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get tree flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		node, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		result, err := session.Redact(node.ID, tree, dryRun)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(result.Nodes) == 0 {
+			fmt.Println("\033[32m✅ No secrets found\033[0m")
+			return
+		}
+
+		for _, redacted := range result.Nodes {
+			fmt.Printf("🧹 \033[33m%s\033[0m \033[90m%s\033[0m\n", shortID(redacted.ID), truncateContent(strings.ReplaceAll(redacted.Content, "\n", " "), 70))
+		}
+
+		rules := make([]string, 0, len(result.Counts))
+		for rule, count := range result.Counts {
+			rules = append(rules, fmt.Sprintf("%d %s", count, rule))
+		}
+		sort.Strings(rules)
+
+		verb := "Redacted"
+		if dryRun {
+			verb = "Would redact"
+		}
+		fmt.Printf("\033[32m✅ %s %d secret(s) in %d node(s)\033[0m \033[90m(%s)\033[0m\n", verb, redact.Total(result.Counts), len(result.Nodes), strings.Join(rules, ", "))
+	},
+}
+
+var redactRuleCmd = &cobra.Command{
+	Use:   "rule",
+	Short: "Manage the rules secrets are matched with",
+}
+
+var redactRuleAddCmd = &cobra.Command{
+	Use:   "add <name> <pattern>",
+	Short: "Add a rule redacting text matching a regular expression",
+	Long: `Add a rule redacting text matching a regular expression in Go's syntax, replacing any rule with the
+same name. If the pattern has a capture group, only the text matching the first group is redacted.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, pattern := args[0], args[1]
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if err := database.SaveRedactionRule(name, pattern); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🧹 \033[32mAdded redaction rule\033[0m \033[33m%s\033[0m\n", name)
+	},
+}
+
+var redactRuleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and custom redaction rules",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		custom, err := database.GetRedactionRules()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		disabled, err := configString(database, db.RedactDisabledConfigKey, "")
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		off := make(map[string]bool)
+		for _, name := range strings.Split(disabled, ",") {
+			off[strings.TrimSpace(name)] = true
+		}
+
+		fmt.Println("🧹 Built-in rules:")
+		for _, rule := range redact.Builtin() {
+			status := ""
+			if off[rule.Name] {
+				status = " \033[90m(off)\033[0m"
+			}
+			fmt.Printf("• \033[33m%s\033[0m%s\n", rule.Name, status)
+		}
+
+		if len(custom) == 0 {
+			fmt.Println("\n\033[90mℹ️  No custom rules. Use 'bai redact rule add <name> <pattern>' to add one.\033[0m")
+			return
+		}
+
+		names := make([]string, 0, len(custom))
+		for name := range custom {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println("\n🧹 Custom rules:")
+		for _, name := range names {
+			fmt.Printf("• \033[33m%s\033[0m: \033[90m%s\033[0m\n", name, custom[name])
+		}
+	},
+}
+
+var redactRuleRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a custom redaction rule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		deleted, err := database.DeleteRedactionRule(name)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if !deleted {
+			fmt.Printf("\033[31m❌ Redaction rule '%s' not found.\033[0m\n", name)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🗑️  \033[32mRemoved redaction rule\033[0m \033[33m%s\033[0m\n", name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(redactCmd)
+	redactCmd.AddCommand(redactRuleCmd)
+	redactRuleCmd.AddCommand(redactRuleAddCmd)
+	redactRuleCmd.AddCommand(redactRuleListCmd)
+	redactRuleCmd.AddCommand(redactRuleRemoveCmd)
+	redactCmd.Flags().Bool("tree", false, "Also scrub every node below the given one")
+	redactCmd.Flags().Bool("dry-run", false, "Show what would be redacted without changing anything")
+}
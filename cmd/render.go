@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/aarose/bonsai/pkg/render"
+)
+
+// noRender keeps LLM responses as raw Markdown, set by the --no-render flag
+var noRender bool
+
+// renderMarkdown formats an LLM response's Markdown for the terminal. The raw text is kept with
+// --no-render, when NO_COLOR is set, or when output isn't going to a terminal.
+func renderMarkdown(content string) string {
+	if noRender || os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+		return content
+	}
+	return render.Markdown(content)
+}
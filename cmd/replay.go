@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [node-id]",
+	Short: "Step through a branch turn by turn",
+	Long: `Step through the conversation from the root down to a node, one turn at a time, pressing Enter to
+advance or q to stop. Without a node ID the branch ending at the current working node is replayed.
+
+With --llm, each LLM response is also regenerated with another model from the same history, and
+both versions are shown, to see how that model would have handled the conversation. Regenerated
+responses are only kept with --save, as siblings of the originals; the current working node stays
+where it is.`,
+	Example: `  bai replay 3f2a9c1b
+  bai replay 3f2a9c1b --llm claude-3-5-sonnet --save`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		save, err := cmd.Flags().GetBool("save")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get save flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if save && llmModel == "" {
+			fmt.Printf("\033[31m❌ --save needs --llm to regenerate responses with.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		var end *bonsai.Node
+		if len(args) == 1 {
+			end, err = session.Node(args[0])
+		} else {
+			end, err = session.Current()
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		branch, err := database.GetConversationHistory(end.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⏯️  Replaying %d turn(s) ending at \033[33m%s\033[0m", len(branch), end.ID)
+		if llmModel != "" {
+			fmt.Printf(", regenerating responses with \033[35m%s\033[0m", llmModel)
+		}
+		fmt.Println()
+
+		interactive := isTerminal(os.Stdin)
+		reader := bufio.NewReader(os.Stdin)
+		for i, node := range branch {
+			printReplayTurn(i+1, len(branch), node)
+
+			if llmModel != "" && node.Type == "llm" && node.Parent != nil {
+				replayResponse(session, branch[i-1], llmModel, save)
+			}
+
+			if interactive && i < len(branch)-1 {
+				fmt.Printf("\033[90m— Enter for the next turn, q to stop —\033[0m ")
+				input, err := reader.ReadString('\n')
+				if err != nil {
+					// Input ran out; play the rest without pausing
+					fmt.Println()
+					interactive = false
+				} else if strings.EqualFold(strings.TrimSpace(input), "q") {
+					return
+				}
+			}
+		}
+		fmt.Printf("\n⏹️  End of branch.\n")
+	},
+}
+
+// printReplayTurn prints one node of a replayed branch
+func printReplayTurn(turn, total int, node *bonsai.Node) {
+	fmt.Printf("\n\033[90m[%d/%d]\033[0m %s \033[33m%s\033[0m", turn, total, nodeTypeIcon(node.Type), shortID(node.ID))
+	if node.Type == "llm" && node.Model != nil {
+		fmt.Printf(" \033[35m%s\033[0m", *node.Model)
+	}
+	content := node.Content
+	if node.Type == "llm" {
+		content = renderMarkdown(content)
+	}
+	fmt.Printf("\n%s\n", content)
+	printQuotes(node)
+}
+
+// replayResponse regenerates the response to the given message with another model and prints it,
+// storing it as a sibling of the original response if save is set
+func replayResponse(session *bonsai.Session, message *bonsai.Node, model string, save bool) {
+	fmt.Printf("\n🔁 \033[35m%s\033[0m:\n", model)
+	warnAboutGeneration(session, message, model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), session.Timeout())
+	defer cancel()
+	ctx, generation := llm.WithGeneration(ctx)
+
+	response, err := session.Generate(ctx, message, model)
+	if err != nil {
+		fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+		return
+	}
+	fmt.Printf("%s\n", renderMarkdown(response))
+
+	if save {
+		node, err := session.AddResponse(message, response, model, generation)
+		if err != nil {
+			fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+			return
+		}
+		fmt.Printf("\033[90m💾 Saved as \033[33m%s\033[0m\n", node.ID)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringP("llm", "l", "", "Also regenerate each LLM response with this model")
+	replayCmd.Flags().Bool("save", false, "Keep regenerated responses as siblings of the originals")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var replyCmd = &cobra.Command{
+	Use:   "reply [node-id] <message>",
+	Short: "Add a message to a conversation, quoting other nodes",
+	Long: `Add a message below the current working node, like 'bai <message>', quoting excerpts of other
+nodes above it with --quote. Quoted nodes can come from any branch or tree. Their IDs are recorded
+in the new node's metadata, so 'bai log' and the visualization can link back to them.
+
+Given a node ID before the message, the message and the model's response are added below that node
+instead, without checking it out. The current working node stays where it is.`,
+	Example: `  # Bring an answer from another branch into this one
+  bai reply --quote 3f2a9c1b "How does this compare with the approach above?"
+
+  # Follow up on another branch without leaving this one
+  bai reply 3f2a9c1b "Can you expand on the second point?"`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var parentID string
+		message := args[len(args)-1]
+		if len(args) == 2 {
+			parentID = args[0]
+		}
+
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		noLLM, err := cmd.Flags().GetBool("no-llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get no-llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		quotes, err := cmd.Flags().GetStringArray("quote")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get quote flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		if err := limitContextFromFlags(cmd, session); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		previous, _ := database.GetCurrentNode()
+		node, err := session.Reply(message, bonsai.ReplyOptions{Parent: parentID, Model: llmModel, NoModel: noLLM, Quotes: quotes})
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🔄 \033[32mCreated child node with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
+		printQuotes(node)
+		if node.Model != nil {
+			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
+		}
+		fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+
+		if node.Model != nil && *node.Model != "" {
+			if _, err := generateChildResponse(session, node, *node.Model); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+
+		if current, err := database.GetCurrentNode(); err == nil && current != nil && previous != nil && *current == *previous {
+			fmt.Printf("📍 Current working node unchanged: \033[33m%s\033[0m\n", *current)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replyCmd)
+	replyCmd.Flags().StringArrayP("quote", "q", nil, "Node to quote above the message (repeatable)")
+	replyCmd.Flags().StringP("llm", "l", "", "LLM model to use (defaults to the branch's model)")
+	replyCmd.Flags().Bool("no-llm", false, "Add the message without a model, so nothing responds to it")
+	replyCmd.MarkFlagsMutuallyExclusive("llm", "no-llm")
+	addContextFlags(replyCmd)
+}
@@ -1,15 +1,14 @@
 package cmd
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"time"
 
 	"github.com/aarose/bonsai/db"
-	"github.com/aarose/bonsai/pkg/config"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/hooks"
 	"github.com/aarose/bonsai/pkg/llm"
 	"github.com/spf13/cobra"
 )
@@ -59,6 +58,12 @@ var rootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		noLLM, err := cmd.Flags().GetBool("no-llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get no-llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Initialize database
 		database, err := initializeDatabase(false)
 		if err != nil {
@@ -67,101 +72,32 @@ var rootCmd = &cobra.Command{
 		}
 		defer database.Close()
 
-		// Get current working node
-		currentNodeID, err := database.GetCurrentNode()
-		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to get current node: %v\033[0m\n", err)
+		session := newSession(database)
+		if err := limitContextFromFlags(cmd, session); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
 			os.Exit(1)
 		}
-
-		if currentNodeID == nil {
+		node, err := session.Reply(message, bonsai.ReplyOptions{Model: llmModel, NoModel: noLLM})
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
 			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
 			return
 		}
-
-		// Get the current working node to inherit model
-		currentNode, err := database.GetNodeByID(*currentNodeID)
 		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to get current node details: %v\033[0m\n", err)
-			os.Exit(1)
-		}
-
-		// Determine which model to use
-		var model *string
-		if llmModel != "" {
-			model = &llmModel // Use flag if provided
-		} else {
-			model = currentNode.Model // Inherit from parent
-		}
-
-		// Create child node
-		node, err := database.CreateChildNode(message, *currentNodeID, model)
-		if err != nil {
-			fmt.Printf("\033[31m❌ Failed to create child node: %v\033[0m\n", err)
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
 		fmt.Printf("🔄 \033[32mCreated child node with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
-		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *currentNodeID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
 		if node.Model != nil {
 			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
 		}
 		fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
 
 		// Generate LLM response if model is available
-		if model != nil && *model != "" {
-			fmt.Printf("Generating LLM response...\n")
-
-			// Get API key from environment or config
-			apiKey := config.GetAPIKey(*model)
-			if apiKey == "" {
-				fmt.Printf("Warning: No API key found for %s. Set %s environment variable.\n", *model, config.GetAPIKeyEnvVar(*model))
-			} else {
-				// Create LLM client
-				llmConfig := llm.Config{
-					APIKey:    apiKey,
-					MaxTokens: 1000, // Reasonable default
-				}
-
-				client, err := llm.NewClient(*model, llmConfig)
-				if err != nil {
-					fmt.Printf("Warning: Failed to create LLM client: %v\n", err)
-				} else {
-					// Get conversation history from the newly created node to root
-					conversationHistory, err := database.GetConversationHistory(node.ID)
-
-					var response string
-					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-					defer cancel()
-
-					if err != nil {
-						fmt.Printf("Warning: Failed to get conversation history: %v\n", err)
-						// Fallback to single message
-						response, err = client.GenerateResponse(ctx, message, *model)
-					} else {
-						// Convert conversation history to LLM messages
-						var messages []llm.Message
-						for _, historyNode := range conversationHistory {
-							messages = append(messages, llm.NodeToMessage(historyNode.Type, historyNode.Content))
-						}
-
-						// Generate response with conversation history
-						response, err = client.GenerateResponseFromHistory(ctx, messages, *model)
-					}
-
-					if err != nil {
-						fmt.Printf("Warning: Failed to get LLM response: %v\n", err)
-					} else {
-						// Create child node with LLM response
-						llmNode, err := database.CreateLLMResponseNode(node.ID, response, *model)
-						if err != nil {
-							fmt.Printf("Warning: Failed to create LLM response node: %v\n", err)
-						} else {
-							fmt.Printf("Created LLM response node with ID: \033[33m%s\033[0m\n", llmNode.ID)
-							fmt.Printf("🤖 LLM Response: %s\n", llmNode.Content)
-						}
-					}
-				}
+		if node.Model != nil && *node.Model != "" {
+			if _, err := generateChildResponse(session, node, *node.Model); err != nil {
+				fmt.Printf("Warning: %v\n", err)
 			}
 		}
 	},
@@ -174,15 +110,11 @@ func Execute() error {
 // initializeDatabase creates and initializes the database, returning the connection
 // If closeAfterInit is true, closes the connection and returns nil database
 func initializeDatabase(closeAfterInit bool) (*db.Database, error) {
-	// Get user's home directory
-	homeDir, err := os.UserHomeDir()
+	dbPath, err := bonsai.DefaultPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
 
-	// Create database path in user's home directory
-	dbPath := filepath.Join(homeDir, ".bonsai", "bonsai.db")
-
 	// Create and initialize database
 	database, err := db.NewDatabase(dbPath)
 	if err != nil {
@@ -203,7 +135,59 @@ func initializeDatabase(closeAfterInit bool) (*db.Database, error) {
 	return database, nil
 }
 
+// newSession wraps the database in a library session that reports hook failures as warnings,
+// generates with the profile chosen with --profile within the --timeout given and, unless
+// git.capture is off, records the git commit checked out in the working directory on new nodes
+func newSession(database *db.Database) *bonsai.Session {
+	session := bonsai.NewSession(database)
+	session.Hooks().OnError = printHookError
+	if capture, err := configString(database, gitCaptureConfigKey, "on"); err == nil && capture == "on" {
+		session.CaptureGit(".")
+	}
+	if enabled, err := database.ResponseCacheEnabled(); err == nil && enabled && !noCache {
+		session.UseCache(true)
+	}
+	session.UsePool(newPool(database))
+	session.UseProfile(selectedProfile(database))
+	session.ForceModel(forceModel)
+	session.UseTimeout(timeoutFlag)
+	return session
+}
+
+// newPool creates the pool bounding the session's requests from the generate.concurrency and
+// generate.provider_limits settings, falling back to the defaults for settings it can't read
+func newPool(database *db.Database) *llm.Pool {
+	workers, err := configInt(database, concurrencyConfigKey, llm.DefaultWorkers)
+	if err != nil {
+		workers = llm.DefaultWorkers
+	}
+
+	var limits map[string]int
+	if value, err := configString(database, providerLimitsConfigKey, ""); err == nil {
+		limits, _ = llm.ParseProviderLimits(value)
+	}
+	return llm.NewPool(workers, limits)
+}
+
+// fireHook runs the hooks for a change the command made directly through the database
+func fireHook(database *db.Database, event hooks.Event, node *db.Node) {
+	newSession(database).Notify(event, node)
+}
+
+// printHookError warns about a hook that failed without failing the command
+func printHookError(event hooks.Event, err error) {
+	fmt.Printf("\033[33m⚠️  %v\033[0m\n", err)
+}
+
 func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.PersistentFlags().BoolVar(&noRender, "no-render", false, "Print LLM responses as raw Markdown instead of formatting them")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always ask the model, bypassing the response cache")
+	rootCmd.PersistentFlags().BoolVar(&forceModel, "force-model", false, "Send model names bai doesn't recognize to the provider as-is instead of failing")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Generate with the model and settings of a profile saved with 'bai profiles save'")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "Longest each LLM request may take, e.g. 5m (defaults to generate.timeout, or 2m)")
 	rootCmd.Flags().StringP("llm", "l", "", "LLM model to use for the conversation (e.g., gpt-4, claude-3-sonnet, gpt-3.5-turbo)")
+	rootCmd.Flags().Bool("no-llm", false, "Add the message without a model, so nothing responds to it")
+	rootCmd.MarkFlagsMutuallyExclusive("llm", "no-llm")
+	addContextFlags(rootCmd)
 }
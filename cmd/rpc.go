@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/rpc"
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc --stdio",
+	Short: "Speak JSON-RPC on stdin and stdout, for editor plugins",
+	Long: `Serve the Bonsai API as JSON-RPC 2.0 on stdin and stdout, so an editor plugin can start one bai
+process and drive it for the whole session instead of running a command for each action.
+
+Each request and response is one line of JSON. The methods mirror the gRPC API in
+proto/bonsai/v1/bonsai.proto, with params and results in its JSON encoding:
+
+  listTrees, getTree, getBranch, listChildren, getNode, createNode, updateNode, deleteNode,
+  search, getCurrent, setCurrent, generate, streamGenerate, watchEvents
+
+streamGenerate sends a "chunk" notification with each piece of the response as it's generated,
+then answers with the stored node. watchEvents sends an "event" notification for each change to
+the tree, including changes made by other bai processes, until it's cancelled. Both carry the
+request's id in their params. Cancel any request with a "$/cancelRequest" notification giving its
+id. Requests run concurrently, and the process exits when stdin is closed.
+
+Hook failures and other diagnostics go to stderr, which plugins should log rather than parse.`,
+	Example: `  $ bai rpc --stdio
+  {"jsonrpc": "2.0", "id": 1, "method": "listTrees", "params": {"limit": 10}}
+  {"jsonrpc": "2.0", "id": 2, "method": "getBranch", "params": {"id": "3f2a9c1b"}}
+  {"jsonrpc": "2.0", "id": 3, "method": "createNode", "params": {"parent": "3f2a9c1b", "content": "Why?"}}
+  {"jsonrpc": "2.0", "id": 4, "method": "streamGenerate", "params": {"parent": "<new node id>"}}
+  {"jsonrpc": "2.0", "method": "$/cancelRequest", "params": {"id": 4}}`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		stdio, err := cmd.Flags().GetBool("stdio")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get stdio flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		readOnly, err := cmd.Flags().GetBool("readonly")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get readonly flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if !stdio {
+			fmt.Printf("\033[31m❌ Pass --stdio: it's the only transport 'bai rpc' speaks. For a network API, use 'bai serve'.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		// Stdout carries the protocol, so nothing else may be printed there
+		session := newSession(database)
+		session.Hooks().OnError = func(event hooks.Event, err error) {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+
+		if err := rpc.ServeJSONRPC(cmd.Context(), os.Stdin, os.Stdout, session, readOnly); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+	rpcCmd.Flags().Bool("stdio", false, "Speak JSON-RPC on stdin and stdout")
+	rpcCmd.Flags().Bool("readonly", false, "Reject every request that would change the tree")
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <workflow.yaml>",
+	Short: "Run a prompt pipeline described in a YAML workflow",
+	Long: `Run the steps of a YAML workflow in order, adding each step's message and the model's response as
+nodes. Steps continue from the previous step unless they name another with 'from', so a workflow can
+branch. A step with a 'when' condition is skipped, with everything that branches from it, unless the
+output it tests contains, lacks or matches the given text.
+
+Prompts are templates: {{name}} is filled in from the workflow's vars, --var flags, or the response
+of an earlier step with that id. Without a 'seed', the workflow runs below the current working node.
+
+  name: code-review
+  model: gpt-4o
+  seed: "Here is some {{language}} code:\n\n{{code}}"
+  steps:
+    - id: bugs
+      prompt: List any bugs in this code. Reply NONE if there are none.
+    - id: fixes
+      prompt: "Suggest fixes for these bugs:\n\n{{bugs}}"
+      when: {not_contains: NONE}
+    - id: style
+      from: start
+      template: style-review
+      model: claude-3-haiku`,
+	Example: `  bai run review.yaml --var language=Go --var code="$(cat main.go)"`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		assignments, err := cmd.Flags().GetStringArray("var")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get var flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		values := make(map[string]string)
+		for _, assignment := range assignments {
+			key, value, ok := strings.Cut(assignment, "=")
+			if !ok || key == "" {
+				fmt.Printf("\033[31m❌ Invalid --var %q: expected name=value\033[0m\n", assignment)
+				os.Exit(1)
+			}
+			values[key] = value
+		}
+
+		workflow, err := bonsai.LoadWorkflow(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		name := workflow.Name
+		if name == "" {
+			name = args[0]
+		}
+		fmt.Printf("🔁 Running workflow \033[36m%s\033[0m (%d step(s))...\n", name, len(workflow.Steps))
+
+		session := newSession(database)
+		started := time.Now()
+		_, err = session.RunWorkflow(context.Background(), workflow, bonsai.WorkflowOptions{
+			Vars:    values,
+			Model:   llmModel,
+			Timeout: session.Timeout(),
+			OnStep:  printWorkflowStep,
+		})
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first, or give the workflow a seed.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		notifyIfSlowOrWarn(database, started, "bai run finished", fmt.Sprintf("Workflow %s finished", name))
+		if current, err := database.GetCurrentNode(); err == nil && current != nil {
+			fmt.Printf("\n✅ \033[32mWorkflow finished.\033[0m Current working node: \033[33m%s\033[0m\n", *current)
+		}
+	},
+}
+
+// printWorkflowStep prints the nodes a workflow step created, or that it was skipped
+func printWorkflowStep(result bonsai.WorkflowResult) {
+	if result.Skipped {
+		fmt.Printf("\n⏭️  \033[90m%s skipped\033[0m\n", result.Step.ID)
+		return
+	}
+
+	fmt.Printf("\n▶️  \033[36m%s\033[0m\n", result.Step.ID)
+	fmt.Printf("💬 Message \033[33m%s\033[0m: \033[90m%s\033[0m\n", result.Message.ID, truncateContent(strings.ReplaceAll(result.Message.Content, "\n", " "), 80))
+	if result.Response != nil {
+		model := ""
+		if result.Response.Model != nil {
+			model = *result.Response.Model
+		}
+		fmt.Printf("🤖 Response \033[33m%s\033[0m (\033[35m%s\033[0m): %s\n", result.Response.ID, model, renderMarkdown(result.Response.Content))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringArray("var", nil, "Value for a workflow variable, as name=value (repeatable)")
+	runCmd.Flags().StringP("llm", "l", "", "Default LLM model, overriding the workflow's")
+}
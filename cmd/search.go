@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search node content across all trees",
+	Long: `Full-text search over the content of every node, best matches first.
+
+Every word in the query must appear in a node for it to match. Words also match longer words
+that start with them and other forms of the same word, so "run" finds "running" and "runs".`,
+	Example: `  bai search goroutine deadlock
+  bai search "train ottawa" --limit 5`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get limit flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		query := strings.Join(args, " ")
+		results, err := database.SearchNodes(query, limit)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("\033[90mℹ️  No nodes match \"%s\".\033[0m\n", query)
+			return
+		}
+
+		highlighter := strings.NewReplacer(db.SnippetMatchStart, "\033[1;33m", db.SnippetMatchEnd, "\033[0m", "\n", " ")
+		fmt.Printf("🔍 %d match(es) for \"%s\":\n\n", len(results), query)
+		for _, result := range results {
+			fmt.Printf("%s \033[33m%s\033[0m \033[90m%s\033[0m\n", nodeTypeIcon(result.Node.Type), shortID(result.Node.ID), formatAge(result.Node.CreatedAt))
+			fmt.Printf("   💬 %s\n", highlighter.Replace(result.Snippet))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntP("limit", "n", 20, "Maximum number of results to show")
+}
@@ -4,22 +4,42 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/aarose/bonsai/db"
-	"github.com/aarose/bonsai/pkg/config"
-	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/aarose/bonsai/pkg/bonsai"
 	"github.com/spf13/cobra"
 )
 
+// fetchTimeout bounds downloading a page for 'bai seed --from-url'
+const fetchTimeout = 30 * time.Second
+
 var seedCmd = &cobra.Command{
-	Use:   "seed <content>",
+	Use:   "seed [content]",
 	Short: "Create a new root node with the given content",
-	Long:  `Create a new root node (no parent) with the provided content. The node type will be set to "user".`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new root node (no parent) with the provided content. The node type will be set to "user".
+The model given with --llm responds to it, or without --llm the one set with 'bai config set
+generate.model'. --no-llm creates just the node, without a model, so nothing responds to it.
+
+The content can also come from a file with --from-file or a web page with --from-url. HTML is reduced
+to its readable text unless --raw is given. Any content given as an argument is added before it, as
+an instruction such as "Summarize this article". With --as-context, the file or page becomes a
+separate root node and the argument is asked as a message below it.
+
+--preset starts the tree from a preset saved with 'bai preset save': its system prompt and parameters
+become the tree's settings, its message scaffold is filled in with the content, and its model
+responds unless --llm or --no-llm is given.`,
+	Example: `  bai seed "Plan a trip to Kyoto" --llm gpt-4
+  bai seed --from-file notes.md --no-llm
+  bai seed "Summarize this article" --from-url https://example.com/post --llm gpt-4
+  bai seed "What are the open questions?" --from-file design.md --as-context --llm gpt-4
+  bai seed --preset brainstorm "names for a coffee shop"`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		content := args[0]
+		var content string
+		if len(args) > 0 {
+			content = args[0]
+		}
 
 		// Get LLM flag value
 		llmModel, err := cmd.Flags().GetString("llm")
@@ -28,37 +48,95 @@ var seedCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Get user's home directory
-		homeDir, err := os.UserHomeDir()
+		noLLM, err := cmd.Flags().GetBool("no-llm")
 		if err != nil {
-			fmt.Printf("Failed to get home directory: %v\n", err)
+			fmt.Printf("\033[31m❌ Failed to get no-llm flag: %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
-		// Create database path in user's home directory
-		dbPath := filepath.Join(homeDir, ".bonsai", "bonsai.db")
+		presetName, err := cmd.Flags().GetString("preset")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get preset flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
 
-		// Create and initialize database
-		database, err := db.NewDatabase(dbPath)
+		source, sourceContent := readSeedSource(cmd)
+		asContext, err := cmd.Flags().GetBool("as-context")
 		if err != nil {
-			fmt.Printf("Failed to create database: %v\n", err)
+			fmt.Printf("\033[31m❌ Failed to get as-context flag: %v\033[0m\n", err)
 			os.Exit(1)
 		}
-		defer database.Close()
 
-		if err := database.Initialize(); err != nil {
-			fmt.Printf("Failed to initialize database: %v\n", err)
+		switch {
+		case source == "" && content == "" && presetName == "":
+			fmt.Printf("\033[31m❌ Give the seed's content, or use --from-file or --from-url.\033[0m\n")
+			os.Exit(1)
+		case source == "" && asContext:
+			fmt.Printf("\033[31m❌ --as-context needs --from-file or --from-url.\033[0m\n")
+			os.Exit(1)
+		case asContext && content == "" && presetName == "":
+			fmt.Printf("\033[31m❌ --as-context needs a message to ask about the content.\033[0m\n")
 			os.Exit(1)
 		}
 
-		// Create root node with model
-		var model *string
-		if llmModel != "" {
-			model = &llmModel
+		session, err := bonsai.OpenDefault()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer session.Close()
+		session.Hooks().OnError = printHookError
+		session.UseProfile(selectedProfile(session.Database()))
+		session.ForceModel(forceModel)
+		session.UseTimeout(timeoutFlag)
+
+		// A preset's scaffold wraps the content, and its model responds unless --llm, --no-llm or a
+		// profile chooses another
+		var preset *db.Preset
+		if presetName != "" {
+			preset = requirePreset(session.Database(), presetName)
+			values := make(map[string]string)
+			if content != "" {
+				values[bonsai.PresetInputVariable] = content
+			}
+			promptForVariables(bonsai.TemplateVariables(preset.Message), values)
+			if content, err = bonsai.PresetMessage(preset, content, values); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			switch {
+			case source == "" && content == "":
+				fmt.Printf("\033[31m❌ Preset '%s' has no message: give the seed's content, or use --from-file or --from-url.\033[0m\n", presetName)
+				os.Exit(1)
+			case asContext && content == "":
+				fmt.Printf("\033[31m❌ --as-context needs a message to ask about the content.\033[0m\n")
+				os.Exit(1)
+			}
+			if llmModel == "" && !noLLM && (session.Profile() == nil || session.Profile().Model == "") {
+				llmModel = preset.Model
+			}
+		}
+
+		// Without --llm, the profile's or the configured default model responds unless --no-llm is given
+		if llmModel == "" && !noLLM {
+			if llmModel, err = session.DefaultModel(); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
 		}
-		node, err := database.CreateRootNode(content, model)
+
+		// Without --as-context, the instruction and the source share the root node
+		rootContent := content
+		if source != "" {
+			rootContent = sourceContent
+			if content != "" && !asContext {
+				rootContent = content + "\n\n" + sourceContent
+			}
+		}
+
+		node, err := session.Seed(rootContent, llmModel)
 		if err != nil {
-			fmt.Printf("Failed to create root node: %v\n", err)
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
@@ -66,51 +144,98 @@ var seedCmd = &cobra.Command{
 		if node.Model != nil {
 			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
 		}
-		fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+		if preset != nil {
+			if err := session.ApplyPreset(node.ID, presetName, preset); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("🎛️  Preset: \033[36m%s\033[0m\n", presetName)
+		}
+		if source != "" {
+			if err := session.Database().SetNodeMetadata(node.ID, "source", source); err != nil {
+				fmt.Printf("\033[33m⚠️  Failed to record the seed's source: %v\033[0m\n", err)
+			}
+			fmt.Printf("📄 Source: \033[36m%s\033[0m \033[90m(%d characters)\033[0m\n", source, len(sourceContent))
+			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", truncateContent(node.Content, 200))
+		} else {
+			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+		}
+
+		// Ask the question below the context node
+		if asContext {
+			node, err = session.Reply(content, bonsai.ReplyOptions{Model: llmModel, NoModel: noLLM})
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("🔄 \033[32mCreated child node with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+		}
 
-		// Generate LLM response if model is specified
+		// Generate LLM response if model is specified or configured
 		if llmModel != "" {
-			fmt.Printf("Generating LLM response...\n")
-
-			// Get API key from environment or config
-			apiKey := config.GetAPIKey(llmModel)
-			if apiKey == "" {
-				fmt.Printf("Warning: No API key found for %s. Set %s environment variable.\n", llmModel, config.GetAPIKeyEnvVar(llmModel))
-			} else {
-				// Create LLM client
-				llmConfig := llm.Config{
-					APIKey:    apiKey,
-					MaxTokens: 1000, // Reasonable default
-				}
-
-				client, err := llm.NewClient(llmModel, llmConfig)
-				if err != nil {
-					fmt.Printf("Warning: Failed to create LLM client: %v\n", err)
-				} else {
-					// Generate response with timeout
-					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-					defer cancel()
-
-					response, err := client.GenerateResponse(ctx, content, llmModel)
-					if err != nil {
-						fmt.Printf("Warning: Failed to get LLM response: %v\n", err)
-					} else {
-						// Create child node with LLM response
-						llmNode, err := database.CreateLLMResponseNode(node.ID, response, llmModel)
-						if err != nil {
-							fmt.Printf("Warning: Failed to create LLM response node: %v\n", err)
-						} else {
-							fmt.Printf("Created LLM response node with ID: \033[33m%s\033[0m\n", llmNode.ID)
-							fmt.Printf("🤖 LLM Response: %s\n", llmNode.Content)
-						}
-					}
-				}
+			if _, err := generateChildResponse(session, node, llmModel); err != nil {
+				fmt.Printf("Warning: %v\n", err)
 			}
+		} else if !noLLM {
+			fmt.Println("\033[90mℹ️  No model responded: pass one with -l, e.g. -l gpt-4o, or set a default with 'bai config set generate.model <model>'. --no-llm skips this hint.\033[0m")
 		}
 	},
 }
 
+// readSeedSource reads the content named by --from-file or --from-url, returning the source and its
+// content, or empty strings if neither flag is set
+func readSeedSource(cmd *cobra.Command) (string, string) {
+	fromFile, err := cmd.Flags().GetString("from-file")
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get from-file flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	fromURL, err := cmd.Flags().GetString("from-url")
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get from-url flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+	raw, err := cmd.Flags().GetBool("raw")
+	if err != nil {
+		fmt.Printf("\033[31m❌ Failed to get raw flag: %v\033[0m\n", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case fromFile != "" && fromURL != "":
+		fmt.Printf("\033[31m❌ Use either --from-file or --from-url, not both.\033[0m\n")
+		os.Exit(1)
+	case fromFile != "":
+		content, err := bonsai.ReadSourceFile(fromFile, raw)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		return fromFile, content
+	case fromURL != "":
+		fmt.Printf("🌐 Fetching \033[36m%s\033[0m...\n", fromURL)
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+
+		content, err := bonsai.FetchSourceURL(ctx, fromURL, raw)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		return fromURL, content
+	}
+	return "", ""
+}
+
 func init() {
 	rootCmd.AddCommand(seedCmd)
-	seedCmd.Flags().StringP("llm", "l", "", "LLM model to use for the conversation (e.g., gpt-4, claude-3-sonnet, gpt-3.5-turbo)")
+	seedCmd.Flags().StringP("llm", "l", "", "LLM model to use for the conversation (e.g., gpt-4, claude-3-sonnet, gpt-3.5-turbo; defaults to generate.model)")
+	seedCmd.Flags().Bool("no-llm", false, "Create the seed without a model, so nothing responds to it")
+	seedCmd.MarkFlagsMutuallyExclusive("llm", "no-llm")
+	seedCmd.Flags().String("from-file", "", "Use the contents of a file as the seed")
+	seedCmd.Flags().String("from-url", "", "Use the text of a web page as the seed")
+	seedCmd.Flags().Bool("raw", false, "Keep HTML from --from-file or --from-url as-is instead of extracting its text")
+	seedCmd.Flags().Bool("as-context", false, "Store the file or page as its own root node and ask the message below it")
+	seedCmd.Flags().String("preset", "", "Start the tree from a preset saved with 'bai preset save'")
 }
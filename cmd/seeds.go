@@ -3,15 +3,37 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/aarose/bonsai/db"
 	"github.com/spf13/cobra"
 )
 
 var seedsCmd = &cobra.Command{
 	Use:   "seeds",
 	Short: "List all root nodes (conversation tree seeds)",
-	Long:  `List all root nodes (conversation tree seeds)`,
+	Long: `List all root nodes (conversation tree seeds), each with its tree's size, depth, number of
+branches and when it was last active.
+
+Filter the roots by model, type or creation date with --model, --type, --since and --until. Trees
+are listed most recently active first; --sort created lists the newest first and --sort size the
+largest. Use --limit with --page or --offset to browse large gardens a page at a time.`,
+	Example: `  bai seeds
+  bai seeds --model 'claude*' --since 2024-01-01
+  bai seeds --sort size --limit 10
+  bai seeds --page 2`,
 	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := nodeFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		opts, err := listOptionsFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
 		// Initialize database
 		database, err := initializeDatabase(false)
 		if err != nil {
@@ -20,48 +42,58 @@ var seedsCmd = &cobra.Command{
 		}
 		defer database.Close()
 
-		// Get all root nodes
-		rootNodes, err := database.GetRootNodes()
+		// Get the page of root nodes matching the filter
+		rootNodes, total, err := database.ListRootNodes(filter, opts)
 		if err != nil {
 			fmt.Printf("\033[31m❌ Failed to get root nodes: %v\033[0m\n", err)
 			os.Exit(1)
 		}
 
-		// Get current working node
+		// Get current working node and the tree it's in
 		currentNodeID, err := database.GetCurrentNode()
 		if err != nil {
 			fmt.Printf("\033[31m❌ Failed to get current node: %v\033[0m\n", err)
 			os.Exit(1)
 		}
+		var currentRootID string
+		if currentNodeID != nil {
+			if currentRootID, err = database.GetRootID(*currentNodeID); err != nil {
+				fmt.Printf("\033[33m⚠️  Warning: Failed to find the tree of the current node: %v\033[0m\n", err)
+			}
+		}
 
 		if len(rootNodes) == 0 {
+			if total > 0 {
+				fmt.Printf("\033[90mℹ️  No seeds on this page; there are %d in all.\033[0m\n", total)
+				return
+			}
+			if !filter.IsZero() {
+				fmt.Println("\033[90mℹ️  No root nodes match the filter.\033[0m")
+				return
+			}
 			fmt.Println("\033[90mℹ️  No root nodes found.\033[0m")
 			return
 		}
 
-		fmt.Printf("🌱 Found %d seed(s) in the Bonsai garden:\n\n", len(rootNodes))
+		// Summarize every listed tree in one query
+		ids := make([]string, len(rootNodes))
+		for i, node := range rootNodes {
+			ids[i] = node.ID
+		}
+		activity, err := database.GetTreeActivityForRoots(ids)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🌱 Found %s seed(s) in the Bonsai garden:\n\n", describeListPage(opts, len(rootNodes), total))
 		for _, node := range rootNodes {
 			var statusMessage string
-
-			if currentNodeID != nil {
-				// Check if this root node is the current working node
-				if *currentNodeID == node.ID {
-					statusMessage = " \033[32m(current working node)\033[0m"
-				} else {
-					// Check if current working node is a descendant of this root node
-					descendants, err := database.GetNodeAndAllChildren(node.ID)
-					if err != nil {
-						fmt.Printf("\033[33m⚠️  Warning: Failed to get descendants for node %s: %v\033[0m\n", node.ID, err)
-					} else {
-						// Check descendants (excluding the root node itself)
-						for _, descendant := range descendants {
-							if descendant.ID == *currentNodeID && descendant.ID != node.ID {
-								statusMessage = " \033[36m(contains current working node)\033[0m"
-								break
-							}
-						}
-					}
-				}
+			switch {
+			case currentNodeID != nil && *currentNodeID == node.ID:
+				statusMessage = " \033[32m(current working node)\033[0m"
+			case currentRootID == node.ID:
+				statusMessage = " \033[36m(contains current working node)\033[0m"
 			}
 
 			// Print the node with highlighting if applicable
@@ -70,7 +102,10 @@ var seedsCmd = &cobra.Command{
 			if node.Model != nil {
 				fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
 			}
-			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+			if tree, ok := activity[node.ID]; ok {
+				printTreeActivity(tree)
+			}
+			fmt.Printf("💬 Message: \033[90m%s\033[0m\n", truncateContent(strings.ReplaceAll(node.Content, "\n", " "), 100))
 			fmt.Println()
 		}
 	},
@@ -78,4 +113,15 @@ var seedsCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(seedsCmd)
+	addNodeFilterFlags(seedsCmd)
+	addListFlags(seedsCmd)
+}
+
+// printTreeActivity prints a tree's size, shape and when it was last active on one line
+func printTreeActivity(tree *db.TreeActivity) {
+	line := fmt.Sprintf("📊 \033[33m%d\033[0m node(s) · depth \033[33m%d\033[0m · \033[33m%d\033[0m branch(es)", tree.NodeCount, tree.MaxDepth, tree.Branches)
+	if tree.LastActivity > 0 {
+		line += " · active \033[90m" + formatAge(tree.LastActivity) + "\033[0m"
+	}
+	fmt.Println(line)
 }
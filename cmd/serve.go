@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/rpc"
+	"github.com/aarose/bonsai/web"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the Bonsai API server without the visualization",
+	Long: `Runs the JSON API served by 'bai visualize' on its own, without the visualization pages.
+
+It's meant to run in the background as a service that other bai instances, TUIs and editor
+plugins talk to over HTTP instead of opening the SQLite database directly. Unlike visualize,
+it creates the database if it doesn't exist yet, and fails instead of moving to another port
+when the port is taken, so clients can rely on the address. The API is described by the
+OpenAPI document at /api/v1/openapi.json.
+
+With --grpc-port, the same API is also served over gRPC, as defined in
+proto/bonsai/v1/bonsai.proto. It uses the same access token, sent as
+'authorization: Bearer <token>' metadata, and the same TLS certificate.
+
+Prompts scheduled with 'bai cron' are run while the server is up, unless it's --readonly.
+
+The same --host, --token, TLS, reverse proxy, CORS and --readonly flags as 'bai visualize'
+apply. The server shuts down gracefully on SIGINT or SIGTERM, and SIGHUP reopens the database
+and rereads its settings. PUT /api/v1/db switches to another database file without a restart.`,
+	Example: `  # Serve the API on localhost:8080
+  bai serve
+
+  # Serve it to other machines, requiring an access token
+  BONSAI_WEB_TOKEN=secret bai serve --host 0.0.0.0
+
+  # Serve gRPC on port 9090 as well
+  bai serve --grpc-port 9090`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := serveFlags.options(serveFlags.port)
+		opts.Headless = true
+
+		var alongside []func(ctx context.Context) error
+		if serveGRPCPort > 0 {
+			alongside = append(alongside, func(ctx context.Context) error {
+				return serveGRPC(ctx, serveFlags.databasePath(), opts)
+			})
+		}
+		// Scheduled prompts write to the tree, so a read-only server leaves them to another one
+		if !opts.ReadOnly {
+			alongside = append(alongside, func(ctx context.Context) error {
+				return runSchedules(ctx, serveFlags.databasePath())
+			})
+		}
+		runServer(serveFlags.databasePath(), opts, alongside...)
+	},
+}
+
+var (
+	serveFlags    serverFlags
+	serveGRPCPort int
+)
+
+// serveGRPC runs the gRPC API on --grpc-port with the web server's host, token and TLS settings until ctx is cancelled
+func serveGRPC(ctx context.Context, dbPath string, opts web.Options) error {
+	session, err := bonsai.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	address := net.JoinHostPort(opts.Host, strconv.Itoa(serveGRPCPort))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	fmt.Printf("🔌 gRPC API listening on %s\n", address)
+
+	return rpc.Serve(ctx, listener, session, rpc.Options{
+		Token:    opts.Token,
+		CertFile: opts.CertFile,
+		KeyFile:  opts.KeyFile,
+		ReadOnly: opts.ReadOnly,
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveFlags.register(serveCmd, 8080)
+	serveCmd.Flags().IntVar(&serveGRPCPort, "grpc-port", 0,
+		"Also serve the API over gRPC on this port")
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aarose/bonsai/web"
+	"github.com/spf13/cobra"
+)
+
+// serverFlags holds the settings shared by the commands that run the web server
+type serverFlags struct {
+	port        int
+	database    string
+	host        string
+	token       string
+	tlsCert     string
+	tlsKey      string
+	basePath    string
+	trustProxy  bool
+	readOnly    bool
+	corsOrigins []string
+}
+
+// register adds the shared server flags to a command
+func (f *serverFlags) register(cmd *cobra.Command, defaultPort int) {
+	cmd.Flags().IntVarP(&f.port, "port", "p", defaultPort,
+		"Port to run the web server on")
+	cmd.Flags().StringVarP(&f.database, "database", "d", "",
+		"Path to database file (defaults to ~/.bonsai/bonsai.db)")
+	cmd.Flags().StringVar(&f.host, "host", web.DefaultHost,
+		"Interface to listen on; use 0.0.0.0 to allow access from other machines")
+	cmd.Flags().StringVar(&f.token, "token", "",
+		"Access token required by every request (generated automatically for non-local hosts)")
+	cmd.Flags().StringVar(&f.tlsCert, "tls-cert", "",
+		"TLS certificate file; serves HTTPS together with --tls-key")
+	cmd.Flags().StringVar(&f.tlsKey, "tls-key", "",
+		"TLS private key file")
+	cmd.Flags().StringVar(&f.basePath, "base-path", "",
+		"Path prefix to serve under, e.g. /bonsai")
+	cmd.Flags().BoolVar(&f.trustProxy, "trust-proxy", false,
+		"Trust X-Forwarded-Proto and X-Forwarded-Prefix headers from a reverse proxy")
+	cmd.Flags().BoolVar(&f.readOnly, "readonly", false,
+		"Disable all API endpoints that change the tree")
+	cmd.Flags().StringSliceVar(&f.corsOrigins, "cors-origin", nil,
+		"Origin allowed to call the API from another site, e.g. http://localhost:5173 (repeatable; * allows any)")
+}
+
+// databasePath returns the --database flag, or the default database path used by the CLI
+func (f *serverFlags) databasePath() string {
+	if f.database != "" {
+		return f.database
+	}
+	defaultPath, err := web.GetDefaultDatabasePath()
+	if err != nil {
+		log.Fatalf("Failed to get default database path: %v", err)
+	}
+	return defaultPath
+}
+
+// options validates the flags and turns them into server options for the given port, exiting on invalid flags
+// An access token is generated when the server is reachable from other machines and none was given.
+func (f *serverFlags) options(port int) web.Options {
+	if (f.tlsCert == "") != (f.tlsKey == "") {
+		fmt.Println("❌ --tls-cert and --tls-key must be used together")
+		os.Exit(1)
+	}
+
+	// Origins given on the command line replace the web.cors_origins setting
+	var corsOrigins []string
+	if f.corsOrigins != nil {
+		corsOrigins = []string{}
+		for _, origin := range f.corsOrigins {
+			for _, origin := range web.ParseOrigins(origin) {
+				if err := web.ValidateOrigin(origin); err != nil {
+					fmt.Printf("❌ %v\n", err)
+					os.Exit(1)
+				}
+				corsOrigins = append(corsOrigins, origin)
+			}
+		}
+	}
+
+	// Require an access token whenever the server is reachable from other machines
+	token := f.token
+	if token == "" {
+		token = os.Getenv("BONSAI_WEB_TOKEN")
+	}
+	if token == "" && !web.IsLoopbackHost(f.host) {
+		generated, err := web.GenerateToken()
+		if err != nil {
+			log.Fatalf("Failed to generate access token: %v", err)
+		}
+		token = generated
+		fmt.Printf("🔑 Listening on %s, so an access token is required. Open the link below, or send\n", f.host)
+		fmt.Printf("   the header 'Authorization: Bearer %s'\n", token)
+	}
+
+	return web.Options{
+		Host:        f.host,
+		Port:        port,
+		Token:       token,
+		CertFile:    f.tlsCert,
+		KeyFile:     f.tlsKey,
+		BasePath:    f.basePath,
+		TrustProxy:  f.trustProxy,
+		ReadOnly:    f.readOnly,
+		CORSOrigins: corsOrigins,
+	}
+}
+
+// runServer runs the web server until SIGINT or SIGTERM, then waits for in-flight requests to finish
+// Responses are generated in sessions like the CLI's, so --profile and --force-model apply to them.
+// SIGHUP reopens the database and rereads its settings without restarting. Each of alongside runs
+// next to the web server with the same context, and the failure of any stops the command.
+func runServer(dbPath string, opts web.Options, alongside ...func(ctx context.Context) error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// Restore the default behaviour so a second Ctrl+C quits without waiting
+		stop()
+		fmt.Println("\n👋 Shutting down server, waiting for requests to finish...")
+	}()
+
+	// SIGHUP reopens the database, e.g. after repointing a symlink at another workspace
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+	reload := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hangups:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case reload <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	opts.Reload = reload
+	opts.NewSession = newSession
+
+	for _, run := range alongside {
+		go func() {
+			if err := run(ctx); err != nil {
+				log.Fatalf("Server error: %v", err)
+			}
+		}()
+	}
+
+	if err := web.StartVisualizationServer(ctx, dbPath, opts); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
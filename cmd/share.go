@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/share"
+	"github.com/spf13/cobra"
+)
+
+// shareServiceConfigKey is the setting naming where 'bai share' uploads branches
+const shareServiceConfigKey = "share.service"
+
+var shareCmd = &cobra.Command{
+	Use:   "share [node-id]",
+	Short: "Share a branch as a GitHub gist or paste",
+	Long: `Export the branch from the root down to a node, or the current working node, as Markdown and upload
+it, printing a URL anyone can open without running 'bai visualize'.
+
+Branches are uploaded as secret GitHub gists by default, or public ones with --public. The gist is
+created with a token from GITHUB_TOKEN or GH_TOKEN, or from the GitHub CLI if it's logged in.
+
+To use a paste service instead, give its URL with --service or the share.service setting. The
+service must accept the Markdown as a raw POST body and respond with the paste's URL, as
+https://paste.rs does. Use --print to see the Markdown without uploading it.`,
+	Example: `  bai share 3f2a9c1b
+  bai share --public
+  bai share 3f2a9c1b --service https://paste.rs
+  bai config set share.service https://paste.rs`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		service, err := cmd.Flags().GetString("service")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get service flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		public, err := cmd.Flags().GetBool("public")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get public flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		printOnly, err := cmd.Flags().GetBool("print")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get print flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if service == "" {
+			if service, err = configString(database, shareServiceConfigKey, share.GistService); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		session := newSession(database)
+		var node *bonsai.Node
+		if len(args) == 1 {
+			node, err = session.Node(args[0])
+		} else {
+			node, err = session.Current()
+		}
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("\033[90mℹ️  No current working node set. Give a node ID to share.\033[0m")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		var markdown bytes.Buffer
+		if err := session.ExportMarkdown(&markdown, node.ID); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if printOnly {
+			fmt.Print(markdown.String())
+			return
+		}
+
+		branch, err := database.GetConversationHistory(node.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get branch: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\033[90m📤 Uploading %d message(s) to %s...\033[0m\n", len(branch), service)
+		url, err := share.Upload(context.Background(), service, share.Document{
+			Filename:    "bonsai-" + shortID(node.ID) + ".md",
+			Description: bonsai.BranchTitle(branch),
+			Content:     markdown.String(),
+			Public:      public,
+		})
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔗 Shared branch ending at \033[33m%s\033[0m: \033[36m%s\033[0m\n", shortID(node.ID), url)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+	shareCmd.Flags().StringP("service", "s", "", "Where to upload: gist, or a paste service URL (defaults to the share.service setting, then gist)")
+	shareCmd.Flags().Bool("public", false, "Create a public gist instead of a secret one")
+	shareCmd.Flags().Bool("print", false, "Print the Markdown instead of uploading it")
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show [node-id]",
+	Short: "Show everything about a node",
+	Long: `Show a node's full content and details: its type, model, author, creation time, parent and children,
+the nodes it quotes and the commits linked to it. For LLM responses, the parameters they were generated
+with are shown too (provider, max tokens, temperature, a hash of the system prompt and the provider's
+request ID), along with the HTTP status and how long the response took, so results can be reproduced
+and followed up on with the provider.
+
+Without a node ID the current working node is shown.`,
+	Example: `  bai show
+  bai show 3f2a9c1b
+  bai show 3f2a9c1b --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get json flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		var node *bonsai.Node
+		if len(args) == 1 {
+			node, err = session.Node(args[0])
+		} else {
+			node, err = session.Current()
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(node); err != nil {
+				fmt.Printf("\033[31m❌ Failed to encode JSON: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		children, err := database.GetDirectChildren(node.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("📄 Node: \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("%s Type: \033[90m%s\033[0m\n", nodeTypeIcon(node.Type), node.Type)
+		if node.Model != nil {
+			fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
+		}
+		printAuthor(node)
+		if node.CreatedAt != 0 {
+			fmt.Printf("🕒 Created: \033[90m%s\033[0m\n", formatAge(node.CreatedAt))
+		}
+		if node.Parent != nil {
+			fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
+		} else {
+			fmt.Printf("🌱 Root of its tree\n")
+		}
+		fmt.Printf("🌿 Children: \033[33m%d\033[0m\n", len(children))
+		printGeneration(node)
+		printQuotes(node)
+		printCommits(node)
+		printExcluded(node)
+
+		fmt.Println()
+		if node.Type == "llm" {
+			fmt.Println(renderMarkdown(node.Content))
+		} else {
+			fmt.Println(strings.TrimRight(node.Content, "\n"))
+		}
+	},
+}
+
+// printGeneration shows the parameters an LLM response was generated with, if they were recorded
+func printGeneration(node *db.Node) {
+	generation := bonsai.GenerationOf(node)
+	if generation == nil {
+		return
+	}
+
+	var details []string
+	if generation.Provider != "" {
+		details = append(details, "provider \033[35m"+generation.Provider+"\033[0m")
+	}
+	if generation.MaxTokens > 0 {
+		details = append(details, fmt.Sprintf("max tokens \033[33m%d\033[0m", generation.MaxTokens))
+	}
+	if generation.Temperature != nil {
+		details = append(details, "temperature \033[33m"+strconv.FormatFloat(*generation.Temperature, 'g', -1, 64)+"\033[0m")
+	} else {
+		details = append(details, "temperature \033[90mdefault\033[0m")
+	}
+	if generation.SystemHash != "" {
+		details = append(details, "system prompt \033[33m"+shortID(generation.SystemHash)+"\033[0m")
+	}
+	if generation.Cached {
+		details = append(details, "\033[36mfrom cache\033[0m")
+	}
+	fmt.Printf("⚙️  Generated with: %s\n", strings.Join(details, " · "))
+	if generation.RequestID != "" {
+		fmt.Printf("🧾 Request ID: \033[90m%s\033[0m\n", generation.RequestID)
+	}
+	if generation.Status != 0 || generation.LatencyMs > 0 {
+		var response []string
+		if generation.Status != 0 {
+			response = append(response, fmt.Sprintf("HTTP \033[33m%d\033[0m", generation.Status))
+		}
+		if generation.LatencyMs > 0 {
+			response = append(response, "in \033[33m"+formatLatency(generation.LatencyMs)+"\033[0m")
+		}
+		fmt.Printf("⏱️  Response: %s\n", strings.Join(response, " "))
+	}
+}
+
+// formatLatency renders a duration in milliseconds, switching to seconds from one second up
+func formatLatency(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().Bool("json", false, "Output the node as JSON")
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split <node-id>",
+	Short: "Split a long node into a chain of shorter ones",
+	Long: `Split a node into several nodes chained one below the other, so you can later branch from a
+specific portion of a long paste instead of only from its end.
+
+The node keeps its ID and the first part; every later part becomes a new node of the same type and
+model below the one before. Anything that was below the node, and the current working node if it was
+this one, moves to the last part, so the conversation reads the same as before.
+
+Say where to split with --delimiter, splitting at every line that is exactly the delimiter (those
+lines are dropped), or with --at, splitting before the given line numbers. Without either, the node is
+shown with line numbers and you're asked where to split it.`,
+	Example: `  bai split 3f2a9c1b --delimiter ---
+  bai split 3f2a9c1b --at 40,112
+  bai split 3f2a9c1b`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		delimiter, err := cmd.Flags().GetString("delimiter")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get delimiter flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		at, err := cmd.Flags().GetString("at")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get at flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get dry-run flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get yes flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		node, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		lines := strings.Split(node.Content, "\n")
+		if len(lines) < 2 {
+			fmt.Println("\033[90mℹ️  Nothing to split: the node is a single line.\033[0m")
+			return
+		}
+		interactive := delimiter == "" && at == ""
+		if interactive {
+			if !isTerminal(os.Stdin) {
+				fmt.Printf("\033[31m❌ No terminal to ask where to split on. Pass --delimiter or --at.\033[0m\n")
+				os.Exit(1)
+			}
+			for i, line := range lines {
+				fmt.Printf("\033[36m%4d\033[0m│ %s\n", i+1, line)
+			}
+			fmt.Printf("\n\033[1mSplit before which lines? (e.g. 12,40):\033[0m ")
+			if at, err = bufio.NewReader(os.Stdin).ReadString('\n'); err != nil {
+				fmt.Printf("\033[31m❌ Failed to read input: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var parts []string
+		if delimiter != "" {
+			parts = splitAtDelimiter(lines, delimiter)
+		} else {
+			starts, err := parseSplitLines(at, len(lines))
+			if err != nil {
+				fmt.Printf("\033[31m❌ Invalid line numbers: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			parts = splitBeforeLines(lines, starts)
+		}
+		if len(parts) < 2 {
+			fmt.Println("\033[90mℹ️  Nothing to split: the node would stay in one piece.\033[0m")
+			return
+		}
+
+		fmt.Printf("✂️  Splitting \033[33m%s\033[0m into %d nodes:\n", shortID(node.ID), len(parts))
+		for i, part := range parts {
+			fmt.Printf("   \033[36m%d.\033[0m %s \033[90m(%d line(s))\033[0m\n", i+1, truncateContent(strings.ReplaceAll(part, "\n", " "), 70), strings.Count(part, "\n")+1)
+		}
+		if dryRun {
+			return
+		}
+		if interactive && !yes && !confirmApply("Split the node?", false) {
+			fmt.Println("Cancelled.")
+			return
+		}
+
+		chain, err := session.Split(node.ID, parts)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		last := chain[len(chain)-1]
+		fmt.Printf("\033[32m✅ Split %s into %d nodes, ending at %s\033[0m\n", shortID(node.ID), len(chain), shortID(last.ID))
+	},
+}
+
+// splitAtDelimiter splits lines into parts at every line that is exactly the delimiter, ignoring
+// surrounding whitespace, dropping the delimiter lines and any parts left empty
+func splitAtDelimiter(lines []string, delimiter string) []string {
+	delimiter = strings.TrimSpace(delimiter)
+	var parts []string
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && strings.TrimSpace(lines[i]) != delimiter {
+			continue
+		}
+		parts = appendPart(parts, lines[start:i])
+		start = i + 1
+	}
+	return parts
+}
+
+// splitBeforeLines splits lines into parts starting at each of the given line numbers, counting from
+// 1 and in increasing order, dropping any parts left empty
+func splitBeforeLines(lines []string, starts []int) []string {
+	var parts []string
+	start := 0
+	for _, line := range starts {
+		parts = appendPart(parts, lines[start:line-1])
+		start = line - 1
+	}
+	return appendPart(parts, lines[start:])
+}
+
+// appendPart appends lines to parts as one part, without the blank lines around it, unless they're all blank
+func appendPart(parts []string, lines []string) []string {
+	part := strings.Trim(strings.Join(lines, "\n"), "\n")
+	if strings.TrimSpace(part) == "" {
+		return parts
+	}
+	return append(parts, part)
+}
+
+// parseSplitLines parses comma-separated line numbers to split before, returning them sorted and
+// without duplicates. Lines must be between 2 and the number of lines, as nothing comes before line 1.
+func parseSplitLines(value string, count int) ([]int, error) {
+	seen := make(map[int]bool)
+	var starts []int
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		line, err := strconv.Atoi(field)
+		if err != nil || line < 2 || line > count {
+			return nil, fmt.Errorf("%q isn't a line number from 2 to %d", field, count)
+		}
+		if !seen[line] {
+			seen[line] = true
+			starts = append(starts, line)
+		}
+	}
+	sort.Ints(starts)
+	return starts, nil
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+	splitCmd.Flags().StringP("delimiter", "d", "", "Split at every line that is exactly this text, e.g. ---")
+	splitCmd.Flags().String("at", "", "Split before these line numbers, separated by commas, e.g. 40,112")
+	splitCmd.Flags().Bool("dry-run", false, "Show the parts without splitting anything")
+	splitCmd.Flags().BoolP("yes", "y", false, "Split without asking for confirmation when choosing lines interactively")
+	splitCmd.MarkFlagsMutuallyExclusive("delimiter", "at")
+}
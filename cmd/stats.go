@@ -0,0 +1,484 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+// treeStats summarizes a single conversation tree
+type treeStats struct {
+	RootID        string  `json:"root_id"`
+	Title         string  `json:"title"`
+	Nodes         int     `json:"nodes"`
+	MaxDepth      int     `json:"max_depth"`
+	Leaves        int     `json:"leaves"`
+	BranchFactor  float64 `json:"branch_factor"`
+	Tokens        int     `json:"tokens"`
+	EstimatedCost float64 `json:"estimated_cost_usd"`
+}
+
+// dayActivity counts the nodes created on a single day
+type dayActivity struct {
+	Date  string `json:"date"`
+	Nodes int    `json:"nodes"`
+}
+
+// evalRecord totals a model's outcomes in 'bai eval' comparisons
+type evalRecord struct {
+	Wins     int                    `json:"wins"`
+	Losses   int                    `json:"losses"`
+	Ties     int                    `json:"ties"`
+	Criteria map[string]*evalRecord `json:"criteria,omitempty"`
+}
+
+// add counts one outcome
+func (r *evalRecord) add(outcome string) {
+	switch outcome {
+	case bonsai.EvalWin:
+		r.Wins++
+	case bonsai.EvalLoss:
+		r.Losses++
+	case bonsai.EvalTie:
+		r.Ties++
+	}
+}
+
+// winRate is the share of decided and tied comparisons won, counting ties as half
+func (r *evalRecord) winRate() float64 {
+	total := r.Wins + r.Losses + r.Ties
+	if total == 0 {
+		return 0
+	}
+	return (float64(r.Wins) + float64(r.Ties)/2) / float64(total)
+}
+
+// evalStats summarizes every 'bai eval' verdict
+type evalStats struct {
+	Comparisons int                    `json:"comparisons"`
+	ByModel     map[string]*evalRecord `json:"by_model"`
+}
+
+// latencyStats summarizes how quickly a model's provider answered, over the responses that recorded it
+type latencyStats struct {
+	Responses int            `json:"responses"`
+	AvgMs     int64          `json:"avg_ms"`
+	P50Ms     int64          `json:"p50_ms"`
+	P95Ms     int64          `json:"p95_ms"`
+	MaxMs     int64          `json:"max_ms"`
+	Statuses  map[string]int `json:"statuses,omitempty"` // HTTP status to number of responses
+	samples   []int64
+}
+
+// finish computes the summary from the collected samples
+func (l *latencyStats) finish() {
+	sort.Slice(l.samples, func(i, j int) bool { return l.samples[i] < l.samples[j] })
+	var total int64
+	for _, ms := range l.samples {
+		total += ms
+	}
+	l.Responses = len(l.samples)
+	l.AvgMs = total / int64(l.Responses)
+	l.P50Ms = l.samples[(l.Responses-1)*50/100]
+	l.P95Ms = l.samples[(l.Responses-1)*95/100]
+	l.MaxMs = l.samples[l.Responses-1]
+}
+
+// gardenStats summarizes every tree in the database
+type gardenStats struct {
+	Trees          int                      `json:"trees"`
+	Nodes          int                      `json:"nodes"`
+	NodesByType    map[string]int           `json:"nodes_by_type"`
+	NodesByModel   map[string]int           `json:"nodes_by_model"`
+	MaxDepth       int                      `json:"max_depth"`
+	BranchFactor   float64                  `json:"branch_factor"`
+	Tokens         int                      `json:"tokens"`
+	EstimatedCost  float64                  `json:"estimated_cost_usd"`
+	UnpricedModels []string                 `json:"unpriced_models,omitempty"`
+	BusiestDays    []dayActivity            `json:"busiest_days"`
+	Evals          *evalStats               `json:"evals,omitempty"`
+	Latency        map[string]*latencyStats `json:"latency_by_model,omitempty"`
+	PerTree        []*treeStats             `json:"per_tree"`
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about your conversation trees",
+	Long: `Show statistics about the Bonsai garden: node counts by type and model, tree depth and
+branching, estimated tokens and cost per tree, the busiest days, how each model has fared in
+'bai eval' comparisons, and how quickly each model's provider answered and with which HTTP statuses.
+
+Token counts are estimated at roughly four characters per token. Cost estimates assume every
+LLM response was generated from its full root-to-parent history, priced at the model's
+list price; models with unknown prices are excluded from cost totals.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get json flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		// Initialize database
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		nodes, err := database.GetAllNodes()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		stats := collectStats(nodes)
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(stats); err != nil {
+				fmt.Printf("\033[31m❌ Failed to encode JSON: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printStats(stats)
+	},
+}
+
+// collectStats computes garden statistics from a flat list of nodes
+func collectStats(nodes []*db.Node) *gardenStats {
+	stats := &gardenStats{
+		NodesByType:  make(map[string]int),
+		NodesByModel: make(map[string]int),
+		BusiestDays:  []dayActivity{},
+		PerTree:      []*treeStats{},
+	}
+
+	byID := make(map[string]*db.Node, len(nodes))
+	childCount := make(map[string]int)
+	for _, node := range nodes {
+		byID[node.ID] = node
+		if node.Parent != nil {
+			childCount[*node.Parent]++
+		}
+	}
+
+	// Memoized walks up the parent chain: depth, root and cumulative history tokens
+	depth := make(map[string]int)
+	root := make(map[string]string)
+	historyTokens := make(map[string]int)
+	var walk func(node *db.Node)
+	walk = func(node *db.Node) {
+		if _, done := depth[node.ID]; done {
+			return
+		}
+		tokens := llm.EstimateTokens(node.Content)
+		if node.Parent == nil || byID[*node.Parent] == nil {
+			depth[node.ID], root[node.ID], historyTokens[node.ID] = 0, node.ID, tokens
+			return
+		}
+		parent := byID[*node.Parent]
+		walk(parent)
+		depth[node.ID] = depth[parent.ID] + 1
+		root[node.ID] = root[parent.ID]
+		historyTokens[node.ID] = historyTokens[parent.ID] + tokens
+	}
+
+	trees := make(map[string]*treeStats)
+	innerNodes := make(map[string]int)
+	unpriced := make(map[string]bool)
+	days := make(map[string]int)
+	comparisons := make(map[string]bool)
+	totalInner, totalChildren := 0, 0
+
+	for _, node := range nodes {
+		walk(node)
+
+		tree, ok := trees[root[node.ID]]
+		if !ok {
+			rootNode := byID[root[node.ID]]
+			tree = &treeStats{RootID: rootNode.ID, Title: strings.ReplaceAll(rootNode.Content, "\n", " ")}
+			trees[rootNode.ID] = tree
+		}
+
+		tokens := llm.EstimateTokens(node.Content)
+		tree.Nodes++
+		tree.Tokens += tokens
+		if depth[node.ID] > tree.MaxDepth {
+			tree.MaxDepth = depth[node.ID]
+		}
+		if childCount[node.ID] == 0 {
+			tree.Leaves++
+		} else {
+			innerNodes[tree.RootID]++
+			totalInner++
+			totalChildren += childCount[node.ID]
+		}
+
+		stats.Nodes++
+		stats.Tokens += tokens
+		stats.NodesByType[node.Type]++
+		model := "(none)"
+		if node.Model != nil && *node.Model != "" {
+			model = *node.Model
+		}
+		stats.NodesByModel[model]++
+
+		// Each LLM response consumed its whole history as input
+		if node.Type == "llm" && node.Model != nil {
+			input := historyTokens[node.ID] - tokens
+			if cost, ok := llm.EstimateCost(*node.Model, input, tokens); ok {
+				tree.EstimatedCost += cost
+				stats.EstimatedCost += cost
+			} else {
+				unpriced[*node.Model] = true
+			}
+		}
+
+		if generation := bonsai.GenerationOf(node); generation != nil && generation.LatencyMs > 0 {
+			if stats.Latency == nil {
+				stats.Latency = make(map[string]*latencyStats)
+			}
+			latency, ok := stats.Latency[model]
+			if !ok {
+				latency = &latencyStats{Statuses: make(map[string]int)}
+				stats.Latency[model] = latency
+			}
+			latency.samples = append(latency.samples, generation.LatencyMs)
+			if generation.Status != 0 {
+				latency.Statuses[strconv.Itoa(generation.Status)]++
+			}
+		}
+
+		if node.CreatedAt > 0 {
+			days[time.Unix(node.CreatedAt, 0).Format("2006-01-02")]++
+		}
+
+		for _, annotation := range bonsai.Evals(node) {
+			if stats.Evals == nil {
+				stats.Evals = &evalStats{ByModel: make(map[string]*evalRecord)}
+			}
+			comparisons[annotation.ID] = true
+
+			record, ok := stats.Evals.ByModel[model]
+			if !ok {
+				record = &evalRecord{Criteria: make(map[string]*evalRecord)}
+				stats.Evals.ByModel[model] = record
+			}
+			record.add(annotation.Overall)
+			for criterion, outcome := range annotation.Criteria {
+				if record.Criteria[criterion] == nil {
+					record.Criteria[criterion] = &evalRecord{}
+				}
+				record.Criteria[criterion].add(outcome)
+			}
+		}
+	}
+	if stats.Evals != nil {
+		stats.Evals.Comparisons = len(comparisons)
+	}
+	for _, latency := range stats.Latency {
+		latency.finish()
+	}
+
+	for rootID, tree := range trees {
+		if innerNodes[rootID] > 0 {
+			tree.BranchFactor = float64(tree.Nodes-1) / float64(innerNodes[rootID])
+		}
+		if tree.MaxDepth > stats.MaxDepth {
+			stats.MaxDepth = tree.MaxDepth
+		}
+		stats.PerTree = append(stats.PerTree, tree)
+	}
+	sort.Slice(stats.PerTree, func(i, j int) bool {
+		return stats.PerTree[i].Nodes > stats.PerTree[j].Nodes
+	})
+
+	stats.Trees = len(trees)
+	if totalInner > 0 {
+		stats.BranchFactor = float64(totalChildren) / float64(totalInner)
+	}
+
+	for model := range unpriced {
+		stats.UnpricedModels = append(stats.UnpricedModels, model)
+	}
+	sort.Strings(stats.UnpricedModels)
+
+	for date, count := range days {
+		stats.BusiestDays = append(stats.BusiestDays, dayActivity{Date: date, Nodes: count})
+	}
+	sort.Slice(stats.BusiestDays, func(i, j int) bool {
+		if stats.BusiestDays[i].Nodes != stats.BusiestDays[j].Nodes {
+			return stats.BusiestDays[i].Nodes > stats.BusiestDays[j].Nodes
+		}
+		return stats.BusiestDays[i].Date > stats.BusiestDays[j].Date
+	})
+	if len(stats.BusiestDays) > 5 {
+		stats.BusiestDays = stats.BusiestDays[:5]
+	}
+
+	return stats
+}
+
+// printStats renders garden statistics as tables
+func printStats(stats *gardenStats) {
+	if stats.Nodes == 0 {
+		fmt.Println("\033[90mℹ️  No nodes found.\033[0m")
+		return
+	}
+
+	fmt.Printf("📊 \033[1mBonsai garden statistics\033[0m\n\n")
+	fmt.Printf("🌳 Trees: \033[33m%d\033[0m   🍃 Nodes: \033[33m%d\033[0m   📏 Max depth: \033[33m%d\033[0m   🌿 Branch factor: \033[33m%.2f\033[0m\n",
+		stats.Trees, stats.Nodes, stats.MaxDepth, stats.BranchFactor)
+	fmt.Printf("🔢 Estimated tokens: \033[33m%d\033[0m   💰 Estimated cost: \033[33m$%.4f\033[0m\n", stats.Tokens, stats.EstimatedCost)
+	if len(stats.UnpricedModels) > 0 {
+		fmt.Printf("\033[90m   (no pricing for: %s)\033[0m\n", strings.Join(stats.UnpricedModels, ", "))
+	}
+
+	fmt.Printf("\n\033[1mNodes by type\033[0m\n")
+	printCounts(stats.NodesByType)
+
+	fmt.Printf("\n\033[1mNodes by model\033[0m\n")
+	printCounts(stats.NodesByModel)
+
+	fmt.Printf("\n\033[1mTrees\033[0m\n")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROOT\tNODES\tDEPTH\tLEAVES\tBRANCH\tTOKENS\tCOST\tTITLE")
+	for _, tree := range stats.PerTree {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.2f\t%d\t$%.4f\t%s\n",
+			shortID(tree.RootID), tree.Nodes, tree.MaxDepth, tree.Leaves, tree.BranchFactor, tree.Tokens, tree.EstimatedCost, truncateContent(tree.Title, 40))
+	}
+	w.Flush()
+
+	if len(stats.BusiestDays) > 0 {
+		fmt.Printf("\n\033[1mBusiest days\033[0m\n")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, day := range stats.BusiestDays {
+			fmt.Fprintf(w, "%s\t%d node(s)\n", day.Date, day.Nodes)
+		}
+		w.Flush()
+	}
+
+	if stats.Evals != nil {
+		printEvalStats(stats.Evals)
+	}
+
+	if stats.Latency != nil {
+		printLatencyStats(stats.Latency)
+	}
+}
+
+// printLatencyStats prints how quickly each model's provider answered, slowest typical response first
+func printLatencyStats(latencies map[string]*latencyStats) {
+	fmt.Printf("\n\033[1mResponse times\033[0m\n")
+
+	models := make([]string, 0, len(latencies))
+	for model := range latencies {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		a, b := latencies[models[i]].P50Ms, latencies[models[j]].P50Ms
+		if a != b {
+			return a > b
+		}
+		return models[i] < models[j]
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tRESPONSES\tAVG\tP50\tP95\tMAX\tSTATUSES")
+	for _, model := range models {
+		latency := latencies[model]
+
+		statuses := make([]string, 0, len(latency.Statuses))
+		for status, count := range latency.Statuses {
+			statuses = append(statuses, fmt.Sprintf("%s×%d", status, count))
+		}
+		sort.Strings(statuses)
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", model, latency.Responses, formatLatency(latency.AvgMs),
+			formatLatency(latency.P50Ms), formatLatency(latency.P95Ms), formatLatency(latency.MaxMs), strings.Join(statuses, ", "))
+	}
+	w.Flush()
+}
+
+// printEvalStats prints each model's record in 'bai eval' comparisons, best win rate first
+func printEvalStats(evals *evalStats) {
+	fmt.Printf("\n\033[1mEvaluations\033[0m (%d comparison(s))\n", evals.Comparisons)
+
+	models := make([]string, 0, len(evals.ByModel))
+	for model := range evals.ByModel {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		a, b := evals.ByModel[models[i]].winRate(), evals.ByModel[models[j]].winRate()
+		if a != b {
+			return a > b
+		}
+		return models[i] < models[j]
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tWINS\tLOSSES\tTIES\tWIN RATE\tBY CRITERION")
+	for _, model := range models {
+		record := evals.ByModel[model]
+
+		criteria := make([]string, 0, len(record.Criteria))
+		for criterion := range record.Criteria {
+			criteria = append(criteria, criterion)
+		}
+		sort.Strings(criteria)
+		for i, criterion := range criteria {
+			c := record.Criteria[criterion]
+			criteria[i] = fmt.Sprintf("%s %d-%d-%d", criterion, c.Wins, c.Losses, c.Ties)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.0f%%\t%s\n", model, record.Wins, record.Losses, record.Ties, record.winRate()*100, strings.Join(criteria, ", "))
+	}
+	w.Flush()
+}
+
+// printCounts prints a map of counts as an aligned table, largest first
+func printCounts(counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%d\n", key, counts[key])
+	}
+	w.Flush()
+}
+
+// shortID returns the abbreviated form of a node ID used in compact listings
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("json", false, "Output statistics as JSON")
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+// maxUnansweredShown is how many unanswered messages 'bai status' lists before summarizing the rest
+const maxUnansweredShown = 3
+
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"where"},
+	Short:   "Show where you are: the current tree and node, and anything left unanswered",
+	Long: `Show where you are at a glance, like 'git status': the working directory and its git branch, the
+database, the current tree and working node (its type, model, depth from the root and children),
+what's pinned to the tree, and any messages in the tree a model was asked to answer but no
+response was stored for, as when generating it failed or was interrupted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if dir, err := os.Getwd(); err == nil {
+			workspace := dir
+			if ref, err := bonsai.ResolveGitRef(dir, "HEAD"); err == nil {
+				workspace += " \033[90m(" + ref.String() + ")\033[0m"
+			}
+			fmt.Printf("📂 Workspace: %s\n", workspace)
+		}
+		if dbPath, err := bonsai.DefaultPath(); err == nil {
+			if database.IsEncrypted() {
+				dbPath += " \033[90m(encrypted)\033[0m"
+			}
+			fmt.Printf("🗄️  Database: %s\n", dbPath)
+		}
+
+		session := newSession(database)
+		node, err := session.Current()
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("\n🌱 No current working node set. Use 'bai seed \"message\"' to start a tree, or 'bai checkout' one.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		branch, err := database.GetConversationHistory(node.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		children, err := database.GetDirectChildren(node.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		root := branch[0]
+
+		fmt.Println()
+		fmt.Printf("🌳 Tree: \033[1m%s\033[0m \033[90m(root %s)\033[0m\n", bonsai.BranchTitle(branch), shortID(root.ID))
+		model := ""
+		if node.Model != nil && *node.Model != "" {
+			model = ", \033[35m" + *node.Model + "\033[0m"
+		}
+		fmt.Printf("📍 Node: \033[33m%s\033[0m %s \033[90m%s\033[0m%s\n", shortID(node.ID), nodeTypeIcon(node.Type), node.Type, model)
+		fmt.Printf("   \033[90m%s\033[0m\n", truncateContent(strings.ReplaceAll(node.Content, "\n", " "), 70))
+		fmt.Printf("📏 Depth %d from the root · 🌿 %d child(ren)\n", len(branch)-1, len(children))
+
+		if _, pinned, err := database.GetPinnedIDs(root.ID); err == nil && len(pinned) > 0 {
+			fmt.Printf("📌 %d node(s) pinned to this tree\n", len(pinned))
+		}
+
+		unanswered, err := database.GetUnansweredNodes(root.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(unanswered) == 0 {
+			fmt.Println("\n\033[32m✅ Every message in this tree has a response\033[0m")
+			return
+		}
+		fmt.Printf("\n\033[33m⚠️  %d message(s) in this tree have no response:\033[0m\n", len(unanswered))
+		for i, message := range unanswered {
+			if i == maxUnansweredShown {
+				fmt.Printf("   \033[90m…and %d more\033[0m\n", len(unanswered)-maxUnansweredShown)
+				break
+			}
+			fmt.Printf("   \033[33m%s\033[0m \033[35m%s\033[0m %s\n", shortID(message.ID), *message.Model, truncateContent(strings.ReplaceAll(message.Content, "\n", " "), 50))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var systemCmd = &cobra.Command{
+	Use:   "system [node-id] <prompt>",
+	Short: "Add a system prompt to a conversation as a node",
+	Long: `Add a system prompt below the current working node. It's kept in the tree like any other
+message, so branches can try different prompts side by side, and every reply below it sends it to
+the model as a system message, after generate.system or the tree's system setting if one is set.
+Nothing responds to the prompt itself.
+
+Given a node ID before the prompt, it's added below that node instead, without checking it out.`,
+	Example: `  bai system "You are a terse reviewer. Point out bugs only."
+  bai system 3f2a9c1b "Answer in French."`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var parentID string
+		prompt := args[len(args)-1]
+		if len(args) == 2 {
+			parentID = args[0]
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		node, err := newSession(database).System(prompt, parentID)
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use 'bai seed \"message\"' to create a root node first.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⚙️  \033[32mCreated system prompt with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
+		fmt.Printf("💬 Prompt: \033[90m%s\033[0m\n", node.Content)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(systemCmd)
+}
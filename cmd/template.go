@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Save and reuse prompt templates",
+	Long: `Save and reuse prompt templates.
+
+Templates are prompts with {{variables}} that are filled in each time the template is used, so
+recurring prompt patterns can seed or extend trees consistently. Templates are stored in the
+Bonsai database.`,
+	Example: `  # Save a template, then use it to extend the current conversation
+  bai template save review "Review this {{language}} code for bugs:\n\n{{code}}"
+  bai template use review --var language=Go --var code="$(cat main.go)"
+
+  # Start a new tree from a template, asking for any variables not given as flags
+  bai template use review --seed --llm gpt-4`,
+}
+
+var templateSaveCmd = &cobra.Command{
+	Use:   "save <name> [text]",
+	Short: "Save a prompt template, reading it from stdin if no text is given",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t\n") {
+			fmt.Printf("\033[31m❌ Template names can't be empty or contain spaces.\033[0m\n")
+			os.Exit(1)
+		}
+
+		var text string
+		if len(args) == 2 {
+			text = args[1]
+		} else {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Printf("\033[31m❌ Failed to read template from stdin: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			text = strings.TrimRight(string(data), "\n")
+		}
+		if strings.TrimSpace(text) == "" {
+			fmt.Printf("\033[31m❌ Template text can't be empty.\033[0m\n")
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if err := database.SaveTemplate(name, text); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("📝 \033[32mSaved template\033[0m \033[33m%s\033[0m\n", name)
+		if variables := bonsai.TemplateVariables(text); len(variables) > 0 {
+			fmt.Printf("🔤 Variables: \033[36m%s\033[0m\n", strings.Join(variables, ", "))
+		}
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved prompt templates",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		templates, err := database.GetTemplates()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		if len(templates) == 0 {
+			fmt.Println("\033[90mℹ️  No templates saved. Use 'bai template save <name> <text>' to add one.\033[0m")
+			return
+		}
+
+		names := make([]string, 0, len(templates))
+		for name := range templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("📝 Found %d template(s):\n\n", len(templates))
+		for _, name := range names {
+			text := templates[name]
+			fmt.Printf("• \033[33m%s\033[0m: \033[90m%s\033[0m\n", name, truncateContent(strings.ReplaceAll(text, "\n", " "), 60))
+			if variables := bonsai.TemplateVariables(text); len(variables) > 0 {
+				fmt.Printf("  🔤 Variables: \033[36m%s\033[0m\n", strings.Join(variables, ", "))
+			}
+		}
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a prompt template",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		deleted, err := database.DeleteTemplate(name)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if !deleted {
+			fmt.Printf("\033[31m❌ Template '%s' not found.\033[0m\n", name)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🗑️  \033[32mDeleted template\033[0m \033[33m%s\033[0m\n", name)
+	},
+}
+
+var templateUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Fill in a prompt template and add it to the current conversation",
+	Long: `Fill in a prompt template and add it as a message below the current working node, or as a
+new seed with --seed. Variables are given with --var name=value; any that are missing are asked
+for when running in a terminal.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		noLLM, err := cmd.Flags().GetBool("no-llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get no-llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		asSeed, err := cmd.Flags().GetBool("seed")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get seed flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		assignments, err := cmd.Flags().GetStringArray("var")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get var flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		values := make(map[string]string)
+		for _, assignment := range assignments {
+			key, value, ok := strings.Cut(assignment, "=")
+			if !ok || key == "" {
+				fmt.Printf("\033[31m❌ Invalid --var %q: expected name=value\033[0m\n", assignment)
+				os.Exit(1)
+			}
+			values[key] = value
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		text, err := database.GetTemplate(name)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if text == nil {
+			fmt.Printf("\033[31m❌ Template '%s' not found. Use 'bai template list' to see saved templates.\033[0m\n", name)
+			os.Exit(1)
+		}
+
+		promptForVariables(bonsai.TemplateVariables(*text), values)
+		message, err := bonsai.RenderTemplate(*text, values)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		session := newSession(database)
+		if err := limitContextFromFlags(cmd, session); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if asSeed {
+			if llmModel == "" && !noLLM {
+				if llmModel, err = session.DefaultModel(); err != nil {
+					fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+					os.Exit(1)
+				}
+			}
+			node, err := session.Seed(message, llmModel)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("🌱 \033[32mCreated seed node with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+			respondToTemplate(session, node)
+			return
+		}
+
+		node, err := session.Reply(message, bonsai.ReplyOptions{Model: llmModel, NoModel: noLLM})
+		if errors.Is(err, bonsai.ErrNoCurrentNode) {
+			fmt.Println("🌱 No current working node set. Use --seed to start a new tree from the template.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔄 \033[32mCreated child node with ID:\033[0m \033[33m%s\033[0m\n", node.ID)
+		fmt.Printf("⬆️  Parent: \033[33m%s\033[0m\n", *node.Parent)
+		respondToTemplate(session, node)
+	},
+}
+
+// promptForVariables asks for the value of each variable not already in values, when stdin is a terminal
+func promptForVariables(variables []string, values map[string]string) {
+	if !isTerminal(os.Stdin) {
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, variable := range variables {
+		if _, ok := values[variable]; ok {
+			continue
+		}
+
+		fmt.Printf("\033[36m%s\033[0m: ", variable)
+		value, err := reader.ReadString('\n')
+		if err != nil && value == "" {
+			fmt.Printf("\n\033[31m❌ Failed to read input: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		values[variable] = strings.TrimRight(value, "\r\n")
+	}
+}
+
+// respondToTemplate prints a node created from a template and generates a response if it has a model
+func respondToTemplate(session *bonsai.Session, node *bonsai.Node) {
+	if node.Model != nil {
+		fmt.Printf("🧠 Model: \033[35m%s\033[0m\n", *node.Model)
+	}
+	fmt.Printf("💬 Message: \033[90m%s\033[0m\n", node.Content)
+
+	if node.Model != nil && *node.Model != "" {
+		if _, err := generateChildResponse(session, node, *node.Model); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	templateCmd.AddCommand(templateSaveCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateDeleteCmd)
+	templateCmd.AddCommand(templateUseCmd)
+
+	templateUseCmd.Flags().StringArray("var", nil, "Value for a template variable, as name=value (repeatable)")
+	templateUseCmd.Flags().Bool("seed", false, "Start a new tree with the filled-in template instead of extending the current one")
+	templateUseCmd.Flags().StringP("llm", "l", "", "LLM model to use (defaults to the branch's model)")
+	templateUseCmd.Flags().Bool("no-llm", false, "Add the message without a model, so nothing responds to it")
+	templateUseCmd.MarkFlagsMutuallyExclusive("llm", "no-llm")
+	addContextFlags(templateUseCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// progressBarWidth is how many cells wide progress bars are drawn
+const progressBarWidth = 24
+
+// spinnerFrames are drawn in turn, one per spinnerInterval, while a response is generated
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// isTerminal reports whether the file is attached to an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressLine keeps a progress bar on the last line of the terminal while other output scrolls
+// above it. When stdout isn't a terminal the bar is left out and lines are printed as usual.
+type progressLine struct {
+	mu      sync.Mutex
+	label   string
+	bar     string
+	enabled bool
+}
+
+// newProgressLine creates a progress line whose bar is preceded by the label
+func newProgressLine(label string) *progressLine {
+	return &progressLine{label: label, enabled: isTerminal(os.Stdout)}
+}
+
+// Printf prints a newline-terminated line above the bar
+func (p *progressLine) Printf(format string, args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.bar != "" {
+		fmt.Print("\r\033[K")
+	}
+	fmt.Printf(format, args...)
+	if p.bar != "" {
+		fmt.Print(p.bar)
+	}
+}
+
+// Update redraws the bar with the latest progress
+func (p *progressLine) Update(progress llm.Progress) {
+	if !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bar = p.label + " " + progress.Bar(progressBarWidth)
+	fmt.Print("\r\033[K" + p.bar)
+}
+
+// Done removes the bar
+func (p *progressLine) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.bar != "" {
+		fmt.Print("\r\033[K")
+		p.bar = ""
+	}
+}
+
+// spinner shows that a response is being generated: the model, the time elapsed and how many tokens
+// have streamed in so far, redrawn until Stop. When stdout isn't a terminal it prints one plain line
+// instead.
+type spinner struct {
+	model   string
+	started time.Time
+
+	mu     sync.Mutex
+	tokens int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startSpinner starts showing that the model is generating a response
+func startSpinner(model string) *spinner {
+	s := &spinner{model: model, started: time.Now()}
+	if !isTerminal(os.Stdout) {
+		fmt.Printf("Generating LLM response...\n")
+		return s
+	}
+
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			s.draw(spinnerFrames[frame%len(spinnerFrames)])
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				fmt.Print("\r\033[K")
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// draw redraws the spinner line
+func (s *spinner) draw(frame string) {
+	s.mu.Lock()
+	tokens := s.tokens
+	s.mu.Unlock()
+
+	line := fmt.Sprintf("\r\033[K\033[36m%s\033[0m Generating with \033[35m%s\033[0m \033[90m· %.1fs", frame, s.model, time.Since(s.started).Seconds())
+	if tokens > 0 {
+		line += fmt.Sprintf(" · %d tokens", tokens)
+	}
+	fmt.Print(line + "\033[0m")
+}
+
+// Chunk counts the tokens of a piece of the response as it streams in; it's an llm.StreamHandler
+func (s *spinner) Chunk(chunk string) error {
+	s.mu.Lock()
+	s.tokens += llm.CountTokens(chunk)
+	s.mu.Unlock()
+	return nil
+}
+
+// Stop removes the spinner
+func (s *spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+// timelineDefaultDays is how many days bai timeline covers when neither --since nor --until is given
+const timelineDefaultDays = 30
+
+// timelineBarWidth is the length of the bar of the busiest day
+const timelineBarWidth = 40
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Show how many nodes you've added each day, across all trees",
+	Long: `Show how much you've been conversing: a bar for each day with the number of nodes created that
+day across all trees. Pass --nodes to also list each day's nodes in the order they were created.
+
+The last 30 days are shown unless --since or --until is given. Filter by model or type with --model
+and --type. The same data is served by the API at /api/timeline.`,
+	Example: `  bai timeline
+  bai timeline --since 2024-01-01 --until 2024-02-01
+  bai timeline --since 7d --type user --nodes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := nodeFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if filter.Since.IsZero() && filter.Until.IsZero() {
+			now := time.Now()
+			filter.Since = time.Date(now.Year(), now.Month(), now.Day()-timelineDefaultDays+1, 0, 0, 0, 0, time.Local)
+		}
+		listNodes, err := cmd.Flags().GetBool("nodes")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get nodes flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		days, err := database.GetActivityByDay(filter, time.Local)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(days) == 0 {
+			fmt.Println("📅 No nodes were created in that time")
+			return
+		}
+
+		byDate := make(map[string][]*db.Node)
+		if listNodes {
+			nodes, err := database.GetTimelinePage(filter, 0, "", -1)
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			for _, node := range nodes {
+				date := time.Unix(node.CreatedAt, 0).Format("2006-01-02")
+				byDate[date] = append(byDate[date], node)
+			}
+		}
+
+		total, busiest := 0, 0
+		for _, day := range days {
+			total += day.Nodes
+			busiest = max(busiest, day.Nodes)
+		}
+		fmt.Printf("📅 Activity from %s to %s: \033[90m%d node(s)\033[0m\n\n", days[0].Date, days[len(days)-1].Date, total)
+
+		for _, day := range days {
+			date, _ := time.ParseInLocation("2006-01-02", day.Date, time.Local)
+			bar := strings.Repeat("█", (day.Nodes*timelineBarWidth+busiest-1)/busiest)
+			fmt.Printf("%s %s \033[32m%-*s\033[0m %d\n", date.Format("Mon"), day.Date, timelineBarWidth, bar, day.Nodes)
+
+			for _, node := range byDate[day.Date] {
+				fmt.Printf("      \033[90m%s\033[0m %s \033[33m%s\033[0m \033[90m%s\033[0m\n", time.Unix(node.CreatedAt, 0).Format("15:04"),
+					nodeTypeIcon(node.Type), shortID(node.ID), truncateContent(strings.Join(strings.Fields(node.Content), " "), 60))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+	addNodeFilterFlags(timelineCmd)
+	timelineCmd.Flags().Bool("nodes", false, "List each day's nodes below its bar")
+}
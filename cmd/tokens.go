@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+// tokensWarnRatio is the share of a model's context window from which bai tokens suggests summarizing or branching
+const tokensWarnRatio = 0.8
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens [node-id]",
+	Short: "Estimate how many tokens a branch's history takes up",
+	Long: `Estimate how many tokens the conversation from the root down to a node would take up as a model's
+input, to decide when to summarize or branch before the history outgrows the model's context window.
+Without a node ID the branch ending at the current working node is counted.
+
+Tokens are estimated the way tiktoken-style tokenizers split text, so counts are close to but not
+exactly what providers bill. The model is taken from --llm or the node. For models bai knows, the
+share of the context window used and the estimated cost of sending the history are shown too.`,
+	Example: `  bai tokens
+  bai tokens 3f2a9c1b --llm gpt-4o`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		var end *bonsai.Node
+		if len(args) == 1 {
+			end, err = session.Node(args[0])
+		} else {
+			end, err = session.Current()
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		branch, err := database.GetConversationHistory(end.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		// Count only what's sent, which leaves out notes unless generate.include_notes is on
+		settings, err := database.GetTreeGenerationSettings(end.ID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		branch = settings.ModelHistory(branch)
+		pinned, err := database.GetPinnedContext(end.ID, branch)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		model := llmModel
+		if model == "" {
+			if model, err = database.InheritedModel(end.ID); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		messages := make([]llm.Message, 0, len(branch)+1)
+		userTokens, llmTokens, pinnedTokens := 0, 0, 0
+		if len(pinned) > 0 {
+			messages = append(messages, llm.ContextMessage(pinned))
+			pinnedTokens = llm.CountTokens(messages[0].Content)
+		}
+		for _, node := range branch {
+			messages = append(messages, llm.NodeToMessage(node.Type, node.Content))
+			if node.Type == "llm" {
+				llmTokens += llm.CountTokens(node.Content)
+			} else {
+				userTokens += llm.CountTokens(node.Content)
+			}
+		}
+		total := llm.CountHistoryTokens(messages)
+
+		fmt.Printf("🔢 \033[33m%d\033[0m estimated tokens in %d message(s) ending at \033[33m%s\033[0m\n", total, len(messages), shortID(end.ID))
+		if pinnedTokens > 0 {
+			fmt.Printf("   📌 pinned %d · 👤 user %d · 🤖 llm %d · formatting %d\n", pinnedTokens, userTokens, llmTokens, total-pinnedTokens-userTokens-llmTokens)
+		} else {
+			fmt.Printf("   👤 user %d · 🤖 llm %d · formatting %d\n", userTokens, llmTokens, total-userTokens-llmTokens)
+		}
+		if model == "" {
+			fmt.Printf("\033[90mUse --llm to compare with a model's context window.\033[0m\n")
+			return
+		}
+
+		window, ok := llm.GetContextWindow(model)
+		if !ok {
+			fmt.Printf("\033[90mThe context window of %s is unknown.\033[0m\n", model)
+		} else {
+			ratio := float64(total) / float64(window)
+			color := "\033[32m"
+			if ratio >= tokensWarnRatio {
+				color = "\033[33m"
+			}
+			if ratio >= 1 {
+				color = "\033[31m"
+			}
+			fmt.Printf("📏 %s%.1f%%\033[0m of \033[35m%s\033[0m's %d-token context window\n", color, ratio*100, model, window)
+			if ratio >= 1 {
+				fmt.Printf("\033[31m⚠️  The history no longer fits; summarize it or branch from an earlier node.\033[0m\n")
+			} else if ratio >= tokensWarnRatio {
+				fmt.Printf("\033[33m⚠️  The history is close to the limit; consider summarizing or branching.\033[0m\n")
+			}
+		}
+		if cost, ok := llm.EstimateCost(model, total, 0); ok {
+			fmt.Printf("💰 About \033[33m$%.4f\033[0m to send as input\n", cost)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokensCmd)
+	tokensCmd.Flags().StringP("llm", "l", "", "Model to count for (defaults to the node's model)")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/spf13/cobra"
+)
+
+var transplantCmd = &cobra.Command{
+	Use:   "transplant <node-id>",
+	Short: "Re-run a branch's user turns against a different model",
+	Long: `Replay every user turn from the root down to a node against a different model, growing a parallel
+branch of fresh responses beside the original for side-by-side comparison. The new branch shares the
+root; later user turns, notes and system messages are copied onto it with their metadata. The
+current working node stays where it is unless --checkout is given.`,
+	Example: `  bai transplant 3f2a9c1b --llm claude-3-5-sonnet
+  bai diff 3f2a9c1b <new-leaf> --branch`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		llmModel, err := cmd.Flags().GetString("llm")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get llm flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		checkout, err := cmd.Flags().GetBool("checkout")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get checkout flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		end, err := session.Node(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🌿 Transplanting the branch ending at \033[33m%s\033[0m onto \033[35m%s\033[0m...\n", end.ID, llmModel)
+
+		progress := newProgressLine("🌿")
+		leaf, err := session.Transplant(context.Background(), end, llmModel, bonsai.TransplantOptions{
+			Timeout: session.Timeout(),
+			OnTurn: func(turn bonsai.TransplantTurn) {
+				progress.Printf("\n👤 \033[33m%s\033[0m \033[90m%s\033[0m\n", shortID(turn.Message.ID), truncateContent(strings.ReplaceAll(turn.Message.Content, "\n", " "), 80))
+				progress.Printf("🤖 \033[33m%s\033[0m %s\n", shortID(turn.Response.ID), renderMarkdown(turn.Response.Content))
+			},
+			OnProgress: progress.Update,
+		})
+		progress.Done()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("\n✅ \033[32mNew branch ends at\033[0m \033[33m%s\033[0m\n", leaf.ID)
+		if checkout {
+			if err := database.SetCurrentNode(leaf.ID); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📍 Checked out \033[33m%s\033[0m\n", leaf.ID)
+		} else {
+			fmt.Printf("\033[90mCompare with: bai diff %s %s --branch\033[0m\n", shortID(end.ID), shortID(leaf.ID))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(transplantCmd)
+	transplantCmd.Flags().StringP("llm", "l", "", "Model to re-run the branch with")
+	transplantCmd.Flags().Bool("checkout", false, "Make the end of the new branch the current working node")
+	transplantCmd.MarkFlagRequired("llm")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [node-id]",
+	Short: "Draw a conversation tree, as it is now or as it was at a time",
+	Long: `Draw a conversation tree in the terminal, each node under its parent, oldest first. Without a node
+ID the whole tree holding the current working node is drawn; with one, the subtree below that node.
+
+Pass --as-of to see what the tree looked like at a time: only the nodes created by then are drawn.
+Edits and prunes aren't recorded, so nodes are shown as they are now and nodes pruned since are
+missing.`,
+	Example: `  bai tree
+  bai tree 3f2a9c1b
+  bai tree --as-of 2024-01-01
+  bai tree --as-of 3d`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		asOfFlag, err := cmd.Flags().GetString("as-of")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get as-of flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		var asOf time.Time
+		if asOfFlag != "" {
+			if asOf, err = parseTimeBound(asOfFlag, time.Now()); err != nil {
+				fmt.Printf("\033[31m❌ Invalid --as-of: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		session := newSession(database)
+		currentID := ""
+		if current, err := database.GetCurrentNode(); err == nil && current != nil {
+			currentID = *current
+		}
+
+		var topID string
+		if len(args) == 1 {
+			node, err := session.Node(args[0])
+			if err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			topID = node.ID
+		} else {
+			if currentID == "" {
+				fmt.Println("\033[90mℹ️  No current working node set. Use 'bai seed' to create a root node or 'bai checkout' to move to an existing node.\033[0m")
+				return
+			}
+			if topID, err = database.GetRootID(currentID); err != nil {
+				fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+				os.Exit(1)
+			}
+		}
+
+		nodes, err := database.GetNodeAndAllChildren(topID)
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get tree: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		total := len(nodes)
+		if !asOf.IsZero() {
+			if nodes, err = database.GetTreeAsOf(topID, asOf); err != nil {
+				fmt.Printf("\033[31m❌ Failed to get tree: %v\033[0m\n", err)
+				os.Exit(1)
+			}
+			if len(nodes) == 0 {
+				fmt.Printf("🌱 Node \033[33m%s\033[0m didn't exist yet on %s\n", shortID(topID), asOf.Format("2006-01-02 15:04"))
+				return
+			}
+			fmt.Printf("🕰️  As of %s: \033[90m%d of the %d nodes existed; edits and prunes since aren't undone\033[0m\n\n",
+				asOf.Format("2006-01-02 15:04"), len(nodes), total)
+		}
+
+		printTree(nodes, topID, currentID)
+	},
+}
+
+// printTree draws nodes as a tree below the top one, each node's children oldest first, marking the
+// current node
+func printTree(nodes []*db.Node, topID, currentID string) {
+	byID := make(map[string]*db.Node, len(nodes))
+	children := make(map[string][]*db.Node)
+	for _, node := range nodes {
+		byID[node.ID] = node
+		if node.Parent != nil && node.ID != topID {
+			children[*node.Parent] = append(children[*node.Parent], node)
+		}
+	}
+	for _, siblings := range children {
+		sort.Slice(siblings, func(i, j int) bool {
+			if siblings[i].CreatedAt != siblings[j].CreatedAt {
+				return siblings[i].CreatedAt < siblings[j].CreatedAt
+			}
+			return siblings[i].ID < siblings[j].ID
+		})
+	}
+
+	var draw func(node *db.Node, branch, indent string)
+	draw = func(node *db.Node, branch, indent string) {
+		line := fmt.Sprintf("%s%s \033[33m%s\033[0m \033[90m%s\033[0m", branch, nodeTypeIcon(node.Type), shortID(node.ID),
+			truncateContent(strings.Join(strings.Fields(node.Content), " "), 60))
+		if node.Model != nil {
+			line += fmt.Sprintf(" \033[35m(%s)\033[0m", *node.Model)
+		}
+		if node.ID == currentID {
+			line += " \033[32m← current\033[0m"
+		}
+		fmt.Println(line)
+
+		below := children[node.ID]
+		for i, child := range below {
+			if i == len(below)-1 {
+				draw(child, indent+"└─ ", indent+"   ")
+			} else {
+				draw(child, indent+"├─ ", indent+"│  ")
+			}
+		}
+	}
+	if top, ok := byID[topID]; ok {
+		draw(top, "", "")
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+	treeCmd.Flags().String("as-of", "", "Draw the tree as it was at a date (2024-01-01), time (RFC 3339) or that long ago (7d, 12h)")
+}
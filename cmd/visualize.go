@@ -2,10 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/aarose/bonsai/web"
 	"github.com/spf13/cobra"
@@ -23,7 +20,29 @@ The visualization includes:
 - Hover tooltips showing full message content
 - Different colors for user vs LLM messages
 - Zoom and pan controls
-- Real-time refresh capability`,
+- Live updates as the tree changes, including from the CLI
+
+The server only accepts connections from this machine unless --host is given. Binding
+to another interface exposes your conversations to the network, so an access token is
+then required: pass --token or set BONSAI_WEB_TOKEN, or one is generated and printed as
+part of the URL to open. Use --token on its own to require it locally too.
+
+To serve HTTPS directly, pass --tls-cert and --tls-key. Behind a reverse proxy such as
+nginx or Tailscale Serve, use --base-path when the proxy forwards the app under a path
+prefix, and --trust-proxy to honor its X-Forwarded-Proto and X-Forwarded-Prefix headers.
+
+Pass --open to open the visualization in your browser. Pages at /node/<node-id> open the
+tree focused on that node; 'bai open <node-id>' opens them directly.
+
+To let a separately hosted front end or a browser extension call the API, allow its
+origin with --cors-origin, or save it with 'bai config set web.cors_origins <origins>'.
+
+Use --readonly to disable the chat box and every API endpoint that changes the tree, for
+safely showing a tree on a shared screen.
+
+To flip between workspaces without restarting, PUT the name of another database in the same
+directory to /api/v1/db, or send the server SIGHUP to reopen its database and reread settings
+such as web.cors_origins.`,
 	Example: `  # Launch with default settings (port 8080)
   bai visualize
 
@@ -31,72 +50,57 @@ The visualization includes:
   bai visualize --port 3000
 
   # Use custom database file
-  bai visualize --database ./custom.db`,
+  bai visualize --database ./custom.db
+
+  # Allow access from other machines on the network, with a generated access token
+  bai visualize --host 0.0.0.0
+
+  # Open the visualization in your browser once it's running
+  bai visualize --open
+
+  # Show the tree without allowing any changes
+  bai visualize --readonly
+
+  # Serve under https://example.com/bonsai/ behind nginx
+  bai visualize --base-path /bonsai --trust-proxy`,
 	Run: runVisualize,
 }
 
 var (
-	visualizePort int
-	visualizeDB   string
+	visualizeFlags serverFlags
+	visualizeOpen  bool
 )
 
 func runVisualize(cmd *cobra.Command, args []string) {
-	// Determine database path
-	dbPath := visualizeDB
-	if dbPath == "" {
-		defaultPath, err := web.GetDefaultDatabasePath()
-		if err != nil {
-			log.Fatalf("Failed to get default database path: %v", err)
-		}
-		dbPath = defaultPath
-	}
+	serveVisualization(visualizeFlags.port, visualizeOpen, "")
+}
 
-	// Check if database file exists
+// serveVisualization runs the visualization server with the visualize command's settings until interrupted,
+// optionally opening a page of it in the browser
+func serveVisualization(port int, openBrowser bool, openPage string) {
+	// A missing database is created empty, and the page explains how to start a conversation
+	dbPath := visualizeFlags.databasePath()
 	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		fmt.Printf("❌ Database not found at: %s\n\n", dbPath)
-		fmt.Println("💡 Try generating some fake data first:")
-		fmt.Println("   ./scripts/generate_fake_data.sh")
-		fmt.Println("   or")
-		fmt.Println("   go run scripts/generate_fake_data.go")
-		os.Exit(1)
+		fmt.Printf("\033[90mℹ️  No database at %s yet; starting with an empty one\033[0m\n", dbPath)
 	}
 
 	// Find available port if the specified one is in use
-	actualPort := web.FindAvailablePort(visualizePort)
-	if actualPort != visualizePort {
-		fmt.Printf("⚠️  Port %d is in use, using port %d instead\n", visualizePort, actualPort)
+	actualPort := web.FindAvailablePort(visualizeFlags.host, port)
+	if actualPort != port {
+		fmt.Printf("⚠️  Port %d is in use, using port %d instead\n", port, actualPort)
 	}
 
-	// Set up signal handling for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	// Start server in a goroutine
-	serverErr := make(chan error, 1)
-	go func() {
-		err := web.StartVisualizationServer(dbPath, actualPort)
-		serverErr <- err
-	}()
-
-	// Wait for either an error or interrupt signal
-	select {
-	case err := <-serverErr:
-		if err != nil {
-			log.Fatalf("Server error: %v", err)
-		}
-	case sig := <-sigChan:
-		fmt.Printf("\n🛑 Received signal: %v\n", sig)
-		fmt.Println("👋 Shutting down visualization server...")
-		os.Exit(0)
-	}
+	opts := visualizeFlags.options(actualPort)
+	opts.OpenBrowser = openBrowser
+	opts.OpenPage = openPage
+	runServer(dbPath, opts)
 }
 
 func init() {
 	rootCmd.AddCommand(visualizeCmd)
 
 	// Add flags
-	visualizeCmd.Flags().IntVarP(&visualizePort, "port", "p", 8080, 
-		"Port to run the web server on")
-	visualizeCmd.Flags().StringVarP(&visualizeDB, "database", "d", "", 
-		"Path to database file (defaults to ~/.bonsai/bonsai.db)")
-}
\ No newline at end of file
+	visualizeFlags.register(visualizeCmd, 8080)
+	visualizeCmd.Flags().BoolVar(&visualizeOpen, "open", false,
+		"Open the visualization in your browser")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print new nodes as they're added, like tail -f for your garden",
+	Long: `Follow the database and print each node as it's added by any process: a chat in another terminal,
+the web UI, scripts, hooks or a pull from another garden. Stop with Ctrl+C.`,
+	Example: `  bai watch
+  bai watch --full`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		full, err := cmd.Flags().GetBool("full")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get full flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		changes, unsubscribe, err := database.Subscribe()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer unsubscribe()
+
+		fmt.Printf("👀 Watching \033[90m%s\033[0m for new nodes. Press Ctrl+C to stop.\n", database.GetPath())
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				return
+			case change := <-changes:
+				if change.Type == db.NodeCreated {
+					printWatchedNode(change.Node, full)
+				}
+			}
+		}
+	},
+}
+
+// printWatchedNode prints a node that was just added, with a preview of its content unless full is set
+func printWatchedNode(node *db.Node, full bool) {
+	typeIcon := nodeTypeIcon(node.Type)
+
+	details := ""
+	if node.Model != nil && *node.Model != "" {
+		details += fmt.Sprintf(" \033[35m%s\033[0m", *node.Model)
+	}
+	if node.Author != nil && *node.Author != "" {
+		details += fmt.Sprintf(" \033[36m%s\033[0m", *node.Author)
+	}
+	if node.Parent != nil {
+		details += fmt.Sprintf(" \033[90m⬆️  %s\033[0m", shortID(*node.Parent))
+	} else {
+		details += " \033[32m🌱 new tree\033[0m"
+	}
+
+	fmt.Printf("\033[90m%s\033[0m %s \033[33m%s\033[0m%s\n", time.Unix(node.CreatedAt, 0).Format("15:04:05"), typeIcon, shortID(node.ID), details)
+	if full {
+		fmt.Printf("%s\n\n", strings.TrimSpace(node.Content))
+		return
+	}
+	fmt.Printf("   💬 %s\n", truncateContent(strings.ReplaceAll(node.Content, "\n", " "), 100))
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().Bool("full", false, "Print the whole content of each node instead of a one-line preview")
+}
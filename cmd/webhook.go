@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/spf13/cobra"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Post node events to URLs, for chat bots and automation",
+	Long: `Post node events to a URL as they happen, so a Slack bot or other automation can react to new
+content, including content added through 'bai serve'. Each delivery is a JSON POST of the event, a
+one-line summary in "text" and the node:
+
+  {"event": "response-received", "text": "Response 3f2a9c1b from gpt-4o: ...", "node": {...}, "sent_at": 1700000000}
+
+The event is also in the X-Bonsai-Event header. With a secret, the X-Bonsai-Signature header holds
+"sha256=" and the hex HMAC-SHA256 of the body keyed with the secret, so the receiver can check the
+delivery is genuine. Webhooks are sent node-created and response-received events unless --event
+chooses others; failed deliveries are reported but not retried.`,
+	Example: `  bai webhook add https://hooks.slack.com/services/T000/B000/XXXX
+  bai webhook add https://example.com/bonsai --secret "$WEBHOOK_SECRET" --event response-received
+  bai webhook list
+  bai webhook test 7c21
+  bai webhook remove 7c21`,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		webhook := &db.Webhook{URL: args[0]}
+		if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			fmt.Printf("\033[31m❌ Invalid webhook URL %q: expected an http:// or https:// URL\033[0m\n", webhook.URL)
+			os.Exit(1)
+		}
+		var err error
+		if webhook.Secret, err = cmd.Flags().GetString("secret"); err != nil {
+			fmt.Printf("\033[31m❌ Failed to get secret flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		events, err := cmd.Flags().GetStringSlice("event")
+		if err != nil {
+			fmt.Printf("\033[31m❌ Failed to get event flag: %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(events) == 0 {
+			for _, event := range hooks.DefaultWebhookEvents {
+				events = append(events, string(event))
+			}
+		}
+		for _, event := range events {
+			if !slices.Contains(hooks.Events, hooks.Event(event)) {
+				fmt.Printf("\033[31m❌ Unknown event %q: expected one of %s\033[0m\n", event, webhookEventNames())
+				os.Exit(1)
+			}
+			if !slices.Contains(webhook.Events, event) {
+				webhook.Events = append(webhook.Events, event)
+			}
+		}
+
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		if err := database.AddWebhook(webhook); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🪝 \033[32mAdded webhook\033[0m \033[33m%s\033[0m for %s\n", shortID(webhook.ID), strings.Join(webhook.Events, ", "))
+		if webhook.Secret == "" {
+			fmt.Println("   \033[90mDeliveries aren't signed; pass --secret to sign them.\033[0m")
+		}
+	},
+}
+
+// webhookEventNames lists the events webhooks can subscribe to, for messages
+func webhookEventNames() string {
+	names := make([]string, len(hooks.Events))
+	for i, event := range hooks.Events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, ", ")
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		webhooks, err := database.GetWebhooks()
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if len(webhooks) == 0 {
+			fmt.Println("🪝 No webhooks. Add one with 'bai webhook add'.")
+			return
+		}
+
+		fmt.Printf("🪝 Found %d webhook(s):\n\n", len(webhooks))
+		for _, webhook := range webhooks {
+			signed := "\033[90munsigned\033[0m"
+			if webhook.Secret != "" {
+				signed = "\033[32msigned\033[0m"
+			}
+			fmt.Printf("• \033[33m%s\033[0m \033[36m%s\033[0m (%s)\n", shortID(webhook.ID), webhook.URL, signed)
+			fmt.Printf("  📣 %s\n", strings.Join(webhook.Events, ", "))
+		}
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove <webhook-id>",
+	Short: "Remove a webhook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		webhook, err := database.GetWebhook(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		if _, err := database.DeleteWebhook(webhook.ID); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🗑️  \033[32mRemoved webhook\033[0m \033[33m%s\033[0m\n", shortID(webhook.ID))
+	},
+}
+
+var webhookTestCmd = &cobra.Command{
+	Use:   "test <webhook-id> [node-id]",
+	Short: "Send a webhook its first event with a node, the current working node by default",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		database, err := initializeDatabase(false)
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		defer database.Close()
+
+		webhook, err := database.GetWebhook(args[0])
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		session := newSession(database)
+		node, err := session.Current()
+		if len(args) > 1 {
+			node, err = session.Node(args[1])
+		}
+		if err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+
+		event := hooks.NodeCreated
+		if len(webhook.Events) > 0 {
+			event = hooks.Event(webhook.Events[0])
+		}
+		if err := hooks.PostWebhook(context.Background(), webhook, event, node); err != nil {
+			fmt.Printf("\033[31m❌ %v\033[0m\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ \033[32mSent\033[0m %s for \033[33m%s\033[0m to \033[36m%s\033[0m\n", event, shortID(node.ID), webhook.URL)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookAddCmd, webhookListCmd, webhookRemoveCmd, webhookTestCmd)
+
+	webhookAddCmd.Flags().String("secret", "", "Secret deliveries are signed with, in the X-Bonsai-Signature header")
+	webhookAddCmd.Flags().StringSlice("event", nil, "Event to send: node-created, response-received or prune (repeatable; defaults to node-created and response-received)")
+}
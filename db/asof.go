@@ -0,0 +1,59 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GetTreeAsOf retrieves the nodes that existed at a time: every tree, or the subtree below nodeID if
+// it isn't empty, cut down to the nodes created by then. Nodes from before creation times were
+// recorded count as always having existed, and a node's children list only names children created by
+// then. Edits and deletions aren't versioned, so nodes are as they are now and nodes pruned since
+// are missing. The subtree is empty if nodeID itself was created after the time.
+func (db *Database) GetTreeAsOf(nodeID string, asOf time.Time) ([]*Node, error) {
+	top, args := `parent IS NULL`, []interface{}{}
+	if nodeID != "" {
+		top, args = `id = ?`, []interface{}{nodeID}
+	}
+	args = append(args, asOf.Unix(), asOf.Unix())
+
+	// UNION rather than UNION ALL, so a cycle of parent pointers can't recurse forever
+	query := `
+		WITH RECURSIVE existing(id) AS (
+			SELECT id FROM Node WHERE ` + top + ` AND COALESCE(created_at, 0) <= ?
+			UNION
+			SELECT Node.id FROM Node JOIN existing ON Node.parent = existing.id
+			WHERE COALESCE(Node.created_at, 0) <= ?
+		)
+		SELECT ` + nodeColumns + ` FROM Node WHERE id IN (SELECT id FROM existing)
+		ORDER BY id
+	`
+	nodes, err := db.queryNodes(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		existing[node.ID] = true
+	}
+	for _, node := range nodes {
+		var children []string
+		if err := json.Unmarshal([]byte(node.Children), &children); err != nil {
+			continue // Leave malformed lists as they are
+		}
+		kept := []string{}
+		for _, child := range children {
+			if existing[child] {
+				kept = append(kept, child)
+			}
+		}
+		encoded, err := json.Marshal(kept)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode children of node %s: %w", node.ID, err)
+		}
+		node.Children = string(encoded)
+	}
+	return nodes, nil
+}
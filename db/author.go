@@ -0,0 +1,41 @@
+package db
+
+import (
+	"os"
+	"os/user"
+)
+
+// AuthorConfigKey is the setting naming who new nodes are attributed to; the login name is used when
+// it isn't set
+const AuthorConfigKey = "user.name"
+
+// Author returns the name new nodes are attributed to
+func (db *Database) Author() string {
+	return db.author
+}
+
+// SetAuthor attributes the nodes created through this connection to someone else, such as the user
+// of a program embedding Bonsai
+func (db *Database) SetAuthor(name string) {
+	db.author = name
+}
+
+// loadAuthor attributes new nodes to the configured identity, or else to the user running bai
+func (db *Database) loadAuthor() error {
+	name, err := db.GetConfigValue(AuthorConfigKey)
+	if err != nil {
+		return err
+	}
+	if name != nil && *name != "" {
+		db.author = *name
+		return nil
+	}
+
+	db.author = os.Getenv("USER")
+	if db.author == "" {
+		if current, err := user.Current(); err == nil {
+			db.author = current.Username
+		}
+	}
+	return nil
+}
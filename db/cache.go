@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ResponseCacheConfigKey is the setting turning the response cache on; it's off unless set to "on"
+const ResponseCacheConfigKey = "cache.responses"
+
+// ResponseCacheEnabled reports whether generations should be answered from the response cache
+func (db *Database) ResponseCacheEnabled() (bool, error) {
+	value, err := db.GetConfigValue(ResponseCacheConfigKey)
+	if err != nil {
+		return false, err
+	}
+	return value != nil && *value == "on", nil
+}
+
+// ensureResponseCache creates the table caching LLM responses by a hash of the request
+func (db *Database) ensureResponseCache() error {
+	createCacheTable := `
+	CREATE TABLE IF NOT EXISTS ResponseCache (
+		key TEXT PRIMARY KEY,
+		model TEXT NOT NULL,
+		response TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);`
+
+	if _, err := db.conn.Exec(createCacheTable); err != nil {
+		return fmt.Errorf("failed to create ResponseCache table: %w", err)
+	}
+	return nil
+}
+
+// CachedResponse returns the response cached under a key, and whether there was one
+func (db *Database) CachedResponse(key string) (string, bool, error) {
+	var response string
+	err := db.conn.QueryRow(`SELECT response FROM ResponseCache WHERE key = ?`, key).Scan(&response)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached response: %w", err)
+	}
+
+	if db.aead != nil {
+		if response, err = open(db.aead, response); err != nil {
+			return "", false, fmt.Errorf("failed to decrypt cached response: %w", err)
+		}
+	}
+	return response, true, nil
+}
+
+// CacheResponse stores a model's response under a key, encrypted if the database is
+func (db *Database) CacheResponse(key, model, response string) error {
+	sealed, err := db.sealValue(&response)
+	if err != nil {
+		return err
+	}
+
+	return db.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO ResponseCache (key, model, response, created_at) VALUES (?, ?, ?, ?)`,
+			key, model, *sealed, time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("failed to cache response: %w", err)
+		}
+		return nil
+	})
+}
+
+// CountCachedResponses returns how many responses are cached
+func (db *Database) CountCachedResponses() (int, error) {
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM ResponseCache`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cached responses: %w", err)
+	}
+	return count, nil
+}
+
+// ClearResponseCache removes every cached response, returning how many there were
+func (db *Database) ClearResponseCache() (int, error) {
+	result, err := db.conn.Exec(`DELETE FROM ResponseCache`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear response cache: %w", err)
+	}
+
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected clearing response cache: %w", err)
+	}
+	return int(cleared), nil
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"modernc.org/sqlite"
+)
+
+// CompressMinSizeConfigKey holds the size in bytes from which content is compressed; see EnableCompression
+const CompressMinSizeConfigKey = "storage.compress_min_size"
+
+// DefaultCompressMinSize is the size from which content is compressed unless another is chosen.
+// Smaller content rarely shrinks enough to be worth it.
+const DefaultCompressMinSize = 4096
+
+// compressionGzip marks content stored gzip-compressed in its compression column
+const compressionGzip = "gzip"
+
+// CompressionStats describes the compressed content in the database
+type CompressionStats struct {
+	Contents        int   // Nodes and shared contents stored compressed
+	CompressedBytes int64 // Space the compressed content takes up
+	OriginalBytes   int64 // Space it would take up uncompressed
+}
+
+// Node content is decompressed by SQLite itself, so the queries and search index reading it don't
+// need to know whether it's compressed. Nothing stored in the database calls the function, so other
+// SQLite tools can still read and write nodes, just not see compressed content.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("bonsai_content", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if compression, _ := args[1].(string); compression == compressionGzip {
+			if compressed, ok := args[0].([]byte); ok {
+				return decompress(compressed)
+			}
+		}
+		return args[0], nil
+	})
+}
+
+// IsCompressed reports whether new content of at least CompressMinSize bytes is compressed
+func (db *Database) IsCompressed() bool {
+	return db.compressMinSize > 0
+}
+
+// CompressMinSize returns the size in bytes from which content is compressed, or 0 if it isn't
+func (db *Database) CompressMinSize() int {
+	return db.compressMinSize
+}
+
+// EnableCompression compresses the content of every node of at least minSize bytes, and of new nodes
+// from now on. Encrypted databases can't be compressed.
+func (db *Database) EnableCompression(minSize int) error {
+	if db.IsEncrypted() {
+		return errors.New("encrypted databases can't be compressed")
+	}
+	if minSize <= 0 {
+		return fmt.Errorf("invalid minimum size %d: it must be positive", minSize)
+	}
+	return db.setCompression(minSize)
+}
+
+// DisableCompression stores the content of every node uncompressed again
+func (db *Database) DisableCompression() error {
+	if !db.IsCompressed() {
+		return errors.New("the database isn't compressed")
+	}
+	return db.setCompression(0)
+}
+
+// setCompression rewrites every node's content for the minimum size and records it, then reclaims the space
+func (db *Database) setCompression(minSize int) error {
+	previous := db.compressMinSize
+	db.compressMinSize = minSize
+	err := db.rewriteNodes(db.aead, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO Config (key, value) VALUES (?, ?)`, CompressMinSizeConfigKey, strconv.Itoa(minSize)); err != nil {
+			return fmt.Errorf("failed to record %s: %w", CompressMinSizeConfigKey, err)
+		}
+		return nil
+	})
+	if err != nil {
+		db.compressMinSize = previous
+		return err
+	}
+	return db.Vacuum()
+}
+
+// GetCompressionStats reports how much content is compressed and the space that saves
+func (db *Database) GetCompressionStats() (*CompressionStats, error) {
+	stats := &CompressionStats{}
+	var compressed, original sql.NullInt64
+	query := `
+		SELECT COUNT(*), SUM(LENGTH(content)), SUM(LENGTH(CAST(bonsai_content(content, compression) AS BLOB)))
+		FROM (
+			SELECT content, compression FROM Node WHERE compression IS NOT NULL
+			UNION ALL
+			SELECT content, compression FROM Content WHERE compression IS NOT NULL
+		)
+	`
+	if err := db.conn.QueryRow(query).Scan(&stats.Contents, &compressed, &original); err != nil {
+		return nil, fmt.Errorf("failed to read compression stats: %w", err)
+	}
+	stats.CompressedBytes, stats.OriginalBytes = compressed.Int64, original.Int64
+	return stats, nil
+}
+
+// loadCompression reads the size from which new content is compressed
+func (db *Database) loadCompression() error {
+	db.compressMinSize = 0
+	value, err := db.GetConfigValue(CompressMinSizeConfigKey)
+	if err != nil || value == nil {
+		return err
+	}
+	minSize, err := strconv.Atoi(*value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", CompressMinSizeConfigKey, *value, err)
+	}
+	db.compressMinSize = minSize
+	return nil
+}
+
+// compressContent returns the content to store and its compression: gzip-compressed if compression
+// is on, it's large enough and compressing it saves space, or else the content itself
+func (db *Database) compressContent(content string) (interface{}, *string, error) {
+	if !db.IsCompressed() || len(content) < db.compressMinSize {
+		return content, nil, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return nil, nil, fmt.Errorf("failed to compress content: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to compress content: %w", err)
+	}
+	if buf.Len() >= len(content) {
+		return content, nil, nil
+	}
+
+	compression := compressionGzip
+	return buf.Bytes(), &compression, nil
+}
+
+func decompress(compressed []byte) (string, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress content: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress content: %w", err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxContentSizeConfigKey holds the largest content, in bytes, a node may be created or edited with
+const MaxContentSizeConfigKey = "storage.max_content_size"
+
+// DefaultMaxContentSize is the largest content a node may have unless another limit is chosen:
+// enough for any conversation turn, but not for a whole log file pasted by mistake
+const DefaultMaxContentSize = 1 << 20
+
+// ErrContentTooLarge is returned when a node's content is larger than the configured limit
+var ErrContentTooLarge = errors.New("content is too large")
+
+// NormalizeContent makes content safe to store, export and send to providers: invalid UTF-8 is
+// replaced with U+FFFD and NUL characters, which SQLite and many C libraries treat as the end of a
+// string, are removed
+func NormalizeContent(content string) string {
+	content = strings.ToValidUTF8(content, "\uFFFD")
+	return strings.ReplaceAll(content, "\x00", "")
+}
+
+// prepareContent is the hook every node's content passes through before it's stored: it's
+// normalized, checked against the size limit and scrubbed of secrets if redaction is on
+func (db *Database) prepareContent(content string) (string, error) {
+	content = NormalizeContent(content)
+	if db.maxContentSize > 0 && len(content) > db.maxContentSize {
+		return "", fmt.Errorf("%w: %d bytes, more than the limit of %d (see %s)", ErrContentTooLarge, len(content), db.maxContentSize, MaxContentSizeConfigKey)
+	}
+	content, _ = db.redactor.Redact(content)
+	return content, nil
+}
+
+// loadContentLimits reads the largest content new nodes may have
+func (db *Database) loadContentLimits() error {
+	db.maxContentSize = DefaultMaxContentSize
+	value, err := db.GetConfigValue(MaxContentSizeConfigKey)
+	if err != nil || value == nil || *value == "" {
+		return err
+	}
+	maxSize, err := strconv.Atoi(*value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", MaxContentSizeConfigKey, *value, err)
+	}
+	db.maxContentSize = maxSize
+	return nil
+}
@@ -1,27 +1,94 @@
 package db
 
 import (
+	"crypto/cipher"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/aarose/bonsai/pkg/redact"
 	"github.com/google/uuid"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// busyTimeout is how long a connection waits for another process's write lock before failing with SQLITE_BUSY
+const busyTimeout = 5 * time.Second
+
+// busyRetries is how many times a transaction that still hits SQLITE_BUSY after busyTimeout is retried
+const busyRetries = 3
+
 type Database struct {
 	conn *sql.DB
 	path string
+	aead cipher.AEAD // Seals node content and metadata when the database is encrypted, nil otherwise
+
+	dedup           bool             // Whether content is stored once in the Content table, see EnableDedup
+	compressMinSize int              // Size from which content is compressed, 0 if it isn't; see EnableCompression
+	redactor        *redact.Redactor // Scrubs secrets from the content of new nodes, nil if that's turned off
+	maxContentSize  int              // Largest content new nodes may have in bytes, 0 for no limit; see prepareContent
+	author          string           // Recorded as the author of new nodes
+	changes         changeFeed       // Subscribers to changes, see Subscribe
 }
 
 type Node struct {
-	ID       string  `json:"id"`
-	Content  string  `json:"content"`
-	Type     string  `json:"type"`
-	Parent   *string `json:"parent,omitempty"`
-	Children string  `json:"children"`
-	Model    *string `json:"model,omitempty"`
+	ID        string  `json:"id"`
+	Content   string  `json:"content"`
+	Type      string  `json:"type"`
+	Parent    *string `json:"parent,omitempty"`
+	Children  string  `json:"children"`
+	Model     *string `json:"model,omitempty"`
+	Metadata  *string `json:"metadata,omitempty"`
+	CreatedAt int64   `json:"created_at,omitempty"` // Unix seconds, 0 for nodes created before timestamps were tracked
+	VisitedAt int64   `json:"visited_at,omitempty"` // Unix seconds the node was last made the current node, 0 if never
+	Author    *string `json:"author,omitempty"`     // Who added the node, nil for nodes created before authors were tracked
+}
+
+// nodeColumns lists the Node columns read by every node query, in the order expected by scanNode
+const nodeColumns = `id, ` + resolvedContent + `, type, parent, children, model, metadata, COALESCE(created_at, 0), COALESCE(visited_at, 0), author`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	execer
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// scanNode scans a row selected with nodeColumns into a Node, decrypting it if the database is encrypted
+func (db *Database) scanNode(scanner rowScanner) (*Node, error) {
+	node := &Node{}
+	err := scanner.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model, &node.Metadata, &node.CreatedAt, &node.VisitedAt, &node.Author)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.openNode(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// GetMetadata decodes the node's metadata JSON, returning an empty map if none is set
+func (n *Node) GetMetadata() map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if n.Metadata != nil && *n.Metadata != "" {
+		// Malformed metadata is treated as empty rather than failing reads
+		_ = json.Unmarshal([]byte(*n.Metadata), &metadata)
+	}
+	return metadata
 }
 
 // NewDatabase creates a new database connection
@@ -32,8 +99,11 @@ func NewDatabase(dbPath string) (*Database, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	conn, err := sql.Open("sqlite", dbPath)
+	// Open database connection. Other bai processes (a chat session, the visualize server, scripts)
+	// may use the same file, so wait for their locks instead of failing immediately, use WAL so readers
+	// don't block the writer, and take the write lock up front so transactions can't deadlock on upgrade.
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate", dbPath, busyTimeout.Milliseconds())
+	conn, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -52,22 +122,139 @@ func NewDatabase(dbPath string) (*Database, error) {
 	return db, nil
 }
 
+// IsBusyError reports whether err was caused by another connection holding the database lock
+func IsBusyError(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff // strip extended result code
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// withTx runs fn inside a write transaction, committing if it returns nil and rolling back otherwise
+// Transactions that fail because another process kept the database busy are retried from the start.
+func (db *Database) withTx(fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 0; attempt <= busyRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+
+		err = db.runTx(fn)
+		if err == nil || !IsBusyError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("database is busy; another bai process is holding it: %w", err)
+}
+
+// runTx makes a single attempt at running fn inside a transaction
+func (db *Database) runTx(fn func(tx *sql.Tx) error) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion is the version of the schema created by Initialize, stored in the database's user_version
+// Bump it whenever Initialize changes the schema.
+const SchemaVersion = 9
+
 // Initialize creates the necessary tables if they don't exist
 func (db *Database) Initialize() error {
+	// Serialize schema migrations so two processes upgrading an old database don't race
+	unlock, err := db.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	createNodeTable := `
 	CREATE TABLE IF NOT EXISTS Node (
 		id TEXT PRIMARY KEY,
 		content TEXT NOT NULL,
-		type TEXT NOT NULL CHECK (type IN ('user', 'llm')),
+		type TEXT NOT NULL ` + nodeTypeCheck() + `,
 		parent TEXT,
 		children TEXT DEFAULT '[]',
-		model TEXT
+		model TEXT,
+		metadata TEXT,
+		created_at INTEGER,
+		visited_at INTEGER,
+		author TEXT,
+		content_hash TEXT,
+		compression TEXT
 	);`
 
 	if _, err := db.conn.Exec(createNodeTable); err != nil {
 		return fmt.Errorf("failed to create Node table: %w", err)
 	}
 
+	// Add columns introduced after the original schema to existing databases
+	if err := db.ensureColumn("Node", "metadata", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("Node", "created_at", "INTEGER"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("Node", "visited_at", "INTEGER"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("Node", "author", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("Node", "content_hash", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.ensureColumn("Node", "compression", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.ensureNodeTypes(); err != nil {
+		return err
+	}
+
+	// Speeds up child lookups and the recursive tree queries on large databases
+	if _, err := db.conn.Exec(`CREATE INDEX IF NOT EXISTS idx_node_parent ON Node(parent)`); err != nil {
+		return fmt.Errorf("failed to create Node parent index: %w", err)
+	}
+	if err := db.ensureFilterIndexes(); err != nil {
+		return err
+	}
+
+	if err := db.ensureContentTable(); err != nil {
+		return err
+	}
+
+	if err := db.ensureSearchIndex(); err != nil {
+		return err
+	}
+
+	if err := db.ensureChangeLog(); err != nil {
+		return err
+	}
+
+	if err := db.ensureResponseCache(); err != nil {
+		return err
+	}
+
+	if err := db.ensureSchedules(); err != nil {
+		return err
+	}
+
+	if err := db.ensureWebhooks(); err != nil {
+		return err
+	}
+
 	createConfigTable := `
 	CREATE TABLE IF NOT EXISTS Config (
 		key TEXT PRIMARY KEY,
@@ -78,11 +265,77 @@ func (db *Database) Initialize() error {
 		return fmt.Errorf("failed to create Config table: %w", err)
 	}
 
+	if _, err := db.conn.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	if err := db.unlockEncryption(); err != nil {
+		return err
+	}
+	if err := db.loadAuthor(); err != nil {
+		return err
+	}
+	if err := db.loadDedup(); err != nil {
+		return err
+	}
+	if err := db.loadCompression(); err != nil {
+		return err
+	}
+	if err := db.loadContentLimits(); err != nil {
+		return err
+	}
+	return db.loadRedaction()
+}
+
+// ensureColumn adds a column to a table if it doesn't already exist
+func (db *Database) ensureColumn(table, column, definition string) error {
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read %s table info: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			colType      string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return fmt.Errorf("failed to scan %s table info: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over %s table info: %w", table, err)
+	}
+	rows.Close()
+
+	alter := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+	if _, err := db.conn.Exec(alter); err != nil {
+		return fmt.Errorf("failed to add %s column to %s table: %w", column, table, err)
+	}
+
 	return nil
 }
 
-// Close closes the database connection
+// Close stops publishing changes to subscribers and closes the database connection
 func (db *Database) Close() error {
+	db.changes.mu.Lock()
+	stop, done := db.changes.stop, db.changes.done
+	db.changes.stop = nil
+	db.changes.mu.Unlock()
+	if stop != nil {
+		stop()
+		<-done
+	}
+
 	if db.conn != nil {
 		return db.conn.Close()
 	}
@@ -110,15 +363,17 @@ func (db *Database) CreateRootNode(content string, model *string) (*Node, error)
 		Model:    model,
 	}
 
-	if err := db.InsertNode(node); err != nil {
+	// Insert the node and make it the current working node atomically
+	err := db.withTx(func(tx *sql.Tx) error {
+		if err := db.insertNode(tx, node); err != nil {
+			return err
+		}
+		return setCurrentNode(tx, node.ID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Set this as the current working node
-	if err := db.SetCurrentNode(node.ID); err != nil {
-		return node, fmt.Errorf("created node but failed to set as current: %w", err)
-	}
-
 	return node, nil
 }
 
@@ -129,15 +384,34 @@ func (db *Database) CreateChildNode(content, parentID string, model *string) (*N
 
 // CreateChildNodeWithType creates a new child node with specific type
 func (db *Database) CreateChildNodeWithType(content, parentID, nodeType string, model *string) (*Node, error) {
-	// Verify parent exists
-	_, err := db.GetNodeByID(parentID)
-	if err != nil {
-		return nil, fmt.Errorf("parent node not found: %w", err)
-	}
+	return db.createChildNode(content, parentID, nodeType, model, moveAlways)
+}
 
+// AddChildNode creates a new child node like CreateChildNodeWithType, but only makes it the current
+// working node if its parent is the current working node, so side branches leave the user's place alone
+func (db *Database) AddChildNode(content, parentID, nodeType string, model *string) (*Node, error) {
+	return db.createChildNode(content, parentID, nodeType, model, moveFromParent)
+}
+
+// CreateBranchNode creates a new child node without changing the current working node
+func (db *Database) CreateBranchNode(content, parentID, nodeType string, model *string) (*Node, error) {
+	return db.createChildNode(content, parentID, nodeType, model, moveNever)
+}
+
+// currentNodeMove says when creating a child node makes it the current working node
+type currentNodeMove int
+
+const (
+	moveAlways     currentNodeMove = iota // Always move to the new node
+	moveFromParent                        // Move only if the parent was the current working node
+	moveNever                             // Leave the current working node alone
+)
+
+// createChildNode inserts a child node and moves the current working node to it as move says
+func (db *Database) createChildNode(content, parentID, nodeType string, model *string, move currentNodeMove) (*Node, error) {
 	// Validate node type
-	if nodeType != "user" && nodeType != "llm" {
-		return nil, fmt.Errorf("invalid node type: %s (must be 'user' or 'llm')", nodeType)
+	if !IsNodeType(nodeType) {
+		return nil, nodeTypeError(nodeType)
 	}
 
 	node := &Node{
@@ -149,13 +423,39 @@ func (db *Database) CreateChildNodeWithType(content, parentID, nodeType string,
 		Model:    model,
 	}
 
-	if err := db.InsertNode(node); err != nil {
-		return nil, err
-	}
+	// Check the parent, insert the node and make it current in one transaction so a concurrent
+	// prune can't delete the parent in between
+	err := db.withTx(func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow(`SELECT 1 FROM Node WHERE id = ?`, parentID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("parent node not found: node with ID %s not found", parentID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up parent node %s: %w", parentID, err)
+		}
 
-	// Set this as the current working node
-	if err := db.SetCurrentNode(node.ID); err != nil {
-		return node, fmt.Errorf("created node but failed to set as current: %w", err)
+		if err := db.insertNode(tx, node); err != nil {
+			return err
+		}
+
+		switch move {
+		case moveNever:
+			return nil
+		case moveFromParent:
+			var currentNodeID string
+			err := tx.QueryRow(`SELECT value FROM Config WHERE key = 'current_node'`).Scan(&currentNodeID)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to get current node: %w", err)
+			}
+			if currentNodeID != parentID {
+				return nil
+			}
+		}
+		return setCurrentNode(tx, node.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return node, nil
@@ -169,12 +469,36 @@ func (db *Database) CreateLLMResponseNode(parentID, content, model string) (*Nod
 
 // InsertNode inserts a node into the database
 func (db *Database) InsertNode(node *Node) error {
+	return db.insertNode(db.conn, node)
+}
+
+// insertNode inserts a node using the given connection or transaction, stamping its creation time and
+// author if unset and preparing its content with prepareContent
+func (db *Database) insertNode(e execer, node *Node) error {
+	if node.CreatedAt == 0 {
+		node.CreatedAt = time.Now().Unix()
+	}
+	if node.Author == nil && db.author != "" {
+		author := db.author
+		node.Author = &author
+	}
+	prepared, err := db.prepareContent(node.Content)
+	if err != nil {
+		return err
+	}
+	node.Content = prepared
+
 	query := `
-		INSERT INTO Node (id, content, type, parent, children, model)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO Node (id, content, content_hash, compression, type, parent, children, model, metadata, created_at, author)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := db.conn.Exec(query, node.ID, node.Content, node.Type, node.Parent, node.Children, node.Model)
+	content, metadata, err := db.sealNode(e, node)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(query, node.ID, content.content, content.hash, content.compression, node.Type, node.Parent, node.Children, node.Model, metadata, node.CreatedAt, node.Author)
 	if err != nil {
 		return fmt.Errorf("failed to insert node: %w", err)
 	}
@@ -182,10 +506,185 @@ func (db *Database) InsertNode(node *Node) error {
 	return nil
 }
 
+// InsertNodes inserts new nodes like InsertNode, in one transaction so either all of them are stored or none
+func (db *Database) InsertNodes(nodes []*Node) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		for _, node := range nodes {
+			if err := db.insertNode(tx, node); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpsertNodes inserts nodes, or overwrites the stored nodes with the same IDs, in one transaction.
+// Nodes keep the time they were last visited in this database.
+func (db *Database) UpsertNodes(nodes []*Node) error {
+	query := `
+		INSERT INTO Node (id, content, content_hash, compression, type, parent, children, model, metadata, created_at, author)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			content = excluded.content, content_hash = excluded.content_hash, compression = excluded.compression, type = excluded.type, parent = excluded.parent,
+			model = excluded.model, metadata = excluded.metadata, created_at = excluded.created_at,
+			author = excluded.author
+	`
+
+	return db.withTx(func(tx *sql.Tx) error {
+		for _, node := range nodes {
+			if !IsNodeType(node.Type) {
+				return fmt.Errorf("invalid type %q for node %s", node.Type, node.ID)
+			}
+			// Nodes from elsewhere are normalized but not held to this database's size limit, so a
+			// sync can't fail halfway over a node another database accepted
+			node.Content = NormalizeContent(node.Content)
+			children := node.Children
+			if children == "" {
+				children = "[]"
+			}
+			content, metadata, err := db.sealNode(tx, node)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(query, node.ID, content.content, content.hash, content.compression, node.Type, node.Parent, children, node.Model, metadata, node.CreatedAt, node.Author); err != nil {
+				return fmt.Errorf("failed to store node %s: %w", node.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// SetNodeMetadata sets a single metadata key on a node, preserving any existing keys
+func (db *Database) SetNodeMetadata(nodeID, key string, value interface{}) error {
+	// Read and write in one transaction so concurrent updates to other keys aren't lost
+	return db.withTx(func(tx *sql.Tx) error {
+		node, err := db.scanNode(tx.QueryRow(`SELECT `+nodeColumns+` FROM Node WHERE id = ?`, nodeID))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("node with ID %s not found", nodeID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get node: %w", err)
+		}
+
+		metadata := node.GetMetadata()
+		metadata[key] = value
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for node %s: %w", nodeID, err)
+		}
+		value := string(encoded)
+		stored, err := db.sealValue(&value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE Node SET metadata = ? WHERE id = ?`, stored, nodeID); err != nil {
+			return fmt.Errorf("failed to update metadata for node %s: %w", nodeID, err)
+		}
+		return nil
+	})
+}
+
+// DeleteNodeMetadata removes a single metadata key from a node, preserving any other keys
+func (db *Database) DeleteNodeMetadata(nodeID, key string) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		node, err := db.scanNode(tx.QueryRow(`SELECT `+nodeColumns+` FROM Node WHERE id = ?`, nodeID))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("node with ID %s not found", nodeID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get node: %w", err)
+		}
+
+		metadata := node.GetMetadata()
+		if _, ok := metadata[key]; !ok {
+			return nil
+		}
+		delete(metadata, key)
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for node %s: %w", nodeID, err)
+		}
+		value := string(encoded)
+		stored, err := db.sealValue(&value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE Node SET metadata = ? WHERE id = ?`, stored, nodeID); err != nil {
+			return fmt.Errorf("failed to update metadata for node %s: %w", nodeID, err)
+		}
+		return nil
+	})
+}
+
+// AppendNodeMetadata appends a value to the list under a metadata key, creating the list if needed
+func (db *Database) AppendNodeMetadata(nodeID, key string, value interface{}) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		node, err := db.scanNode(tx.QueryRow(`SELECT `+nodeColumns+` FROM Node WHERE id = ?`, nodeID))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("node with ID %s not found", nodeID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get node: %w", err)
+		}
+
+		metadata := node.GetMetadata()
+		list, _ := metadata[key].([]interface{})
+		metadata[key] = append(list, value)
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for node %s: %w", nodeID, err)
+		}
+		value := string(encoded)
+		stored, err := db.sealValue(&value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE Node SET metadata = ? WHERE id = ?`, stored, nodeID); err != nil {
+			return fmt.Errorf("failed to update metadata for node %s: %w", nodeID, err)
+		}
+		return nil
+	})
+}
+
+// UpdateNodeContent replaces the content of an existing node, preparing the new content with
+// prepareContent
+func (db *Database) UpdateNodeContent(nodeID, content string) error {
+	content, err := db.prepareContent(content)
+	if err != nil {
+		return err
+	}
+	stored, err := db.storeContent(db.conn, content)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.conn.Exec(`UPDATE Node SET content = ?, content_hash = ?, compression = ? WHERE id = ?`,
+		stored.content, stored.hash, stored.compression, nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to update node %s: %w", nodeID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for node %s: %w", nodeID, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("node with ID %s not found", nodeID)
+	}
+
+	return nil
+}
+
 // GetRootNodes retrieves all nodes that have no parent (root nodes)
 func (db *Database) GetRootNodes() ([]*Node, error) {
 	query := `
-		SELECT id, content, type, parent, children, model
+		SELECT ` + nodeColumns + `
 		FROM Node
 		WHERE parent IS NULL
 		ORDER BY id
@@ -199,8 +698,7 @@ func (db *Database) GetRootNodes() ([]*Node, error) {
 
 	var nodes []*Node
 	for rows.Next() {
-		node := &Node{}
-		err := rows.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model)
+		node, err := db.scanNode(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan node: %w", err)
 		}
@@ -214,6 +712,50 @@ func (db *Database) GetRootNodes() ([]*Node, error) {
 	return nodes, nil
 }
 
+// GetAllNodes retrieves every node in the database
+func (db *Database) GetAllNodes() ([]*Node, error) {
+	var nodes []*Node
+	err := db.EachNode(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// EachNode calls fn with every node in the database in turn, as each is read, stopping at the first
+// error fn returns. Unlike GetAllNodes it doesn't hold every node in memory at once.
+func (db *Database) EachNode(fn func(node *Node) error) error {
+	query := `
+		SELECT ` + nodeColumns + `
+		FROM Node
+		ORDER BY id
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query nodes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		node, err := db.scanNode(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan node: %w", err)
+		}
+		if err := fn(node); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return nil
+}
+
 // GetCurrentNode retrieves the current working node ID
 func (db *Database) GetCurrentNode() (*string, error) {
 	query := `SELECT value FROM Config WHERE key = 'current_node'`
@@ -230,21 +772,110 @@ func (db *Database) GetCurrentNode() (*string, error) {
 	return &nodeID, nil
 }
 
-// SetCurrentNode sets the current working node
+// SetCurrentNode sets the current working node and records when it was visited
 func (db *Database) SetCurrentNode(nodeID string) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		return setCurrentNode(tx, nodeID)
+	})
+}
+
+// setCurrentNode updates the global and per-tree current node pointers using the given transaction
+func setCurrentNode(q querier, nodeID string) error {
 	query := `
-		INSERT INTO Config (key, value) VALUES ('current_node', ?)
-		ON CONFLICT(key) DO UPDATE SET value = ?
+		INSERT INTO Config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
 	`
 
-	_, err := db.conn.Exec(query, nodeID, nodeID)
-	if err != nil {
+	if _, err := q.Exec(query, "current_node", nodeID); err != nil {
 		return fmt.Errorf("failed to set current node: %w", err)
 	}
 
+	if _, err := q.Exec(`UPDATE Node SET visited_at = ? WHERE id = ?`, time.Now().Unix(), nodeID); err != nil {
+		return fmt.Errorf("failed to record visit to node %s: %w", nodeID, err)
+	}
+
+	// Remember the position within this tree so switching back to it restores the user's place
+	rootID, err := getRootID(q, nodeID)
+	if err != nil {
+		return err
+	}
+	if _, err := q.Exec(query, treeCurrentNodeKey(rootID), nodeID); err != nil {
+		return fmt.Errorf("failed to set current node for tree %s: %w", rootID, err)
+	}
+
+	return nil
+}
+
+// moveCurrentNode points the global and per-tree current node pointers at the node toID instead of
+// fromID, which was merged away or split, making toID the current working node with setCurrentNode
+// if fromID was
+func moveCurrentNode(q querier, fromID, toID string) error {
+	var current string
+	err := q.QueryRow(`SELECT value FROM Config WHERE key = 'current_node'`).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get current node: %w", err)
+	}
+
+	if _, err := q.Exec(`UPDATE Config SET value = ? WHERE key GLOB 'current_node:*' AND value = ?`, toID, fromID); err != nil {
+		return fmt.Errorf("failed to move current node of trees: %w", err)
+	}
+	if current == fromID {
+		return setCurrentNode(q, toID)
+	}
 	return nil
 }
 
+// GetTreeCurrentNode retrieves the last current node within the tree rooted at rootID
+// Returns nil if the tree has no remembered position or the remembered node no longer belongs to it
+func (db *Database) GetTreeCurrentNode(rootID string) (*string, error) {
+	nodeID, err := db.GetConfigValue(treeCurrentNodeKey(rootID))
+	if err != nil || nodeID == nil {
+		return nil, err
+	}
+
+	nodeRootID, err := db.GetRootID(*nodeID)
+	if err != nil || nodeRootID != rootID {
+		// The node was pruned or moved to another tree since it was remembered
+		return nil, nil
+	}
+
+	return nodeID, nil
+}
+
+// treeCurrentNodeKey returns the Config key holding the current node of a single tree
+func treeCurrentNodeKey(rootID string) string {
+	return "current_node:" + rootID
+}
+
+// GetRootID returns the ID of the root node of the tree containing the given node
+func (db *Database) GetRootID(nodeID string) (string, error) {
+	return getRootID(db.conn, nodeID)
+}
+
+// getRootID looks up the root of a node's tree using the given connection or transaction
+func getRootID(q querier, nodeID string) (string, error) {
+	query := `
+		WITH RECURSIVE ancestor(id, parent) AS (
+			SELECT id, parent FROM Node WHERE id = ?
+			UNION ALL
+			SELECT Node.id, Node.parent FROM Node JOIN ancestor ON Node.id = ancestor.parent
+		)
+		SELECT id FROM ancestor WHERE parent IS NULL OR parent NOT IN (SELECT id FROM Node)
+		LIMIT 1
+	`
+
+	var rootID string
+	err := q.QueryRow(query, nodeID).Scan(&rootID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("node with ID %s not found", nodeID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find root of node %s: %w", nodeID, err)
+	}
+
+	return rootID, nil
+}
+
 // ClearCurrentNode removes the current working node setting
 func (db *Database) ClearCurrentNode() error {
 	query := `DELETE FROM Config WHERE key = 'current_node'`
@@ -257,6 +888,68 @@ func (db *Database) ClearCurrentNode() error {
 	return nil
 }
 
+// GetConfigValue retrieves a configuration value by key, returning nil if it isn't set
+func (db *Database) GetConfigValue(key string) (*string, error) {
+	var value string
+	err := db.conn.QueryRow(`SELECT value FROM Config WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config value %s: %w", key, err)
+	}
+
+	return &value, nil
+}
+
+// SetConfigValue sets a configuration value, replacing any existing value for the key
+func (db *Database) SetConfigValue(key, value string) error {
+	query := `
+		INSERT INTO Config (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`
+
+	if _, err := db.conn.Exec(query, key, value); err != nil {
+		return fmt.Errorf("failed to set config value %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// DeleteConfigValue removes a configuration value
+func (db *Database) DeleteConfigValue(key string) error {
+	if _, err := db.conn.Exec(`DELETE FROM Config WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to delete config value %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// GetConfigValues retrieves all configuration values whose key starts with the given prefix
+func (db *Database) GetConfigValues(prefix string) (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT key, value FROM Config WHERE substr(key, 1, length(?)) = ? ORDER BY key`, prefix, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config values: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan config value: %w", err)
+		}
+		values[key] = value.String
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over config rows: %w", err)
+	}
+
+	return values, nil
+}
+
 // GetNodeAndAllChildren retrieves a node and all its descendants recursively
 func (db *Database) GetNodeAndAllChildren(nodeID string) ([]*Node, error) {
 	var allNodes []*Node
@@ -278,11 +971,10 @@ func (db *Database) collectNodeAndChildren(nodeID string, allNodes *[]*Node, vis
 	visited[nodeID] = true
 
 	// Get the current node
-	query := `SELECT id, content, type, parent, children, model FROM Node WHERE id = ?`
+	query := `SELECT ` + nodeColumns + ` FROM Node WHERE id = ?`
 	row := db.conn.QueryRow(query, nodeID)
 
-	node := &Node{}
-	err := row.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model)
+	node, err := db.scanNode(row)
 	if err == sql.ErrNoRows {
 		return nil // Node doesn't exist, skip
 	}
@@ -325,11 +1017,10 @@ func (db *Database) collectNodeAndChildren(nodeID string, allNodes *[]*Node, vis
 
 // GetNodeByID retrieves a single node by its ID
 func (db *Database) GetNodeByID(nodeID string) (*Node, error) {
-	query := `SELECT id, content, type, parent, children, model FROM Node WHERE id = ?`
+	query := `SELECT ` + nodeColumns + ` FROM Node WHERE id = ?`
 	row := db.conn.QueryRow(query, nodeID)
 
-	node := &Node{}
-	err := row.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model)
+	node, err := db.scanNode(row)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("node with ID %s not found", nodeID)
 	}
@@ -340,10 +1031,83 @@ func (db *Database) GetNodeByID(nodeID string) (*Node, error) {
 	return node, nil
 }
 
+// ResolveNodeID expands a full or abbreviated node ID into the full ID of a single existing node
+func (db *Database) ResolveNodeID(idOrPrefix string) (string, error) {
+	if idOrPrefix == "" {
+		return "", fmt.Errorf("node ID must not be empty")
+	}
+
+	rows, err := db.conn.Query(`SELECT id FROM Node WHERE substr(id, 1, length(?)) = ? LIMIT 2`, idOrPrefix, idOrPrefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up node %s: %w", idOrPrefix, err)
+	}
+	defer rows.Close()
+
+	var matches []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", fmt.Errorf("failed to scan node ID: %w", err)
+		}
+		matches = append(matches, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating over node IDs: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("node with ID %s not found", idOrPrefix)
+	case 1:
+		return matches[0], nil
+	default:
+		// An exact match wins over longer IDs sharing the prefix
+		for _, id := range matches {
+			if id == idOrPrefix {
+				return id, nil
+			}
+		}
+		return "", fmt.Errorf("node ID %s is ambiguous; use more characters", idOrPrefix)
+	}
+}
+
+// GetRecentNodes retrieves the most recently created or visited nodes across all trees
+func (db *Database) GetRecentNodes(limit int) ([]*Node, error) {
+	query := `
+		SELECT ` + nodeColumns + `
+		FROM Node
+		WHERE created_at IS NOT NULL OR visited_at IS NOT NULL
+		ORDER BY MAX(COALESCE(created_at, 0), COALESCE(visited_at, 0)) DESC
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*Node
+	for rows.Next() {
+		node, err := db.scanNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return nodes, nil
+}
+
 // GetDirectChildren retrieves all direct children of a node (non-recursive)
 func (db *Database) GetDirectChildren(parentID string) ([]*Node, error) {
 	query := `
-		SELECT id, content, type, parent, children, model
+		SELECT ` + nodeColumns + `
 		FROM Node
 		WHERE parent = ?
 		ORDER BY id
@@ -357,8 +1121,7 @@ func (db *Database) GetDirectChildren(parentID string) ([]*Node, error) {
 
 	var nodes []*Node
 	for rows.Next() {
-		node := &Node{}
-		err := rows.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model)
+		node, err := db.scanNode(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan child node: %w", err)
 		}
@@ -372,6 +1135,107 @@ func (db *Database) GetDirectChildren(parentID string) ([]*Node, error) {
 	return nodes, nil
 }
 
+// GetRootNodesPage retrieves up to limit root nodes ordered by ID, starting after the given ID ("" for the first page)
+func (db *Database) GetRootNodesPage(after string, limit int) ([]*Node, error) {
+	query := `
+		SELECT ` + nodeColumns + `
+		FROM Node
+		WHERE parent IS NULL AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`
+	return db.queryNodes(query, after, limit)
+}
+
+// GetChildrenPage retrieves up to limit direct children of a node ordered by ID, starting after the given ID ("" for the first page)
+func (db *Database) GetChildrenPage(parentID, after string, limit int) ([]*Node, error) {
+	query := `
+		SELECT ` + nodeColumns + `
+		FROM Node
+		WHERE parent = ? AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`
+	return db.queryNodes(query, parentID, after, limit)
+}
+
+// CountChildren returns the number of direct children of each given node; nodes without children are omitted
+func (db *Database) CountChildren(nodeIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(nodeIDs) == 0 {
+		return counts, nil
+	}
+
+	placeholders := strings.Repeat("?, ", len(nodeIDs)-1) + "?"
+	args := make([]interface{}, len(nodeIDs))
+	for i, id := range nodeIDs {
+		args[i] = id
+	}
+
+	rows, err := db.conn.Query(`SELECT parent, COUNT(*) FROM Node WHERE parent IN (`+placeholders+`) GROUP BY parent`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count children: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentID string
+		var count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan child count: %w", err)
+		}
+		counts[parentID] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over child counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetUnansweredNodes returns the user messages in the subtree of the given node, itself included, that
+// have a model but no LLM response below them, as when generating the response failed or was
+// interrupted, oldest first
+func (db *Database) GetUnansweredNodes(nodeID string) ([]*Node, error) {
+	query := `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM Node WHERE id = ?
+			UNION
+			SELECT Node.id FROM Node JOIN subtree ON Node.parent = subtree.id
+		)
+		SELECT ` + nodeColumns + ` FROM Node
+		WHERE id IN (SELECT id FROM subtree) AND type = 'user' AND model IS NOT NULL AND model != ''
+			AND NOT EXISTS (SELECT 1 FROM Node AS reply WHERE reply.parent = Node.id AND reply.type = 'llm')
+		ORDER BY created_at
+	`
+	return db.queryNodes(query, nodeID)
+}
+
+// queryNodes runs a query selecting nodeColumns and scans every resulting node
+func (db *Database) queryNodes(query string, args ...interface{}) ([]*Node, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*Node
+	for rows.Next() {
+		node, err := db.scanNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return nodes, nil
+}
+
 // GetParentPath retrieves the parent chain from a given node up to the root
 // If maxLevels is 0 or negative, returns the complete path to the root
 // If maxLevels is positive, returns up to that many parent levels
@@ -445,35 +1309,242 @@ func (db *Database) GetConversationHistory(nodeID string) ([]*Node, error) {
 
 // DeleteNodeAndAllChildren deletes a node and all its descendants recursively
 func (db *Database) DeleteNodeAndAllChildren(nodeID string) (int, error) {
-	// First, get all nodes to be deleted
-	nodesToDelete, err := db.GetNodeAndAllChildren(nodeID)
+	// A single statement deletes the whole subtree atomically, including any children another
+	// process attaches while we're working
+	query := `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM Node WHERE id = ?
+			UNION
+			SELECT Node.id FROM Node JOIN subtree ON Node.parent = subtree.id
+		)
+		DELETE FROM Node WHERE id IN (SELECT id FROM subtree)
+	`
+
+	var deletedCount int64
+	err := db.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, nodeID)
+		if err != nil {
+			return fmt.Errorf("failed to delete node %s: %w", nodeID, err)
+		}
+
+		deletedCount, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected for node %s: %w", nodeID, err)
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get nodes to delete: %w", err)
+		return 0, err
 	}
 
-	if len(nodesToDelete) == 0 {
-		return 0, nil
+	return int(deletedCount), nil
+}
+
+// DeleteNodeKeepChildren deletes a single node and reattaches its direct children to the node's parent
+// If the node is a root, its children become new root nodes. Returns the number of reattached children.
+func (db *Database) DeleteNodeKeepChildren(nodeID string) (int, error) {
+	var reattached int64
+	err := db.withTx(func(tx *sql.Tx) error {
+		var parent *string
+		err := tx.QueryRow(`SELECT parent FROM Node WHERE id = ?`, nodeID).Scan(&parent)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("node with ID %s not found", nodeID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get node: %w", err)
+		}
+
+		result, err := tx.Exec(`UPDATE Node SET parent = ? WHERE parent = ?`, parent, nodeID)
+		if err != nil {
+			return fmt.Errorf("failed to reattach children of node %s: %w", nodeID, err)
+		}
+
+		reattached, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected for node %s: %w", nodeID, err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM Node WHERE id = ?`, nodeID); err != nil {
+			return fmt.Errorf("failed to delete node %s: %w", nodeID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	// Delete all nodes (children first, then parents)
-	// We'll delete in reverse order to handle foreign key constraints
-	deletedCount := 0
-	for i := len(nodesToDelete) - 1; i >= 0; i-- {
-		node := nodesToDelete[i]
+	return int(reattached), nil
+}
 
-		deleteQuery := `DELETE FROM Node WHERE id = ?`
-		result, err := db.conn.Exec(deleteQuery, node.ID)
+// MergeNodeInto deletes a node after moving its direct children below another node, such as a sibling
+// holding the same content, and makes that node the current working node if the deleted one was.
+// Returns the number of children moved.
+func (db *Database) MergeNodeInto(nodeID, intoID string) (int, error) {
+	if nodeID == intoID {
+		return 0, fmt.Errorf("cannot merge node %s into itself", nodeID)
+	}
+
+	var moved int64
+	err := db.withTx(func(tx *sql.Tx) error {
+		for _, id := range []string{nodeID, intoID} {
+			var exists int
+			err := tx.QueryRow(`SELECT 1 FROM Node WHERE id = ?`, id).Scan(&exists)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("node with ID %s not found", id)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get node: %w", err)
+			}
+		}
+
+		result, err := tx.Exec(`UPDATE Node SET parent = ? WHERE parent = ?`, intoID, nodeID)
 		if err != nil {
-			return deletedCount, fmt.Errorf("failed to delete node %s: %w", node.ID, err)
+			return fmt.Errorf("failed to move children of node %s: %w", nodeID, err)
+		}
+		if moved, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get rows affected for node %s: %w", nodeID, err)
 		}
 
-		rowsAffected, err := result.RowsAffected()
+		if _, err := tx.Exec(`DELETE FROM Node WHERE id = ?`, nodeID); err != nil {
+			return fmt.Errorf("failed to delete node %s: %w", nodeID, err)
+		}
+		return moveCurrentNode(tx, nodeID, intoID)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved), nil
+}
+
+// CloneBranch copies the branch from the root down to the given node into a brand-new root tree
+// If includeSubtree is true, all descendants of the node are copied as well. Each copy records the
+// node it was cloned from in its metadata. Returns the copy of the given node and the number of nodes copied.
+func (db *Database) CloneBranch(nodeID string, includeSubtree bool) (*Node, int, error) {
+	sources, err := db.GetConversationHistory(nodeID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if includeSubtree {
+		// The first entry is the node itself, which is already the tip of the branch
+		subtree, err := db.GetNodeAndAllChildren(nodeID)
 		if err != nil {
-			return deletedCount, fmt.Errorf("failed to get rows affected for node %s: %w", node.ID, err)
+			return nil, 0, fmt.Errorf("failed to get subtree of node %s: %w", nodeID, err)
 		}
+		sources = append(sources, subtree[1:]...)
+	}
 
-		deletedCount += int(rowsAffected)
+	var clonedTip *Node
+	err = db.withTx(func(tx *sql.Tx) error {
+		// Map source IDs to their copies so parents are rewritten as we go; parents always precede children
+		newIDs := make(map[string]string, len(sources))
+		for i, source := range sources {
+			metadata := source.GetMetadata()
+			metadata["cloned_from"] = source.ID
+			encoded, err := json.Marshal(metadata)
+			if err != nil {
+				return fmt.Errorf("failed to encode metadata for node %s: %w", source.ID, err)
+			}
+			encodedMetadata := string(encoded)
+
+			clone := &Node{
+				ID:       uuid.New().String(),
+				Content:  source.Content,
+				Type:     source.Type,
+				Children: "[]",
+				Model:    source.Model,
+				Metadata: &encodedMetadata,
+				Author:   source.Author, // The copied turns were still written by the same people
+			}
+			if i > 0 && source.Parent != nil {
+				parentID := newIDs[*source.Parent]
+				clone.Parent = &parentID
+			}
+
+			if err := db.insertNode(tx, clone); err != nil {
+				return fmt.Errorf("failed to clone node %s: %w", source.ID, err)
+			}
+
+			newIDs[source.ID] = clone.ID
+			if source.ID == nodeID {
+				clonedTip = clone
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return clonedTip, len(sources), nil
+}
+
+// SplitNode splits a node into a chain of nodes holding the given parts in order. The node keeps its ID
+// and the first part; each later part becomes a child of the one before, with the node's type, model,
+// author and creation time, and the node's original children move below the last part. If the node was
+// the current working node, the last part becomes it. Returns the nodes of the chain, the node first.
+func (db *Database) SplitNode(nodeID string, parts []string) ([]*Node, error) {
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("splitting a node needs at least two parts")
+	}
+
+	node, err := db.GetNodeByID(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []*Node{node}
+	err = db.withTx(func(tx *sql.Tx) error {
+		first, err := db.prepareContent(parts[0])
+		if err != nil {
+			return err
+		}
+		stored, err := db.storeContent(tx, first)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE Node SET content = ?, content_hash = ?, compression = ? WHERE id = ?`,
+			stored.content, stored.hash, stored.compression, nodeID); err != nil {
+			return fmt.Errorf("failed to update node %s: %w", nodeID, err)
+		}
+		node.Content = first
+
+		encoded, err := json.Marshal(map[string]interface{}{"split_from": nodeID})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		metadata := string(encoded)
+
+		parentID := nodeID
+		for _, part := range parts[1:] {
+			piece := &Node{
+				ID:        uuid.New().String(),
+				Content:   part,
+				Type:      node.Type,
+				Parent:    &parentID,
+				Children:  "[]",
+				Model:     node.Model,
+				Metadata:  &metadata,
+				CreatedAt: node.CreatedAt,
+				Author:    node.Author,
+			}
+			if err := db.insertNode(tx, piece); err != nil {
+				return fmt.Errorf("failed to add split part: %w", err)
+			}
+			chain = append(chain, piece)
+			parentID = piece.ID
+		}
+
+		last := chain[len(chain)-1].ID
+		if _, err := tx.Exec(`UPDATE Node SET parent = ? WHERE parent = ? AND id != ?`, last, nodeID, chain[1].ID); err != nil {
+			return fmt.Errorf("failed to reattach children of node %s: %w", nodeID, err)
+		}
+		return moveCurrentNode(tx, nodeID, last)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return deletedCount, nil
+	return chain, nil
 }
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// DedupConfigKey records whether content shared by several nodes is stored once; see EnableDedup
+const DedupConfigKey = "storage.dedup"
+
+// DedupMinSize is the length in bytes from which content is stored in the shared Content table.
+// Shorter content takes less space inline than a reference to it would.
+const DedupMinSize = 256
+
+// resolvedContent is the SQL expression for a Node row's content, following its reference to the
+// shared Content table if it has one and decompressing it if it's compressed
+const resolvedContent = `(CASE WHEN Node.content_hash IS NULL THEN bonsai_content(Node.content, Node.compression)
+	ELSE (SELECT bonsai_content(content, compression) FROM Content WHERE hash = Node.content_hash) END)`
+
+// DedupStats describes the content stored once and shared by nodes
+type DedupStats struct {
+	Contents   int   // Distinct pieces of content in the shared table
+	References int   // Nodes referring to shared content
+	SavedBytes int64 // Bytes that copies of the shared content would take up inline
+}
+
+// ensureContentTable creates the table holding content shared by nodes, keyed by its SHA-256 hash
+func (db *Database) ensureContentTable() error {
+	createContentTable := `
+	CREATE TABLE IF NOT EXISTS Content (
+		hash TEXT PRIMARY KEY,
+		content TEXT NOT NULL,
+		compression TEXT
+	);`
+
+	if _, err := db.conn.Exec(createContentTable); err != nil {
+		return fmt.Errorf("failed to create Content table: %w", err)
+	}
+	if err := db.ensureColumn("Content", "compression", "TEXT"); err != nil {
+		return err
+	}
+	if _, err := db.conn.Exec(`CREATE INDEX IF NOT EXISTS idx_node_content_hash ON Node(content_hash)`); err != nil {
+		return fmt.Errorf("failed to create Node content hash index: %w", err)
+	}
+	return nil
+}
+
+// IsDeduplicated reports whether new content is stored once however many nodes share it
+func (db *Database) IsDeduplicated() bool {
+	return db.dedup
+}
+
+// EnableDedup stores the content of every node once, with nodes that share content referring to
+// the same copy, and does the same for new nodes from now on. Encrypted databases can't be
+// deduplicated, since matching hashes would reveal which nodes have the same content.
+func (db *Database) EnableDedup() error {
+	if db.IsEncrypted() {
+		return errors.New("encrypted databases can't be deduplicated")
+	}
+	if db.dedup {
+		return errors.New("the database is already deduplicated")
+	}
+	return db.setDedup(true)
+}
+
+// DisableDedup stores a copy of the content in every node again
+func (db *Database) DisableDedup() error {
+	if !db.dedup {
+		return errors.New("the database isn't deduplicated")
+	}
+	return db.setDedup(false)
+}
+
+// setDedup rewrites every node's content for the setting and records it, then reclaims the space
+func (db *Database) setDedup(enabled bool) error {
+	previous := db.dedup
+	db.dedup = enabled
+	err := db.rewriteNodes(db.aead, func(tx *sql.Tx) error {
+		value := "off"
+		if enabled {
+			value = "on"
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO Config (key, value) VALUES (?, ?)`, DedupConfigKey, value); err != nil {
+			return fmt.Errorf("failed to record %s: %w", DedupConfigKey, err)
+		}
+		return nil
+	})
+	if err != nil {
+		db.dedup = previous
+		return err
+	}
+	return db.Vacuum()
+}
+
+// GetDedupStats reports how much content is shared and the space that saves
+func (db *Database) GetDedupStats() (*DedupStats, error) {
+	stats := &DedupStats{}
+	var copies sql.NullInt64
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM Content),
+			(SELECT COUNT(*) FROM Node WHERE content_hash IS NOT NULL),
+			(SELECT SUM(LENGTH(CAST(Content.content AS BLOB)) * (uses - 1)) FROM Content
+				JOIN (SELECT content_hash, COUNT(*) AS uses FROM Node GROUP BY content_hash) AS refs
+				ON refs.content_hash = Content.hash)
+	`
+	if err := db.conn.QueryRow(query).Scan(&stats.Contents, &stats.References, &copies); err != nil {
+		return nil, fmt.Errorf("failed to read deduplication stats: %w", err)
+	}
+	stats.SavedBytes = copies.Int64
+	return stats, nil
+}
+
+// loadDedup reads whether new content is deduplicated
+func (db *Database) loadDedup() error {
+	value, err := db.GetConfigValue(DedupConfigKey)
+	if err != nil {
+		return err
+	}
+	db.dedup = value != nil && *value == "on"
+	return nil
+}
+
+// storedContent is how a node's content is written to its content, content_hash and compression columns
+type storedContent struct {
+	content     interface{} // The content, possibly encrypted or compressed, or "" if it's shared
+	hash        *string     // The shared copy's hash if the content is deduplicated
+	compression *string     // How the content, or its shared copy, is compressed if it is
+}
+
+// storeContent returns how a node's content is stored: encrypted if the database is, otherwise
+// compressed if it's large, and stored as a reference to a shared copy when deduplicating
+func (db *Database) storeContent(e execer, content string) (*storedContent, error) {
+	if db.aead != nil {
+		sealed, err := seal(db.aead, content)
+		return &storedContent{content: sealed}, err
+	}
+
+	value, compression, err := db.compressContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if !db.dedup || len(content) < DedupMinSize {
+		return &storedContent{content: value, compression: compression}, nil
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	// Replace a copy stored with different compression so the setting applies to shared content too
+	query := `
+		INSERT INTO Content (hash, content, compression) VALUES (?, ?, ?)
+		ON CONFLICT(hash) DO UPDATE SET content = excluded.content, compression = excluded.compression
+		WHERE compression IS NOT excluded.compression
+	`
+	if _, err := e.Exec(query, hash, value, compression); err != nil {
+		return nil, fmt.Errorf("failed to store shared content: %w", err)
+	}
+	return &storedContent{content: "", hash: &hash}, nil
+}
+
+// pruneContent removes shared content that no node refers to any more
+func (db *Database) pruneContent() error {
+	if _, err := db.conn.Exec(`DELETE FROM Content WHERE hash NOT IN (SELECT content_hash FROM Node WHERE content_hash IS NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to remove unused content: %w", err)
+	}
+	return nil
+}
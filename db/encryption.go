@@ -0,0 +1,330 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Ways an encryption key is derived, recorded in the database so it can be unlocked again
+const (
+	KDFPassphrase = "pbkdf2-sha256" // A passphrase stretched with PBKDF2-SHA256
+	KDFKeyFile    = "keyfile"       // The contents of a key file, hashed with the salt
+)
+
+// Environment variables an encrypted database is unlocked with
+const (
+	PassphraseEnv = "BONSAI_PASSPHRASE"
+	KeyFileEnv    = "BONSAI_KEY_FILE"
+)
+
+// Config keys describing how the database is encrypted
+const (
+	encryptionKDFKey     = "encryption.kdf"
+	encryptionSaltKey    = "encryption.salt"
+	encryptionCheckKey   = "encryption.check"
+	encryptionKeyFileKey = "encryption.key_file"
+)
+
+// encryptedPrefix marks a sealed value: base64 of the nonce followed by the AES-GCM ciphertext
+const encryptedPrefix = "bonsai-enc:v1:"
+
+// encryptionCheck is sealed with the key on encryption so a wrong key is noticed before it's used
+const encryptionCheck = "bonsai"
+
+// pbkdf2Iterations follows OWASP's recommendation for PBKDF2-HMAC-SHA256
+const pbkdf2Iterations = 600000
+
+var (
+	// ErrLocked is returned when opening an encrypted database without a passphrase or key file
+	ErrLocked = fmt.Errorf("the database is encrypted; set %s or %s to unlock it", PassphraseEnv, KeyFileEnv)
+	// ErrWrongKey is returned when the passphrase or key file doesn't unlock the database
+	ErrWrongKey = errors.New("the passphrase or key file doesn't unlock this database")
+)
+
+// IsEncrypted reports whether node content and metadata are encrypted in the database file
+func (db *Database) IsEncrypted() bool {
+	return db.aead != nil
+}
+
+// unlockEncryption prepares an encrypted database for use with the key given in the environment, or
+// the key file recorded when it was encrypted. Unencrypted databases are left alone.
+func (db *Database) unlockEncryption() error {
+	settings, err := db.GetConfigValues("encryption.")
+	if err != nil {
+		return err
+	}
+	check, ok := settings[encryptionCheckKey]
+	if !ok {
+		return nil
+	}
+	salt, err := base64.StdEncoding.DecodeString(settings[encryptionSaltKey])
+	if err != nil {
+		return fmt.Errorf("invalid encryption salt: %w", err)
+	}
+
+	var key []byte
+	switch kdf := settings[encryptionKDFKey]; kdf {
+	case KDFKeyFile:
+		path := os.Getenv(KeyFileEnv)
+		if path == "" {
+			path = settings[encryptionKeyFileKey]
+		}
+		if key, err = keyFromFile(path, salt); err != nil {
+			return err
+		}
+	case KDFPassphrase:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return ErrLocked
+		}
+		if key, err = keyFromPassphrase(passphrase, salt); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("the database is encrypted with %q, which this version of bai doesn't support", kdf)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	if opened, err := open(aead, check); err != nil || opened != encryptionCheck {
+		return ErrWrongKey
+	}
+	db.aead = aead
+	return nil
+}
+
+// EnableEncryption encrypts the content and metadata of every node, and of nodes added later, with
+// a key derived from secret: a passphrase for KDFPassphrase, or the path of a key file for KDFKeyFile.
+// The database is vacuumed afterwards so no plaintext is left in free pages or the write-ahead log.
+func (db *Database) EnableEncryption(kdf, secret string) error {
+	if db.IsEncrypted() {
+		return errors.New("the database is already encrypted")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	var key []byte
+	var err error
+	settings := map[string]string{
+		encryptionKDFKey:  kdf,
+		encryptionSaltKey: base64.StdEncoding.EncodeToString(salt),
+	}
+	switch kdf {
+	case KDFPassphrase:
+		if secret == "" {
+			return errors.New("the passphrase is empty")
+		}
+		key, err = keyFromPassphrase(secret, salt)
+	case KDFKeyFile:
+		var path string
+		if path, err = filepath.Abs(secret); err != nil {
+			return fmt.Errorf("failed to resolve key file path: %w", err)
+		}
+		settings[encryptionKeyFileKey] = path
+		key, err = keyFromFile(path, salt)
+	default:
+		return fmt.Errorf("unknown key derivation %q", kdf)
+	}
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	check, err := seal(aead, encryptionCheck)
+	if err != nil {
+		return err
+	}
+	settings[encryptionCheckKey] = check
+
+	err = db.rewriteNodes(aead, func(tx *sql.Tx) error {
+		for key, value := range settings {
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO Config (key, value) VALUES (?, ?)`, key, value); err != nil {
+				return fmt.Errorf("failed to record encryption settings: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return db.Vacuum()
+}
+
+// DisableEncryption decrypts every node and stops encrypting new ones
+func (db *Database) DisableEncryption() error {
+	if !db.IsEncrypted() {
+		return errors.New("the database isn't encrypted")
+	}
+
+	err := db.rewriteNodes(nil, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM Config WHERE key LIKE 'encryption.%'`); err != nil {
+			return fmt.Errorf("failed to remove encryption settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return db.Vacuum()
+}
+
+// rewriteNodes stores every node again sealed with aead, or in plaintext if aead is nil, running
+// finish in the same transaction. Content is compressed and deduplicated again if the database does so.
+func (db *Database) rewriteNodes(aead cipher.AEAD, finish func(tx *sql.Tx) error) error {
+	nodes, err := db.GetAllNodes()
+	if err != nil {
+		return err
+	}
+
+	previous := db.aead
+	db.aead = aead
+	err = db.withTx(func(tx *sql.Tx) error {
+		for _, node := range nodes {
+			content, metadata, err := db.sealNode(tx, node)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`UPDATE Node SET content = ?, content_hash = ?, compression = ?, metadata = ? WHERE id = ?`,
+				content.content, content.hash, content.compression, metadata, node.ID)
+			if err != nil {
+				return fmt.Errorf("failed to rewrite node %s: %w", node.ID, err)
+			}
+		}
+		// Cached responses are cheap to regenerate, so they're dropped rather than rewritten
+		if _, err := tx.Exec(`DELETE FROM ResponseCache`); err != nil {
+			return fmt.Errorf("failed to clear response cache: %w", err)
+		}
+		return finish(tx)
+	})
+	if err != nil {
+		db.aead = previous
+	}
+	return err
+}
+
+// sealNode returns the node's content and metadata as they're stored: encrypted if the database
+// is, otherwise with large content compressed and shared content stored once if the database does so
+func (db *Database) sealNode(e execer, node *Node) (*storedContent, *string, error) {
+	content, err := db.storeContent(e, node.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata, err := db.sealValue(node.Metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, metadata, nil
+}
+
+// sealValue encrypts an optional column value if the database is encrypted
+func (db *Database) sealValue(value *string) (*string, error) {
+	if db.aead == nil || value == nil {
+		return value, nil
+	}
+	sealed, err := seal(db.aead, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// openNode decrypts a node read from the database in place. Values stored before the database
+// was encrypted are left as they are.
+func (db *Database) openNode(node *Node) error {
+	if db.aead == nil {
+		return nil
+	}
+	content, err := open(db.aead, node.Content)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt node %s: %w", node.ID, err)
+	}
+	node.Content = content
+	if node.Metadata != nil {
+		metadata, err := open(db.aead, *node.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt metadata of node %s: %w", node.ID, err)
+		}
+		node.Metadata = &metadata
+	}
+	return nil
+}
+
+func seal(aead cipher.AEAD, plaintext string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func open(aead cipher.AEAD, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed ciphertext")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func keyFromPassphrase(passphrase string, salt []byte) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+func keyFromFile(path string, salt []byte) ([]byte, error) {
+	if path == "" {
+		return nil, ErrLocked
+	}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("key file %s not found; set %s if it moved", path, KeyFileEnv)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if len(contents) < 16 {
+		return nil, fmt.Errorf("key file %s is too short to be a key", path)
+	}
+	hash := sha256.New()
+	hash.Write(salt)
+	hash.Write(contents)
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NodeFilter narrows a listing of nodes; its zero value matches every node
+type NodeFilter struct {
+	Model string    // Glob the node's model must match, e.g. "claude*"; case-sensitive
+	Type  string    // "user" or "llm"
+	Since time.Time // Only nodes created at or after this time
+	Until time.Time // Only nodes created before this time
+}
+
+// IsZero reports whether the filter matches every node
+func (f NodeFilter) IsZero() bool {
+	return f.Model == "" && f.Type == "" && f.Since.IsZero() && f.Until.IsZero()
+}
+
+// conditions returns the filter as SQL conditions on Node to AND onto a WHERE clause, and their arguments
+func (f NodeFilter) conditions() (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	if f.Model != "" {
+		clauses = append(clauses, "model GLOB ?")
+		args = append(args, f.Model)
+	}
+	if f.Type != "" {
+		clauses = append(clauses, "type = ?")
+		args = append(args, f.Type)
+	}
+	if !f.Since.IsZero() {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, f.Since.Unix())
+	}
+	if !f.Until.IsZero() {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, f.Until.Unix())
+	}
+
+	var sql strings.Builder
+	for _, clause := range clauses {
+		sql.WriteString(" AND " + clause)
+	}
+	return sql.String(), args
+}
+
+// ensureFilterIndexes creates the indexes that let filtered listings of roots and children be
+// answered without scanning every node
+func (db *Database) ensureFilterIndexes() error {
+	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_node_parent_model ON Node(parent, model)`,
+		`CREATE INDEX IF NOT EXISTS idx_node_parent_created ON Node(parent, created_at)`,
+	}
+	for _, index := range indexes {
+		if _, err := db.conn.Exec(index); err != nil {
+			return fmt.Errorf("failed to create Node filter index: %w", err)
+		}
+	}
+	return nil
+}
+
+// Orders ListRootNodes and ListChildNodes can sort by
+const (
+	SortRecent  = "recent"  // Most recently active tree or subtree first
+	SortCreated = "created" // Newest node first
+	SortSize    = "size"    // Largest tree or subtree first
+)
+
+// ListOptions sorts and pages a listing of nodes
+type ListOptions struct {
+	Sort   string // One of SortRecent, SortCreated and SortSize; empty for SortRecent
+	Limit  int    // Most nodes to return; 0 for all of them
+	Offset int    // Nodes to skip before the first one returned
+}
+
+// listOrders maps each sort to its ORDER BY clause over Node and the subtree statistics
+var listOrders = map[string]string{
+	SortRecent:  `subtree.last_activity DESC, Node.id`,
+	SortCreated: `COALESCE(Node.created_at, 0) DESC, Node.id`,
+	SortSize:    `subtree.size DESC, subtree.last_activity DESC, Node.id`,
+}
+
+// ListRootNodes retrieves a sorted page of the root nodes matching the filter, along with how many
+// roots match in all
+func (db *Database) ListRootNodes(filter NodeFilter, opts ListOptions) ([]*Node, int, error) {
+	return db.listNodes(`parent IS NULL`, nil, filter, opts)
+}
+
+// ListChildNodes retrieves a sorted page of a node's direct children matching the filter, along with
+// how many children match in all
+func (db *Database) ListChildNodes(parentID string, filter NodeFilter, opts ListOptions) ([]*Node, int, error) {
+	return db.listNodes(`parent = ?`, []interface{}{parentID}, filter, opts)
+}
+
+// listNodes lists the nodes matching the condition and the filter, sorted by their own creation
+// time or by the size and activity of the subtrees below them, which are computed in the same query
+func (db *Database) listNodes(condition string, args []interface{}, filter NodeFilter, opts ListOptions) ([]*Node, int, error) {
+	sort := opts.Sort
+	if sort == "" {
+		sort = SortRecent
+	}
+	order, ok := listOrders[sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown sort %q", opts.Sort)
+	}
+
+	conditions, filterArgs := filter.conditions()
+	args = append(append([]interface{}{}, args...), filterArgs...)
+
+	var total int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM Node WHERE `+condition+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count nodes: %w", err)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	query := `
+		WITH RECURSIVE tree(top, id, created_at) AS (
+			SELECT id, id, created_at FROM Node WHERE ` + condition + conditions + `
+			UNION ALL
+			SELECT tree.top, Node.id, Node.created_at FROM Node JOIN tree ON Node.parent = tree.id
+		),
+		subtree AS (
+			SELECT top, COUNT(*) AS size, COALESCE(MAX(created_at), 0) AS last_activity FROM tree GROUP BY top
+		)
+		SELECT ` + nodeColumns + `
+		FROM Node JOIN subtree ON subtree.top = Node.id
+		ORDER BY ` + order + `
+		LIMIT ? OFFSET ?
+	`
+	nodes, err := db.queryNodes(query, append(args, limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return nodes, total, nil
+}
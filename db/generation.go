@@ -0,0 +1,141 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Settings for the parameters responses are generated with; the provider's defaults are used when unset
+const (
+	TemperatureConfigKey   = "generate.temperature"
+	MaxTokensConfigKey     = "generate.max_tokens"
+	SystemConfigKey        = "generate.system"
+	ModelConfigKey         = "generate.model"          // Model used when none is given, inherited or set for the tree
+	IncludeNotesConfigKey  = "generate.include_notes"  // Whether note nodes are sent to models: on or off (the default)
+	ProviderRulesConfigKey = "generate.provider_rules" // Providers serving models by name prefix, e.g. "llama=gateway"
+
+	TimeoutConfigKey        = "generate.timeout"         // Longest a whole request may take, e.g. "2m"
+	ConnectTimeoutConfigKey = "generate.connect_timeout" // Longest connecting to the provider may take
+)
+
+// GenerationMetadataKey is the metadata key recording the parameters an LLM response was generated
+// with and the provider's ID for the request
+const GenerationMetadataKey = "generation"
+
+// ExcludedMetadataKey is the metadata key marking a node that stays in its tree but is never sent to
+// models, such as one holding a secret or an irrelevant tangent
+const ExcludedMetadataKey = "excluded"
+
+// IsExcluded reports whether a node is marked to be left out of what's sent to models
+func IsExcluded(node *Node) bool {
+	excluded, _ := node.GetMetadata()[ExcludedMetadataKey].(bool)
+	return excluded
+}
+
+// GenerationSettings are the configured parameters for generating responses
+type GenerationSettings struct {
+	Temperature  *float64 // nil for the provider's default
+	MaxTokens    int      // 0 for the default
+	System       string   // System prompt, if any
+	IncludeNotes bool     // Whether note nodes are sent along with the rest of the conversation
+
+	Timeout        time.Duration // 0 for the caller's default
+	ConnectTimeout time.Duration // 0 for llm.DefaultConnectTimeout
+}
+
+// GetGenerationSettings reads the configured parameters for generating responses
+func (db *Database) GetGenerationSettings() (*GenerationSettings, error) {
+	settings := &GenerationSettings{}
+
+	temperature, err := db.GetConfigValue(TemperatureConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if temperature != nil && *temperature != "" {
+		value, err := strconv.ParseFloat(*temperature, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", TemperatureConfigKey, *temperature, err)
+		}
+		settings.Temperature = &value
+	}
+
+	maxTokens, err := db.GetConfigValue(MaxTokensConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if maxTokens != nil && *maxTokens != "" {
+		if settings.MaxTokens, err = strconv.Atoi(*maxTokens); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", MaxTokensConfigKey, *maxTokens, err)
+		}
+	}
+
+	system, err := db.GetConfigValue(SystemConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if system != nil {
+		settings.System = *system
+	}
+
+	includeNotes, err := db.GetConfigValue(IncludeNotesConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	settings.IncludeNotes = includeNotes != nil && *includeNotes == "on"
+
+	if settings.Timeout, err = db.durationConfigValue(TimeoutConfigKey); err != nil {
+		return nil, err
+	}
+	if settings.ConnectTimeout, err = db.durationConfigValue(ConnectTimeoutConfigKey); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// ModelHistory returns the nodes of a conversation that are sent to models: all of them, less those
+// marked excluded and any notes unless IncludeNotes is set
+func (s *GenerationSettings) ModelHistory(history []*Node) []*Node {
+	kept := make([]*Node, 0, len(history))
+	for _, node := range history {
+		if IsExcluded(node) || (node.Type == "note" && !s.IncludeNotes) {
+			continue
+		}
+		kept = append(kept, node)
+	}
+	return kept
+}
+
+// DefaultModel returns the model used when none is given, inherited or set for the tree, or an empty
+// string if none is configured
+func (db *Database) DefaultModel() (string, error) {
+	model, err := db.GetConfigValue(ModelConfigKey)
+	if err != nil || model == nil {
+		return "", err
+	}
+	return *model, nil
+}
+
+// ProviderRules returns the configured rules routing models to providers by name prefix, as
+// "prefix=provider" pairs for llm.ParseProviderRules, or an empty string if none are configured
+func (db *Database) ProviderRules() (string, error) {
+	rules, err := db.GetConfigValue(ProviderRulesConfigKey)
+	if err != nil || rules == nil {
+		return "", err
+	}
+	return *rules, nil
+}
+
+// durationConfigValue reads a setting holding a duration such as "90s", or 0 if it isn't set
+func (db *Database) durationConfigValue(key string) (time.Duration, error) {
+	value, err := db.GetConfigValue(key)
+	if err != nil || value == nil || *value == "" {
+		return 0, err
+	}
+	duration, err := time.ParseDuration(*value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, *value, err)
+	}
+	return duration, nil
+}
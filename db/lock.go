@@ -0,0 +1,43 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout is how long Lock waits for another bai process to release the advisory lock
+const lockTimeout = 30 * time.Second
+
+// Lock takes an exclusive advisory lock shared by every bai process using this database file,
+// waiting up to lockTimeout for the current holder. Single statements and transactions are already
+// safe on their own; the lock is for multi-step operations such as gc or schema migrations that must
+// not interleave with each other. Call the returned function to release it.
+func (db *Database) Lock() (func(), error) {
+	file, err := os.OpenFile(db.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock database: %w", err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for another bai process to release %s", file.Name())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
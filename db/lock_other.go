@@ -0,0 +1,13 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package db
+
+import "os"
+
+// tryLockFile always succeeds on platforms without file locking; SQLite's own locking still applies
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile is a no-op on platforms without file locking
+func unlockFile(f *os.File) {}
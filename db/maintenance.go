@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Stats summarizes the state of the database for health checks
+type Stats struct {
+	SchemaVersion int   `json:"schema_version"`
+	NodeCount     int   `json:"node_count"`
+	TreeCount     int   `json:"tree_count"`
+	FileSize      int64 `json:"file_size"`  // Bytes, including the write-ahead log
+	LastWrite     int64 `json:"last_write"` // Unix seconds the database files were last modified
+}
+
+// TreeActivity summarizes the size, shape and recency of a single conversation tree
+type TreeActivity struct {
+	RootID       string `json:"root_id"`
+	NodeCount    int    `json:"node_count"`
+	MaxDepth     int    `json:"max_depth"`     // Edges from the root to the deepest node
+	Branches     int    `json:"branches"`      // Leaves, i.e. distinct conversations from the root
+	LastActivity int64  `json:"last_activity"` // Unix seconds of the newest node, 0 if no node has a timestamp
+}
+
+// GetTreeActivity returns the size, shape and last activity of every tree, computed in a single query
+func (db *Database) GetTreeActivity() ([]*TreeActivity, error) {
+	return db.queryTreeActivity(`parent IS NULL`)
+}
+
+// GetTreeActivityForRoots returns the size, shape and last activity of the trees under the given root nodes, keyed by root ID
+func (db *Database) GetTreeActivityForRoots(rootIDs []string) (map[string]*TreeActivity, error) {
+	byRoot := make(map[string]*TreeActivity, len(rootIDs))
+	if len(rootIDs) == 0 {
+		return byRoot, nil
+	}
+
+	placeholders := strings.Repeat("?, ", len(rootIDs)-1) + "?"
+	args := make([]interface{}, len(rootIDs))
+	for i, id := range rootIDs {
+		args[i] = id
+	}
+
+	trees, err := db.queryTreeActivity(`id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		byRoot[tree.RootID] = tree
+	}
+	return byRoot, nil
+}
+
+// queryTreeActivity computes the size, shape and last activity of the trees under the nodes matching the WHERE condition
+func (db *Database) queryTreeActivity(rootCondition string, args ...interface{}) ([]*TreeActivity, error) {
+	query := `
+		WITH RECURSIVE tree(root, id, depth, created_at) AS (
+			SELECT id, id, 0, created_at FROM Node WHERE ` + rootCondition + `
+			UNION ALL
+			SELECT tree.root, Node.id, tree.depth + 1, Node.created_at FROM Node JOIN tree ON Node.parent = tree.id
+		)
+		SELECT root, COUNT(*), MAX(depth),
+			SUM(NOT EXISTS (SELECT 1 FROM Node AS child WHERE child.parent = tree.id)),
+			COALESCE(MAX(created_at), 0)
+		FROM tree
+		GROUP BY root
+		ORDER BY root
+	`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tree activity: %w", err)
+	}
+	defer rows.Close()
+
+	var trees []*TreeActivity
+	for rows.Next() {
+		tree := &TreeActivity{}
+		if err := rows.Scan(&tree.RootID, &tree.NodeCount, &tree.MaxDepth, &tree.Branches, &tree.LastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan tree activity: %w", err)
+		}
+		trees = append(trees, tree)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over tree activity rows: %w", err)
+	}
+
+	return trees, nil
+}
+
+// DeleteOrphanedNodes deletes nodes whose parent no longer exists, along with all their descendants
+func (db *Database) DeleteOrphanedNodes() (int, error) {
+	query := `
+		WITH RECURSIVE orphan(id) AS (
+			SELECT id FROM Node
+			WHERE parent IS NOT NULL AND parent NOT IN (SELECT id FROM Node)
+			UNION
+			SELECT Node.id FROM Node JOIN orphan ON Node.parent = orphan.id
+		)
+		DELETE FROM Node WHERE id IN (SELECT id FROM orphan)
+	`
+
+	result, err := db.conn.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete orphaned nodes: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+// Vacuum rebuilds the database file, reclaiming the space freed by deletions
+func (db *Database) Vacuum() error {
+	if err := db.pruneContent(); err != nil {
+		return err
+	}
+	if _, err := db.conn.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	// In WAL mode the rebuilt pages land in the write-ahead log; copy them back so the file actually shrinks
+	if _, err := db.conn.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+
+	// VACUUM may renumber node rowids, which the search index refers to
+	return db.rebuildSearchIndex()
+}
+
+// GetFileSize returns the size of the database file in bytes
+func (db *Database) GetFileSize() (int64, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat database file: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// GetStats checks that the database can be queried and returns its schema version, size and last write time
+func (db *Database) GetStats(ctx context.Context) (*Stats, error) {
+	stats := &Stats{}
+	if err := db.conn.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&stats.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	err := db.conn.QueryRowContext(ctx, `SELECT COUNT(*), COUNT(*) FILTER (WHERE parent IS NULL) FROM Node`).Scan(&stats.NodeCount, &stats.TreeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count nodes: %w", err)
+	}
+
+	// Recent writes may still be in the write-ahead log rather than the main file
+	for _, path := range []string{db.path, db.path + "-wal"} {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat database file: %w", err)
+		}
+		stats.FileSize += info.Size()
+		if modified := info.ModTime().Unix(); modified > stats.LastWrite {
+			stats.LastWrite = modified
+		}
+	}
+
+	return stats, nil
+}
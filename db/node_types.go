@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NodeTypes lists the types a node can have: a user message, an LLM response, a note of your own
+// that isn't sent to models unless generate.include_notes is on, or a system prompt sent to models
+// as a system message
+var NodeTypes = []string{"user", "llm", "note", "system"}
+
+// IsNodeType reports whether t is one of NodeTypes
+func IsNodeType(t string) bool {
+	for _, nodeType := range NodeTypes {
+		if t == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeTypeError reports a type that isn't one of NodeTypes
+func nodeTypeError(t string) error {
+	return fmt.Errorf("invalid node type: %s (must be one of %s)", t, strings.Join(NodeTypes, ", "))
+}
+
+// nodeTypeCheck returns the CHECK constraint limiting Node.type to NodeTypes
+func nodeTypeCheck() string {
+	quoted := make([]string, len(NodeTypes))
+	for i, nodeType := range NodeTypes {
+		quoted[i] = "'" + nodeType + "'"
+	}
+	return "CHECK (type IN (" + strings.Join(quoted, ", ") + "))"
+}
+
+// nodeTypeCheckPattern matches the CHECK constraint on Node.type as any version of the schema wrote it
+var nodeTypeCheckPattern = regexp.MustCompile(`CHECK \(type IN \([^)]*\)\)`)
+
+// ensureNodeTypes widens the CHECK constraint on Node.type in databases created before some of
+// NodeTypes existed. SQLite can't alter a constraint, but one that only allows more values can be
+// rewritten in the stored schema without touching any rows.
+func (db *Database) ensureNodeTypes() error {
+	var definition string
+	if err := db.conn.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'Node'`).Scan(&definition); err != nil {
+		return fmt.Errorf("failed to read Node table definition: %w", err)
+	}
+	check := nodeTypeCheck()
+	if strings.Contains(definition, check) || !nodeTypeCheckPattern.MatchString(definition) {
+		return nil
+	}
+	definition = nodeTypeCheckPattern.ReplaceAllLiteralString(definition, check)
+
+	return db.withTx(func(tx *sql.Tx) error {
+		var version int
+		if err := tx.QueryRow(`PRAGMA schema_version`).Scan(&version); err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if _, err := tx.Exec(`PRAGMA writable_schema = ON`); err != nil {
+			return fmt.Errorf("failed to allow new node types: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE sqlite_master SET sql = ? WHERE type = 'table' AND name = 'Node'`, definition); err != nil {
+			return fmt.Errorf("failed to allow new node types: %w", err)
+		}
+		// Bumping the schema version makes every connection reload the schema
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA schema_version = %d`, version+1)); err != nil {
+			return fmt.Errorf("failed to allow new node types: %w", err)
+		}
+		if _, err := tx.Exec(`PRAGMA writable_schema = OFF`); err != nil {
+			return fmt.Errorf("failed to allow new node types: %w", err)
+		}
+		return nil
+	})
+}
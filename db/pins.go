@@ -0,0 +1,101 @@
+package db
+
+import "fmt"
+
+// PinnedMetadataKey is the metadata key of a root node listing the IDs of the nodes pinned to its
+// tree, in the order they were pinned
+const PinnedMetadataKey = "pinned"
+
+// pinnedIDs reads the IDs of the nodes pinned to a root node's tree
+func pinnedIDs(root *Node) []string {
+	values, _ := root.GetMetadata()[PinnedMetadataKey].([]interface{})
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GetPinnedIDs returns the IDs of the nodes pinned to the tree holding the given node, in the order
+// they were pinned, and the ID of the tree's root. Pinned nodes may since have been deleted.
+func (db *Database) GetPinnedIDs(nodeID string) (string, []string, error) {
+	rootID, err := db.GetRootID(nodeID)
+	if err != nil {
+		return "", nil, err
+	}
+	root, err := db.GetNodeByID(rootID)
+	if err != nil {
+		return "", nil, err
+	}
+	return rootID, pinnedIDs(root), nil
+}
+
+// PinNode pins a node, from any tree, to the tree with the given root, returning false if it
+// already was
+func (db *Database) PinNode(rootID, nodeID string) (bool, error) {
+	if _, err := db.GetNodeByID(nodeID); err != nil {
+		return false, err
+	}
+	added := false
+	err := db.updateRootMetadata(rootID, func(root *Node, metadata map[string]interface{}) {
+		ids := pinnedIDs(root)
+		for _, id := range ids {
+			if id == nodeID {
+				return
+			}
+		}
+		metadata[PinnedMetadataKey] = append(ids, nodeID)
+		added = true
+	})
+	return added, err
+}
+
+// UnpinNode unpins a node from the tree with the given root, returning false if it wasn't pinned
+func (db *Database) UnpinNode(rootID, nodeID string) (bool, error) {
+	removed := false
+	err := db.updateRootMetadata(rootID, func(root *Node, metadata map[string]interface{}) {
+		var kept []string
+		for _, id := range pinnedIDs(root) {
+			if id == nodeID {
+				removed = true
+			} else {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == 0 {
+			delete(metadata, PinnedMetadataKey)
+		} else {
+			metadata[PinnedMetadataKey] = kept
+		}
+	})
+	return removed, err
+}
+
+// GetPinnedContext returns the content of the nodes pinned to the tree holding the given node that
+// should be sent ahead of the given history: those not already in it, not excluded and not since
+// deleted, in the order they were pinned
+func (db *Database) GetPinnedContext(nodeID string, history []*Node) ([]string, error) {
+	_, ids, err := db.GetPinnedIDs(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pinned nodes: %w", err)
+	}
+
+	sent := make(map[string]bool, len(history))
+	for _, node := range history {
+		sent[node.ID] = true
+	}
+	var contents []string
+	for _, id := range ids {
+		if sent[id] {
+			continue
+		}
+		node, err := db.GetNodeByID(id)
+		if err != nil || IsExcluded(node) {
+			continue
+		}
+		contents = append(contents, node.Content)
+	}
+	return contents, nil
+}
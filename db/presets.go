@@ -0,0 +1,79 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// presetKeyPrefix prefixes the Config keys holding seed presets
+const presetKeyPrefix = "preset:"
+
+// Preset describes how a kind of tree is started with 'bai seed --preset': the first message and
+// the settings the tree is given. Its system prompt, temperature and max_tokens are stored as the
+// new tree's settings. Empty fields leave the usual defaults in place.
+type Preset struct {
+	Profile
+	Message string `json:"message,omitempty"` // Scaffold of the first message, with {{variables}} such as {{input}}
+}
+
+// SavePreset stores a seed preset under the given name, replacing any existing one
+func (db *Database) SavePreset(name string, preset *Preset) error {
+	encoded, err := json.Marshal(preset)
+	if err != nil {
+		return fmt.Errorf("failed to encode preset %s: %w", name, err)
+	}
+	if err := db.SetConfigValue(presetKeyPrefix+name, string(encoded)); err != nil {
+		return fmt.Errorf("failed to save preset %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetPreset retrieves a seed preset by name, returning nil if it doesn't exist
+func (db *Database) GetPreset(name string) (*Preset, error) {
+	value, err := db.GetConfigValue(presetKeyPrefix + name)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return decodePreset(name, *value)
+}
+
+// GetPresets retrieves all seed presets, keyed by name
+func (db *Database) GetPresets() (map[string]*Preset, error) {
+	values, err := db.GetConfigValues(presetKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make(map[string]*Preset, len(values))
+	for key, value := range values {
+		name := strings.TrimPrefix(key, presetKeyPrefix)
+		if presets[name], err = decodePreset(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return presets, nil
+}
+
+// DeletePreset removes a seed preset, returning false if it didn't exist
+func (db *Database) DeletePreset(name string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Config WHERE key = ?`, presetKeyPrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete preset %s: %w", name, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for preset %s: %w", name, err)
+	}
+	return deleted > 0, nil
+}
+
+// decodePreset reads a seed preset stored as JSON
+func decodePreset(name, value string) (*Preset, error) {
+	var preset Preset
+	if err := json.Unmarshal([]byte(value), &preset); err != nil {
+		return nil, fmt.Errorf("invalid preset %s: %w", name, err)
+	}
+	return &preset, nil
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// profileKeyPrefix prefixes the Config keys holding execution profiles
+const profileKeyPrefix = "profile:"
+
+// Profile is a named set of generation settings a command can run with, chosen with --profile.
+// Empty fields leave the usual settings in place.
+type Profile struct {
+	Model       string   `json:"model,omitempty"`       // Model that responds when no --llm is given
+	Temperature *float64 `json:"temperature,omitempty"` // Overrides generate.temperature and the tree's temperature
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Overrides generate.max_tokens and the tree's max_tokens
+	System      string   `json:"system,omitempty"`      // Overrides generate.system and the tree's system prompt
+}
+
+// Override returns a copy of the given generation settings with the ones the profile sets replaced
+func (p *Profile) Override(settings *GenerationSettings) *GenerationSettings {
+	overridden := *settings
+	if p.Temperature != nil {
+		overridden.Temperature = p.Temperature
+	}
+	if p.MaxTokens > 0 {
+		overridden.MaxTokens = p.MaxTokens
+	}
+	if p.System != "" {
+		overridden.System = p.System
+	}
+	return &overridden
+}
+
+// SaveProfile stores an execution profile under the given name, replacing any existing one
+func (db *Database) SaveProfile(name string, profile *Profile) error {
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile %s: %w", name, err)
+	}
+	if err := db.SetConfigValue(profileKeyPrefix+name, string(encoded)); err != nil {
+		return fmt.Errorf("failed to save profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetProfile retrieves an execution profile by name, returning nil if it doesn't exist
+func (db *Database) GetProfile(name string) (*Profile, error) {
+	value, err := db.GetConfigValue(profileKeyPrefix + name)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	var profile Profile
+	if err := json.Unmarshal([]byte(*value), &profile); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %w", name, err)
+	}
+	return &profile, nil
+}
+
+// GetProfiles retrieves all execution profiles, keyed by name
+func (db *Database) GetProfiles() (map[string]*Profile, error) {
+	values, err := db.GetConfigValues(profileKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*Profile, len(values))
+	for key, value := range values {
+		name := strings.TrimPrefix(key, profileKeyPrefix)
+		var profile Profile
+		if err := json.Unmarshal([]byte(value), &profile); err != nil {
+			return nil, fmt.Errorf("invalid profile %s: %w", name, err)
+		}
+		profiles[name] = &profile
+	}
+	return profiles, nil
+}
+
+// DeleteProfile removes an execution profile, returning false if it didn't exist
+func (db *Database) DeleteProfile(name string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Config WHERE key = ?`, profileKeyPrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete profile %s: %w", name, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for profile %s: %w", name, err)
+	}
+	return deleted > 0, nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/redact"
+)
+
+// Settings controlling when secrets are redacted
+const (
+	RedactOnCreateConfigKey = "redact.on_create" // on (the default) or off
+	RedactOnExportConfigKey = "redact.on_export" // on (the default) or off
+	RedactDisabledConfigKey = "redact.disabled"  // Comma-separated built-in rules not to apply
+)
+
+// redactionRuleKeyPrefix prefixes the Config keys holding custom redaction rules
+const redactionRuleKeyPrefix = "redact:"
+
+// SaveRedactionRule stores a custom redaction rule matching a regular expression, replacing any
+// existing rule with the same name
+func (db *Database) SaveRedactionRule(name, pattern string) error {
+	if redact.IsBuiltin(name) {
+		return fmt.Errorf("%s is a built-in rule; add it to %s to turn it off", name, RedactDisabledConfigKey)
+	}
+	if _, err := redact.NewRule(name, pattern); err != nil {
+		return err
+	}
+	if err := db.SetConfigValue(redactionRuleKeyPrefix+name, pattern); err != nil {
+		return fmt.Errorf("failed to save redaction rule %s: %w", name, err)
+	}
+	return db.loadRedaction()
+}
+
+// GetRedactionRules retrieves the custom redaction rules' patterns, keyed by name
+func (db *Database) GetRedactionRules() (map[string]string, error) {
+	values, err := db.GetConfigValues(redactionRuleKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(map[string]string, len(values))
+	for key, pattern := range values {
+		rules[strings.TrimPrefix(key, redactionRuleKeyPrefix)] = pattern
+	}
+	return rules, nil
+}
+
+// DeleteRedactionRule removes a custom redaction rule, returning false if it didn't exist
+func (db *Database) DeleteRedactionRule(name string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Config WHERE key = ?`, redactionRuleKeyPrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete redaction rule %s: %w", name, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for redaction rule %s: %w", name, err)
+	}
+	return deleted > 0, db.loadRedaction()
+}
+
+// Redactor returns a redactor applying the enabled built-in rules followed by the custom rules,
+// in order of name
+func (db *Database) Redactor() (*redact.Redactor, error) {
+	disabled := make(map[string]bool)
+	if value, err := db.GetConfigValue(RedactDisabledConfigKey); err != nil {
+		return nil, err
+	} else if value != nil {
+		for _, name := range strings.Split(*value, ",") {
+			disabled[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var rules []redact.Rule
+	for _, rule := range redact.Builtin() {
+		if !disabled[rule.Name] {
+			rules = append(rules, rule)
+		}
+	}
+
+	custom, err := db.GetRedactionRules()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule, err := redact.NewRule(name, custom[name])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return redact.New(rules), nil
+}
+
+// ExportRedactor returns the redactor to scrub nodes leaving the database with, or nil if
+// redaction on export is turned off
+func (db *Database) ExportRedactor() (*redact.Redactor, error) {
+	if enabled, err := db.redactionEnabled(RedactOnExportConfigKey); err != nil || !enabled {
+		return nil, err
+	}
+	return db.Redactor()
+}
+
+// loadRedaction prepares the redactor applied to the content of new nodes, unless redaction on
+// creation is turned off
+func (db *Database) loadRedaction() error {
+	db.redactor = nil
+	if enabled, err := db.redactionEnabled(RedactOnCreateConfigKey); err != nil || !enabled {
+		return err
+	}
+
+	redactor, err := db.Redactor()
+	if err != nil {
+		return err
+	}
+	db.redactor = redactor
+	return nil
+}
+
+func (db *Database) redactionEnabled(key string) (bool, error) {
+	value, err := db.GetConfigValue(key)
+	if err != nil {
+		return false, err
+	}
+	return value == nil || *value != "off", nil
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schedule is a prompt added to a tree on a cron schedule by the scheduler 'bai serve' runs. Like
+// templates, schedules are stored in plaintext even in encrypted databases.
+type Schedule struct {
+	ID        string            `json:"id"`
+	Spec      string            `json:"spec"`               // Cron expression, e.g. "0 9 * * 1"
+	NodeID    string            `json:"node_id"`            // Each run adds the prompt as a new branch below this node
+	Template  string            `json:"template,omitempty"` // Name of the template the prompt is rendered from, if any
+	Vars      map[string]string `json:"vars,omitempty"`     // Values of the template's variables
+	Prompt    string            `json:"prompt,omitempty"`   // The prompt itself, when there's no template
+	Model     string            `json:"model,omitempty"`    // Model answering the prompt; empty inherits one
+	CreatedAt int64             `json:"created_at"`
+	LastRun   int64             `json:"last_run,omitempty"`   // Unix seconds of the latest run, 0 if it hasn't run
+	LastNode  string            `json:"last_node,omitempty"`  // The prompt node the latest run added
+	LastError string            `json:"last_error,omitempty"` // Why the latest run failed, empty if it didn't
+}
+
+// scheduleColumns lists the Schedule columns read by every schedule query, in the order expected by scanSchedule
+const scheduleColumns = `id, spec, node_id, template, vars, prompt, model, created_at, COALESCE(last_run, 0), COALESCE(last_node, ''), COALESCE(last_error, '')`
+
+// ensureSchedules creates the table of scheduled prompts
+func (db *Database) ensureSchedules() error {
+	createScheduleTable := `
+	CREATE TABLE IF NOT EXISTS Schedule (
+		id TEXT PRIMARY KEY,
+		spec TEXT NOT NULL,
+		node_id TEXT NOT NULL,
+		template TEXT NOT NULL DEFAULT '',
+		vars TEXT NOT NULL DEFAULT '{}',
+		prompt TEXT NOT NULL DEFAULT '',
+		model TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		last_run INTEGER,
+		last_node TEXT,
+		last_error TEXT
+	);`
+
+	if _, err := db.conn.Exec(createScheduleTable); err != nil {
+		return fmt.Errorf("failed to create Schedule table: %w", err)
+	}
+	return nil
+}
+
+// scanSchedule scans a row selected with scheduleColumns into a Schedule
+func scanSchedule(scanner rowScanner) (*Schedule, error) {
+	schedule := &Schedule{}
+	var vars string
+	err := scanner.Scan(&schedule.ID, &schedule.Spec, &schedule.NodeID, &schedule.Template, &vars, &schedule.Prompt,
+		&schedule.Model, &schedule.CreatedAt, &schedule.LastRun, &schedule.LastNode, &schedule.LastError)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(vars), &schedule.Vars); err != nil {
+		return nil, fmt.Errorf("failed to parse variables of schedule %s: %w", schedule.ID, err)
+	}
+	return schedule, nil
+}
+
+// AddSchedule stores a new schedule, filling in its ID and creation time
+func (db *Database) AddSchedule(schedule *Schedule) error {
+	vars, err := json.Marshal(schedule.Vars)
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule variables: %w", err)
+	}
+	if schedule.Vars == nil {
+		vars = []byte("{}")
+	}
+
+	schedule.ID = uuid.New().String()
+	schedule.CreatedAt = time.Now().Unix()
+	_, err = db.conn.Exec(`INSERT INTO Schedule (id, spec, node_id, template, vars, prompt, model, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		schedule.ID, schedule.Spec, schedule.NodeID, schedule.Template, string(vars), schedule.Prompt, schedule.Model, schedule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add schedule: %w", err)
+	}
+	return nil
+}
+
+// GetSchedules retrieves every schedule, oldest first
+func (db *Database) GetSchedules() ([]*Schedule, error) {
+	rows, err := db.conn.Query(`SELECT ` + scheduleColumns + ` FROM Schedule ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// GetSchedule retrieves a schedule by its full ID or a unique prefix of it
+func (db *Database) GetSchedule(idOrPrefix string) (*Schedule, error) {
+	if idOrPrefix == "" {
+		return nil, fmt.Errorf("schedule ID must not be empty")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+scheduleColumns+` FROM Schedule WHERE substr(id, 1, length(?)) = ? LIMIT 2`, idOrPrefix, idOrPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up schedule %s: %w", idOrPrefix, err)
+	}
+	defer rows.Close()
+
+	var matches []*Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		matches = append(matches, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over schedules: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("schedule with ID %s not found", idOrPrefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("schedule ID %s is ambiguous; use more characters", idOrPrefix)
+	}
+}
+
+// DeleteSchedule removes a schedule, returning false if it didn't exist
+func (db *Database) DeleteSchedule(id string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Schedule WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete schedule %s: %w", id, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for schedule %s: %w", id, err)
+	}
+	return deleted > 0, nil
+}
+
+// ClaimScheduleRun marks a schedule as run at the given time if it hasn't run since then, reporting
+// whether it was claimed. Several servers sharing a database each try to claim a due run, and only
+// the one that succeeds runs it.
+func (db *Database) ClaimScheduleRun(id string, at time.Time) (bool, error) {
+	result, err := db.conn.Exec(`UPDATE Schedule SET last_run = ? WHERE id = ? AND COALESCE(last_run, 0) < ?`, at.Unix(), id, at.Unix())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim run of schedule %s: %w", id, err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for schedule %s: %w", id, err)
+	}
+	return claimed > 0, nil
+}
+
+// RecordScheduleRun stores the outcome of a schedule's latest run: the prompt node it added, if
+// any, and the error that stopped it, if any
+func (db *Database) RecordScheduleRun(id, nodeID string, runErr error) error {
+	var lastError sql.NullString
+	if runErr != nil {
+		lastError = sql.NullString{String: runErr.Error(), Valid: true}
+	}
+	var lastNode sql.NullString
+	if nodeID != "" {
+		lastNode = sql.NullString{String: nodeID, Valid: true}
+	}
+
+	if _, err := db.conn.Exec(`UPDATE Schedule SET last_node = ?, last_error = ? WHERE id = ?`, lastNode, lastError, id); err != nil {
+		return fmt.Errorf("failed to record run of schedule %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Markers placed around matched terms in search snippets; callers replace them with their own highlighting
+const (
+	SnippetMatchStart = "\x02"
+	SnippetMatchEnd   = "\x03"
+)
+
+// SearchResult is a node matching a search query, with an excerpt around the matches
+type SearchResult struct {
+	Node    *Node
+	Snippet string // Matched terms are wrapped in SnippetMatchStart and SnippetMatchEnd
+}
+
+// ensureSearchIndex creates the full-text search index over node content and the triggers that keep
+// it in sync, indexing existing nodes the first time it's created
+//
+// The triggers are plain SQL, so other SQLite tools can still write to the database: they queue new
+// and edited nodes in NodeSearchPending, which indexPendingNodes indexes before each search. Only Go
+// can read compressed content, so the index is contentless and snippets are made in Go.
+func (db *Database) ensureSearchIndex() error {
+	var definition sql.NullString
+	err := db.conn.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'NodeSearch'`).Scan(&definition)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check for search index: %w", err)
+	}
+
+	var statements []string
+	if definition.Valid && !strings.Contains(definition.String, "contentless_delete") {
+		// Older indexes read node text through triggers calling bonsai_content, which only bai can run
+		statements = append(statements,
+			`DROP TRIGGER IF EXISTS node_search_insert`,
+			`DROP TRIGGER IF EXISTS node_search_delete`,
+			`DROP TRIGGER IF EXISTS node_search_update`,
+			`DROP VIEW IF EXISTS NodeText`,
+			`DROP TABLE NodeSearch`,
+		)
+		definition.Valid = false
+	}
+
+	statements = append(statements,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS NodeSearch USING fts5(
+			content, content='', contentless_delete=1, tokenize='porter unicode61'
+		)`,
+		// No unique key, since an upsert's conflict handling would apply to the triggers' inserts too
+		`CREATE TABLE IF NOT EXISTS NodeSearchPending (node_rowid INTEGER NOT NULL)`,
+		`CREATE TRIGGER IF NOT EXISTS node_search_insert AFTER INSERT ON Node BEGIN
+			INSERT INTO NodeSearchPending(node_rowid) VALUES (new.rowid);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS node_search_delete AFTER DELETE ON Node BEGIN
+			DELETE FROM NodeSearch WHERE rowid = old.rowid;
+			DELETE FROM NodeSearchPending WHERE node_rowid = old.rowid;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS node_search_update AFTER UPDATE OF content, content_hash, compression ON Node BEGIN
+			DELETE FROM NodeSearch WHERE rowid = old.rowid;
+			INSERT INTO NodeSearchPending(node_rowid) VALUES (new.rowid);
+		END`,
+	)
+	// Replace everything at once so no node written meanwhile misses the index
+	err = db.withTx(func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("failed to create search index: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !definition.Valid {
+		return db.rebuildSearchIndex()
+	}
+	return nil
+}
+
+// indexPendingNodes indexes the nodes queued by the search triggers since the last search
+func (db *Database) indexPendingNodes() error {
+	return db.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO NodeSearch(rowid, content)
+			SELECT Node.rowid, ` + resolvedContent + ` FROM Node
+			WHERE Node.rowid IN (SELECT node_rowid FROM NodeSearchPending)`)
+		if err != nil {
+			return fmt.Errorf("failed to index new nodes: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM NodeSearchPending`); err != nil {
+			return fmt.Errorf("failed to index new nodes: %w", err)
+		}
+		return nil
+	})
+}
+
+// rebuildSearchIndex reindexes every node. The index refers to nodes by rowid, so this is needed
+// whenever rowids may have changed, such as after a VACUUM.
+func (db *Database) rebuildSearchIndex() error {
+	return db.withTx(func(tx *sql.Tx) error {
+		statements := []string{
+			`DELETE FROM NodeSearchPending`,
+			`INSERT INTO NodeSearch(NodeSearch) VALUES ('delete-all')`,
+			`INSERT INTO NodeSearch(rowid, content) SELECT Node.rowid, ` + resolvedContent + ` FROM Node`,
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("failed to rebuild search index: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SearchNodes finds up to limit nodes containing every word of the query (matching word prefixes
+// and stems), best matches first
+func (db *Database) SearchNodes(query string, limit int) ([]*SearchResult, error) {
+	match := searchMatchExpression(query)
+	if match == "" {
+		return nil, fmt.Errorf("search query must contain at least one word")
+	}
+
+	// The index only holds ciphertext when the database is encrypted
+	if db.IsEncrypted() {
+		return db.searchDecrypted(query, limit)
+	}
+
+	if err := db.indexPendingNodes(); err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT ` + nodeColumns + `
+		FROM (
+			SELECT rowid AS match_rowid, rank
+			FROM NodeSearch
+			WHERE NodeSearch MATCH ?
+			ORDER BY rank
+			LIMIT ?
+		) AS matches
+		JOIN Node ON Node.rowid = matches.match_rowid
+		ORDER BY matches.rank
+	`
+
+	terms := strings.Fields(strings.ToLower(query))
+	rows, err := db.conn.Query(sqlQuery, match, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		node := &Node{}
+		result := &SearchResult{Node: node}
+		err := rows.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model, &node.Metadata, &node.CreatedAt, &node.VisitedAt, &node.Author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.Snippet = searchSnippet(contentWords(node.Content), terms)
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// searchMatchExpression turns free text into an FTS5 query matching every word as a prefix,
+// quoting each word so punctuation in the input can't be parsed as query syntax
+func searchMatchExpression(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchDecrypted searches an encrypted database by decrypting every node, matching the words of
+// the query as prefixes of words in the content, most matches first
+func (db *Database) searchDecrypted(query string, limit int) ([]*SearchResult, error) {
+	nodes, err := db.GetAllNodes()
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query))
+
+	type match struct {
+		result *SearchResult
+		hits   int
+	}
+	var matches []match
+	for _, node := range nodes {
+		words := contentWords(node.Content)
+		hits := 0
+		for _, term := range terms {
+			found := false
+			for _, word := range words {
+				if matchesTerm(word, term) {
+					found = true
+					hits++
+				}
+			}
+			if !found {
+				hits = 0
+				break
+			}
+		}
+		if hits > 0 {
+			matches = append(matches, match{&SearchResult{Node: node, Snippet: searchSnippet(words, terms)}, hits})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].hits != matches[j].hits {
+			return matches[i].hits > matches[j].hits
+		}
+		return matches[i].result.Node.CreatedAt > matches[j].result.Node.CreatedAt
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]*SearchResult, len(matches))
+	for i, m := range matches {
+		results[i] = m.result
+	}
+	return results, nil
+}
+
+// contentWords splits content into words at whitespace, keeping their punctuation for snippets
+func contentWords(content string) []string {
+	return strings.Fields(content)
+}
+
+// matchesTerm reports whether a word, ignoring case and surrounding punctuation, starts with a search term
+func matchesTerm(word, term string) bool {
+	word = strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.HasPrefix(strings.ToLower(word), term)
+}
+
+// searchSnippet excerpts up to 16 words starting a little before the first match, marking the matched words
+func searchSnippet(words, terms []string) string {
+	const size = 16
+
+	isMatch := func(word string) bool {
+		for _, term := range terms {
+			if matchesTerm(word, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	start := 0
+	for i, word := range words {
+		if isMatch(word) {
+			start = max(0, i-size/4)
+			break
+		}
+	}
+	end := min(len(words), start+size)
+
+	excerpt := make([]string, 0, end-start)
+	for _, word := range words[start:end] {
+		if isMatch(word) {
+			word = SnippetMatchStart + word + SnippetMatchEnd
+		}
+		excerpt = append(excerpt, word)
+	}
+
+	snippet := strings.Join(excerpt, " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(words) {
+		snippet += "…"
+	}
+	return snippet
+}
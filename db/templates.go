@@ -0,0 +1,50 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// templateKeyPrefix prefixes the Config keys holding prompt templates
+const templateKeyPrefix = "template:"
+
+// SaveTemplate stores a prompt template under the given name, replacing any existing one
+func (db *Database) SaveTemplate(name, text string) error {
+	if err := db.SetConfigValue(templateKeyPrefix+name, text); err != nil {
+		return fmt.Errorf("failed to save template %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetTemplate retrieves a prompt template by name, returning nil if it doesn't exist
+func (db *Database) GetTemplate(name string) (*string, error) {
+	return db.GetConfigValue(templateKeyPrefix + name)
+}
+
+// GetTemplates retrieves all prompt templates, keyed by name
+func (db *Database) GetTemplates() (map[string]string, error) {
+	values, err := db.GetConfigValues(templateKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]string, len(values))
+	for key, text := range values {
+		templates[strings.TrimPrefix(key, templateKeyPrefix)] = text
+	}
+	return templates, nil
+}
+
+// DeleteTemplate removes a prompt template, returning false if it didn't exist
+func (db *Database) DeleteTemplate(name string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Config WHERE key = ?`, templateKeyPrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete template %s: %w", name, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for template %s: %w", name, err)
+	}
+	return deleted > 0, nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// ActivityDay is how many nodes were created on one day, for drawing activity heatmaps
+type ActivityDay struct {
+	Date   string         `json:"date"` // YYYY-MM-DD
+	Nodes  int            `json:"nodes"`
+	ByType map[string]int `json:"by_type"` // Nodes of each type created that day; types without any are omitted
+}
+
+// GetActivityByDay counts the nodes matching the filter created on each day in loc, oldest day first.
+// Days without activity between the first and last active ones are included with a count of zero, so
+// the days can be drawn as a heatmap as they are. Nodes from before creation times were recorded
+// aren't counted.
+func (db *Database) GetActivityByDay(filter NodeFilter, loc *time.Location) ([]*ActivityDay, error) {
+	conditions, args := filter.conditions()
+	rows, err := db.conn.Query(`SELECT created_at, type FROM Node WHERE created_at > 0`+conditions, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity: %w", err)
+	}
+	defer rows.Close()
+
+	byDate := make(map[string]*ActivityDay)
+	var first, last time.Time
+	for rows.Next() {
+		var createdAt int64
+		var nodeType string
+		if err := rows.Scan(&createdAt, &nodeType); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+
+		created := time.Unix(createdAt, 0).In(loc)
+		date := created.Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day = &ActivityDay{Date: date, ByType: make(map[string]int)}
+			byDate[date] = day
+		}
+		day.Nodes++
+		day.ByType[nodeType]++
+
+		if first.IsZero() || created.Before(first) {
+			first = created
+		}
+		if created.After(last) {
+			last = created
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over activity: %w", err)
+	}
+
+	days := []*ActivityDay{}
+	if len(byDate) == 0 {
+		return days, nil
+	}
+	// Step by calendar day rather than 24 hours, which would skip or repeat days across DST changes
+	end := last.Format("2006-01-02")
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 12, 0, 0, 0, loc); ; day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if activity, ok := byDate[date]; ok {
+			days = append(days, activity)
+		} else {
+			days = append(days, &ActivityDay{Date: date, ByType: map[string]int{}})
+		}
+		if date == end {
+			break
+		}
+	}
+	return days, nil
+}
+
+// GetTimelinePage retrieves up to limit nodes matching the filter across every tree, oldest first,
+// starting after the node created at afterCreated with ID afterID (0 and "" for the first page). Nodes
+// from before creation times were recorded aren't included.
+func (db *Database) GetTimelinePage(filter NodeFilter, afterCreated int64, afterID string, limit int) ([]*Node, error) {
+	conditions, args := filter.conditions()
+	query := `
+		SELECT ` + nodeColumns + `
+		FROM Node
+		WHERE created_at > 0 AND (created_at > ? OR (created_at = ? AND id > ?))` + conditions + `
+		ORDER BY created_at, id
+		LIMIT ?
+	`
+	args = append([]interface{}{afterCreated, afterCreated, afterID}, args...)
+	return db.queryNodes(query, append(args, limit)...)
+}
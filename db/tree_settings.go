@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Settings a tree can set for the conversations in it, overriding the global ones
+const (
+	TreeModelSetting       = "model"       // Model new messages use when no ancestor has one
+	TreeTemperatureSetting = "temperature" // Overrides generate.temperature
+	TreeMaxTokensSetting   = "max_tokens"  // Overrides generate.max_tokens
+	TreeSystemSetting      = "system"      // Overrides generate.system
+)
+
+// TreeSettingsMetadataKey is the metadata key of a root node holding its tree's settings, so they
+// travel with the tree when it's synced, bundled or archived and go away when it's pruned
+const TreeSettingsMetadataKey = "settings"
+
+// GetTreeSettings returns the settings of the tree holding the given node, by name, and the ID of
+// the tree's root
+func (db *Database) GetTreeSettings(nodeID string) (string, map[string]string, error) {
+	rootID, err := db.GetRootID(nodeID)
+	if err != nil {
+		return "", nil, err
+	}
+	root, err := db.GetNodeByID(rootID)
+	if err != nil {
+		return "", nil, err
+	}
+	return rootID, treeSettings(root), nil
+}
+
+// treeSettings reads the settings stored on a root node
+func treeSettings(root *Node) map[string]string {
+	settings := make(map[string]string)
+	values, _ := root.GetMetadata()[TreeSettingsMetadataKey].(map[string]interface{})
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			settings[key] = s
+		}
+	}
+	return settings
+}
+
+// SetTreeSetting sets one of a tree's settings, given the ID of its root
+func (db *Database) SetTreeSetting(rootID, key, value string) error {
+	return db.updateTreeSettings(rootID, func(settings map[string]string) {
+		settings[key] = value
+	})
+}
+
+// UnsetTreeSetting removes one of a tree's settings so the global one applies again
+func (db *Database) UnsetTreeSetting(rootID, key string) error {
+	return db.updateTreeSettings(rootID, func(settings map[string]string) {
+		delete(settings, key)
+	})
+}
+
+// updateTreeSettings changes the settings stored on a root node in one transaction, so concurrent
+// changes to other settings or metadata aren't lost
+func (db *Database) updateTreeSettings(rootID string, update func(map[string]string)) error {
+	return db.updateRootMetadata(rootID, func(root *Node, metadata map[string]interface{}) {
+		settings := treeSettings(root)
+		update(settings)
+		if len(settings) == 0 {
+			delete(metadata, TreeSettingsMetadataKey)
+		} else {
+			metadata[TreeSettingsMetadataKey] = settings
+		}
+	})
+}
+
+// updateRootMetadata changes the metadata of a root node in one transaction, failing if the node
+// isn't a root
+func (db *Database) updateRootMetadata(rootID string, update func(root *Node, metadata map[string]interface{})) error {
+	return db.withTx(func(tx *sql.Tx) error {
+		root, err := db.scanNode(tx.QueryRow(`SELECT `+nodeColumns+` FROM Node WHERE id = ?`, rootID))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("node with ID %s not found", rootID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get node: %w", err)
+		}
+		if root.Parent != nil {
+			return fmt.Errorf("node %s isn't the root of a tree", rootID)
+		}
+
+		metadata := root.GetMetadata()
+		update(root, metadata)
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for node %s: %w", rootID, err)
+		}
+		value := string(encoded)
+		stored, err := db.sealValue(&value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE Node SET metadata = ? WHERE id = ?`, stored, rootID); err != nil {
+			return fmt.Errorf("failed to update metadata of tree %s: %w", rootID, err)
+		}
+		return nil
+	})
+}
+
+// InheritedModel returns the model new messages below the given node use when none is given: the
+// model of the node or of its nearest ancestor that has one, so a user message added without a model
+// doesn't break the chain, or else the default model of its tree, or else the global default model.
+// It's empty if none of them is set.
+func (db *Database) InheritedModel(nodeID string) (string, error) {
+	query := `
+		WITH RECURSIVE ancestor(id, parent, model, depth) AS (
+			SELECT id, parent, model, 0 FROM Node WHERE id = ?
+			UNION ALL
+			SELECT Node.id, Node.parent, Node.model, ancestor.depth + 1 FROM Node JOIN ancestor ON Node.id = ancestor.parent
+		)
+		SELECT model FROM ancestor WHERE model IS NOT NULL AND model != ''
+		ORDER BY depth
+		LIMIT 1
+	`
+
+	var model string
+	err := db.conn.QueryRow(query, nodeID).Scan(&model)
+	if err == nil {
+		return model, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to find the model of node %s: %w", nodeID, err)
+	}
+
+	_, settings, err := db.GetTreeSettings(nodeID)
+	if err != nil {
+		return "", err
+	}
+	if model := settings[TreeModelSetting]; model != "" {
+		return model, nil
+	}
+	return db.DefaultModel()
+}
+
+// GetTreeGenerationSettings reads the parameters for generating responses in the tree holding the
+// given node: the global ones, overridden by any the tree sets
+func (db *Database) GetTreeGenerationSettings(nodeID string) (*GenerationSettings, error) {
+	settings, err := db.GetGenerationSettings()
+	if err != nil {
+		return nil, err
+	}
+	rootID, tree, err := db.GetTreeSettings(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if temperature, ok := tree[TreeTemperatureSetting]; ok {
+		value, err := strconv.ParseFloat(temperature, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid temperature %q for tree %s: %w", temperature, rootID, err)
+		}
+		settings.Temperature = &value
+	}
+	if maxTokens, ok := tree[TreeMaxTokensSetting]; ok {
+		if settings.MaxTokens, err = strconv.Atoi(maxTokens); err != nil {
+			return nil, fmt.Errorf("invalid max_tokens %q for tree %s: %w", maxTokens, rootID, err)
+		}
+	}
+	if system, ok := tree[TreeSystemSetting]; ok {
+		settings.System = system
+	}
+	return settings, nil
+}
@@ -0,0 +1,401 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeDetector reports whether the database has been modified since it last checked,
+// including changes committed by other processes such as a CLI running in another terminal
+type ChangeDetector struct {
+	conn    *sql.Conn
+	version int64
+}
+
+// NewChangeDetector creates a change detector. It holds a dedicated connection, since SQLite's
+// data_version is only comparable between calls on the same connection.
+func (db *Database) NewChangeDetector(ctx context.Context) (*ChangeDetector, error) {
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open change detection connection: %w", err)
+	}
+
+	detector := &ChangeDetector{conn: conn}
+	if detector.version, err = detector.dataVersion(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return detector, nil
+}
+
+// Changed reports whether any other connection has committed a change since the previous call
+func (d *ChangeDetector) Changed(ctx context.Context) (bool, error) {
+	version, err := d.dataVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	changed := version != d.version
+	d.version = version
+	return changed, nil
+}
+
+// Close releases the detector's connection
+func (d *ChangeDetector) Close() error {
+	return d.conn.Close()
+}
+
+// dataVersion reads SQLite's data_version counter for the detector's connection
+func (d *ChangeDetector) dataVersion(ctx context.Context) (int64, error) {
+	var version int64
+	if err := d.conn.QueryRowContext(ctx, `PRAGMA data_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read data version: %w", err)
+	}
+	return version, nil
+}
+
+// ChangePollInterval is how often a database with subscribers checks for changes made by any process
+const ChangePollInterval = 500 * time.Millisecond
+
+// ChangeType identifies what a Change did to the tree
+type ChangeType string
+
+const (
+	NodeCreated    ChangeType = "node-created"
+	NodeUpdated    ChangeType = "node-updated" // The node's content, parent, type, model, author or metadata (such as its tags, exclusion or pins) changed
+	NodeDeleted    ChangeType = "node-deleted"
+	CurrentChanged ChangeType = "current-changed" // The current working node moved or was cleared
+)
+
+// Change is a modification to the tree made by this or any other process
+type Change struct {
+	Type        ChangeType `json:"type"`
+	Node        *Node      `json:"node,omitempty"`         // Set for node-created and node-updated
+	ID          string     `json:"id,omitempty"`           // Set for node-deleted
+	CurrentNode *string    `json:"current_node,omitempty"` // Set for current-changed; omitted when it was cleared
+}
+
+// changeFeed polls for changes while the database has subscribers, fanning them out to each one
+type changeFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan Change]struct{}
+	stop        context.CancelFunc // Stops the poller; nil while nobody is subscribed
+	done        chan struct{}      // Closed when the poller has stopped
+}
+
+// Subscribe returns a channel receiving every change to the tree from now on, and a function to
+// stop receiving them. The first subscriber starts polling the database, shared by all subscribers,
+// and the last one to unsubscribe stops it. Changes are dropped for subscribers too slow to keep up.
+func (db *Database) Subscribe() (<-chan Change, func(), error) {
+	feed := &db.changes
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	if feed.stop == nil {
+		if err := db.startPolling(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ch := make(chan Change, 64)
+	if feed.subscribers == nil {
+		feed.subscribers = make(map[chan Change]struct{})
+	}
+	feed.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			feed.mu.Lock()
+			delete(feed.subscribers, ch)
+			var done chan struct{}
+			if len(feed.subscribers) == 0 && feed.stop != nil {
+				feed.stop()
+				feed.stop, done = nil, feed.done
+			}
+			feed.mu.Unlock()
+
+			if done != nil {
+				<-done
+			}
+		})
+	}
+	return ch, unsubscribe, nil
+}
+
+// publish sends a change to every subscriber
+func (f *changeFeed) publish(change Change) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// changeLogRetention is how long entries stay in the NodeChange log. Subscribers read it every
+// ChangePollInterval, so anything older has been seen by every subscriber still running.
+const changeLogRetention = time.Hour
+
+// ensureChangeLog creates the log of node writes that subscribers poll, and the triggers filling it
+//
+// Like the search triggers, these are plain SQL so writes from other SQLite tools are logged too.
+// Each insert, delete and change to a stored column is logged with the node's ID; visits, which only
+// touch visited_at, aren't.
+func (db *Database) ensureChangeLog() error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS NodeChange (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			node_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			changed_at INTEGER NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER))
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS node_change_insert AFTER INSERT ON Node BEGIN
+			INSERT INTO NodeChange(node_id, kind) VALUES (new.id, 'insert');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS node_change_delete AFTER DELETE ON Node BEGIN
+			INSERT INTO NodeChange(node_id, kind) VALUES (old.id, 'delete');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS node_change_update AFTER UPDATE ON Node
+		WHEN old.content IS NOT new.content OR old.content_hash IS NOT new.content_hash
+			OR old.type IS NOT new.type OR old.parent IS NOT new.parent OR old.model IS NOT new.model
+			OR old.metadata IS NOT new.metadata OR old.author IS NOT new.author
+		BEGIN
+			INSERT INTO NodeChange(node_id, kind) VALUES (new.id, 'update');
+		END`,
+	}
+	err := db.withTx(func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("failed to create change log: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return db.pruneChangeLog()
+}
+
+// pruneChangeLog drops log entries every subscriber has had time to read, keeping the newest so
+// Revision can still tell when the nodes last changed
+func (db *Database) pruneChangeLog() error {
+	cutoff := time.Now().Add(-changeLogRetention).Unix()
+	_, err := db.conn.Exec(`DELETE FROM NodeChange WHERE changed_at < ? AND seq < (SELECT MAX(seq) FROM NodeChange)`, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to prune change log: %w", err)
+	}
+	return nil
+}
+
+// Revision identifies a state of the nodes, for clients checking whether they've changed
+type Revision struct {
+	Seq       int64 // Increases with every write to a node by any process; 0 if none has been logged
+	ChangedAt int64 // Unix seconds of the latest write, 0 if unknown
+}
+
+// Revision returns the current revision of the nodes. Writes are counted by the change log's
+// triggers, so it moves on with writes by other processes too.
+func (db *Database) Revision() (*Revision, error) {
+	revision := &Revision{}
+	err := db.conn.QueryRow(`SELECT seq, changed_at FROM NodeChange ORDER BY seq DESC LIMIT 1`).Scan(&revision.Seq, &revision.ChangedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to read revision: %w", err)
+	}
+	return revision, nil
+}
+
+// startPolling starts the goroutine publishing changes; the feed must be locked
+func (db *Database) startPolling() error {
+	ctx, stop := context.WithCancel(context.Background())
+	detector, err := db.NewChangeDetector(ctx)
+	if err != nil {
+		stop()
+		return err
+	}
+	previous, err := db.takeSnapshot()
+	if err != nil {
+		detector.Close()
+		stop()
+		return err
+	}
+
+	done := make(chan struct{})
+	db.changes.stop, db.changes.done = stop, done
+	go func() {
+		defer close(done)
+		defer detector.Close()
+		db.pollChanges(ctx, detector, previous)
+	}()
+	return nil
+}
+
+// pollChanges publishes a change for each node created, updated or deleted, and whenever the
+// current node moves, until ctx is cancelled. Failed checks are retried on the next tick.
+func (db *Database) pollChanges(ctx context.Context, detector *ChangeDetector, previous *treeSnapshot) {
+	ticker := time.NewTicker(ChangePollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if changed, err := detector.Changed(ctx); err != nil || !changed {
+			continue
+		}
+		snapshot, err := db.takeSnapshot()
+		if err != nil {
+			continue
+		}
+		changes, err := db.nodeChangesSince(previous.seq, snapshot.seq)
+		if err != nil {
+			continue
+		}
+
+		for _, change := range changes {
+			db.changes.publish(change)
+		}
+		if snapshot.currentNode != previous.currentNode {
+			change := Change{Type: CurrentChanged}
+			if snapshot.currentNode != "" {
+				current := snapshot.currentNode
+				change.CurrentNode = &current
+			}
+			db.changes.publish(change)
+		}
+
+		previous = snapshot
+		if time.Since(lastPrune) > changeLogRetention {
+			db.pruneChangeLog()
+			lastPrune = time.Now()
+		}
+	}
+}
+
+// treeSnapshot is how far the change log had got, and where the current node was, at one poll
+type treeSnapshot struct {
+	seq         int64 // The newest NodeChange entry
+	currentNode string
+}
+
+// takeSnapshot reads the end of the change log and the current node
+func (db *Database) takeSnapshot() (*treeSnapshot, error) {
+	snapshot := &treeSnapshot{}
+	if err := db.conn.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM NodeChange`).Scan(&snapshot.seq); err != nil {
+		return nil, fmt.Errorf("failed to read change log: %w", err)
+	}
+
+	currentNode, err := db.GetCurrentNode()
+	if err != nil {
+		return nil, err
+	}
+	if currentNode != nil {
+		snapshot.currentNode = *currentNode
+	}
+	return snapshot, nil
+}
+
+// nodeChangesSince returns the changes logged after one sequence number up to and including another,
+// one per node: created nodes parents first, then updated ones, then deleted ones. A node created
+// and deleted within the range isn't reported at all.
+func (db *Database) nodeChangesSince(after, upTo int64) ([]Change, error) {
+	rows, err := db.conn.Query(`SELECT node_id, kind FROM NodeChange WHERE seq > ? AND seq <= ? ORDER BY seq`, after, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read change log: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	inserted := make(map[string]bool) // Node ID to whether its first entry in the range was its insert
+	for rows.Next() {
+		var id, kind string
+		if err := rows.Scan(&id, &kind); err != nil {
+			return nil, fmt.Errorf("failed to scan change log: %w", err)
+		}
+		if _, seen := inserted[id]; !seen {
+			inserted[id] = kind == "insert"
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over change log: %w", err)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.Repeat("?, ", len(ids)-1) + "?"
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	nodes, err := db.queryNodes(`SELECT `+nodeColumns+` FROM Node WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+
+	var changes, deleted []Change
+	created := make(map[string]*Node)
+	for _, id := range ids {
+		node, exists := byID[id]
+		switch {
+		case exists && inserted[id]:
+			created[id] = node
+		case exists:
+			changes = append(changes, Change{Type: NodeUpdated, Node: node})
+		case !inserted[id]:
+			deleted = append(deleted, Change{Type: NodeDeleted, ID: id})
+		}
+	}
+
+	ordered := make([]Change, 0, len(created)+len(changes)+len(deleted))
+	for _, node := range parentsFirst(created) {
+		ordered = append(ordered, Change{Type: NodeCreated, Node: node})
+	}
+	ordered = append(ordered, changes...)
+	return append(ordered, deleted...), nil
+}
+
+// parentsFirst orders newly created nodes so that each node comes after its parent if that is new too
+func parentsFirst(created map[string]*Node) []*Node {
+	ordered := make([]*Node, 0, len(created))
+	emitted := make(map[string]bool, len(created))
+
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if emitted[node.ID] {
+			return
+		}
+		emitted[node.ID] = true
+		if node.Parent != nil {
+			if parent, ok := created[*node.Parent]; ok {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, node)
+	}
+
+	for _, node := range created {
+		visit(node)
+	}
+	return ordered
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a URL that node events are posted to. Like schedules, webhooks and their secrets are
+// stored in plaintext even in encrypted databases.
+type Webhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"-"`      // Key the payload is signed with, empty to send it unsigned
+	Events    []string `json:"events"` // Events posted, e.g. node-created
+	CreatedAt int64    `json:"created_at"`
+}
+
+// webhookColumns lists the Webhook columns read by every webhook query, in the order expected by scanWebhook
+const webhookColumns = `id, url, secret, events, created_at`
+
+// ensureWebhooks creates the table of webhooks
+func (db *Database) ensureWebhooks() error {
+	createWebhookTable := `
+	CREATE TABLE IF NOT EXISTS Webhook (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL DEFAULT '',
+		events TEXT NOT NULL DEFAULT '[]',
+		created_at INTEGER NOT NULL
+	);`
+
+	if _, err := db.conn.Exec(createWebhookTable); err != nil {
+		return fmt.Errorf("failed to create Webhook table: %w", err)
+	}
+	return nil
+}
+
+// scanWebhook scans a row selected with webhookColumns into a Webhook
+func scanWebhook(scanner rowScanner) (*Webhook, error) {
+	webhook := &Webhook{}
+	var events string
+	if err := scanner.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to parse events of webhook %s: %w", webhook.ID, err)
+	}
+	return webhook, nil
+}
+
+// AddWebhook stores a new webhook, filling in its ID and creation time
+func (db *Database) AddWebhook(webhook *Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+	if webhook.Events == nil {
+		events = []byte("[]")
+	}
+
+	webhook.ID = uuid.New().String()
+	webhook.CreatedAt = time.Now().Unix()
+	_, err = db.conn.Exec(`INSERT INTO Webhook (id, url, secret, events, created_at) VALUES (?, ?, ?, ?, ?)`,
+		webhook.ID, webhook.URL, webhook.Secret, string(events), webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhooks retrieves every webhook, oldest first
+func (db *Database) GetWebhooks() ([]*Webhook, error) {
+	rows, err := db.conn.Query(`SELECT ` + webhookColumns + ` FROM Webhook ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// GetWebhook retrieves a webhook by its full ID or a unique prefix of it
+func (db *Database) GetWebhook(idOrPrefix string) (*Webhook, error) {
+	if idOrPrefix == "" {
+		return nil, fmt.Errorf("webhook ID must not be empty")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+webhookColumns+` FROM Webhook WHERE substr(id, 1, length(?)) = ? LIMIT 2`, idOrPrefix, idOrPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up webhook %s: %w", idOrPrefix, err)
+	}
+	defer rows.Close()
+
+	var matches []*Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		matches = append(matches, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhooks: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("webhook with ID %s not found", idOrPrefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("webhook ID %s is ambiguous; use more characters", idOrPrefix)
+	}
+}
+
+// DeleteWebhook removes a webhook, returning false if it didn't exist
+func (db *Database) DeleteWebhook(id string) (bool, error) {
+	result, err := db.conn.Exec(`DELETE FROM Webhook WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook %s: %w", id, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for webhook %s: %w", id, err)
+	}
+	return deleted > 0, nil
+}
@@ -0,0 +1,203 @@
+package bonsai
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// BatchOptions configures a Batch
+type BatchOptions struct {
+	Model       string             // Model for every prompt; empty inherits one as InheritedModel does
+	Concurrency int                // Responses generated at once; zero uses the session pool's workers
+	Timeout     time.Duration      // Bound on each response; zero leaves it to ctx
+	OnResult    func(BatchResult)  // Called as each prompt finishes, never concurrently
+	OnProgress  func(llm.Progress) // Called as each response finishes, never concurrently
+}
+
+// BatchResult is the outcome of one prompt in a Batch
+type BatchResult struct {
+	Index    int   // Position of the prompt in the input
+	Message  *Node // The prompt's user node, nil if it couldn't be created
+	Response *Node // The model's reply, nil if there was no model or generating it failed
+	Err      error
+}
+
+// Batch adds each prompt as its own branch below the parent and generates the model's replies
+// concurrently with the session's pool. The current working node is left where it is. Results are
+// returned in input order; a failed prompt doesn't stop the others.
+func (s *Session) Batch(ctx context.Context, parent *Node, prompts []string, opts BatchOptions) []BatchResult {
+	model := opts.Model
+	var err error
+	if model == "" {
+		model, err = s.InheritedModel(parent)
+	}
+	if err == nil {
+		err = s.checkModel(model)
+	}
+	if err != nil {
+		results := make([]BatchResult, len(prompts))
+		for i := range results {
+			results[i] = BatchResult{Index: i, Err: err}
+			if opts.OnResult != nil {
+				opts.OnResult(results[i])
+			}
+		}
+		return results
+	}
+
+	items := make([]batchItem, len(prompts))
+	for i, prompt := range prompts {
+		items[i] = batchItem{prompt: prompt, model: optionalModel(model)}
+	}
+	return s.batch(ctx, parent, items, opts)
+}
+
+// batchItem is one prompt of a batch and the model that answers it
+type batchItem struct {
+	prompt string
+	model  *string
+}
+
+// batch adds each item as a branch below the parent and generates the replies concurrently.
+// opts.Model is ignored in favor of each item's model.
+func (s *Session) batch(ctx context.Context, parent *Node, items []batchItem, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(items))
+	var reportMu sync.Mutex
+	report := func(result BatchResult) {
+		results[result.Index] = result
+		if opts.OnResult != nil {
+			reportMu.Lock()
+			defer reportMu.Unlock()
+			opts.OnResult(result)
+		}
+	}
+
+	// Create the branches up front so they're stored in input order
+	var pending []BatchResult
+	for i, item := range items {
+		node, err := s.db.CreateBranchNode(item.prompt, parent.ID, "user", item.model)
+		if err != nil {
+			report(BatchResult{Index: i, Err: fmt.Errorf("failed to create child node: %w", err)})
+			continue
+		}
+		s.Notify(hooks.NodeCreated, node)
+
+		if item.model == nil || *item.model == "" {
+			report(BatchResult{Index: i, Message: node})
+			continue
+		}
+		pending = append(pending, BatchResult{Index: i, Message: node})
+	}
+
+	s.pool.Run(len(pending), opts.Concurrency, func(i int) error {
+		result := pending[i]
+		result.Response, result.Err = s.respondWithin(ctx, opts.Timeout, result.Message, *result.Message.Model)
+		report(result)
+		return result.Err
+	}, opts.OnProgress)
+	return results
+}
+
+// respondWithin is Respond bounded by a timeout, if one is given
+func (s *Session) respondWithin(ctx context.Context, timeout time.Duration, node *Node, model string) (*Node, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.Respond(ctx, node, model)
+}
+
+// ReadBatchInputs reads the prompts for a Batch from a file, or stdin if path is "-". Each non-blank
+// line is a prompt, except in .csv files, whose first row names the columns: each following row is a
+// prompt, taken from the "prompt" column if there is one and otherwise written as "column: value" lines.
+func ReadBatchInputs(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSVPrompts(r, path)
+	}
+
+	var prompts []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSourceSize)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			prompts = append(prompts, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return prompts, nil
+}
+
+// readCSVPrompts turns the rows of a CSV file with a header row into prompts
+func readCSVPrompts(r io.Reader, path string) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	promptColumn := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), "prompt") {
+			promptColumn = i
+			break
+		}
+	}
+
+	var prompts []string
+	for _, record := range records[1:] {
+		var prompt string
+		if promptColumn >= 0 {
+			if promptColumn < len(record) {
+				prompt = strings.TrimSpace(record[promptColumn])
+			}
+		} else {
+			var lines []string
+			for i, value := range record {
+				value = strings.TrimSpace(value)
+				if value == "" {
+					continue
+				}
+				if i < len(header) && strings.TrimSpace(header[i]) != "" {
+					value = strings.TrimSpace(header[i]) + ": " + value
+				}
+				lines = append(lines, value)
+			}
+			prompt = strings.Join(lines, "\n")
+		}
+
+		if prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts, nil
+}
@@ -0,0 +1,278 @@
+// Package bonsai lets Go programs grow and navigate Bonsai conversation trees directly,
+// without shelling out to the bai CLI.
+//
+// A Session wraps a Bonsai database and tracks the current working node the same way the
+// CLI does, so programs and the CLI can share one database:
+//
+//	session, err := bonsai.OpenDefault()
+//	if err != nil {
+//		return err
+//	}
+//	defer session.Close()
+//
+//	if _, err := session.Seed("Plan a trip to Kyoto", "gpt-4"); err != nil {
+//		return err
+//	}
+//	turn, err := session.Say(ctx, "Three days, mostly temples", "")
+package bonsai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/config"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// Node is a single message in a conversation tree
+type Node = db.Node
+
+// ErrNoCurrentNode is returned when an operation needs a current working node and none is set
+var ErrNoCurrentNode = errors.New("no current working node set")
+
+// Session is a handle on a Bonsai database and its current working node
+type Session struct {
+	db      *db.Database
+	hooks   *hooks.Dispatcher
+	owned   bool   // Whether Close should close the database
+	gitDir  string // Directory whose git commit is recorded on new nodes; empty to record none
+	cache   bool   // Whether generations are answered from the response cache
+	pool    *llm.Pool
+	window  ContextWindow // How much of each branch is sent to models, see LimitContext
+	profile *db.Profile   // Settings generations are made with, overriding the configured ones; nil for none
+	force   bool          // Whether model names no provider is known to serve are sent as-is
+	timeout time.Duration // Bound on each request overriding generate.timeout; zero for none
+}
+
+// DefaultTimeout bounds a single LLM request unless the generate.timeout setting or UseTimeout says
+// otherwise
+const DefaultTimeout = 2 * time.Minute
+
+// DefaultPath returns the path of the database used by the bai CLI, ~/.bonsai/bonsai.db
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".bonsai", "bonsai.db"), nil
+}
+
+// Open opens the database at the given path, creating and initializing it if needed
+func Open(path string) (*Session, error) {
+	database, err := db.NewDatabase(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+
+	if err := database.Initialize(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	session := NewSession(database)
+	session.owned = true
+	return session, nil
+}
+
+// OpenDefault opens the database shared with the bai CLI
+func OpenDefault() (*Session, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// NewSession wraps an already initialized database. Closing the session leaves the database open.
+func NewSession(database *db.Database) *Session {
+	return &Session{db: database, hooks: hooks.NewDispatcher(database), pool: llm.NewPool(0, nil)}
+}
+
+// UsePool makes the session's generations share the pool's limits on requests at once, overall
+// and per provider. Sessions start with a pool of llm.DefaultWorkers and no provider limits.
+func (s *Session) UsePool(pool *llm.Pool) {
+	s.pool = pool
+}
+
+// Close closes the database if the session opened it
+func (s *Session) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Database returns the underlying database for operations the session doesn't cover
+func (s *Session) Database() *db.Database {
+	return s.db
+}
+
+// Hooks returns the dispatcher that runs hooks for the session's changes, e.g. to set its OnError
+func (s *Session) Hooks() *hooks.Dispatcher {
+	return s.hooks
+}
+
+// Node returns the node with the given ID, which may be abbreviated to a unique prefix
+func (s *Session) Node(id string) (*Node, error) {
+	nodeID, err := s.db.ResolveNodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.GetNodeByID(nodeID)
+}
+
+// Current returns the current working node, or ErrNoCurrentNode if none is set
+func (s *Session) Current() (*Node, error) {
+	currentNodeID, err := s.db.GetCurrentNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current node: %w", err)
+	}
+	if currentNodeID == nil {
+		return nil, ErrNoCurrentNode
+	}
+
+	node, err := s.db.GetNodeByID(*currentNodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current node details: %w", err)
+	}
+	return node, nil
+}
+
+// CurrentRootID returns the root ID of the tree containing the current working node, or "" if none is set
+func (s *Session) CurrentRootID() (string, error) {
+	currentNodeID, err := s.db.GetCurrentNode()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current node: %w", err)
+	}
+	if currentNodeID == nil {
+		return "", nil
+	}
+
+	rootID, err := s.db.GetRootID(*currentNodeID)
+	if err != nil {
+		// A stale pointer to a deleted node doesn't belong to any tree
+		return "", nil
+	}
+	return rootID, nil
+}
+
+// Seed starts a new tree with the given message and makes it the current working node.
+// An empty model leaves the tree without a default model.
+func (s *Session) Seed(content, model string) (*Node, error) {
+	if err := s.checkModel(model); err != nil {
+		return nil, err
+	}
+	node, err := s.db.CreateRootNode(content, optionalModel(model))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root node: %w", err)
+	}
+
+	s.Notify(hooks.NodeCreated, node)
+	return node, nil
+}
+
+// Append adds a user message below the current working node and moves to it. An empty model
+// inherits one as InheritedModel does.
+func (s *Session) Append(message, model string) (*Node, error) {
+	return s.Reply(message, ReplyOptions{Model: model})
+}
+
+// InheritedModel returns the model new messages below parent use when none is given: the model of
+// the session's profile if it sets one, or else the model of parent or its nearest ancestor that has
+// one, or else the default model of its tree, or else the global default model. It's empty if none
+// of them is set.
+func (s *Session) InheritedModel(parent *Node) (string, error) {
+	if s.profile != nil && s.profile.Model != "" {
+		return s.profile.Model, nil
+	}
+	model, err := s.db.InheritedModel(parent.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the model to inherit: %w", err)
+	}
+	return model, nil
+}
+
+// DefaultModel returns the model new trees use when none is given: the model of the session's
+// profile if it sets one, or else the global default model. It's empty if neither is set.
+func (s *Session) DefaultModel() (string, error) {
+	if s.profile != nil && s.profile.Model != "" {
+		return s.profile.Model, nil
+	}
+	return s.db.DefaultModel()
+}
+
+// UseProfile makes the session generate with an execution profile: its model answers when none is
+// given, and its temperature, max_tokens and system prompt override the global and tree settings.
+// nil goes back to the configured settings.
+func (s *Session) UseProfile(profile *db.Profile) {
+	s.profile = profile
+}
+
+// Profile returns the execution profile the session generates with, or nil if it has none
+func (s *Session) Profile() *db.Profile {
+	return s.profile
+}
+
+// ForceModel makes the session send model names no provider is known to serve as-is, instead of
+// failing with an llm.UnknownModelError, for models released after this version of bai
+func (s *Session) ForceModel(enabled bool) {
+	s.force = enabled
+}
+
+// UseTimeout bounds each LLM request the session's callers make by Timeout, overriding the
+// generate.timeout setting. Zero goes back to the setting.
+func (s *Session) UseTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// Timeout returns how long a single LLM request may take: the timeout given to UseTimeout, else the
+// generate.timeout setting, else DefaultTimeout
+func (s *Session) Timeout() time.Duration {
+	if s.timeout > 0 {
+		return s.timeout
+	}
+	if settings, err := s.db.GetGenerationSettings(); err == nil && settings.Timeout > 0 {
+		return settings.Timeout
+	}
+	return DefaultTimeout
+}
+
+// checkModel fails for a model no provider is known to serve, before anything is stored for it,
+// unless the session forces models. An empty model is fine.
+func (s *Session) checkModel(model string) error {
+	if model == "" {
+		return nil
+	}
+	rules, err := s.providerRules()
+	if err != nil {
+		return err
+	}
+	return config.ValidateModel(model, rules, s.force)
+}
+
+// providerRules returns the rules of the generate.provider_rules setting, which route models to
+// providers by name prefix
+func (s *Session) providerRules() ([]llm.ProviderRule, error) {
+	value, err := s.db.ProviderRules()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := llm.ParseProviderRules(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", db.ProviderRulesConfigKey, err)
+	}
+	return rules, nil
+}
+
+// optionalModel converts an empty model name to nil
+func optionalModel(model string) *string {
+	if model == "" {
+		return nil
+	}
+	return &model
+}
@@ -0,0 +1,126 @@
+package bonsai
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BundleFormat identifies a bundle file, and BundleVersion the version of the format written
+const (
+	BundleFormat  = "bonsai-bundle"
+	BundleVersion = 1
+)
+
+// Bundle is a subtree packed to be sent to someone else and imported into their own garden. It
+// holds the branch from the root down to the bundled node, so the subtree keeps its context, and
+// everything below that node.
+//
+// A bundle file is the Bundle as gzip-compressed JSON, conventionally named with a .bonsai extension.
+type Bundle struct {
+	Format    string  `json:"format"`
+	Version   int     `json:"version"`
+	Node      string  `json:"node"` // ID of the bundled node
+	CreatedAt int64   `json:"created_at"`
+	Nodes     []*Node `json:"nodes"` // Ancestors first, then the subtree
+}
+
+// NewBundle packs the given node, its ancestors and its descendants, with secrets redacted
+func (s *Session) NewBundle(nodeID string) (*Bundle, error) {
+	branch, err := s.db.GetConversationHistory(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch %s: %w", nodeID, err)
+	}
+	if len(branch) == 0 {
+		return nil, fmt.Errorf("node with ID %s not found", nodeID)
+	}
+	subtree, err := s.db.GetNodeAndAllChildren(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtree %s: %w", nodeID, err)
+	}
+
+	// The subtree starts with the node itself, which ends the branch
+	nodes := append(branch[:len(branch)-1:len(branch)-1], subtree...)
+	for _, node := range nodes {
+		// When a node was last looked at is private to the sender's garden
+		node.VisitedAt = 0
+	}
+	if err := s.redactForExport(nodes); err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		Format:    BundleFormat,
+		Version:   BundleVersion,
+		Node:      nodeID,
+		CreatedAt: time.Now().Unix(),
+		Nodes:     nodes,
+	}, nil
+}
+
+// Write writes the bundle to w in the bundle file format
+func (b *Bundle) Write(w io.Writer) error {
+	compressed := gzip.NewWriter(w)
+	if err := json.NewEncoder(compressed).Encode(b); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := compressed.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// ReadBundle reads a bundle written with Bundle.Write
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	decompressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New("not a Bonsai bundle")
+	}
+	defer decompressed.Close()
+
+	var b Bundle
+	if err := json.NewDecoder(decompressed).Decode(&b); err != nil || b.Format != BundleFormat {
+		return nil, errors.New("not a Bonsai bundle")
+	}
+	if b.Version > BundleVersion {
+		return nil, fmt.Errorf("the bundle was made by a newer version of bai (format version %d); upgrade bai to import it", b.Version)
+	}
+	if len(b.Nodes) == 0 {
+		return nil, errors.New("the bundle has no nodes")
+	}
+	for _, node := range b.Nodes {
+		if node == nil || node.ID == "" {
+			return nil, errors.New("the bundle has a node without an ID")
+		}
+	}
+	return &b, nil
+}
+
+// Root returns the root node of the bundled branch
+func (b *Bundle) Root() *Node {
+	return b.Nodes[0]
+}
+
+// ApplyBundle imports a bundle's nodes into the garden the way Pull does, so applying a bundle
+// twice changes nothing, and nodes changed both here and in the bundle are conflicts unless
+// opts.Force is set
+func (s *Session) ApplyBundle(ctx context.Context, b *Bundle, opts SyncOptions) (*SyncResult, error) {
+	return s.Pull(ctx, bundleRemote{b}, opts)
+}
+
+// bundleRemote lets a bundle be pulled from like a Remote
+type bundleRemote struct {
+	bundle *Bundle
+}
+
+func (r bundleRemote) Nodes(ctx context.Context) ([]*Node, error) {
+	return r.bundle.Nodes, nil
+}
+
+func (r bundleRemote) Put(ctx context.Context, nodes []*Node) error {
+	return errors.New("bundles can't be pushed to")
+}
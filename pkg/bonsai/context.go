@@ -0,0 +1,56 @@
+package bonsai
+
+import "fmt"
+
+// ContextWindow limits how much of a branch is sent to the model. System prompts in the tree are
+// always sent, even from before the window, so it doesn't change how the model is told to answer.
+type ContextWindow struct {
+	Turns int    // Send only the last this many user messages, with the nodes after them; 0 for all
+	From  string // Send only from this node down, by full or abbreviated ID; empty to start at the root
+}
+
+// LimitContext makes the session send only the given window of each branch it generates replies to
+func (s *Session) LimitContext(window ContextWindow) {
+	s.window = window
+}
+
+// apply returns the part of a conversation, root first, that the window sends
+func (w ContextWindow) apply(s *Session, history []*Node) ([]*Node, error) {
+	start := 0
+	if w.From != "" {
+		from, err := s.Node(w.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the start of the context: %w", err)
+		}
+		start = -1
+		for i, node := range history {
+			if node.ID == from.ID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("node %s isn't on the branch being replied to", shortID(from.ID))
+		}
+	}
+	if w.Turns > 0 {
+		turns := 0
+		for i := len(history) - 1; i >= start; i-- {
+			if history[i].Type != "user" {
+				continue
+			}
+			if turns++; turns == w.Turns {
+				start = i
+				break
+			}
+		}
+	}
+
+	window := make([]*Node, 0, len(history)-start)
+	for i, node := range history {
+		if i >= start || node.Type == "system" {
+			window = append(window, node)
+		}
+	}
+	return window, nil
+}
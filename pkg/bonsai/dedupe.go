@@ -0,0 +1,168 @@
+package bonsai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// DefaultDuplicateThreshold is how similar two sibling branches must be for FindDuplicateBranches
+// to report them unless told otherwise
+const DefaultDuplicateThreshold = 0.9
+
+// DuplicateBranch is a branch that nearly repeats one of its siblings
+type DuplicateBranch struct {
+	Keep       *Node   // The sibling to keep: the one with the most nodes below it, then the oldest
+	Duplicate  *Node   // The sibling repeating it
+	Similarity float64 // From 0 to 1, where 1 means the two nodes hold the same text
+	KeepSize   int     // Nodes in the kept branch, counting its first node
+	Size       int     // Nodes in the duplicate branch, counting its first node
+}
+
+// FindDuplicateBranches finds sibling nodes below the given nodes, which include the nodes
+// themselves, whose content is at least threshold similar. Only the siblings themselves are compared,
+// not what followed them, so a regenerated response counts as a duplicate even if only one copy was
+// followed up. Each duplicate is paired with the sibling it's most like among those kept.
+func (s *Session) FindDuplicateBranches(nodeIDs []string, threshold float64) ([]DuplicateBranch, error) {
+	children := make(map[string][]*Node)
+	var parents []string
+	for _, nodeID := range nodeIDs {
+		nodes, err := s.db.GetNodeAndAllChildren(nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subtree %s: %w", nodeID, err)
+		}
+		for _, node := range nodes {
+			if node.ID == nodeID || node.Parent == nil {
+				continue
+			}
+			if _, ok := children[*node.Parent]; !ok {
+				parents = append(parents, *node.Parent)
+			}
+			children[*node.Parent] = append(children[*node.Parent], node)
+		}
+	}
+
+	// Count the nodes in each branch bottom-up, memoized as subtrees are shared
+	sizes := make(map[string]int)
+	var size func(node *Node) int
+	size = func(node *Node) int {
+		if n, ok := sizes[node.ID]; ok {
+			return n
+		}
+		n := 1
+		for _, child := range children[node.ID] {
+			n += size(child)
+		}
+		sizes[node.ID] = n
+		return n
+	}
+
+	var duplicates []DuplicateBranch
+	for _, parent := range parents {
+		siblings := children[parent]
+		if len(siblings) < 2 {
+			continue
+		}
+		shingles := make(map[string]map[string]bool, len(siblings))
+		for _, sibling := range siblings {
+			shingles[sibling.ID] = textShingles(sibling.Content)
+			size(sibling)
+		}
+		sort.SliceStable(siblings, func(i, j int) bool {
+			if sizes[siblings[i].ID] != sizes[siblings[j].ID] {
+				return sizes[siblings[i].ID] > sizes[siblings[j].ID]
+			}
+			return siblings[i].CreatedAt < siblings[j].CreatedAt
+		})
+
+		var kept []*Node
+		for _, sibling := range siblings {
+			var best *Node
+			bestSimilarity := 0.0
+			for _, keep := range kept {
+				if keep.Type != sibling.Type {
+					continue
+				}
+				similarity := 1.0
+				if keep.Content != sibling.Content {
+					similarity = jaccard(shingles[keep.ID], shingles[sibling.ID])
+				}
+				if similarity >= threshold && similarity > bestSimilarity {
+					best, bestSimilarity = keep, similarity
+				}
+			}
+			if best == nil {
+				kept = append(kept, sibling)
+				continue
+			}
+			duplicates = append(duplicates, DuplicateBranch{
+				Keep:       best,
+				Duplicate:  sibling,
+				Similarity: bestSimilarity,
+				KeepSize:   sizes[best.ID],
+				Size:       sizes[sibling.ID],
+			})
+		}
+	}
+	return duplicates, nil
+}
+
+// textShingles splits text into the set of runs of three consecutive words, lowercased, so that
+// reworded or reordered passages only count as partly the same. Texts of fewer words are one shingle.
+func textShingles(text string) map[string]bool {
+	words := strings.Fields(strings.ToLower(text))
+	shingles := make(map[string]bool)
+	if len(words) < 3 {
+		shingles[strings.Join(words, " ")] = true
+		return shingles
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		shingles[strings.Join(words[i:i+3], " ")] = true
+	}
+	return shingles
+}
+
+// jaccard returns the share of shingles two sets have in common, out of those in either
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for shingle := range a {
+		if b[shingle] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// MergeDuplicate deletes a duplicate branch's first node and moves everything that followed it below
+// the sibling it repeats, along with the current working node if it was the deleted node. Returns the
+// number of children moved.
+func (s *Session) MergeDuplicate(d DuplicateBranch) (int, error) {
+	moved, err := s.db.MergeNodeInto(d.Duplicate.ID, d.Keep.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge node: %w", err)
+	}
+	s.hooks.Fire(hooks.Prune, d.Duplicate)
+	return moved, nil
+}
+
+// PruneDuplicate deletes a duplicate branch and everything in it. If the current working node was in
+// it, the sibling it repeats becomes the current working node.
+func (s *Session) PruneDuplicate(d DuplicateBranch) (*PruneResult, error) {
+	result, err := s.Prune(d.Duplicate.ID, false)
+	if err != nil {
+		return result, err
+	}
+	if result.CurrentCleared {
+		if err := s.db.SetCurrentNode(d.Keep.ID); err != nil {
+			return result, fmt.Errorf("pruned branch but failed to move current node: %w", err)
+		}
+		result.CurrentCleared = false
+		result.CurrentMovedTo = &d.Keep.ID
+	}
+	return result, nil
+}
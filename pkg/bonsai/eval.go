@@ -0,0 +1,184 @@
+package bonsai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/google/uuid"
+)
+
+// EvalMetadataKey is the metadata key listing the comparisons a node has been judged in
+const EvalMetadataKey = "evals"
+
+// Outcomes of a comparison for one side
+const (
+	EvalWin  = "win"
+	EvalLoss = "loss"
+	EvalTie  = "tie"
+)
+
+// evalLine matches a "criterion: A|B|tie" line of a judge's verdict
+var evalLine = regexp.MustCompile(`(?i)^\W*([a-z][\w -]*?)\W*:\W*(a|b|tie)\b`)
+
+// evalReason matches the "reason:" line of a judge's verdict
+var evalReason = regexp.MustCompile(`(?i)^\W*reason\W*:\s*(.+)$`)
+
+// Verdict is a judge model's comparison of two nodes
+type Verdict struct {
+	ID       string
+	Judge    string
+	A, B     *Node
+	Winners  map[string]string // Criterion to "a", "b" or "tie"
+	Overall  string            // "a", "b" or "tie"
+	Reason   string
+	Criteria []string
+}
+
+// EvalAnnotation is one comparison as recorded on each of the compared nodes
+type EvalAnnotation struct {
+	ID        string            `json:"id"` // Shared by the annotations on both nodes
+	Judge     string            `json:"judge"`
+	Against   string            `json:"against"`  // The other node
+	Criteria  map[string]string `json:"criteria"` // Criterion to this node's outcome
+	Overall   string            `json:"overall"`
+	Reason    string            `json:"reason,omitempty"`
+	CreatedAt int64             `json:"created_at"`
+}
+
+// Evaluate asks the judge model which of two nodes is better on each criterion and overall, and
+// records the verdict as an EvalAnnotation on both nodes under EvalMetadataKey
+func (s *Session) Evaluate(ctx context.Context, a, b *Node, judge string, criteria []string) (*Verdict, error) {
+	var prompt strings.Builder
+	prompt.WriteString("You are judging two responses, A and B.\n\n")
+	writeEvalCandidate(&prompt, s, "A", a)
+	writeEvalCandidate(&prompt, s, "B", b)
+	prompt.WriteString("Compare them on each of these criteria, then overall. Reply with exactly one line per criterion, in the form \"criterion: A\", \"criterion: B\" or \"criterion: tie\", then \"overall: A|B|tie\", then \"reason:\" and one sentence explaining the overall verdict.\n\nCriteria:\n")
+	for _, criterion := range criteria {
+		fmt.Fprintf(&prompt, "- %s\n", criterion)
+	}
+
+	reply, err := s.Complete(ctx, judge, []llm.Message{{Role: "user", Content: prompt.String()}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verdict from %s: %w", judge, err)
+	}
+
+	verdict, err := parseVerdict(reply, criteria)
+	if err != nil {
+		return nil, fmt.Errorf("judge %s: %w", judge, err)
+	}
+	verdict.ID = uuid.New().String()
+	verdict.Judge = judge
+	verdict.A, verdict.B = a, b
+
+	now := time.Now().Unix()
+	for _, side := range []struct {
+		node, other *Node
+		self        string
+	}{{a, b, "a"}, {b, a, "b"}} {
+		annotation := EvalAnnotation{
+			ID:        verdict.ID,
+			Judge:     judge,
+			Against:   side.other.ID,
+			Criteria:  make(map[string]string, len(verdict.Winners)),
+			Overall:   evalOutcome(verdict.Overall, side.self),
+			Reason:    verdict.Reason,
+			CreatedAt: now,
+		}
+		for criterion, winner := range verdict.Winners {
+			annotation.Criteria[criterion] = evalOutcome(winner, side.self)
+		}
+		if err := s.db.AppendNodeMetadata(side.node.ID, EvalMetadataKey, annotation); err != nil {
+			return verdict, fmt.Errorf("failed to record verdict: %w", err)
+		}
+	}
+	return verdict, nil
+}
+
+// writeEvalCandidate adds a node, and the message it answers if it's a response, to a judge prompt
+func writeEvalCandidate(b *strings.Builder, s *Session, label string, node *Node) {
+	if node.Type == "llm" && node.Parent != nil {
+		if parent, err := s.db.GetNodeByID(*node.Parent); err == nil {
+			fmt.Fprintf(b, "<prompt_%s>\n%s\n</prompt_%s>\n\n", label, parent.Content, label)
+		}
+	}
+	fmt.Fprintf(b, "<response_%s>\n%s\n</response_%s>\n\n", label, node.Content, label)
+}
+
+// parseVerdict reads the per-criterion and overall winners from a judge's reply
+func parseVerdict(reply string, criteria []string) (*Verdict, error) {
+	verdict := &Verdict{Winners: make(map[string]string), Criteria: criteria}
+
+	wanted := make(map[string]string, len(criteria))
+	for _, criterion := range criteria {
+		wanted[strings.ToLower(criterion)] = criterion
+	}
+
+	for _, line := range strings.Split(reply, "\n") {
+		if match := evalReason.FindStringSubmatch(line); match != nil {
+			verdict.Reason = strings.TrimSpace(match[1])
+			continue
+		}
+		match := evalLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		name, winner := strings.ToLower(strings.TrimSpace(match[1])), strings.ToLower(match[2])
+		if name == "overall" {
+			verdict.Overall = winner
+		} else if criterion, ok := wanted[name]; ok {
+			verdict.Winners[criterion] = winner
+		}
+	}
+
+	if verdict.Overall == "" {
+		return nil, fmt.Errorf("no overall verdict in reply: %s", strings.TrimSpace(reply))
+	}
+	return verdict, nil
+}
+
+// evalOutcome converts a winner ("a", "b" or "tie") into the outcome for one side
+func evalOutcome(winner, self string) string {
+	switch winner {
+	case "tie":
+		return EvalTie
+	case self:
+		return EvalWin
+	default:
+		return EvalLoss
+	}
+}
+
+// Evals returns the comparisons a node has been judged in
+func Evals(node *Node) []EvalAnnotation {
+	values, _ := node.GetMetadata()[EvalMetadataKey].([]interface{})
+
+	annotations := make([]EvalAnnotation, 0, len(values))
+	for _, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var annotation EvalAnnotation
+		if err := json.Unmarshal(encoded, &annotation); err == nil {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations
+}
+
+// ParseCriteria splits a comma-separated list of criteria, dropping blanks
+func ParseCriteria(list string) []string {
+	var criteria []string
+	for _, criterion := range strings.Split(list, ",") {
+		if criterion = strings.TrimSpace(criterion); criterion != "" {
+			criteria = append(criteria, criterion)
+		}
+	}
+	return criteria
+}
@@ -0,0 +1,27 @@
+package bonsai
+
+import (
+	"fmt"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// Exclude marks a node, by full or abbreviated ID, to be left out of what's sent to models, or with
+// excluded false includes it again. The node and everything below it stay in the tree; replies below
+// it carry on the conversation without it.
+func (s *Session) Exclude(nodeID string, excluded bool) (*Node, error) {
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if excluded {
+		err = s.db.SetNodeMetadata(node.ID, db.ExcludedMetadataKey, true)
+	} else {
+		err = s.db.DeleteNodeMetadata(node.ID, db.ExcludedMetadataKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark node %s: %w", node.ID, err)
+	}
+	return s.db.GetNodeByID(node.ID)
+}
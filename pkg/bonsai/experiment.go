@@ -0,0 +1,275 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+const (
+	// ExperimentMetadataKey is the metadata key recording the prompt variant and model of an experiment's message
+	ExperimentMetadataKey = "experiment"
+
+	// JudgeMetadataKey is the metadata key recording a judge model's score for a response
+	JudgeMetadataKey = "judge"
+)
+
+// DefaultJudgeCriteria is what a judge scores responses on unless told otherwise
+const DefaultJudgeCriteria = "accuracy, helpfulness and clarity"
+
+// judgeScore finds the score in a judge's reply
+var judgeScore = regexp.MustCompile(`(?i)score\W*(10|[1-9])\b`)
+
+// ExperimentOptions configures RunExperiment
+type ExperimentOptions struct {
+	Concurrency     int                // Requests made at once; zero uses the session pool's workers
+	Timeout         time.Duration      // Bound on each response and verdict; zero leaves it to ctx
+	Judge           string             // Model that scores each response from 1 to 10; empty skips judging
+	Criteria        string             // What the judge scores on; empty uses DefaultJudgeCriteria
+	OnResult        func(BatchResult)  // Called as each response finishes, never concurrently
+	OnProgress      func(llm.Progress) // Called as each response finishes, never concurrently
+	OnJudgeProgress func(llm.Progress) // Called as each verdict finishes, never concurrently
+}
+
+// ExperimentRun is one combination of prompt variant and model in an experiment
+type ExperimentRun struct {
+	Variant  int // Index into Experiment.Variants
+	Model    string
+	Message  *Node
+	Response *Node
+	Err      error
+	Score    int    // The judge's score from 1 to 10, or 0 if not judged
+	Verdict  string // The judge's reasoning
+}
+
+// Experiment is the outcome of running every prompt variant against every model
+type Experiment struct {
+	Parent   *Node
+	Variants []string
+	Models   []string
+	Judge    string
+	Runs     []ExperimentRun // Grouped by variant, in the order the models were given
+}
+
+// RunExperiment adds every combination of prompt variant and model as sibling branches below the
+// parent and generates the responses concurrently, leaving the current working node where it is.
+// With a judge model, each response is then scored and the score stored in its metadata under
+// JudgeMetadataKey.
+func (s *Session) RunExperiment(ctx context.Context, parent *Node, variants, models []string, opts ExperimentOptions) (*Experiment, error) {
+	if len(variants) == 0 || len(models) == 0 {
+		return nil, fmt.Errorf("an experiment needs at least one prompt variant and one model")
+	}
+	for _, model := range append(slices.Clone(models), opts.Judge) {
+		if err := s.checkModel(model); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]batchItem, 0, len(variants)*len(models))
+	for _, variant := range variants {
+		for _, model := range models {
+			items = append(items, batchItem{prompt: variant, model: optionalModel(model)})
+		}
+	}
+
+	results := s.batch(ctx, parent, items, BatchOptions{
+		Concurrency: opts.Concurrency,
+		Timeout:     opts.Timeout,
+		OnResult:    opts.OnResult,
+		OnProgress:  opts.OnProgress,
+	})
+
+	exp := &Experiment{Parent: parent, Variants: variants, Models: models, Judge: opts.Judge}
+	for i, result := range results {
+		run := ExperimentRun{
+			Variant:  i / len(models),
+			Model:    models[i%len(models)],
+			Message:  result.Message,
+			Response: result.Response,
+			Err:      result.Err,
+		}
+		if run.Message != nil {
+			metadata := map[string]interface{}{"variant": run.Variant + 1, "model": run.Model}
+			if err := s.db.SetNodeMetadata(run.Message.ID, ExperimentMetadataKey, metadata); err != nil && run.Err == nil {
+				run.Err = fmt.Errorf("failed to record experiment: %w", err)
+			}
+		}
+		exp.Runs = append(exp.Runs, run)
+	}
+
+	if opts.Judge != "" {
+		criteria := opts.Criteria
+		if criteria == "" {
+			criteria = DefaultJudgeCriteria
+		}
+
+		s.pool.Run(len(exp.Runs), opts.Concurrency, func(i int) error {
+			run := &exp.Runs[i]
+			if run.Response == nil {
+				return nil
+			}
+			err := s.judgeRun(ctx, opts.Judge, criteria, opts.Timeout, run)
+			if err != nil && run.Err == nil {
+				run.Err = err
+			}
+			return err
+		}, opts.OnJudgeProgress)
+	}
+
+	return exp, nil
+}
+
+// judgeRun asks the judge model to score a run's response and records the score on the response
+func (s *Session) judgeRun(ctx context.Context, judge, criteria string, timeout time.Duration, run *ExperimentRun) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	prompt := fmt.Sprintf(`You are judging the response to a prompt on %s.
+
+<prompt>
+%s
+</prompt>
+
+<response>
+%s
+</response>
+
+Explain your assessment in a sentence or two, then end with a final line of the form "Score: N", where N is from 1 (poor) to 10 (excellent).`,
+		criteria, run.Message.Content, run.Response.Content)
+
+	verdict, err := s.Complete(ctx, judge, []llm.Message{{Role: "user", Content: prompt}})
+	if err != nil {
+		return fmt.Errorf("failed to get verdict from %s: %w", judge, err)
+	}
+
+	matches := judgeScore.FindAllStringSubmatch(verdict, -1)
+	if len(matches) == 0 {
+		return fmt.Errorf("judge %s gave no score", judge)
+	}
+	run.Score, _ = strconv.Atoi(matches[len(matches)-1][1])
+	run.Verdict = strings.TrimSpace(judgeScore.ReplaceAllString(verdict, ""))
+
+	metadata := map[string]interface{}{"model": judge, "criteria": criteria, "score": run.Score, "verdict": run.Verdict}
+	if err := s.db.SetNodeMetadata(run.Response.ID, JudgeMetadataKey, metadata); err != nil {
+		return fmt.Errorf("failed to record verdict: %w", err)
+	}
+	return nil
+}
+
+// Report renders the experiment as a Markdown comparison of every run, with average scores per
+// variant and per model when the runs were judged
+func (e *Experiment) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Experiment below %s\n\n", shortID(e.Parent.ID))
+	fmt.Fprintf(&b, "%d prompt variant(s) × %d model(s)", len(e.Variants), len(e.Models))
+	if e.Judge != "" {
+		fmt.Fprintf(&b, ", judged by %s", e.Judge)
+	}
+	b.WriteString("\n\n## Variants\n\n")
+	for i, variant := range e.Variants {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, reportCell(variant, 200))
+	}
+
+	b.WriteString("\n## Results\n\n")
+	b.WriteString("| Variant | Model | Node | Tokens |")
+	if e.Judge != "" {
+		b.WriteString(" Score |")
+	}
+	b.WriteString(" Response |\n|---|---|---|---|")
+	if e.Judge != "" {
+		b.WriteString("---|")
+	}
+	b.WriteString("---|\n")
+
+	for _, run := range e.Runs {
+		node, tokens, response := "-", "-", ""
+		switch {
+		case run.Response != nil:
+			node = shortID(run.Response.ID)
+			tokens = strconv.Itoa(llm.EstimateTokens(run.Response.Content))
+			response = reportCell(run.Response.Content, 120)
+		case run.Err != nil:
+			response = "❌ " + reportCell(run.Err.Error(), 120)
+		}
+		if run.Response == nil && run.Message != nil {
+			node = shortID(run.Message.ID)
+		}
+
+		fmt.Fprintf(&b, "| %d | %s | %s | %s |", run.Variant+1, run.Model, node, tokens)
+		if e.Judge != "" {
+			score := "-"
+			if run.Score > 0 {
+				score = strconv.Itoa(run.Score)
+			}
+			fmt.Fprintf(&b, " %s |", score)
+		}
+		fmt.Fprintf(&b, " %s |\n", response)
+	}
+
+	if e.Judge != "" {
+		byVariant := make(map[string][]int)
+		byModel := make(map[string][]int)
+		for _, run := range e.Runs {
+			if run.Score > 0 {
+				variant := strconv.Itoa(run.Variant + 1)
+				byVariant[variant] = append(byVariant[variant], run.Score)
+				byModel[run.Model] = append(byModel[run.Model], run.Score)
+			}
+		}
+		writeAverages(&b, "Variant", byVariant)
+		writeAverages(&b, "Model", byModel)
+	}
+	return b.String()
+}
+
+// writeAverages adds a table of average scores per group, best first
+func writeAverages(b *strings.Builder, group string, scores map[string][]int) {
+	if len(scores) == 0 {
+		return
+	}
+
+	type average struct {
+		name  string
+		score float64
+		runs  int
+	}
+	averages := make([]average, 0, len(scores))
+	for name, values := range scores {
+		total := 0
+		for _, value := range values {
+			total += value
+		}
+		averages = append(averages, average{name, float64(total) / float64(len(values)), len(values)})
+	}
+	sort.Slice(averages, func(i, j int) bool {
+		if averages[i].score != averages[j].score {
+			return averages[i].score > averages[j].score
+		}
+		return averages[i].name < averages[j].name
+	})
+
+	fmt.Fprintf(b, "\n## Average score by %s\n\n| %s | Score | Runs |\n|---|---|---|\n", strings.ToLower(group), group)
+	for _, avg := range averages {
+		fmt.Fprintf(b, "| %s | %.1f | %d |\n", avg.name, avg.score, avg.runs)
+	}
+}
+
+// reportCell flattens text onto one line and shortens it to fit in a Markdown table cell
+func reportCell(text string, maxLen int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	text = strings.ReplaceAll(text, "|", "\\|")
+	if len([]rune(text)) > maxLen {
+		text = string([]rune(text)[:maxLen-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,248 @@
+package bonsai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/config"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// Turn is a user message and the model's reply to it
+type Turn struct {
+	Message  *Node
+	Response *Node // nil if no model was available to reply
+}
+
+// Complete sends the messages to the given model and returns the response text. The provider's
+// API key is read from the environment.
+func Complete(ctx context.Context, model string, messages []llm.Message) (string, error) {
+	client, err := config.NewClientForModel(model)
+	if err != nil {
+		return "", err
+	}
+	return client.GenerateResponseFromHistory(ctx, messages, model)
+}
+
+// UseCache makes the session answer generations it has made before from the database's response
+// cache, and cache new ones, so re-running identical conversations returns instantly and costs nothing
+func (s *Session) UseCache(enabled bool) {
+	s.cache = enabled
+}
+
+// Complete sends the messages to the given model like the package's Complete, with the generation
+// parameters configured in the database or the session's profile, using the response cache if the session does. It waits
+// for the session's pool to have room for another request to the model's provider first. If ctx
+// carries an llm.StreamHandler, the response is streamed to it as it arrives.
+func (s *Session) Complete(ctx context.Context, model string, messages []llm.Message) (string, error) {
+	settings, err := s.db.GetGenerationSettings()
+	if err != nil {
+		return "", err
+	}
+	return s.complete(ctx, model, messages, settings)
+}
+
+// CompleteInTree is Complete with the generation parameters of the tree containing the given node,
+// for a response that will be stored in that tree
+func (s *Session) CompleteInTree(ctx context.Context, nodeID, model string, messages []llm.Message) (string, error) {
+	settings, err := s.db.GetTreeGenerationSettings(nodeID)
+	if err != nil {
+		return "", err
+	}
+	return s.complete(ctx, model, messages, settings)
+}
+
+// complete is Complete with the given generation parameters
+func (s *Session) complete(ctx context.Context, model string, messages []llm.Message, settings *db.GenerationSettings) (string, error) {
+	rules, err := s.providerRules()
+	if err != nil {
+		return "", err
+	}
+	release, err := s.pool.Acquire(ctx, llm.RouteModel(model, rules))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if s.profile != nil {
+		settings = s.profile.Override(settings)
+	}
+	options := config.ClientOptions{
+		MaxTokens:      settings.MaxTokens,
+		Temperature:    settings.Temperature,
+		System:         settings.System,
+		ConnectTimeout: settings.ConnectTimeout,
+		ForceModel:     s.force,
+		ProviderRules:  rules,
+	}
+	if s.cache {
+		options.Cache = s.db
+	}
+	client, err := config.NewClientWithOptions(model, options)
+	if err != nil {
+		return "", err
+	}
+	if onChunk := llm.StreamHandlerFrom(ctx); onChunk != nil {
+		return client.StreamResponseFromHistory(ctx, messages, model, onChunk)
+	}
+	return client.GenerateResponseFromHistory(ctx, messages, model)
+}
+
+// CheckGeneration checks generating the model's reply to the conversation ending at the given node
+// against what the model is known to accept, as llm.CheckRequest does, returning a warning for each
+// problem, such as history too long for the model's context window. Nothing is sent to the model.
+func (s *Session) CheckGeneration(node *Node, model string) ([]string, error) {
+	messages, settings, err := s.request(node)
+	if err != nil {
+		return nil, err
+	}
+	if s.profile != nil {
+		settings = s.profile.Override(settings)
+	}
+
+	maxTokens := settings.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = config.DefaultMaxTokens
+	}
+	return llm.CheckRequest(model, messages, settings.System, maxTokens, settings.Temperature), nil
+}
+
+// request returns the messages sent to generate a reply to the conversation ending at the given
+// node, with the generation parameters of the node's tree and only the part of it the session's
+// ContextWindow allows
+func (s *Session) request(node *Node) ([]llm.Message, *db.GenerationSettings, error) {
+	history, err := s.db.GetConversationHistory(node.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get conversation history: %w", err)
+	}
+	settings, err := s.db.GetTreeGenerationSettings(node.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if history, err = s.window.apply(s, history); err != nil {
+		return nil, nil, err
+	}
+
+	history = settings.ModelHistory(history)
+	pinned, err := s.db.GetPinnedContext(node.ID, history)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messages := make([]llm.Message, 0, len(history)+1)
+	if len(pinned) > 0 {
+		messages = append(messages, llm.ContextMessage(pinned))
+	}
+	for _, historyNode := range history {
+		messages = append(messages, llm.NodeToMessage(historyNode.Type, historyNode.Content))
+	}
+	return messages, settings, nil
+}
+
+// Say appends a user message below the current working node and, if a model is given or inherited,
+// stores the model's reply as its child. The reply becomes the current working node.
+func (s *Session) Say(ctx context.Context, message, model string) (*Turn, error) {
+	node, err := s.Append(message, model)
+	if err != nil {
+		return nil, err
+	}
+
+	turn := &Turn{Message: node}
+	if node.Model == nil || *node.Model == "" {
+		return turn, nil
+	}
+
+	turn.Response, err = s.Respond(ctx, node, *node.Model)
+	if err != nil {
+		return turn, err
+	}
+	return turn, nil
+}
+
+// Respond generates the model's reply to the conversation ending at the given node and stores it
+// as a new child of that node. The reply becomes the current working node if the given node was.
+func (s *Session) Respond(ctx context.Context, node *Node, model string) (*Node, error) {
+	ctx, generation := llm.WithGeneration(ctx)
+	response, err := s.Generate(ctx, node, model)
+	if err != nil {
+		return nil, err
+	}
+
+	llmNode, err := s.db.AddChildNode(response, node.ID, "llm", &model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM response node: %w", err)
+	}
+	if err := s.recordGeneration(llmNode, generation); err != nil && s.hooks.OnError != nil {
+		s.hooks.OnError(hooks.ResponseReceived, err)
+	}
+
+	s.Notify(hooks.ResponseReceived, llmNode)
+	return llmNode, nil
+}
+
+// Generate returns the model's reply to the conversation ending at the given node without storing it,
+// with the generation parameters of the node's tree and only the part of it the session's
+// ContextWindow allows
+func (s *Session) Generate(ctx context.Context, node *Node, model string) (string, error) {
+	messages, settings, err := s.request(node)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := s.complete(ctx, model, messages, settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to get LLM response: %w", err)
+	}
+	return response, nil
+}
+
+// AddResponse stores an already generated reply as a new child of the given node, leaving the
+// current working node where it is. The generation, if not nil, is recorded on the new node, as
+// Respond does; pass the one llm.WithGeneration returned for the context the reply was generated with.
+func (s *Session) AddResponse(parent *Node, content, model string, generation *llm.Generation) (*Node, error) {
+	llmNode, err := s.db.CreateBranchNode(content, parent.ID, "llm", &model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM response node: %w", err)
+	}
+	if generation != nil {
+		if err := s.recordGeneration(llmNode, generation); err != nil && s.hooks.OnError != nil {
+			s.hooks.OnError(hooks.ResponseReceived, err)
+		}
+	}
+
+	s.Notify(hooks.ResponseReceived, llmNode)
+	return llmNode, nil
+}
+
+// recordGeneration stores the parameters a response was generated with on its node
+func (s *Session) recordGeneration(node *Node, generation *llm.Generation) error {
+	if err := s.db.SetNodeMetadata(node.ID, db.GenerationMetadataKey, generation); err != nil {
+		return fmt.Errorf("failed to record generation parameters for node %s: %w", node.ID, err)
+	}
+	updated, err := s.db.GetNodeByID(node.ID)
+	if err != nil {
+		return err
+	}
+	node.Metadata = updated.Metadata
+	return nil
+}
+
+// GenerationOf returns the parameters an LLM response was generated with, or nil if they weren't recorded
+func GenerationOf(node *Node) *llm.Generation {
+	value, ok := node.GetMetadata()[db.GenerationMetadataKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var generation llm.Generation
+	if err := json.Unmarshal(data, &generation); err != nil {
+		return nil
+	}
+	return &generation
+}
@@ -0,0 +1,165 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// GitMetadataKey is the metadata key recording the git commit checked out where a node was created
+const GitMetadataKey = "git"
+
+// CommitsMetadataKey is the metadata key listing the commits linked to a node with LinkCommit
+const CommitsMetadataKey = "commits"
+
+// ErrNotGitRepo is returned when a directory isn't inside a git repository
+var ErrNotGitRepo = errors.New("not inside a git repository")
+
+// GitRef identifies a commit in a local git repository
+type GitRef struct {
+	Repo     string `json:"repo"`             // Top-level directory of the repository
+	Remote   string `json:"remote,omitempty"` // URL of the origin remote, if there is one
+	Branch   string `json:"branch,omitempty"` // Empty when HEAD is detached
+	Commit   string `json:"commit"`
+	LinkedAt int64  `json:"linked_at,omitempty"` // Unix seconds, set for commits linked with LinkCommit
+}
+
+// String describes the ref as "repo@branch abcdef123456"
+func (r GitRef) String() string {
+	name := filepath.Base(r.Repo)
+	if r.Branch != "" {
+		name += "@" + r.Branch
+	}
+	commit := r.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	return name + " " + commit
+}
+
+// ResolveGitRef resolves a revision such as "HEAD" or an abbreviated SHA in the repository
+// containing dir. It returns ErrNotGitRepo if dir isn't in one or git isn't installed.
+func ResolveGitRef(dir, rev string) (*GitRef, error) {
+	repo, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotGitRepo
+	}
+
+	commit, err := git(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		// A repository without commits yet has nothing to link to
+		return nil, fmt.Errorf("unknown commit %s in %s", rev, repo)
+	}
+
+	ref := &GitRef{Repo: repo, Commit: commit}
+	ref.Remote, _ = git(dir, "remote", "get-url", "origin")
+	if rev == "HEAD" {
+		ref.Branch, _ = git(dir, "symbolic-ref", "--short", "--quiet", "HEAD")
+	}
+	return ref, nil
+}
+
+// git runs a git command in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CaptureGit makes the session record the commit checked out in dir's git repository on every
+// node it creates, under GitMetadataKey. Nodes created outside a repository are left as they are.
+func (s *Session) CaptureGit(dir string) {
+	s.gitDir = dir
+}
+
+// Notify records what the session captures about a node it created and runs the event's hooks.
+// Use it for changes made directly through Database so they're treated like the session's own.
+func (s *Session) Notify(event hooks.Event, node *Node) {
+	if s.gitDir != "" && node != nil && event != hooks.Prune {
+		if err := s.recordGit(node); err != nil && s.hooks.OnError != nil {
+			s.hooks.OnError(event, err)
+		}
+	}
+	s.hooks.Fire(event, node)
+}
+
+// recordGit stores the commit checked out in the session's git directory on the node
+func (s *Session) recordGit(node *Node) error {
+	ref, err := ResolveGitRef(s.gitDir, "HEAD")
+	if err != nil {
+		// Outside a repository, or in one without commits, there's nothing to record
+		return nil
+	}
+
+	if err := s.db.SetNodeMetadata(node.ID, GitMetadataKey, ref); err != nil {
+		return fmt.Errorf("failed to record git commit for node %s: %w", node.ID, err)
+	}
+	updated, err := s.db.GetNodeByID(node.ID)
+	if err != nil {
+		return err
+	}
+	node.Metadata = updated.Metadata
+	return nil
+}
+
+// LinkCommit links a commit to a node, e.g. the commit that implemented what it discusses.
+// Linked commits are listed under CommitsMetadataKey; linking the same commit again does nothing.
+func (s *Session) LinkCommit(node *Node, ref GitRef) error {
+	for _, linked := range LinkedCommits(node) {
+		if linked.Commit == ref.Commit {
+			return nil
+		}
+	}
+
+	ref.LinkedAt = time.Now().Unix()
+	if err := s.db.AppendNodeMetadata(node.ID, CommitsMetadataKey, ref); err != nil {
+		return fmt.Errorf("failed to link commit: %w", err)
+	}
+	return nil
+}
+
+// CreatedAtCommit returns the commit recorded when the node was created, or nil if none was
+func CreatedAtCommit(node *Node) *GitRef {
+	value, ok := node.GetMetadata()[GitMetadataKey]
+	if !ok {
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var ref GitRef
+	if err := json.Unmarshal(encoded, &ref); err != nil || ref.Commit == "" {
+		return nil
+	}
+	return &ref
+}
+
+// LinkedCommits returns the commits linked to a node with LinkCommit, oldest first
+func LinkedCommits(node *Node) []GitRef {
+	values, _ := node.GetMetadata()[CommitsMetadataKey].([]interface{})
+
+	refs := make([]GitRef, 0, len(values))
+	for _, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var ref GitRef
+		if err := json.Unmarshal(encoded, &ref); err == nil {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,150 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSourceSize caps how much of a file or web page is ingested into a node
+const maxSourceSize = 5 << 20
+
+var (
+	// hiddenElements match elements whose content is never part of the readable text of a page
+	hiddenElements = hiddenElementPatterns("script", "style", "noscript", "template", "svg", "head", "nav", "header", "footer", "aside", "form")
+
+	// comments matches HTML comments
+	comments = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// blockTags matches tags that start a new line of text
+	blockTags = regexp.MustCompile(`(?i)<(?:br|/?p|/?div|/?section|/?article|/?li|/?tr|/?h[1-6]|/?blockquote|/?pre|/?ul|/?ol|/?table)\b[^>]*>`)
+
+	// anyTag matches any remaining tag
+	anyTag = regexp.MustCompile(`(?s)<[^>]*>`)
+
+	// blankLines matches runs of lines with nothing but whitespace
+	blankLines = regexp.MustCompile(`\n(?:[ \t]*\n){2,}`)
+
+	// spaceRuns matches runs of horizontal whitespace
+	spaceRuns = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// ReadSourceFile reads a local file to use as node content. HTML files are reduced to their readable
+// text unless raw is set.
+func ReadSourceFile(path string, raw bool) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	content, err := readSource(file, path)
+	if err != nil {
+		return "", err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !raw && (ext == ".html" || ext == ".htm") {
+		content = ExtractText(content)
+	}
+	return content, nil
+}
+
+// FetchSourceURL downloads a web page to use as node content. HTML pages are reduced to their
+// readable text unless raw is set.
+func FetchSourceURL(ctx context.Context, url string, raw bool) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", url, err)
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, */*;q=0.5")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+
+	content, err := readSource(resp.Body, url)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !raw && (mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+		content = ExtractText(content)
+	}
+	return content, nil
+}
+
+// readSource reads up to maxSourceSize bytes of text from r, naming the source in errors
+func readSource(r io.Reader, name string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSourceSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxSourceSize {
+		return "", fmt.Errorf("%s is larger than %d MB", name, maxSourceSize>>20)
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("%s is not UTF-8 text", name)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return "", fmt.Errorf("%s is empty", name)
+	}
+	return content, nil
+}
+
+// ExtractText reduces an HTML document to its readable text, dropping scripts, styles and page
+// chrome such as navigation, headers and footers
+func ExtractText(document string) string {
+	// Prefer the main content of the page when it's marked up
+	for _, tag := range []string{"article", "main"} {
+		if start := strings.Index(strings.ToLower(document), "<"+tag); start >= 0 {
+			if end := strings.LastIndex(strings.ToLower(document), "</"+tag); end > start {
+				document = document[start:end]
+				break
+			}
+		}
+	}
+
+	text := comments.ReplaceAllString(document, "")
+	for _, element := range hiddenElements {
+		text = element.ReplaceAllString(text, "")
+	}
+	text = blockTags.ReplaceAllString(text, "\n")
+	text = anyTag.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRuns.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+
+	return strings.TrimSpace(text)
+}
+
+// hiddenElementPatterns returns a pattern matching each of the given elements and their content
+func hiddenElementPatterns(tags ...string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(tags))
+	for i, tag := range tags {
+		patterns[i] = regexp.MustCompile(`(?is)<` + tag + `\b[^>]*>.*?</` + tag + `\s*>`)
+	}
+	return patterns
+}
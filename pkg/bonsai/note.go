@@ -0,0 +1,46 @@
+package bonsai
+
+import (
+	"fmt"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// Note adds a note below the current working node and moves to it, or below the given parent,
+// leaving the current working node where it is unless it was the parent. Notes have no model, so
+// nothing responds to them, and they're left out of what's sent to models unless
+// generate.include_notes is on.
+func (s *Session) Note(text, parentID string) (*Node, error) {
+	return s.addWithoutModel(text, parentID, "note")
+}
+
+// System adds a system prompt below the current working node, or below the given parent, like Note.
+// It's sent to models as a system message ahead of the rest of the conversation below it, after any
+// configured system prompt.
+func (s *Session) System(prompt, parentID string) (*Node, error) {
+	return s.addWithoutModel(prompt, parentID, "system")
+}
+
+// addWithoutModel adds a node of the given type that nothing responds to, as Note describes
+func (s *Session) addWithoutModel(content, parentID, nodeType string) (*Node, error) {
+	var (
+		parent *Node
+		err    error
+	)
+	if parentID != "" {
+		parent, err = s.Node(parentID)
+	} else {
+		parent, err = s.Current()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := s.db.AddChildNode(content, parent.ID, nodeType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s node: %w", nodeType, err)
+	}
+
+	s.Notify(hooks.NodeCreated, node)
+	return node, nil
+}
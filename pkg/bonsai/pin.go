@@ -0,0 +1,97 @@
+package bonsai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PinnedNode is a node pinned to a tree, which may since have been deleted
+type PinnedNode struct {
+	ID   string
+	Node *Node // nil if the node was deleted
+}
+
+// Pin pins a node, by full or abbreviated ID and from any tree, to the tree holding the current
+// working node, so its content is sent ahead of every conversation in the tree wherever the branch
+// is. Returns the pinned node and false if it already was pinned.
+func (s *Session) Pin(nodeID string) (*Node, bool, error) {
+	rootID, err := s.currentRootOrErr()
+	if err != nil {
+		return nil, false, err
+	}
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	added, err := s.db.PinNode(rootID, node.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to pin node %s: %w", node.ID, err)
+	}
+	return node, added, nil
+}
+
+// Unpin unpins a node from the tree holding the current working node. The ID may be abbreviated
+// even if the node was deleted since it was pinned. Returns the full ID of the unpinned node.
+func (s *Session) Unpin(nodeID string) (string, error) {
+	rootID, err := s.currentRootOrErr()
+	if err != nil {
+		return "", err
+	}
+	_, ids, err := s.db.GetPinnedIDs(rootID)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, nodeID) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no node matching %s is pinned to this tree", nodeID)
+	case 1:
+	default:
+		return "", fmt.Errorf("%s matches %d pinned nodes; use more of the ID", nodeID, len(matches))
+	}
+
+	if _, err := s.db.UnpinNode(rootID, matches[0]); err != nil {
+		return "", fmt.Errorf("failed to unpin node %s: %w", matches[0], err)
+	}
+	return matches[0], nil
+}
+
+// Pinned returns the nodes pinned to the tree holding the current working node, in the order they
+// were pinned
+func (s *Session) Pinned() ([]PinnedNode, error) {
+	rootID, err := s.currentRootOrErr()
+	if err != nil {
+		return nil, err
+	}
+	_, ids, err := s.db.GetPinnedIDs(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make([]PinnedNode, 0, len(ids))
+	for _, id := range ids {
+		node, _ := s.db.GetNodeByID(id)
+		pinned = append(pinned, PinnedNode{ID: id, Node: node})
+	}
+	return pinned, nil
+}
+
+// currentRootOrErr returns the root of the tree holding the current working node, or
+// ErrNoCurrentNode if there's none
+func (s *Session) currentRootOrErr() (string, error) {
+	rootID, err := s.CurrentRootID()
+	if err != nil {
+		return "", err
+	}
+	if rootID == "" {
+		return "", ErrNoCurrentNode
+	}
+	return rootID, nil
+}
@@ -0,0 +1,67 @@
+package bonsai
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// PresetInputVariable is the variable of a preset's message scaffold the seed's content fills in
+const PresetInputVariable = "input"
+
+// PresetMetadataKey is the metadata key of a root node naming the preset its tree was started with
+const PresetMetadataKey = "preset"
+
+// PresetMessage fills in the message scaffold of a preset with the seed's content: in place of
+// {{input}}, or after the scaffold if it has no {{input}}. Other variables are filled in from values.
+// Without a scaffold, the message is just the content.
+func PresetMessage(preset *db.Preset, content string, values map[string]string) (string, error) {
+	if preset.Message == "" {
+		return content, nil
+	}
+
+	filled := make(map[string]string, len(values)+1)
+	for name, value := range values {
+		filled[name] = value
+	}
+	usesInput := slices.Contains(TemplateVariables(preset.Message), PresetInputVariable)
+	if content != "" || !usesInput {
+		filled[PresetInputVariable] = content
+	}
+
+	message, err := RenderTemplate(preset.Message, filled)
+	if err != nil {
+		return "", err
+	}
+	if content != "" && !usesInput {
+		message += "\n\n" + content
+	}
+	return message, nil
+}
+
+// ApplyPreset gives the tree rooted at rootID the settings of a preset, so every reply in it is
+// generated with the preset's system prompt and parameters, and records the preset's name on the root
+func (s *Session) ApplyPreset(rootID, name string, preset *db.Preset) error {
+	settings := make(map[string]string)
+	if preset.System != "" {
+		settings[db.TreeSystemSetting] = preset.System
+	}
+	if preset.Temperature != nil {
+		settings[db.TreeTemperatureSetting] = strconv.FormatFloat(*preset.Temperature, 'g', -1, 64)
+	}
+	if preset.MaxTokens > 0 {
+		settings[db.TreeMaxTokensSetting] = strconv.Itoa(preset.MaxTokens)
+	}
+
+	for key, value := range settings {
+		if err := s.db.SetTreeSetting(rootID, key, value); err != nil {
+			return fmt.Errorf("failed to apply preset %s: %w", name, err)
+		}
+	}
+	if err := s.db.SetNodeMetadata(rootID, PresetMetadataKey, name); err != nil {
+		return fmt.Errorf("failed to record preset %s: %w", name, err)
+	}
+	return nil
+}
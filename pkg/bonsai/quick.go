@@ -0,0 +1,63 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboxConfigKey is the setting holding the node quick captures are added below
+const InboxConfigKey = "quick.inbox"
+
+// inboxTitle is the content of the inbox tree's root when Inbox creates it
+const inboxTitle = "Inbox"
+
+// Inbox returns the node quick captures are added below: the one the quick.inbox setting names, or
+// else a new "Inbox" tree, which is recorded in the setting. The inbox is created again if its tree
+// was pruned. The current working node isn't moved.
+func (s *Session) Inbox() (*Node, error) {
+	id, err := s.db.GetConfigValue(InboxConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if id != nil && *id != "" {
+		if node, err := s.Node(*id); err == nil {
+			return node, nil
+		}
+	}
+
+	root := &Node{ID: uuid.New().String(), Content: inboxTitle, Type: "user", Children: "[]"}
+	if err := s.db.InsertNode(root); err != nil {
+		return nil, fmt.Errorf("failed to create inbox: %w", err)
+	}
+	if err := s.db.SetConfigValue(InboxConfigKey, root.ID); err != nil {
+		return nil, fmt.Errorf("failed to record inbox: %w", err)
+	}
+	return root, nil
+}
+
+// Quick adds a prompt as a new branch below the inbox and generates the reply with the given or
+// inherited model, within timeout if it's not zero. The current working node isn't moved. It fails
+// if there's no model, since a quick capture is asked for its answer.
+func (s *Session) Quick(ctx context.Context, prompt, model string, timeout time.Duration) (*Turn, error) {
+	inbox, err := s.Inbox()
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		if model, err = s.InheritedModel(inbox); err != nil {
+			return nil, err
+		}
+		if model == "" {
+			return nil, fmt.Errorf("no model to answer with: pass one, or set the generate.model setting")
+		}
+	}
+
+	result := s.Batch(ctx, inbox, []string{prompt}, BatchOptions{Model: model, Timeout: timeout})[0]
+	if result.Message == nil {
+		return nil, result.Err
+	}
+	return &Turn{Message: result.Message, Response: result.Response}, result.Err
+}
@@ -0,0 +1,76 @@
+package bonsai
+
+import "fmt"
+
+// RedactResult describes the secrets Redact scrubbed, or would scrub with dryRun
+type RedactResult struct {
+	Nodes  []*Node        // Nodes that contained secrets, with their content as redacted
+	Counts map[string]int // Secrets replaced, by rule name
+}
+
+// Redact scrubs secrets matching the redaction rules from the content of a node that's already
+// stored, and from all of its descendants if subtree is set. The database is vacuumed afterwards so
+// the secrets don't linger in free pages or the write-ahead log.
+func (s *Session) Redact(nodeID string, subtree, dryRun bool) (*RedactResult, error) {
+	var nodes []*Node
+	if subtree {
+		all, err := s.db.GetNodeAndAllChildren(nodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subtree %s: %w", nodeID, err)
+		}
+		nodes = all
+	} else {
+		node, err := s.db.GetNodeByID(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		nodes = []*Node{node}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("node with ID %s not found", nodeID)
+	}
+
+	redactor, err := s.db.Redactor()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RedactResult{Counts: make(map[string]int)}
+	for _, node := range nodes {
+		content, counts := redactor.Redact(node.Content)
+		if len(counts) == 0 {
+			continue
+		}
+		for rule, count := range counts {
+			result.Counts[rule] += count
+		}
+		node.Content = content
+		result.Nodes = append(result.Nodes, node)
+
+		if !dryRun {
+			if err := s.db.UpdateNodeContent(node.ID, content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(result.Nodes) > 0 && !dryRun {
+		if err := s.db.Vacuum(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// redactForExport scrubs secrets from nodes about to leave the database, such as in a shared
+// transcript or a bundle, unless redaction on export is turned off. The nodes are changed in place.
+func (s *Session) redactForExport(nodes []*Node) error {
+	redactor, err := s.db.ExportRedactor()
+	if err != nil || redactor == nil {
+		return err
+	}
+	for _, node := range nodes {
+		node.Content, _ = redactor.Redact(node.Content)
+	}
+	return nil
+}
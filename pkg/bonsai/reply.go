@@ -0,0 +1,121 @@
+package bonsai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// QuotesMetadataKey is the metadata key listing the IDs of the nodes a message quotes
+const QuotesMetadataKey = "quotes"
+
+// maxQuoteLength is how many characters of a quoted node are embedded in a message
+const maxQuoteLength = 500
+
+// ReplyOptions configures a Reply
+type ReplyOptions struct {
+	Parent  string   // Node to reply to, by full or abbreviated ID; empty replies to the current working node
+	Model   string   // Model for the message; empty inherits one as InheritedModel does
+	NoModel bool     // Leave the message without a model, even an inherited one, so nothing responds to it
+	Quotes  []string // Nodes to quote above the message, by full or abbreviated ID
+}
+
+// Reply adds a user message below the current working node and moves to it, or below
+// opts.Parent, leaving the current working node where it is unless it was the parent. Excerpts of
+// quoted nodes, from any tree, are placed above the message and their IDs recorded in the
+// message's metadata under QuotesMetadataKey.
+func (s *Session) Reply(message string, opts ReplyOptions) (*Node, error) {
+	quoted := make([]*Node, 0, len(opts.Quotes))
+	quotedIDs := make([]string, 0, len(opts.Quotes))
+	for _, id := range opts.Quotes {
+		node, err := s.Node(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find quoted node: %w", err)
+		}
+		quoted = append(quoted, node)
+		quotedIDs = append(quotedIDs, node.ID)
+	}
+
+	var (
+		parent *Node
+		err    error
+	)
+	if opts.Parent != "" {
+		parent, err = s.Node(opts.Parent)
+	} else {
+		parent, err = s.Current()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	model := opts.Model
+	if model == "" && !opts.NoModel {
+		if model, err = s.InheritedModel(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkModel(model); err != nil {
+		return nil, err
+	}
+
+	node, err := s.db.AddChildNode(QuoteMessage(message, quoted), parent.ID, "user", optionalModel(model))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create child node: %w", err)
+	}
+
+	if len(quotedIDs) > 0 {
+		if err := s.db.SetNodeMetadata(node.ID, QuotesMetadataKey, quotedIDs); err != nil {
+			return nil, fmt.Errorf("failed to record quoted nodes: %w", err)
+		}
+		if node, err = s.db.GetNodeByID(node.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.Notify(hooks.NodeCreated, node)
+	return node, nil
+}
+
+// QuoteMessage prefixes a message with a Markdown block quote of an excerpt of each quoted node
+func QuoteMessage(message string, quoted []*Node) string {
+	var b strings.Builder
+	for _, node := range quoted {
+		excerpt := strings.TrimSpace(node.Content)
+		if utf8.RuneCountInString(excerpt) > maxQuoteLength {
+			excerpt = string([]rune(excerpt)[:maxQuoteLength]) + "…"
+		}
+
+		for _, line := range strings.Split(excerpt, "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		fmt.Fprintf(&b, "> — %s %s\n\n", node.Type, shortID(node.ID))
+	}
+
+	b.WriteString(message)
+	return b.String()
+}
+
+// Quotes returns the IDs of the nodes a message quotes
+func Quotes(node *Node) []string {
+	values, _ := node.GetMetadata()[QuotesMetadataKey].([]interface{})
+
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// shortID abbreviates a node ID to the prefix shown by 'bai recent'
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
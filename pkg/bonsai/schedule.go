@@ -0,0 +1,158 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/cron"
+)
+
+// Schedule is a prompt added to a tree on a cron schedule
+type Schedule = db.Schedule
+
+// AddSchedule checks and stores a schedule: its cron expression must be valid, its node must exist,
+// and it needs either a prompt or a template whose variables all have values. The node may be given
+// as an abbreviated ID, and is stored in full.
+func (s *Session) AddSchedule(schedule *Schedule) error {
+	if _, err := cron.Parse(schedule.Spec); err != nil {
+		return err
+	}
+	node, err := s.Node(schedule.NodeID)
+	if err != nil {
+		return err
+	}
+	schedule.NodeID = node.ID
+
+	switch {
+	case schedule.Template != "" && schedule.Prompt != "":
+		return fmt.Errorf("a schedule takes a prompt or a template, not both")
+	case schedule.Template == "" && schedule.Prompt == "":
+		return fmt.Errorf("a schedule needs a prompt or a template")
+	}
+	if _, err := s.schedulePrompt(schedule); err != nil {
+		return err
+	}
+	return s.db.AddSchedule(schedule)
+}
+
+// schedulePrompt returns the prompt a schedule adds, rendering its template if it has one
+func (s *Session) schedulePrompt(schedule *Schedule) (string, error) {
+	if schedule.Template == "" {
+		return schedule.Prompt, nil
+	}
+	text, err := s.db.GetTemplate(schedule.Template)
+	if err != nil {
+		return "", err
+	}
+	if text == nil {
+		return "", fmt.Errorf("template %s not found", schedule.Template)
+	}
+	return RenderTemplate(*text, schedule.Vars)
+}
+
+// RunSchedule adds a schedule's prompt as a new branch below its node and, if a model is given or
+// inherited, generates the model's reply, leaving the current working node where it is. The outcome
+// is recorded on the schedule.
+func (s *Session) RunSchedule(ctx context.Context, schedule *Schedule) (*Turn, error) {
+	turn, err := s.runSchedule(ctx, schedule)
+	promptID := ""
+	if turn != nil && turn.Message != nil {
+		promptID = turn.Message.ID
+	}
+	if recordErr := s.db.RecordScheduleRun(schedule.ID, promptID, err); recordErr != nil && err == nil {
+		err = recordErr
+	}
+	return turn, err
+}
+
+func (s *Session) runSchedule(ctx context.Context, schedule *Schedule) (*Turn, error) {
+	prompt, err := s.schedulePrompt(schedule)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.Node(schedule.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.Batch(ctx, parent, []string{prompt}, BatchOptions{Model: schedule.Model})[0]
+	if result.Message == nil {
+		return nil, result.Err
+	}
+	return &Turn{Message: result.Message, Response: result.Response}, result.Err
+}
+
+// SchedulerOptions configures RunScheduler
+type SchedulerOptions struct {
+	// OnRun is called after each run, possibly concurrently, and with a nil schedule when the schedules couldn't be read
+	OnRun func(schedule *Schedule, turn *Turn, err error)
+}
+
+// RunScheduler runs each schedule whenever its cron expression comes due, in local time, until ctx is
+// cancelled, then waits for runs in progress to stop. Schedules are reread every minute, so ones
+// added or removed meanwhile take effect without a restart. Runs that came due while no scheduler
+// was running aren't made up. Several schedulers sharing a database run each due prompt once.
+func (s *Session) RunScheduler(ctx context.Context, opts SchedulerOptions) error {
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	checked := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(time.Now().Truncate(time.Minute).Add(time.Minute))):
+		}
+
+		now := time.Now()
+		schedules, err := s.db.GetSchedules()
+		if err != nil {
+			// Retried at the next minute, which still runs what came due since the last check
+			if opts.OnRun != nil {
+				opts.OnRun(nil, nil, err)
+			}
+			continue
+		}
+
+		for _, schedule := range schedules {
+			spec, err := cron.Parse(schedule.Spec)
+			if err != nil {
+				continue // Checked when the schedule was added, so only a hand-edited database gets here
+			}
+			from := checked
+			if created := time.Unix(schedule.CreatedAt, 0); created.After(from) {
+				from = created
+			}
+			due := spec.Next(from)
+			if due.IsZero() || due.After(now) {
+				continue
+			}
+			if claimed, err := s.db.ClaimScheduleRun(schedule.ID, due); err != nil || !claimed {
+				continue
+			}
+
+			running.Add(1)
+			go func(schedule *Schedule) {
+				defer running.Done()
+				turn, err := s.RunSchedule(ctx, schedule)
+				if opts.OnRun != nil {
+					opts.OnRun(schedule, turn, err)
+				}
+			}(schedule)
+		}
+		checked = now
+	}
+}
+
+// NextRun returns when a schedule next comes due after the given time, or the zero time if its cron
+// expression is invalid or never matches
+func NextRun(schedule *Schedule, after time.Time) time.Time {
+	spec, err := cron.Parse(schedule.Spec)
+	if err != nil {
+		return time.Time{}
+	}
+	return spec.Next(after)
+}
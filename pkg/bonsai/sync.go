@@ -0,0 +1,251 @@
+package bonsai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Remote is another copy of a Bonsai garden that nodes can be pushed to and pulled from, such as
+// another database file or a bai serve API. Implementations live in package remote.
+type Remote interface {
+	// Nodes returns every node in the remote
+	Nodes(ctx context.Context) ([]*Node, error)
+	// Put adds the nodes to the remote, overwriting nodes with the same IDs
+	Put(ctx context.Context, nodes []*Node) error
+}
+
+// SyncOptions controls a Push or Pull
+type SyncOptions struct {
+	Force  bool // Overwrite conflicting nodes on the receiving side instead of skipping them
+	DryRun bool // Work out what would change without changing anything
+}
+
+// SyncResult describes what a Push or Pull changed, or would change with DryRun
+type SyncResult struct {
+	Added     []*Node // Nodes the receiving side didn't have
+	Updated   []*Node // Nodes the receiving side had an older or conflicting (with Force) version of
+	UpToDate  int     // Nodes already the same on both sides
+	Conflicts []SyncConflict
+}
+
+// SyncConflict is a node changed differently on both sides, which isn't synced without Force
+type SyncConflict struct {
+	ID     string
+	Fields []string // What differs, e.g. "content" or "metadata.judge"
+}
+
+// Push sends the nodes the remote is missing or has older versions of to it
+func (s *Session) Push(ctx context.Context, remote Remote, opts SyncOptions) (*SyncResult, error) {
+	local, err := s.db.GetAllNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local nodes: %w", err)
+	}
+	theirs, err := remote.Nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote nodes: %w", err)
+	}
+
+	result := planSync(local, theirs, opts.Force)
+	if opts.DryRun || result.changes() == 0 {
+		return result, nil
+	}
+	if err := remote.Put(ctx, result.nodes()); err != nil {
+		return nil, fmt.Errorf("failed to push nodes: %w", err)
+	}
+	return result, nil
+}
+
+// Pull fetches the nodes this database is missing or has older versions of from the remote. The
+// current working node is left where it is.
+func (s *Session) Pull(ctx context.Context, remote Remote, opts SyncOptions) (*SyncResult, error) {
+	theirs, err := remote.Nodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote nodes: %w", err)
+	}
+	local, err := s.db.GetAllNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local nodes: %w", err)
+	}
+
+	result := planSync(theirs, local, opts.Force)
+	if opts.DryRun || result.changes() == 0 {
+		return result, nil
+	}
+	if err := s.db.UpsertNodes(result.nodes()); err != nil {
+		return nil, fmt.Errorf("failed to store pulled nodes: %w", err)
+	}
+	return result, nil
+}
+
+// changes counts the nodes the sync writes
+func (r *SyncResult) changes() int {
+	return len(r.Added) + len(r.Updated)
+}
+
+// nodes lists the nodes the sync writes
+func (r *SyncResult) nodes() []*Node {
+	nodes := make([]*Node, 0, r.changes())
+	nodes = append(nodes, r.Added...)
+	return append(nodes, r.Updated...)
+}
+
+// planSync works out what sending the nodes in from to the side holding to would change.
+//
+// Nodes are matched by ID. Since nodes are only ever added to, a node that differs on the two sides
+// is merged when only its metadata does and the two versions don't disagree on any key: lists such as
+// evals and linked commits are combined. Any other difference is a conflict, which is skipped unless
+// force is set, in which case the sending side's version wins.
+func planSync(from, to []*Node, force bool) *SyncResult {
+	existing := make(map[string]*Node, len(to))
+	for _, node := range to {
+		existing[node.ID] = node
+	}
+
+	result := &SyncResult{}
+	for _, node := range from {
+		theirs, ok := existing[node.ID]
+		if !ok {
+			result.Added = append(result.Added, node)
+			continue
+		}
+
+		fields := differentFields(node, theirs)
+		if len(fields) == 0 {
+			result.UpToDate++
+			continue
+		}
+
+		if merged, ok := mergeMetadata(node, theirs); ok && len(fields) == 1 && fields[0] == "metadata" {
+			if sameMetadata(merged, theirs.GetMetadata()) {
+				// The receiving side already has everything the sending side does
+				result.UpToDate++
+				continue
+			}
+			updated := *node
+			encoded, err := json.Marshal(merged)
+			if err == nil {
+				metadata := string(encoded)
+				updated.Metadata = &metadata
+				result.Updated = append(result.Updated, &updated)
+				continue
+			}
+		}
+
+		if force {
+			result.Updated = append(result.Updated, node)
+			continue
+		}
+		result.Conflicts = append(result.Conflicts, SyncConflict{ID: node.ID, Fields: conflictingFields(node, theirs, fields)})
+	}
+	return result
+}
+
+// differentFields names the stored fields that differ between two versions of a node
+func differentFields(a, b *Node) []string {
+	var fields []string
+	if a.Content != b.Content {
+		fields = append(fields, "content")
+	}
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if stringOrEmpty(a.Parent) != stringOrEmpty(b.Parent) {
+		fields = append(fields, "parent")
+	}
+	if stringOrEmpty(a.Model) != stringOrEmpty(b.Model) {
+		fields = append(fields, "model")
+	}
+	if stringOrEmpty(a.Author) != stringOrEmpty(b.Author) {
+		fields = append(fields, "author")
+	}
+	if !sameMetadata(a.GetMetadata(), b.GetMetadata()) {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+// conflictingFields replaces "metadata" in fields with the metadata keys the versions disagree on
+func conflictingFields(a, b *Node, fields []string) []string {
+	var result []string
+	for _, field := range fields {
+		if field != "metadata" {
+			result = append(result, field)
+			continue
+		}
+
+		ours, theirs := a.GetMetadata(), b.GetMetadata()
+		for key, value := range ours {
+			other, ok := theirs[key]
+			if !ok || reflect.DeepEqual(value, other) {
+				continue
+			}
+			if _, ok := mergeValues(value, other); !ok {
+				result = append(result, "metadata."+key)
+			}
+		}
+	}
+	return result
+}
+
+// mergeMetadata combines two versions of a node's metadata, reporting false if they disagree on a
+// key whose values can't be combined
+func mergeMetadata(a, b *Node) (map[string]interface{}, bool) {
+	merged := b.GetMetadata()
+	for key, value := range a.GetMetadata() {
+		other, ok := merged[key]
+		if !ok {
+			merged[key] = value
+			continue
+		}
+		combined, ok := mergeValues(value, other)
+		if !ok {
+			return nil, false
+		}
+		merged[key] = combined
+	}
+	return merged, true
+}
+
+// mergeValues combines two values of the same metadata key: equal values are kept, and lists are
+// joined without repeating items. Other differing values can't be combined.
+func mergeValues(a, b interface{}) (interface{}, bool) {
+	if reflect.DeepEqual(a, b) {
+		return a, true
+	}
+
+	listA, okA := a.([]interface{})
+	listB, okB := b.([]interface{})
+	if !okA || !okB {
+		return nil, false
+	}
+
+	combined := append([]interface{}{}, listB...)
+	for _, item := range listA {
+		found := false
+		for _, existing := range listB {
+			if reflect.DeepEqual(item, existing) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			combined = append(combined, item)
+		}
+	}
+	return combined, true
+}
+
+// sameMetadata reports whether two decoded metadata maps hold the same keys and values
+func sameMetadata(a, b map[string]interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// stringOrEmpty dereferences an optional string
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
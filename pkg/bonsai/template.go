@@ -0,0 +1,40 @@
+package bonsai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateVariable matches a {{variable}} placeholder in a prompt template
+var templateVariable = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*}}`)
+
+// TemplateVariables returns the names of the {{variables}} in a prompt template, in order of first use
+func TemplateVariables(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range templateVariable.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// RenderTemplate fills in the {{variables}} of a prompt template, failing if any has no value
+func RenderTemplate(text string, values map[string]string) (string, error) {
+	var missing []string
+	for _, name := range TemplateVariables(text) {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing value for template variable(s): %s", strings.Join(missing, ", "))
+	}
+
+	return templateVariable.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return values[templateVariable.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
@@ -0,0 +1,210 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/google/uuid"
+)
+
+// IngestedFromMetadataKey is the metadata key naming the tool a node was ingested from with IngestTranscript
+const IngestedFromMetadataKey = "ingested_from"
+
+// DefaultIngestSource names the tool transcripts are ingested from when none is given
+const DefaultIngestSource = "api"
+
+// ErrInvalidTranscript is returned by IngestTranscript for transcripts it can't read
+var ErrInvalidTranscript = errors.New("invalid transcript")
+
+// TranscriptMessage is one message of a chat transcript in OpenAI's chat completions format. Content
+// is either a string or a list of parts, of which only the text parts are kept.
+type TranscriptMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// transcriptRoles maps the roles of transcript messages to the node types they're stored as
+var transcriptRoles = map[string]string{
+	"system":    "system",
+	"developer": "system",
+	"user":      "user",
+	"assistant": "llm",
+	"tool":      "note",
+	"function":  "note",
+}
+
+// Text returns the text of the message, joining its text parts if it has several
+func (m TranscriptMessage) Text() (string, error) {
+	if len(m.Content) == 0 || string(m.Content) == "null" {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(m.Content, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(m.Content, &parts); err != nil {
+		return "", fmt.Errorf("content of a %s message must be a string or a list of parts", m.Role)
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// IngestOptions configures IngestTranscript
+type IngestOptions struct {
+	Parent string // Node to add the transcript below; empty to add it as a tree of its own
+	Model  string // Model the assistant messages came from, also recorded on the user messages
+	Source string // Name of the tool the transcript came from; defaults to DefaultIngestSource
+}
+
+// IngestResult is what IngestTranscript stored
+type IngestResult struct {
+	RootID  string  // Root of the tree holding the transcript
+	Last    *Node   // Node holding the transcript's last message
+	Created []*Node // Nodes added, in transcript order; empty if the whole transcript was already stored
+}
+
+// IngestTranscript stores a chat transcript from another tool as a branch of nodes: system and
+// developer messages as system prompts, user messages as user nodes, assistant messages as LLM
+// responses and tool results as notes. Messages with no text, such as assistant messages that only
+// call tools, are skipped.
+//
+// Messages repeating a branch already stored are matched to it instead of stored again: below the
+// parent if one is given, or otherwise from a root ingested from the same source. A tool can send
+// its whole transcript after every turn and only the new messages are added, and sessions opening
+// the same way become branches of one tree. The current working node isn't moved.
+func (s *Session) IngestTranscript(messages []TranscriptMessage, opts IngestOptions) (*IngestResult, error) {
+	if opts.Source == "" {
+		opts.Source = DefaultIngestSource
+	}
+
+	type entry struct{ nodeType, content string }
+	var entries []entry
+	for i, message := range messages {
+		nodeType, ok := transcriptRoles[message.Role]
+		if !ok {
+			return nil, fmt.Errorf("%w: message %d has unknown role %q", ErrInvalidTranscript, i+1, message.Role)
+		}
+		text, err := message.Text()
+		if err != nil {
+			return nil, fmt.Errorf("%w: message %d: %v", ErrInvalidTranscript, i+1, err)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		entries = append(entries, entry{nodeType, text})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no messages with text", ErrInvalidTranscript)
+	}
+
+	// Follow the branch the transcript repeats as far as it goes
+	var (
+		parent     *Node
+		candidates []*Node
+		err        error
+	)
+	if opts.Parent != "" {
+		if parent, err = s.Node(opts.Parent); err != nil {
+			return nil, err
+		}
+		candidates, err = s.db.GetDirectChildren(parent.ID)
+	} else {
+		candidates, err = s.db.GetRootNodes()
+		candidates = ingestedFrom(candidates, opts.Source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	matched := 0
+	for matched < len(entries) {
+		next := matchingNode(candidates, entries[matched].nodeType, entries[matched].content)
+		if next == nil {
+			break
+		}
+		parent = next
+		matched++
+		if matched < len(entries) {
+			if candidates, err = s.db.GetDirectChildren(parent.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	metadata, err := json.Marshal(map[string]string{IngestedFromMetadataKey: opts.Source})
+	if err != nil {
+		return nil, err
+	}
+	encodedMetadata := string(metadata)
+	var created []*Node
+	for _, e := range entries[matched:] {
+		node := &Node{
+			ID:       uuid.New().String(),
+			Content:  e.content,
+			Type:     e.nodeType,
+			Children: "[]",
+			Metadata: &encodedMetadata,
+		}
+		if parent != nil {
+			node.Parent = &parent.ID
+		}
+		if opts.Model != "" && (e.nodeType == "user" || e.nodeType == "llm") {
+			model := opts.Model
+			node.Model = &model
+		}
+		created = append(created, node)
+		parent = node
+	}
+	if err := s.db.InsertNodes(created); err != nil {
+		return nil, fmt.Errorf("failed to store transcript: %w", err)
+	}
+
+	for _, node := range created {
+		event := hooks.NodeCreated
+		if node.Type == "llm" {
+			event = hooks.ResponseReceived
+		}
+		s.Notify(event, node)
+	}
+
+	rootID, err := s.db.GetRootID(parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &IngestResult{RootID: rootID, Last: parent, Created: created}, nil
+}
+
+// ingestedFrom returns the nodes ingested from the given source
+func ingestedFrom(nodes []*Node, source string) []*Node {
+	var matching []*Node
+	for _, node := range nodes {
+		if from, _ := node.GetMetadata()[IngestedFromMetadataKey].(string); from == source {
+			matching = append(matching, node)
+		}
+	}
+	return matching
+}
+
+// matchingNode returns the node among candidates holding the given message, or nil if there's none
+func matchingNode(candidates []*Node, nodeType, content string) *Node {
+	normalized := db.NormalizeContent(content)
+	for _, candidate := range candidates {
+		if candidate.Type == nodeType && (candidate.Content == content || candidate.Content == normalized) {
+			return candidate
+		}
+	}
+	return nil
+}
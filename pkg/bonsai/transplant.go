@@ -0,0 +1,144 @@
+package bonsai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/google/uuid"
+)
+
+// TransplantMetadataKey is the metadata key on a transplanted node naming the node it was copied
+// from or, for a response, the original response it replaces
+const TransplantMetadataKey = "transplanted_from"
+
+// TransplantOptions configures a Transplant
+type TransplantOptions struct {
+	Timeout    time.Duration             // Bound on each response; zero leaves it to ctx
+	OnTurn     func(turn TransplantTurn) // Called as each user turn is answered
+	OnProgress func(llm.Progress)        // Called after OnTurn with how many user turns are answered
+}
+
+// TransplantTurn is one user turn of a transplanted branch and the new model's response to it
+type TransplantTurn struct {
+	Original *Node // The user turn on the original branch
+	Message  *Node // The same turn on the new branch; the root is shared rather than copied
+	Response *Node
+}
+
+// Transplant replays every user turn from the root down to end against another model, building a
+// parallel branch that shares the root and has fresh responses in place of the original ones. Every
+// other node after the root, such as user turns, notes and system messages, is copied onto the new
+// branch with its type and metadata. The current working node is left where it is. It returns the
+// new branch's last node.
+func (s *Session) Transplant(ctx context.Context, end *Node, model string, opts TransplantOptions) (*Node, error) {
+	if model == "" {
+		return nil, fmt.Errorf("a transplant needs a model")
+	}
+
+	branch, err := s.db.GetConversationHistory(end.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation history: %w", err)
+	}
+
+	progress := llm.Progress{}
+	for _, node := range branch {
+		if node.Type == "user" {
+			progress.Total++
+		}
+	}
+	started := time.Now()
+
+	tip := branch[0]
+	for i, original := range branch {
+		message := original
+		switch {
+		case i == 0:
+		case original.Type == "llm":
+			// Replaced by the response generated for the turn before it
+			continue
+		default:
+			if message, err = s.copyTransplanted(original, tip, model); err != nil {
+				return nil, err
+			}
+		}
+		tip = message
+		if original.Type != "user" {
+			continue
+		}
+
+		turnCtx, generation := llm.WithGeneration(ctx)
+		content, err := s.generateWithin(turnCtx, opts.Timeout, message, model)
+		if err != nil {
+			return nil, fmt.Errorf("turn %s: %w", original.ID, err)
+		}
+		response, err := s.AddResponse(message, content, model, generation)
+		if err != nil {
+			return nil, err
+		}
+		if i+1 < len(branch) && branch[i+1].Type == "llm" {
+			if err := s.db.SetNodeMetadata(response.ID, TransplantMetadataKey, branch[i+1].ID); err != nil {
+				return nil, fmt.Errorf("failed to record transplant: %w", err)
+			}
+		}
+
+		tip = response
+		progress.Done++
+		progress.Elapsed = time.Since(started)
+		if opts.OnTurn != nil {
+			opts.OnTurn(TransplantTurn{Original: original, Message: message, Response: response})
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	if progress.Done == 0 {
+		return nil, fmt.Errorf("branch ending at %s has no user turns to replay", end.ID)
+	}
+	return tip, nil
+}
+
+// copyTransplanted copies a node of a transplanted branch below parent, keeping its type, content,
+// author and metadata and recording where it came from. User turns are addressed to the new model.
+func (s *Session) copyTransplanted(original, parent *Node, model string) (*Node, error) {
+	metadata := original.GetMetadata()
+	metadata[TransplantMetadataKey] = original.ID
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata for node %s: %w", original.ID, err)
+	}
+	encodedMetadata := string(encoded)
+
+	copied := &Node{
+		ID:       uuid.New().String(),
+		Content:  original.Content,
+		Type:     original.Type,
+		Parent:   &parent.ID,
+		Children: "[]",
+		Model:    original.Model,
+		Metadata: &encodedMetadata,
+		Author:   original.Author,
+	}
+	if original.Type == "user" {
+		copied.Model = &model
+	}
+	if err := s.db.InsertNode(copied); err != nil {
+		return nil, fmt.Errorf("failed to copy node %s: %w", original.ID, err)
+	}
+	s.Notify(hooks.NodeCreated, copied)
+	return copied, nil
+}
+
+// generateWithin is Generate bounded by a timeout, if one is given
+func (s *Session) generateWithin(ctx context.Context, timeout time.Duration, node *Node, model string) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return s.Generate(ctx, node, model)
+}
@@ -0,0 +1,127 @@
+package bonsai
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// rolesClient answers every request with the roles of the messages it was sent, so tests can see
+// what reached the model
+type rolesClient struct{}
+
+func (rolesClient) GenerateResponse(ctx context.Context, prompt string, model string) (string, error) {
+	return "user", nil
+}
+
+func (rolesClient) GenerateResponseFromHistory(ctx context.Context, messages []llm.Message, model string) (string, error) {
+	roles := make([]string, len(messages))
+	for i, message := range messages {
+		roles[i] = message.Role
+	}
+	return strings.Join(roles, ","), nil
+}
+
+func (c rolesClient) StreamResponseFromHistory(ctx context.Context, messages []llm.Message, model string, onChunk llm.StreamHandler) (string, error) {
+	return c.GenerateResponseFromHistory(ctx, messages, model)
+}
+
+func (rolesClient) GetAvailableModels() []string { return []string{"roles"} }
+
+func (rolesClient) GetProviderName() string { return "test" }
+
+func TestTransplantCopiesEveryNodeType(t *testing.T) {
+	err := llm.Register(llm.Provider{Name: "test", New: func(config llm.Config) (llm.Client, error) {
+		return rolesClient{}, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := Open(filepath.Join(t.TempDir(), "bonsai.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	database := session.Database()
+
+	root, err := session.Seed("Plan a trip to Kyoto", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := session.AddResponse(root, "Sure", "old-model", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := session.Note("Ask about the budget next", answer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetNodeMetadata(note.ID, "tags", []string{"todo"}); err != nil {
+		t.Fatal(err)
+	}
+	system, err := session.System("Answer in one sentence", note.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	question, err := database.CreateBranchNode("What should I pack?", system.ID, "user", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end, err := session.AddResponse(question, "Layers", "old-model", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tip, err := session.Transplant(context.Background(), end, "test/roles", TransplantOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := database.GetConversationHistory(tip.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTypes := []string{"user", "llm", "note", "system", "user", "llm"}
+	if len(branch) != len(wantTypes) {
+		t.Fatalf("transplanted branch has %d nodes, want %d", len(branch), len(wantTypes))
+	}
+	for i, node := range branch {
+		if node.Type != wantTypes[i] {
+			t.Errorf("node %d has type %q, want %q", i, node.Type, wantTypes[i])
+		}
+	}
+	if branch[0].ID != root.ID {
+		t.Errorf("transplanted branch starts at %s, want the shared root %s", branch[0].ID, root.ID)
+	}
+
+	copiedNote, copiedSystem := branch[2], branch[3]
+	for _, copied := range []struct {
+		node, original *Node
+	}{{copiedNote, note}, {copiedSystem, system}} {
+		if copied.node.ID == copied.original.ID {
+			t.Errorf("%s node was reused instead of copied", copied.original.Type)
+		}
+		if copied.node.Content != copied.original.Content {
+			t.Errorf("copied %s has content %q, want %q", copied.original.Type, copied.node.Content, copied.original.Content)
+		}
+		if from := copied.node.GetMetadata()[TransplantMetadataKey]; from != copied.original.ID {
+			t.Errorf("copied %s records %v as its original, want %s", copied.original.Type, from, copied.original.ID)
+		}
+	}
+	tags, _ := copiedNote.GetMetadata()["tags"].([]interface{})
+	if len(tags) != 1 || tags[0] != "todo" {
+		t.Errorf("copied note has tags %v, want [todo]", tags)
+	}
+
+	// The copied system message reaches the model; the note doesn't
+	if want := "user,assistant,system,user"; tip.Content != want {
+		t.Errorf("last response was generated from roles %q, want %q", tip.Content, want)
+	}
+	if GenerationOf(tip) == nil {
+		t.Error("last response has no recorded generation")
+	}
+}
@@ -0,0 +1,307 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// Tree is a root node and all of its descendants
+type Tree struct {
+	Root  *Node
+	Nodes []*Node // Root first, then descendants depth-first
+}
+
+// Children returns the direct children of the given node within the tree
+func (t *Tree) Children(nodeID string) []*Node {
+	var children []*Node
+	for _, node := range t.Nodes {
+		if node.Parent != nil && *node.Parent == nodeID {
+			children = append(children, node)
+		}
+	}
+	return children
+}
+
+// CheckoutResult describes where a Checkout moved the current working node
+type CheckoutResult struct {
+	Node           *Node // The new current working node
+	Restored       bool  // A root was requested and the tree's last position was restored instead
+	AlreadyCurrent bool  // The node was already the current working node
+}
+
+// PruneResult describes what a Prune removed
+type PruneResult struct {
+	Deleted        int     // Number of nodes deleted
+	Reattached     int     // Number of children moved up to the pruned node's parent
+	CurrentCleared bool    // The current working node was deleted and is now unset
+	CurrentMovedTo *string // The current working node was deleted and moved to this parent
+}
+
+// Tree returns the whole tree containing the given node
+func (s *Session) Tree(nodeID string) (*Tree, error) {
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootID, err := s.db.GetRootID(node.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.db.GetNodeAndAllChildren(rootID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree %s: %w", rootID, err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("node with ID %s not found", rootID)
+	}
+
+	return &Tree{Root: nodes[0], Nodes: nodes}, nil
+}
+
+// Checkout makes the given node the current working node. Checking out the root of a different
+// tree restores that tree's last position unless exact is set.
+func (s *Session) Checkout(nodeID string, exact bool) (*CheckoutResult, error) {
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentNodeID, err := s.db.GetCurrentNode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current node: %w", err)
+	}
+
+	result := &CheckoutResult{Node: node}
+	if node.Parent == nil && !exact {
+		currentRootID, err := s.CurrentRootID()
+		if err != nil {
+			return nil, err
+		}
+
+		if currentRootID != node.ID {
+			lastNodeID, err := s.db.GetTreeCurrentNode(node.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get last position in tree: %w", err)
+			}
+
+			if lastNodeID != nil && *lastNodeID != node.ID {
+				if result.Node, err = s.db.GetNodeByID(*lastNodeID); err != nil {
+					return nil, err
+				}
+				result.Restored = true
+			}
+		}
+	}
+
+	if currentNodeID != nil && *currentNodeID == result.Node.ID {
+		result.AlreadyCurrent = true
+		return result, nil
+	}
+
+	if err := s.db.SetCurrentNode(result.Node.ID); err != nil {
+		return nil, fmt.Errorf("failed to set current node: %w", err)
+	}
+	return result, nil
+}
+
+// Prune deletes the given node and everything below it. With keepChildren, only the node itself is
+// deleted and its children are reattached to its parent. If the current working node is deleted it
+// moves up to the parent when keepChildren is set, and is cleared otherwise.
+//
+// A non-nil result with an error means the nodes were deleted but the current node couldn't be fixed up.
+func (s *Session) Prune(nodeID string, keepChildren bool) (*PruneResult, error) {
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.prune(node, keepChildren)
+	if result != nil {
+		// Run hooks after the lock is released, so they can use bai themselves
+		s.hooks.Fire(hooks.Prune, node)
+	}
+	return result, err
+}
+
+// prune deletes a node under the database lock and fixes up the current working node
+func (s *Session) prune(node *Node, keepChildren bool) (*PruneResult, error) {
+	// Hold the lock so another bai process can't move the current node between the delete and the check below
+	unlock, err := s.db.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	result := &PruneResult{}
+	if keepChildren {
+		if result.Reattached, err = s.db.DeleteNodeKeepChildren(node.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete node: %w", err)
+		}
+		result.Deleted = 1
+	} else if result.Deleted, err = s.db.DeleteNodeAndAllChildren(node.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete nodes: %w", err)
+	}
+
+	currentNodeID, err := s.db.GetCurrentNode()
+	if err != nil || currentNodeID == nil {
+		return result, nil
+	}
+	if _, err := s.db.GetNodeByID(*currentNodeID); err == nil {
+		return result, nil
+	}
+
+	if keepChildren && *currentNodeID == node.ID && node.Parent != nil {
+		if err := s.db.SetCurrentNode(*node.Parent); err != nil {
+			return result, fmt.Errorf("deleted node but failed to move current node: %w", err)
+		}
+		result.CurrentMovedTo = node.Parent
+		return result, nil
+	}
+
+	if err := s.db.ClearCurrentNode(); err != nil {
+		return result, fmt.Errorf("deleted nodes but failed to clear current node: %w", err)
+	}
+	result.CurrentCleared = true
+	return result, nil
+}
+
+// Split splits a node into a chain of nodes holding the given parts in order, moving the node's
+// children and the current working node to the end of the chain. Returns the chain, the node first.
+func (s *Session) Split(nodeID string, parts []string) ([]*Node, error) {
+	node, err := s.Node(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.db.SplitNode(node.ID, parts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split node: %w", err)
+	}
+	return chain, nil
+}
+
+// Export writes the given node and all of its descendants to w as indented JSON
+func (s *Session) Export(w io.Writer, nodeID string) error {
+	nodes, err := s.db.GetNodeAndAllChildren(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to read tree %s: %w", nodeID, err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("node with ID %s not found", nodeID)
+	}
+
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tree %s: %w", nodeID, err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// ExportMarkdown writes the branch from the root down to the given node to w as a Markdown
+// transcript, titled with the first line of the root message, with secrets redacted
+func (s *Session) ExportMarkdown(w io.Writer, nodeID string) error {
+	branch, err := s.db.GetConversationHistory(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to read branch %s: %w", nodeID, err)
+	}
+	if len(branch) == 0 {
+		return fmt.Errorf("node with ID %s not found", nodeID)
+	}
+	if err := s.redactForExport(branch); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", BranchTitle(branch))
+	for _, node := range branch {
+		heading := "👤 User"
+		switch node.Type {
+		case "llm":
+			heading = "🤖 Assistant"
+		case "note":
+			heading = "📝 Note"
+		case "system":
+			heading = "⚙️ System"
+		}
+		if node.Model != nil && node.Type == "llm" {
+			heading += " (" + *node.Model + ")"
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", heading, strings.TrimSpace(node.Content))
+	}
+	fmt.Fprintf(&b, "\n---\n\n*Exported from Bonsai, node `%s`.*\n", shortID(branch[len(branch)-1].ID))
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// dotColors are the fill colors of each node type in a Graphviz export, as in the web UI
+var dotColors = map[string]string{
+	"user":   "#e74c3c",
+	"llm":    "#27ae60",
+	"note":   "#f1c40f",
+	"system": "#8e44ad",
+}
+
+// ExportDot writes the given node and all of its descendants to w as a Graphviz digraph, each node
+// labelled with its type and the start of its content, with secrets redacted
+func (s *Session) ExportDot(w io.Writer, nodeID string) error {
+	nodes, err := s.db.GetNodeAndAllChildren(nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to read tree %s: %w", nodeID, err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("node with ID %s not found", nodeID)
+	}
+	if err := s.redactForExport(nodes); err != nil {
+		return err
+	}
+
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	var b strings.Builder
+	b.WriteString("digraph bonsai {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontcolor=white];\n")
+	for _, node := range nodes {
+		text := strings.Join(strings.Fields(node.Content), " ")
+		if runes := []rune(text); len(runes) > 40 {
+			text = string(runes[:37]) + "..."
+		}
+		color, ok := dotColors[node.Type]
+		if !ok {
+			color = "#3498db"
+		}
+		fmt.Fprintf(&b, "  \"%s\" [label=\"%s: %s\", fillcolor=\"%s\"];\n", node.ID, node.Type, quote.Replace(text), color)
+	}
+	for _, node := range nodes[1:] {
+		if node.Parent != nil {
+			fmt.Fprintf(&b, "  \"%s\" -> \"%s\";\n", *node.Parent, node.ID)
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// BranchTitle names a branch after the first line of its root message, shortened if it's long
+func BranchTitle(branch []*Node) string {
+	if len(branch) == 0 {
+		return "Bonsai conversation"
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(branch[0].Content), "\n")
+	title = strings.TrimLeft(title, "# ")
+	if runes := []rune(title); len(runes) > 80 {
+		title = string(runes[:77]) + "..."
+	}
+	if title == "" {
+		return "Bonsai conversation"
+	}
+	return title
+}
@@ -0,0 +1,277 @@
+package bonsai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowMetadataKey is the metadata key recording the workflow and step that created a message
+const WorkflowMetadataKey = "workflow"
+
+// WorkflowStart names the node a workflow starts from, for use in a step's from
+const WorkflowStart = "start"
+
+// workflowStepID matches step IDs usable as {{variables}} in later steps
+var workflowStepID = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Workflow is a reproducible chain of prompts, read from a YAML file by LoadWorkflow
+type Workflow struct {
+	Name  string            `yaml:"name"`
+	Model string            `yaml:"model"` // Default model for the steps; empty inherits the starting node's
+	Seed  string            `yaml:"seed"`  // Template for a new root to run below; empty runs below the current working node
+	Vars  map[string]string `yaml:"vars"`  // Default values for template variables
+	Steps []WorkflowStep    `yaml:"steps"`
+}
+
+// WorkflowStep is one prompt in a workflow. Its message and the model's response become nodes, and
+// the response is available to later steps as the {{<id>}} template variable.
+type WorkflowStep struct {
+	ID       string             `yaml:"id"`
+	Prompt   string             `yaml:"prompt"`   // Template for the message
+	Template string             `yaml:"template"` // Name of a saved template to use instead of prompt
+	Model    string             `yaml:"model"`    // Overrides the workflow's model
+	From     string             `yaml:"from"`     // Step to branch from, or "start"; defaults to the previous step
+	When     *WorkflowCondition `yaml:"when"`     // Skip the step unless this holds
+}
+
+// WorkflowCondition tests the output of an earlier step. Every test given must pass.
+type WorkflowCondition struct {
+	Step        string `yaml:"step"` // Step whose output is tested; defaults to the step branched from
+	Contains    string `yaml:"contains"`
+	NotContains string `yaml:"not_contains"`
+	Matches     string `yaml:"matches"` // Regular expression
+
+	matches *regexp.Regexp
+}
+
+// WorkflowOptions configures RunWorkflow
+type WorkflowOptions struct {
+	Vars    map[string]string           // Template variables, overriding the workflow's vars
+	Model   string                      // Overrides the workflow's default model
+	Timeout time.Duration               // Bound on each response; zero leaves it to ctx
+	OnStep  func(result WorkflowResult) // Called as each step finishes or is skipped
+}
+
+// WorkflowResult is the outcome of one step of a workflow
+type WorkflowResult struct {
+	Step     *WorkflowStep
+	Message  *Node // nil if the step was skipped
+	Response *Node // nil if the step was skipped or had no model
+	Skipped  bool
+}
+
+// LoadWorkflow reads and validates a workflow from a YAML file
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow %s: %w", path, err)
+	}
+	return ParseWorkflow(data)
+}
+
+// ParseWorkflow parses and validates a workflow written in YAML
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	var wf Workflow
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	if err := wf.validate(); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	return &wf, nil
+}
+
+// validate checks that the steps are well formed and only refer to steps before them
+func (wf *Workflow) validate() error {
+	if len(wf.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+
+	seen := map[string]bool{WorkflowStart: true}
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("step%d", i+1)
+		}
+		if !workflowStepID.MatchString(step.ID) {
+			return fmt.Errorf("step id %q must be a valid template variable name", step.ID)
+		}
+		if seen[step.ID] {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+		if (step.Prompt == "") == (step.Template == "") {
+			return fmt.Errorf("step %s needs exactly one of prompt or template", step.ID)
+		}
+		if step.From != "" && !seen[step.From] {
+			return fmt.Errorf("step %s branches from unknown or later step %q", step.ID, step.From)
+		}
+
+		if cond := step.When; cond != nil {
+			if cond.Step != "" && !seen[cond.Step] {
+				return fmt.Errorf("step %s tests unknown or later step %q", step.ID, cond.Step)
+			}
+			if cond.Matches != "" {
+				re, err := regexp.Compile(cond.Matches)
+				if err != nil {
+					return fmt.Errorf("step %s has an invalid matches pattern: %w", step.ID, err)
+				}
+				cond.matches = re
+			}
+		}
+		seen[step.ID] = true
+	}
+	return nil
+}
+
+// holds reports whether the condition passes for the given step output
+func (c *WorkflowCondition) holds(output string) bool {
+	if c.Contains != "" && !strings.Contains(output, c.Contains) {
+		return false
+	}
+	if c.NotContains != "" && strings.Contains(output, c.NotContains) {
+		return false
+	}
+	if c.matches != nil && !c.matches.MatchString(output) {
+		return false
+	}
+	return true
+}
+
+// RunWorkflow runs a workflow's steps in order below the current working node, or below a new root
+// if the workflow has a seed. Each step adds its message below the step it branches from and, if it
+// has a model, the model's response below that. A step whose condition fails is skipped, along with
+// every step that branches from it. The current working node follows the steps along the chain it
+// started on.
+func (s *Session) RunWorkflow(ctx context.Context, wf *Workflow, opts WorkflowOptions) ([]WorkflowResult, error) {
+	vars := make(map[string]string, len(wf.Vars)+len(opts.Vars)+len(wf.Steps))
+	for name, value := range wf.Vars {
+		vars[name] = value
+	}
+	for name, value := range opts.Vars {
+		vars[name] = value
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = wf.Model
+	}
+
+	var start *Node
+	if wf.Seed != "" {
+		content, err := RenderTemplate(wf.Seed, vars)
+		if err != nil {
+			return nil, fmt.Errorf("seed: %w", err)
+		}
+		if start, err = s.Seed(content, model); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if start, err = s.Current(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each step's last node, or nil if it was skipped
+	ends := map[string]*Node{WorkflowStart: start}
+	outputs := map[string]string{WorkflowStart: start.Content}
+	previous := WorkflowStart
+
+	results := make([]WorkflowResult, 0, len(wf.Steps))
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		from := step.From
+		if from == "" {
+			from = previous
+		}
+		previous = step.ID
+
+		parent := ends[from]
+		skip := parent == nil
+		if !skip && step.When != nil {
+			tested := step.When.Step
+			if tested == "" {
+				tested = from
+			}
+			skip = ends[tested] == nil || !step.When.holds(outputs[tested])
+		}
+		if skip {
+			ends[step.ID] = nil
+			result := WorkflowResult{Step: step, Skipped: true}
+			results = append(results, result)
+			if opts.OnStep != nil {
+				opts.OnStep(result)
+			}
+			continue
+		}
+
+		result, err := s.runWorkflowStep(ctx, wf, step, parent, model, vars, opts.Timeout)
+		if err != nil {
+			if result.Message != nil {
+				results = append(results, result)
+			}
+			return results, fmt.Errorf("step %s: %w", step.ID, err)
+		}
+
+		end := result.Message
+		if result.Response != nil {
+			end = result.Response
+		}
+		ends[step.ID] = end
+		outputs[step.ID] = end.Content
+		vars[step.ID] = end.Content
+
+		results = append(results, result)
+		if opts.OnStep != nil {
+			opts.OnStep(result)
+		}
+	}
+	return results, nil
+}
+
+// runWorkflowStep adds one step's message below the parent and generates the response to it
+func (s *Session) runWorkflowStep(ctx context.Context, wf *Workflow, step *WorkflowStep, parent *Node, model string, vars map[string]string, timeout time.Duration) (WorkflowResult, error) {
+	result := WorkflowResult{Step: step}
+
+	text := step.Prompt
+	if step.Template != "" {
+		template, err := s.db.GetTemplate(step.Template)
+		if err != nil {
+			return result, err
+		}
+		if template == nil {
+			return result, fmt.Errorf("template '%s' not found", step.Template)
+		}
+		text = *template
+	}
+
+	message, err := RenderTemplate(text, vars)
+	if err != nil {
+		return result, err
+	}
+
+	if step.Model != "" {
+		model = step.Model
+	}
+	node, err := s.Reply(message, ReplyOptions{Parent: parent.ID, Model: model})
+	if err != nil {
+		return result, err
+	}
+
+	if err := s.db.SetNodeMetadata(node.ID, WorkflowMetadataKey, map[string]string{"name": wf.Name, "step": step.ID}); err != nil {
+		return result, fmt.Errorf("failed to record workflow step: %w", err)
+	}
+	result.Message = node
+
+	if node.Model == nil || *node.Model == "" {
+		return result, nil
+	}
+	result.Response, err = s.respondWithin(ctx, timeout, node, *node.Model)
+	return result, err
+}
@@ -0,0 +1,96 @@
+// Package clipboard places text on the system clipboard.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no way to reach the clipboard was found
+var ErrUnavailable = errors.New("no clipboard available: install pbcopy, wl-copy, xclip or xsel, or use a terminal that supports OSC 52")
+
+// tool is a command that reads text for the clipboard on stdin
+type tool struct {
+	name string
+	args []string
+}
+
+// Copy places text on the clipboard and returns the name of the method used. It uses the
+// platform's clipboard command where there is one, and otherwise asks the terminal to do it with
+// an OSC 52 escape sequence, which also works over SSH. In an SSH session OSC 52 is tried first, so
+// the text lands on the local machine's clipboard rather than the remote one.
+func Copy(text string) (string, error) {
+	remote := os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+	if remote {
+		if err := copyOSC52(text); err == nil {
+			return "OSC 52", nil
+		}
+	}
+
+	for _, t := range tools() {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(path, t.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("%s failed: %w: %s", t.name, err, strings.TrimSpace(string(output)))
+		}
+		return t.name, nil
+	}
+
+	if !remote {
+		if err := copyOSC52(text); err == nil {
+			return "OSC 52", nil
+		}
+	}
+	return "", ErrUnavailable
+}
+
+// tools lists the clipboard commands to try on this platform, in order of preference
+func tools() []tool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []tool{{"pbcopy", nil}}
+	case "windows":
+		return []tool{{"clip", nil}}
+	}
+
+	var candidates []tool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, tool{"wl-copy", nil})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		candidates = append(candidates,
+			tool{"xclip", []string{"-selection", "clipboard"}},
+			tool{"xsel", []string{"--clipboard", "--input"}},
+		)
+	}
+	return candidates
+}
+
+// copyOSC52 asks the terminal to set the clipboard by writing an OSC 52 escape sequence to it
+func copyOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to send OSC 52 to: %w", err)
+	}
+	defer tty.Close()
+
+	sequence := "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		// tmux only passes escape sequences through to the outer terminal when wrapped, with
+		// their escape characters doubled
+		sequence = "\033Ptmux;" + strings.ReplaceAll(sequence, "\033", "\033\033") + "\033\\"
+	}
+
+	_, err = tty.WriteString(sequence)
+	return err
+}
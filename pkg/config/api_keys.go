@@ -9,33 +9,36 @@ import (
 // GetAPIKey retrieves the API key for the specified LLM provider
 func GetAPIKey(provider string) string {
 	envVar := GetAPIKeyEnvVar(provider)
+	if envVar == "" {
+		return ""
+	}
 	return os.Getenv(envVar)
 }
 
-// GetAPIKeyEnvVar returns the environment variable name for the API key
+// GetAPIKeyEnvVar returns the environment variable name for the API key of a provider, or of the
+// provider serving a model, or an empty string if the provider has none or none is known to serve it
 func GetAPIKeyEnvVar(provider string) string {
-	switch provider {
-	case "openai", "gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o":
-		return "OPENAI_API_KEY"
-	case "anthropic", "claude-3-sonnet", "claude-3-haiku", "claude-3-opus", "claude-3-5-sonnet":
-		return "ANTHROPIC_API_KEY"
-	default:
-		// Try to detect provider from model name
-		detectedProvider := llm.DetectProviderFromModel(provider)
-		return GetAPIKeyEnvVar(detectedProvider)
+	registered, ok := llm.LookupProvider(provider)
+	if !ok {
+		registered, ok = llm.LookupProvider(llm.ProviderName(provider))
 	}
+	if !ok {
+		return ""
+	}
+	return registered.APIKeyEnv
 }
 
-// GetDefaultModel returns a cost-effective default model for the provider
+// GetDefaultModel returns a cost-effective default model for the provider, or for the provider
+// serving a model, or an empty string if there's none
 func GetDefaultModel(provider string) string {
 	switch provider {
 	case "openai":
 		return "gpt-3.5-turbo"
 	case "anthropic":
 		return "claude-3-haiku"
-	default:
-		// Try to detect provider from model name
-		detectedProvider := llm.DetectProviderFromModel(provider)
-		return GetDefaultModel(detectedProvider)
 	}
+	if detected := llm.ProviderName(provider); detected == "openai" || detected == "anthropic" {
+		return GetDefaultModel(detected)
+	}
+	return ""
 }
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// DefaultMaxTokens is the longest response asked for when no max_tokens is configured
+const DefaultMaxTokens = 1000
+
+// NewClientForModel creates an LLM client for the given model using the provider's API key from the environment
+// Models named "<provider>/<model>" use a registered provider or provider plugin instead.
+func NewClientForModel(model string) (llm.Client, error) {
+	client, _, err := newClient(model, ClientOptions{})
+	return client, err
+}
+
+// ClientOptions adjusts the clients created by NewClientWithOptions
+type ClientOptions struct {
+	Cache       llm.ResponseCache // Answers requests seen before; nil to always ask the provider
+	MaxTokens   int               // Longest response to ask for; 0 for the default
+	Temperature *float64          // nil for the provider's default
+	System      string            // System prompt sent ahead of the conversation, if any
+	ForceModel  bool              // Send model names no provider is known to serve as-is instead of failing
+
+	ProviderRules []llm.ProviderRule // Route models to providers by name prefix, ahead of the built-in ones
+
+	ConnectTimeout time.Duration // Longest connecting to the provider may take; 0 for the default
+}
+
+// NewClientWithOptions creates a client like NewClientForModel with the given generation parameters,
+// answering requests it has seen before from the cache if there is one
+func NewClientWithOptions(model string, options ClientOptions) (llm.Client, error) {
+	client, llmConfig, err := newClient(model, options)
+	if err != nil || options.Cache == nil {
+		return client, err
+	}
+	return llm.WithCache(client, options.Cache, llmConfig), nil
+}
+
+// newClient creates the client for a model along with the config it was created with
+func newClient(model string, options ClientOptions) (llm.Client, llm.Config, error) {
+	maxTokens := options.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
+	LoadPlugins()
+	model = llm.RouteModel(model, options.ProviderRules)
+	if provider, _, ok := llm.ProviderForModel(model); ok {
+		llmConfig := llm.Config{MaxTokens: maxTokens, Temperature: options.Temperature, System: options.System, ConnectTimeout: options.ConnectTimeout, ForceModel: options.ForceModel}
+		if provider.APIKeyEnv != "" {
+			llmConfig.APIKey = os.Getenv(provider.APIKeyEnv)
+		}
+		client, err := llm.NewClient(model, llmConfig)
+		return client, llmConfig, err
+	}
+
+	// Fail on a mistyped model before asking for its provider's API key
+	if err := ValidateModel(model, nil, options.ForceModel); err != nil {
+		return nil, llm.Config{}, err
+	}
+	apiKey := GetAPIKey(model)
+	if apiKey == "" {
+		return nil, llm.Config{}, fmt.Errorf("no API key found for %s. Set %s environment variable", model, GetAPIKeyEnvVar(model))
+	}
+
+	llmConfig := llm.Config{
+		APIKey:      apiKey,
+		MaxTokens:   maxTokens,
+		Temperature: options.Temperature,
+		System:      options.System,
+		ForceModel:  options.ForceModel,
+
+		ConnectTimeout: options.ConnectTimeout,
+	}
+
+	client, err := llm.NewClient(model, llmConfig)
+	if err != nil {
+		return nil, llm.Config{}, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	return client, llmConfig, nil
+}
+
+// ValidateModel checks that a model, once routed by the rules, is served by a registered provider
+// or plugin or known to a built-in one, failing with an llm.UnknownModelError suggesting similar
+// names otherwise. With force, any name following a built-in provider's naming is accepted.
+func ValidateModel(model string, rules []llm.ProviderRule, force bool) error {
+	LoadPlugins()
+	model = llm.RouteModel(model, rules)
+	if _, _, ok := llm.ProviderForModel(model); ok {
+		return nil
+	}
+	if name, _, ok := strings.Cut(model, "/"); ok {
+		dir, _ := PluginDir()
+		return fmt.Errorf("no provider named %s serves %s; add a plugin at %s", name, model, filepath.Join(dir, name))
+	}
+	_, _, err := llm.ResolveModel(model, force)
+	return err
+}
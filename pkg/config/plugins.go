@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+var loadPluginsOnce sync.Once
+
+// PluginDir returns the directory provider plugins are loaded from, ~/.bonsai/providers
+func PluginDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".bonsai", "providers"), nil
+}
+
+// LoadPlugins registers every executable in the plugin directory as an LLM provider named after
+// the file, so "<dir>/gateway" serves models like "gateway/llama-3-70b". It only runs once.
+func LoadPlugins() {
+	loadPluginsOnce.Do(func() {
+		dir, err := PluginDir()
+		if err != nil {
+			return
+		}
+
+		// A missing directory just means no plugins are installed
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			llm.Register(llm.Provider{
+				Name: strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+				New: func(config llm.Config) (llm.Client, error) {
+					return llm.NewPluginClient(path, config), nil
+				},
+			})
+		}
+	})
+}
+
+// isExecutable reports whether path is a regular file that can be run as a plugin
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,181 @@
+// Package cron parses the standard five-field cron expressions and works out when they next match.
+//
+// Each expression lists the minutes (0-59), hours (0-23), days of the month (1-31), months (1-12 or
+// jan-dec) and days of the week (0-7 or sun-sat, with both 0 and 7 for Sunday) it matches. A field is
+// a comma-separated list of values, ranges (1-5) and steps (*/15, 1-30/2), or * for every value. As in
+// Vixie cron, when both the day of the month and the day of the week are restricted, a day matching
+// either one matches. The shorthands @yearly, @monthly, @weekly, @daily and @hourly are accepted too.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // Bit n is set if the field matches n
+	domStar, dowStar              bool   // Whether the day fields were unrestricted, for how they combine
+}
+
+// field describes one field of a cron expression
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int // Names accepted for values, e.g. "jan"
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// shorthands are the @ expressions and what they stand for
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// searchYears bounds how far ahead Next looks for a match
+const searchYears = 5
+
+// Parse reads a cron expression, failing if it's malformed or can never match, like "0 0 31 2 *"
+func Parse(spec string) (*Schedule, error) {
+	expression := strings.TrimSpace(spec)
+	if expanded, ok := shorthands[strings.ToLower(expression)]; ok {
+		expression = expanded
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week), not %d", spec, len(fields))
+	}
+
+	// As in Vixie cron, a day field starting with *, such as */2, counts as unrestricted
+	schedule := &Schedule{domStar: strings.HasPrefix(fields[2], "*"), dowStar: strings.HasPrefix(fields[4], "*")}
+	var err error
+	for i, parsed := range []struct {
+		bits  *uint64
+		field field
+	}{
+		{&schedule.minute, minuteField},
+		{&schedule.hour, hourField},
+		{&schedule.dom, domField},
+		{&schedule.month, monthField},
+		{&schedule.dow, dowField},
+	} {
+		if *parsed.bits, err = parsed.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", spec)
+	}
+	return schedule, nil
+}
+
+// parse reads one field of an expression into a bit set of the values it matches
+func (f field) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepText, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeText != "*" {
+			lowText, highText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highText); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max // 5/15 means from 5 on, every 15
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q in %s field runs backwards", rangeText, f.name)
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// value reads a single value of the field, as a number or a name
+func (f field) value(text string) (int, error) {
+	if value, ok := f.names[strings.ToLower(text)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%q isn't a valid %s (%d-%d)", text, f.name, f.min, f.max)
+	}
+	return value, nil
+}
+
+// Next returns the first time after the given one that the schedule matches, to the minute, in the
+// given time's location. It returns the zero time if there's no match within five years.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+	loc := t.Location()
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule matches the day of t
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,144 @@
+// Package hooks runs user-defined actions when the conversation tree changes.
+//
+// A hook is either a shell command stored in the hooks.<event> setting, which receives the affected
+// node as JSON on stdin, or a Go function added with Register by a program embedding Bonsai. Events
+// are also posted to the webhooks stored in the database that subscribe to them.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// Timeout bounds how long a single hook may run before it's killed
+const Timeout = 10 * time.Second
+
+// Event identifies a point in a node's lifecycle that hooks can react to
+type Event string
+
+const (
+	NodeCreated      Event = "node-created"      // A user node was added, with the new node
+	ResponseReceived Event = "response-received" // An LLM response was stored, with the response node
+	Prune            Event = "prune"             // A node was pruned, with the node as it was before deletion
+)
+
+// Events lists every event in the order they're documented
+var Events = []Event{NodeCreated, ResponseReceived, Prune}
+
+// Func is a hook implemented in Go
+type Func func(ctx context.Context, event Event, node *db.Node) error
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Event][]Func)
+)
+
+// Register adds a Go hook for an event. Registered hooks run in order, before the configured command.
+func Register(event Event, fn Func) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[event] = append(registry[event], fn)
+}
+
+// ConfigKey returns the setting holding the shell command run for an event, e.g. hooks.node_created
+func ConfigKey(event Event) string {
+	return "hooks." + strings.ReplaceAll(string(event), "-", "_")
+}
+
+// Dispatcher runs the hooks configured in one database
+type Dispatcher struct {
+	db *db.Database
+
+	// OnError is called for each hook that fails. Hook failures never undo the change that fired them.
+	OnError func(event Event, err error)
+}
+
+// NewDispatcher creates a dispatcher that reads hook commands from the given database
+func NewDispatcher(database *db.Database) *Dispatcher {
+	return &Dispatcher{db: database}
+}
+
+// Fire runs every hook for the event with the given node and posts it to the webhooks subscribed to
+// the event, waiting for them to finish
+func (d *Dispatcher) Fire(event Event, node *db.Node) {
+	if d == nil || node == nil {
+		return
+	}
+
+	registryMu.RLock()
+	funcs := registry[event]
+	registryMu.RUnlock()
+
+	for _, fn := range funcs {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		err := fn(ctx, event, node)
+		cancel()
+		if err != nil {
+			d.reportError(event, fmt.Errorf("%s hook failed: %w", event, err))
+		}
+	}
+
+	command, err := d.db.GetConfigValue(ConfigKey(event))
+	if err != nil {
+		d.reportError(event, err)
+	} else if command != nil && strings.TrimSpace(*command) != "" {
+		if err := d.runCommand(event, *command, node); err != nil {
+			d.reportError(event, err)
+		}
+	}
+
+	d.postWebhooks(event, node)
+}
+
+// runCommand runs a hook command through the shell with the node as JSON on stdin
+func (d *Dispatcher) runCommand(event Event, command string, node *db.Node) error {
+	payload, err := json.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	// Keep hook output off stdout so it can't interfere with output other programs parse
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"BONSAI_EVENT="+string(event),
+		"BONSAI_NODE_ID="+node.ID,
+		"BONSAI_DB="+d.db.GetPath(),
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s hook timed out after %s", event, Timeout)
+		}
+		return fmt.Errorf("%s hook failed: %w", event, err)
+	}
+	return nil
+}
+
+// reportError passes a hook failure to OnError, if set
+func (d *Dispatcher) reportError(event Event, err error) {
+	if d.OnError != nil {
+		d.OnError(event, err)
+	}
+}
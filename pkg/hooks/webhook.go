@@ -0,0 +1,120 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// DefaultWebhookEvents are the events a webhook is sent when none are chosen
+var DefaultWebhookEvents = []Event{NodeCreated, ResponseReceived}
+
+// WebhookPayload is the JSON body posted to webhooks
+type WebhookPayload struct {
+	Event  Event    `json:"event"`
+	Text   string   `json:"text"` // One-line summary, which Slack's incoming webhooks show as the message
+	Node   *db.Node `json:"node"`
+	SentAt int64    `json:"sent_at"` // Unix seconds
+}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the webhook's secret and prefixed
+// with "sha256=", so receivers can check a delivery came from a database holding the secret
+const SignatureHeader = "X-Bonsai-Signature"
+
+// EventHeader carries the event a delivery is for
+const EventHeader = "X-Bonsai-Event"
+
+var webhookClient = &http.Client{Timeout: Timeout}
+
+// PostWebhook posts the event with the given node to a webhook, signing it if the webhook has a secret
+func PostWebhook(ctx context.Context, webhook *db.Webhook, event Event, node *db.Node) error {
+	body, err := json.Marshal(WebhookPayload{Event: event, Text: summarize(event, node), Node: node, SentAt: time.Now().Unix()})
+	if err != nil {
+		return fmt.Errorf("failed to encode node %s: %w", node.ID, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL %s: %w", webhook.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bonsai-webhook")
+	req.Header.Set(EventHeader, string(event))
+	if webhook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(webhook.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s webhook to %s failed: %w", event, webhook.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook to %s failed: %s: %s", event, webhook.URL, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// summarize describes an event on one line
+func summarize(event Event, node *db.Node) string {
+	content := strings.Join(strings.Fields(node.Content), " ")
+	if runes := []rune(content); len(runes) > 200 {
+		content = string(runes[:200]) + "…"
+	}
+
+	id := node.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	switch event {
+	case ResponseReceived:
+		model := "the model"
+		if node.Model != nil && *node.Model != "" {
+			model = *node.Model
+		}
+		return fmt.Sprintf("Response %s from %s: %s", id, model, content)
+	case Prune:
+		return fmt.Sprintf("Pruned %s: %s", id, content)
+	default:
+		return fmt.Sprintf("New %s node %s: %s", node.Type, id, content)
+	}
+}
+
+// postWebhooks posts the event to every webhook subscribed to it at once, waiting for them to finish
+func (d *Dispatcher) postWebhooks(event Event, node *db.Node) {
+	webhooks, err := d.db.GetWebhooks()
+	if err != nil {
+		d.reportError(event, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.Events, string(event)) {
+			continue
+		}
+		wg.Add(1)
+		go func(webhook *db.Webhook) {
+			defer wg.Done()
+			if err := PostWebhook(context.Background(), webhook, event, node); err != nil {
+				d.reportError(event, err)
+			}
+		}(webhook)
+	}
+	wg.Wait()
+}
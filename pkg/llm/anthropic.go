@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -18,9 +19,12 @@ type AnthropicClient struct {
 
 // AnthropicRequest represents the request structure for Anthropic API
 type AnthropicRequest struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
+	Model       string    `json:"model"`
+	MaxTokens   int       `json:"max_tokens"`
+	System      string    `json:"system,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	Messages    []Message `json:"messages"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // AnthropicResponse represents the response structure from Anthropic API
@@ -35,6 +39,13 @@ type ContentBlock struct {
 	Text string `json:"text"`
 }
 
+// AnthropicStreamEvent represents a single event in a streamed Anthropic response
+type AnthropicStreamEvent struct {
+	Type  string       `json:"type"`
+	Delta ContentBlock `json:"delta"`
+	Error *APIError    `json:"error,omitempty"`
+}
+
 // NewAnthropicClient creates a new Anthropic client
 func NewAnthropicClient(config Config) (*AnthropicClient, error) {
 	if config.APIKey == "" {
@@ -42,10 +53,8 @@ func NewAnthropicClient(config Config) (*AnthropicClient, error) {
 	}
 
 	return &AnthropicClient{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		config:     config,
+		httpClient: newHTTPClient(config),
 	}, nil
 }
 
@@ -62,58 +71,28 @@ func (c *AnthropicClient) GenerateResponse(ctx context.Context, prompt string, m
 
 // GenerateResponseFromHistory generates a response using conversation history
 func (c *AnthropicClient) GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error) {
-	// Use the provided model or default to claude-3-haiku
-	if model == "" {
-		model = "claude-3-haiku-20240307"
-	}
-
-	// Normalize model name for Anthropic
-	model = normalizeAnthropicModel(model)
-
-	maxTokens := 1000 // Default max tokens
-	if c.config.MaxTokens > 0 {
-		maxTokens = c.config.MaxTokens
-	}
-
-	request := AnthropicRequest{
-		Model:     model,
-		MaxTokens: maxTokens,
-		Messages:  messages,
-	}
-
-	jsonData, err := json.Marshal(request)
+	req, err := c.newRequest(ctx, messages, model, false)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.config.APIKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
+	defer recordLatency(ctx, time.Now())
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return "", requestError(err, c.config)
 	}
 	defer resp.Body.Close()
+	recordResponse(ctx, resp.StatusCode, resp.Header.Get("request-id"))
+
+	if err := checkResponseStatus(resp); err != nil {
+		return "", err
+	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-		}
-		return "", fmt.Errorf("API error: %s", apiErr.Message)
-	}
-
 	var response AnthropicResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return "", fmt.Errorf("failed to unmarshal response: %w", err)
@@ -134,6 +113,114 @@ func (c *AnthropicClient) GenerateResponseFromHistory(ctx context.Context, messa
 	return result, nil
 }
 
+// StreamResponseFromHistory generates a response using conversation history, passing text to
+// onChunk as it arrives, and returns the complete response
+func (c *AnthropicClient) StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error) {
+	req, err := c.newRequest(ctx, messages, model, true)
+	if err != nil {
+		return "", err
+	}
+
+	defer recordLatency(ctx, time.Now())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", requestError(err, c.config)
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp.StatusCode, resp.Header.Get("request-id"))
+
+	if err := checkResponseStatus(resp); err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type != "text_delta" {
+				return nil
+			}
+			result.WriteString(event.Delta.Text)
+			return onChunk(event.Delta.Text)
+		case "error":
+			if event.Error != nil {
+				return fmt.Errorf("API error: %s", event.Error.Message)
+			}
+			return fmt.Errorf("API error: %s", data)
+		}
+		return nil
+	})
+	if err != nil {
+		return result.String(), err
+	}
+
+	if result.Len() == 0 {
+		return "", fmt.Errorf("no response content received")
+	}
+
+	return result.String(), nil
+}
+
+// newRequest builds a Messages API request for the conversation
+func (c *AnthropicClient) newRequest(ctx context.Context, messages []Message, model string, stream bool) (*http.Request, error) {
+	// Normalize model name for Anthropic
+	model, err := normalizeAnthropicModel(model, c.config.ForceModel)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := 1000 // Default max tokens
+	if c.config.MaxTokens > 0 {
+		maxTokens = c.config.MaxTokens
+	}
+
+	// The Messages API takes system prompts apart from the conversation, so system messages from
+	// the tree follow the configured one there
+	system := []string{}
+	if c.config.System != "" {
+		system = append(system, c.config.System)
+	}
+	conversation := make([]Message, 0, len(messages))
+	for _, message := range messages {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+		} else {
+			conversation = append(conversation, message)
+		}
+	}
+
+	request := AnthropicRequest{
+		Model:       model,
+		MaxTokens:   maxTokens,
+		System:      strings.Join(system, "\n\n"),
+		Temperature: c.config.Temperature,
+		Messages:    conversation,
+		Stream:      stream,
+	}
+	recordGeneration(ctx, c.GetProviderName(), c.config, maxTokens)
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.config.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	return req, nil
+}
+
 // GetAvailableModels returns the list of available Anthropic models
 func (c *AnthropicClient) GetAvailableModels() []string {
 	return []string{
@@ -149,21 +236,8 @@ func (c *AnthropicClient) GetProviderName() string {
 	return "anthropic"
 }
 
-// normalizeAnthropicModel normalizes model names for Anthropic API
-func normalizeAnthropicModel(model string) string {
-	switch model {
-	case "claude-3-haiku", "claude-3.5-haiku":
-		return "claude-3-haiku-20240307"
-	case "claude-3-sonnet", "claude-3.5-sonnet":
-		return "claude-3-5-sonnet-20241022"
-	case "claude-3-opus":
-		return "claude-3-opus-20240229"
-	default:
-		// If it's already a full model name, use it as-is
-		if contains(model, []string{"claude-3", "claude-3.5"}) {
-			return model
-		}
-		// Default to haiku if unrecognized
-		return "claude-3-haiku-20240307"
-	}
+// normalizeAnthropicModel returns the name the Anthropic API knows a model by, failing for names it
+// doesn't recognize unless force is set, rather than answering with a model that wasn't asked for
+func normalizeAnthropicModel(model string, force bool) (string, error) {
+	return apiModelName("anthropic", model, force)
 }
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ResponseCache stores responses under a key identifying the request that produced them
+type ResponseCache interface {
+	CachedResponse(key string) (response string, ok bool, err error)
+	CacheResponse(key, model, response string) error
+}
+
+// CacheKey identifies a request by the provider, model, generation parameters and full message history
+func CacheKey(provider, model string, config Config, messages []Message) string {
+	data, _ := json.Marshal(struct {
+		Provider    string    `json:"provider"`
+		Model       string    `json:"model"`
+		BaseURL     string    `json:"base_url,omitempty"`
+		MaxTokens   int       `json:"max_tokens"`
+		Temperature *float64  `json:"temperature,omitempty"`
+		System      string    `json:"system,omitempty"`
+		Messages    []Message `json:"messages"`
+	}{provider, model, config.BaseURL, config.MaxTokens, config.Temperature, config.System, messages})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedClient answers requests it has seen before from a ResponseCache instead of the provider
+type cachedClient struct {
+	Client
+	cache  ResponseCache
+	config Config
+}
+
+// WithCache wraps a client so identical requests are answered from the cache. The config must be the
+// one the client was created with, since its parameters are part of each request's key. Cache
+// failures never fail a request; the provider is asked instead.
+func WithCache(client Client, cache ResponseCache, config Config) Client {
+	return &cachedClient{Client: client, cache: cache, config: config}
+}
+
+// GenerateResponse generates a response to a single prompt, using the cache
+func (c *cachedClient) GenerateResponse(ctx context.Context, prompt string, model string) (string, error) {
+	return c.GenerateResponseFromHistory(ctx, []Message{{Role: "user", Content: prompt}}, model)
+}
+
+// GenerateResponseFromHistory returns the cached response to the history, generating and storing it on a miss
+func (c *cachedClient) GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error) {
+	key := CacheKey(c.GetProviderName(), model, c.config, messages)
+	if response, ok, err := c.cache.CachedResponse(key); err == nil && ok {
+		c.recordHit(ctx)
+		return response, nil
+	}
+
+	response, err := c.Client.GenerateResponseFromHistory(ctx, messages, model)
+	if err != nil {
+		return "", err
+	}
+	c.cache.CacheResponse(key, model, response)
+	return response, nil
+}
+
+// StreamResponseFromHistory streams a response, delivering a cached one as a single chunk
+func (c *cachedClient) StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error) {
+	key := CacheKey(c.GetProviderName(), model, c.config, messages)
+	if response, ok, err := c.cache.CachedResponse(key); err == nil && ok {
+		c.recordHit(ctx)
+		if err := onChunk(response); err != nil {
+			return "", err
+		}
+		return response, nil
+	}
+
+	response, err := c.Client.StreamResponseFromHistory(ctx, messages, model, onChunk)
+	if err != nil {
+		return "", err
+	}
+	c.cache.CacheResponse(key, model, response)
+	return response, nil
+}
+
+// recordHit records a cached response's parameters, which are those of the request that produced it
+func (c *cachedClient) recordHit(ctx context.Context) {
+	recordGeneration(ctx, c.GetProviderName(), c.config, c.config.MaxTokens)
+	if generation := generationFrom(ctx); generation != nil {
+		generation.Cached = true
+	}
+}
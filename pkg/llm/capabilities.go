@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Modality is a kind of input a model understands
+type Modality string
+
+const (
+	ModalityText  Modality = "text"
+	ModalityImage Modality = "image"
+)
+
+// Capabilities describes what a model accepts, for checking requests before they're sent
+type Capabilities struct {
+	ContextWindow   int        // Tokens the model handles in one request, counting the history and the response
+	MaxOutputTokens int        // Longest response the model can give, in tokens
+	Modalities      []Modality // Kinds of input the model understands
+	MaxTemperature  float64    // Highest sampling temperature the model accepts; 0 if it doesn't take one
+}
+
+// Accepts reports whether the model understands input of the given kind
+func (c Capabilities) Accepts(modality Modality) bool {
+	return slices.Contains(c.Modalities, modality)
+}
+
+var (
+	textOnly  = []Modality{ModalityText}
+	textImage = []Modality{ModalityText, ModalityImage}
+)
+
+var (
+	capabilitiesMu sync.RWMutex
+
+	// modelCapabilities describes known models, keyed by model name prefix like modelPricing
+	modelCapabilities = map[string]Capabilities{
+		"gpt-3.5-turbo":     {ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: textOnly, MaxTemperature: 2},
+		"gpt-4":             {ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: textOnly, MaxTemperature: 2},
+		"gpt-4-turbo":       {ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: textImage, MaxTemperature: 2},
+		"gpt-4o":            {ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, MaxTemperature: 2},
+		"gpt-4o-mini":       {ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, MaxTemperature: 2},
+		"o1":                {ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage},
+		"o1-mini":           {ContextWindow: 128000, MaxOutputTokens: 65536, Modalities: textOnly},
+		"claude-3-haiku":    {ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, MaxTemperature: 1},
+		"claude-3.5-haiku":  {ContextWindow: 200000, MaxOutputTokens: 8192, Modalities: textOnly, MaxTemperature: 1},
+		"claude-3-sonnet":   {ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, MaxTemperature: 1},
+		"claude-3-5-sonnet": {ContextWindow: 200000, MaxOutputTokens: 8192, Modalities: textImage, MaxTemperature: 1},
+		"claude-3.5-sonnet": {ContextWindow: 200000, MaxOutputTokens: 8192, Modalities: textImage, MaxTemperature: 1},
+		"claude-3-opus":     {ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, MaxTemperature: 1},
+	}
+)
+
+// RegisterModel describes the models whose names start with prefix, replacing any earlier
+// description, so requests to models added by a provider or plugin can be checked too
+func RegisterModel(prefix string, capabilities Capabilities) error {
+	if prefix == "" {
+		return fmt.Errorf("invalid model prefix %q", prefix)
+	}
+
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+
+	modelCapabilities[prefix] = capabilities
+	return nil
+}
+
+// GetCapabilities returns what a model accepts, and false if the model is unknown. The longest
+// registered prefix of the model's name is used, so e.g. gpt-4o-mini isn't described as gpt-4o.
+func GetCapabilities(model string) (Capabilities, bool) {
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+
+	bestPrefix := ""
+	for prefix := range modelCapabilities {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+	if bestPrefix == "" {
+		return Capabilities{}, false
+	}
+	return modelCapabilities[bestPrefix], true
+}
+
+// imageReference matches an image embedded in a message: a data URI, or Markdown linking an image file
+var imageReference = regexp.MustCompile(`data:image/[A-Za-z0-9.+-]+;base64,|!\[[^\]]*\]\([^)\s]+\.(?i:png|jpe?g|gif|webp)\)`)
+
+// ContainsImage reports whether text embeds an image, which a text-only model sees as plain text
+func ContainsImage(text string) bool {
+	return imageReference.MatchString(text)
+}
+
+// CheckRequest checks a request against what the model is known to accept, returning a warning for
+// each problem: history that doesn't fit the context window or leaves too little room for the
+// response, images sent to a text-only model, and max_tokens or a temperature beyond the model's
+// limits. maxTokens is the longest response asked for. Nothing is reported for unknown models.
+func CheckRequest(model string, messages []Message, system string, maxTokens int, temperature *float64) []string {
+	capabilities, ok := GetCapabilities(model)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	if capabilities.ContextWindow > 0 {
+		input := CountHistoryTokens(messages)
+		if system != "" {
+			input += messageOverheadTokens + CountTokens(system)
+		}
+		switch {
+		case input >= capabilities.ContextWindow:
+			warnings = append(warnings, fmt.Sprintf("the history is about %d tokens, more than %s's %d-token context window; summarize it or branch from an earlier node",
+				input, model, capabilities.ContextWindow))
+		case maxTokens > 0 && input+maxTokens > capabilities.ContextWindow:
+			warnings = append(warnings, fmt.Sprintf("the history is about %d tokens, leaving room in %s's %d-token context window for a response of only %d of the %d tokens asked for",
+				input, model, capabilities.ContextWindow, capabilities.ContextWindow-input, maxTokens))
+		}
+	}
+
+	if !capabilities.Accepts(ModalityImage) {
+		for i, message := range messages {
+			if ContainsImage(message.Content) {
+				warnings = append(warnings, fmt.Sprintf("message %d embeds an image, but %s only accepts text; the image is sent as text", i+1, model))
+				break
+			}
+		}
+	}
+
+	if capabilities.MaxOutputTokens > 0 && maxTokens > capabilities.MaxOutputTokens {
+		warnings = append(warnings, fmt.Sprintf("max_tokens %d is more than the %d tokens %s can respond with", maxTokens, capabilities.MaxOutputTokens, model))
+	}
+	if temperature != nil {
+		if capabilities.MaxTemperature == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s doesn't take a temperature, but one is set", model))
+		} else if *temperature > capabilities.MaxTemperature {
+			warnings = append(warnings, fmt.Sprintf("temperature %s is more than the %s %s accepts",
+				strconv.FormatFloat(*temperature, 'g', -1, 64), strconv.FormatFloat(capabilities.MaxTemperature, 'g', -1, 64), model))
+		}
+	}
+	return warnings
+}
@@ -2,9 +2,17 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
 )
 
+// DefaultConnectTimeout bounds connecting to a provider unless Config.ConnectTimeout says otherwise
+const DefaultConnectTimeout = 10 * time.Second
+
 // Message represents a message in the conversation
 type Message struct {
 	Role    string `json:"role"`
@@ -21,6 +29,7 @@ type APIError struct {
 type Client interface {
 	GenerateResponse(ctx context.Context, prompt string, model string) (string, error)
 	GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error)
+	StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error)
 	GetAvailableModels() []string
 	GetProviderName() string
 }
@@ -30,55 +39,99 @@ type Config struct {
 	APIKey    string
 	BaseURL   string // Optional, for custom endpoints
 	MaxTokens int    // Optional, for response length limits
+
+	Temperature *float64 // Optional; the provider's default is used if nil
+	System      string   // Optional system prompt sent ahead of the conversation
+
+	ForceModel bool // Send model names the provider isn't known to serve as-is instead of failing
+
+	// Optional bound on connecting to the provider; DefaultConnectTimeout if zero. The request as a
+	// whole, including streaming the response, is bounded by its context alone.
+	ConnectTimeout time.Duration
 }
 
-// NewClient creates a new LLM client based on the provider
-func NewClient(provider string, config Config) (Client, error) {
-	switch provider {
-	case "openai", "gpt-3.5-turbo", "gpt-4", "gpt-4-turbo":
-		return NewOpenAIClient(config)
-	case "anthropic", "claude-3-sonnet", "claude-3-haiku", "claude-3-opus", "claude-3-5-sonnet":
-		return NewAnthropicClient(config)
-	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s", provider)
+// newHTTPClient creates the HTTP client a provider's requests are sent with, which gives up on
+// connecting after the config's connect timeout but leaves bounding the whole request to its context
+func newHTTPClient(config Config) *http.Client {
+	connect := config.ConnectTimeout
+	if connect <= 0 {
+		connect = DefaultConnectTimeout
 	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connect
+	return &http.Client{Transport: transport}
 }
 
-// DetectProviderFromModel detects the provider from a model name
-func DetectProviderFromModel(model string) string {
-	switch {
-	case contains(model, []string{"gpt-3.5", "gpt-4", "gpt-4o"}):
-		return "openai"
-	case contains(model, []string{"claude-3", "claude-3.5"}):
-		return "anthropic"
-	default:
-		return "openai" // Default fallback
+// requestError describes a request that failed before the provider answered, telling the request
+// running out of time apart from failing to connect in time
+func requestError(err error, config Config) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out: %w", err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		connect := config.ConnectTimeout
+		if connect <= 0 {
+			connect = DefaultConnectTimeout
+		}
+		return fmt.Errorf("failed to connect within %s: %w", connect, err)
 	}
+	return fmt.Errorf("failed to make request: %w", err)
 }
 
-// contains checks if a string contains any of the substrings
-func contains(s string, substrings []string) bool {
-	for _, sub := range substrings {
-		if len(sub) <= len(s) {
-			for i := 0; i <= len(s)-len(sub); i++ {
-				if s[i:i+len(sub)] == sub {
-					return true
-				}
-			}
+// NewClient creates a new LLM client based on the provider, or the model it serves
+// Models of registered providers, named "<name>/<model>", use that provider's client. Other model
+// names must be known to ResolveModel unless config.ForceModel is set.
+func NewClient(provider string, config Config) (Client, error) {
+	if registered, _, ok := ProviderForModel(provider); ok {
+		client, err := registered.New(config)
+		if err != nil {
+			return nil, err
 		}
+		return &prefixedClient{Client: client, name: registered.Name}, nil
 	}
-	return false
+
+	switch provider {
+	case "openai":
+		return NewOpenAIClient(config)
+	case "anthropic":
+		return NewAnthropicClient(config)
+	}
+
+	// Otherwise it's a model, served by the provider ResolveModel finds
+	resolved, _, err := ResolveModel(provider, config.ForceModel)
+	if err != nil {
+		return nil, err
+	}
+	if resolved == "anthropic" {
+		return NewAnthropicClient(config)
+	}
+	return NewOpenAIClient(config)
 }
 
 // NodeToMessage converts a database node to an LLM message
-// Maps node types: "user" -> "user", "llm" -> "assistant"
+// Maps node types: "user" -> "user", "llm" -> "assistant", "system" -> "system", "note" -> "user"
 func NodeToMessage(nodeType, content string) Message {
 	role := "user"
-	if nodeType == "llm" {
+	switch nodeType {
+	case "llm":
 		role = "assistant"
+	case "system":
+		role = "system"
 	}
 	return Message{
 		Role:    role,
 		Content: content,
 	}
 }
+
+// ContextMessage combines context pinned to a conversation into one user message, sent ahead of it
+// so the model sees it whichever branch is being continued
+func ContextMessage(contents []string) Message {
+	return Message{
+		Role:    "user",
+		Content: "Context pinned to this conversation:\n\n" + strings.Join(contents, "\n\n---\n\n"),
+	}
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Generation records the parameters a response was generated with, how the provider identified and
+// answered the request and how long it took, so results can be reproduced and followed up on with
+// the provider
+type Generation struct {
+	Provider    string   `json:"provider,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"` // Omitted when the provider's default was used
+	SystemHash  string   `json:"system_hash,omitempty"` // SHA-256 of the system prompt, if there was one
+	RequestID   string   `json:"request_id,omitempty"`
+	Status      int      `json:"status,omitempty"`     // HTTP status of the provider's response
+	LatencyMs   int64    `json:"latency_ms,omitempty"` // Wall-clock time from sending the request to the end of the response
+	Cached      bool     `json:"cached,omitempty"`     // Whether the response came from the response cache
+}
+
+type generationKey struct{}
+
+// WithGeneration returns a context whose requests record their parameters in the returned Generation
+func WithGeneration(ctx context.Context) (context.Context, *Generation) {
+	generation := &Generation{}
+	return context.WithValue(ctx, generationKey{}, generation), generation
+}
+
+// generationFrom returns the Generation a request should record itself in, or nil if nobody asked
+func generationFrom(ctx context.Context) *Generation {
+	generation, _ := ctx.Value(generationKey{}).(*Generation)
+	return generation
+}
+
+// recordGeneration records a request's parameters if the context asks for them
+func recordGeneration(ctx context.Context, provider string, config Config, maxTokens int) {
+	generation := generationFrom(ctx)
+	if generation == nil {
+		return
+	}
+	generation.Provider = provider
+	generation.MaxTokens = maxTokens
+	generation.Temperature = config.Temperature
+	generation.SystemHash = SystemHash(config.System)
+}
+
+// recordResponse records the provider's ID for a request and the HTTP status it answered with, if
+// the context asks for them. Providers that don't answer over HTTP pass a status of 0.
+func recordResponse(ctx context.Context, status int, requestID string) {
+	if generation := generationFrom(ctx); generation != nil {
+		generation.Status = status
+		generation.RequestID = requestID
+	}
+}
+
+// recordLatency records the time since a request was sent if the context asks for it; deferred
+// right before sending so it covers reading the whole response, streamed or not
+func recordLatency(ctx context.Context, sent time.Time) {
+	if generation := generationFrom(ctx); generation != nil {
+		generation.LatencyMs = time.Since(sent).Milliseconds()
+	}
+}
+
+// SystemHash identifies a system prompt without storing it, or is empty if there's none
+func SystemHash(system string) string {
+	if system == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(system))
+	return hex.EncodeToString(sum[:])
+}
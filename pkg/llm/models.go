@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// modelAlias is the provider serving a model name bai accepts and the name sent to its API
+type modelAlias struct {
+	provider string
+	apiModel string
+}
+
+// modelAliases lists the model names bai accepts for the built-in providers
+var modelAliases = map[string]modelAlias{
+	"gpt-3.5":           {"openai", "gpt-3.5-turbo"},
+	"gpt-3.5-turbo":     {"openai", "gpt-3.5-turbo"},
+	"gpt-4":             {"openai", "gpt-4"},
+	"gpt-4-turbo":       {"openai", "gpt-4-turbo"},
+	"gpt-4o":            {"openai", "gpt-4o"},
+	"gpt-4o-mini":       {"openai", "gpt-4o-mini"},
+	"o1":                {"openai", "o1"},
+	"o1-mini":           {"openai", "o1-mini"},
+	"claude-3-haiku":    {"anthropic", "claude-3-haiku-20240307"},
+	"claude-3.5-haiku":  {"anthropic", "claude-3-5-haiku-20241022"},
+	"claude-3-sonnet":   {"anthropic", "claude-3-sonnet-20240229"},
+	"claude-3-5-sonnet": {"anthropic", "claude-3-5-sonnet-20241022"},
+	"claude-3.5-sonnet": {"anthropic", "claude-3-5-sonnet-20241022"},
+	"claude-3-opus":     {"anthropic", "claude-3-opus-20240229"},
+}
+
+// pinnedModels match the full names providers give each release of a model, which are sent as-is,
+// e.g. claude-3-5-sonnet-20241022 or gpt-4o-2024-08-06
+var pinnedModels = []struct {
+	provider string
+	pattern  *regexp.Regexp
+}{
+	{"anthropic", regexp.MustCompile(`^claude-[a-z0-9.-]+-(\d{8}|latest)$`)},
+	{"openai", regexp.MustCompile(`^(gpt-[a-z0-9.-]+|o1(-mini|-preview)?)-\d{4}-\d{2}-\d{2}$`)},
+}
+
+// builtinPrefixes name the built-in provider serving models bai doesn't know yet, sent as-is with
+// ForceModel, such as a newly released gpt-5 or claude-opus-5
+var builtinPrefixes = []ProviderRule{
+	{Prefix: "gpt-", Provider: "openai"},
+	{Prefix: "chatgpt-", Provider: "openai"},
+	{Prefix: "o1", Provider: "openai"},
+	{Prefix: "o3", Provider: "openai"},
+	{Prefix: "o4", Provider: "openai"},
+	{Prefix: "claude-", Provider: "anthropic"},
+}
+
+// maxSuggestions is how many similar names an UnknownModelError suggests
+const maxSuggestions = 3
+
+// UnknownModelError is returned for model names no built-in provider recognizes, instead of sending
+// the request to a model that wasn't asked for
+type UnknownModelError struct {
+	Model       string
+	Suggestions []string // Known names similar to Model, most similar first
+}
+
+func (e *UnknownModelError) Error() string {
+	message := fmt.Sprintf("unknown model %q", e.Model)
+	if len(e.Suggestions) > 0 {
+		message += "; did you mean " + strings.Join(e.Suggestions, " or ") + "?"
+	}
+	return message + " (--force-model sends unrecognized names to the provider as-is)"
+}
+
+// ResolveModel returns the built-in provider serving a model and the name sent to its API: the
+// full name for one of the short names bai accepts, or the name itself for a provider's full name
+// for a release. Other names fail with an UnknownModelError suggesting similar ones, unless force is
+// set, in which case they're sent as-is to the built-in provider whose naming they follow. Names
+// following neither provider's naming fail even then; they name their provider as "<name>/<model>".
+func ResolveModel(model string, force bool) (string, string, error) {
+	if alias, ok := modelAliases[model]; ok {
+		return alias.provider, alias.apiModel, nil
+	}
+	for _, pinned := range pinnedModels {
+		if pinned.pattern.MatchString(model) {
+			return pinned.provider, model, nil
+		}
+	}
+	if force && model != "" {
+		if route := RouteModel(model, builtinPrefixes); route != model {
+			return ProviderName(route), model, nil
+		}
+		return "", "", fmt.Errorf("can't tell which provider serves %q; name it as <provider>/%s, e.g. openai/%s, or route it with a provider rule", model, model, model)
+	}
+	return "", "", &UnknownModelError{Model: model, Suggestions: suggestModels(model)}
+}
+
+// DetectProvider returns the name of the provider serving a model: the prefix of "<name>/<model>"
+// models, otherwise the built-in provider ResolveModel finds for it when forced. It returns false
+// for names no provider is known to serve, rather than guessing one.
+func DetectProvider(model string) (string, bool) {
+	if name, _, ok := strings.Cut(model, "/"); ok {
+		return name, true
+	}
+	provider, _, err := ResolveModel(model, true)
+	return provider, err == nil
+}
+
+// apiModelName returns the name a built-in provider's API knows a model by, failing for models it
+// doesn't serve unless force is set, in which case names bai doesn't know are sent as-is. An empty
+// model always fails rather than being answered by a default model.
+func apiModelName(provider, model string, force bool) (string, error) {
+	if model == "" {
+		return "", fmt.Errorf("no model given for %s; name one, e.g. with --llm", provider)
+	}
+	resolved, apiModel, err := ResolveModel(model, false)
+	if err != nil {
+		if force {
+			return model, nil
+		}
+		return "", err
+	}
+	if resolved != provider && !force {
+		return "", fmt.Errorf("%s is served by %s, not %s", model, resolved, provider)
+	}
+	return apiModel, nil
+}
+
+// KnownModels returns the short model names bai accepts for the built-in providers, sorted
+func KnownModels() []string {
+	names := make([]string, 0, len(modelAliases))
+	for name := range modelAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// suggestModels returns the known model names most similar to an unknown one: those within a few
+// edits of it, or that it starts with, as when a release date is mistyped
+func suggestModels(model string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	lowered := strings.ToLower(model)
+	limit := max(2, len(lowered)/3)
+
+	var candidates []candidate
+	for _, name := range KnownModels() {
+		distance := editDistance(lowered, name)
+		if strings.HasPrefix(lowered, name) {
+			distance = min(distance, 1)
+		}
+		if distance <= limit {
+			candidates = append(candidates, candidate{name, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, c.name)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -18,9 +19,11 @@ type OpenAIClient struct {
 
 // OpenAIRequest represents the request structure for OpenAI API
 type OpenAIRequest struct {
-	Model     string    `json:"model"`
-	Messages  []Message `json:"messages"`
-	MaxTokens int       `json:"max_tokens,omitempty"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // OpenAIResponse represents the response structure from OpenAI API
@@ -34,6 +37,17 @@ type Choice struct {
 	Message Message `json:"message"`
 }
 
+// OpenAIStreamChunk represents a single chunk of a streamed OpenAI response
+type OpenAIStreamChunk struct {
+	Choices []StreamChoice `json:"choices"`
+	Error   *APIError      `json:"error,omitempty"`
+}
+
+// StreamChoice represents a choice in a streamed OpenAI response chunk
+type StreamChoice struct {
+	Delta Message `json:"delta"`
+}
+
 
 // NewOpenAIClient creates a new OpenAI client
 func NewOpenAIClient(config Config) (*OpenAIClient, error) {
@@ -42,10 +56,8 @@ func NewOpenAIClient(config Config) (*OpenAIClient, error) {
 	}
 
 	return &OpenAIClient{
-		config: config,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		config:     config,
+		httpClient: newHTTPClient(config),
 	}, nil
 }
 
@@ -62,65 +74,131 @@ func (c *OpenAIClient) GenerateResponse(ctx context.Context, prompt string, mode
 
 // GenerateResponseFromHistory generates a response using conversation history
 func (c *OpenAIClient) GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error) {
-	// Use the provided model or default to gpt-3.5-turbo
-	if model == "" {
-		model = "gpt-3.5-turbo"
+	req, err := c.newRequest(ctx, messages, model, false)
+	if err != nil {
+		return "", err
 	}
 
-	// Normalize model name for OpenAI
-	model = normalizeOpenAIModel(model)
-
-	request := OpenAIRequest{
-		Model:    model,
-		Messages: messages,
+	defer recordLatency(ctx, time.Now())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", requestError(err, c.config)
 	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp.StatusCode, resp.Header.Get("x-request-id"))
 
-	if c.config.MaxTokens > 0 {
-		request.MaxTokens = c.config.MaxTokens
+	if err := checkResponseStatus(resp); err != nil {
+		return "", err
 	}
 
-	jsonData, err := json.Marshal(request)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	var response OpenAIResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("no response choices received")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+// StreamResponseFromHistory generates a response using conversation history, passing text to
+// onChunk as it arrives, and returns the complete response
+func (c *OpenAIClient) StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error) {
+	req, err := c.newRequest(ctx, messages, model, true)
+	if err != nil {
+		return "", err
+	}
 
+	defer recordLatency(ctx, time.Now())
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return "", requestError(err, c.config)
 	}
 	defer resp.Body.Close()
+	recordResponse(ctx, resp.StatusCode, resp.Header.Get("x-request-id"))
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+	if err := checkResponseStatus(resp); err != nil {
+		return "", err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err != nil {
-			return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	var result strings.Builder
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
 		}
-		return "", fmt.Errorf("API error: %s", apiErr.Message)
-	}
 
-	var response OpenAIResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		result.WriteString(text)
+		return onChunk(text)
+	})
+	if err != nil {
+		return result.String(), err
 	}
 
-	if len(response.Choices) == 0 {
+	if result.Len() == 0 {
 		return "", fmt.Errorf("no response choices received")
 	}
 
-	return response.Choices[0].Message.Content, nil
+	return result.String(), nil
+}
+
+// newRequest builds a Chat Completions API request for the conversation
+func (c *OpenAIClient) newRequest(ctx context.Context, messages []Message, model string, stream bool) (*http.Request, error) {
+	// Normalize model name for OpenAI
+	model, err := normalizeOpenAIModel(model, c.config.ForceModel)
+	if err != nil {
+		return nil, err
+	}
+
+	// The system prompt goes ahead of the conversation as a message of its own
+	if c.config.System != "" {
+		messages = append([]Message{{Role: "system", Content: c.config.System}}, messages...)
+	}
+
+	request := OpenAIRequest{
+		Model:       model,
+		Messages:    messages,
+		Temperature: c.config.Temperature,
+		Stream:      stream,
+	}
+
+	if c.config.MaxTokens > 0 {
+		request.MaxTokens = c.config.MaxTokens
+	}
+	recordGeneration(ctx, c.GetProviderName(), c.config, request.MaxTokens)
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+
+	return req, nil
 }
 
 // GetAvailableModels returns the list of available OpenAI models
@@ -139,17 +217,8 @@ func (c *OpenAIClient) GetProviderName() string {
 	return "openai"
 }
 
-// normalizeOpenAIModel normalizes model names for OpenAI API
-func normalizeOpenAIModel(model string) string {
-	switch model {
-	case "gpt-4", "gpt-4-turbo":
-		return "gpt-4"
-	case "gpt-3.5", "gpt-3.5-turbo":
-		return "gpt-3.5-turbo"
-	case "gpt-4o", "gpt-4o-mini":
-		return model
-	default:
-		// If it's not a recognized model, try to use it as-is
-		return model
-	}
+// normalizeOpenAIModel returns the name the OpenAI API knows a model by, failing for names it
+// doesn't recognize unless force is set
+func normalizeOpenAIModel(model string, force bool) (string, error) {
+	return apiModelName("openai", model, force)
 }
@@ -0,0 +1,212 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pluginModelsTimeout bounds how long a plugin may take to list its models
+const pluginModelsTimeout = 10 * time.Second
+
+// PluginClient implements the Client interface by running an external provider plugin
+//
+// The plugin is an executable started once per request. It reads a single PluginRequest as JSON
+// on stdin and writes PluginResponse objects to stdout, one JSON object per line: any number of
+// chunks while a streamed response is generated, then one with the content or an error.
+type PluginClient struct {
+	path   string
+	config Config
+}
+
+// PluginRequest is sent to a provider plugin on stdin
+type PluginRequest struct {
+	Type      string    `json:"type"` // "generate" or "models"
+	Model     string    `json:"model,omitempty"`
+	Messages  []Message `json:"messages,omitempty"`
+	MaxTokens int       `json:"max_tokens,omitempty"`
+	Stream    bool      `json:"stream,omitempty"` // Whether the plugin may send chunks before the content
+
+	Temperature *float64 `json:"temperature,omitempty"` // Omitted to use the provider's default
+	System      string   `json:"system,omitempty"`
+}
+
+// PluginResponse is a line of output from a provider plugin
+type PluginResponse struct {
+	Chunk   string   `json:"chunk,omitempty"`   // Part of a streamed response
+	Content string   `json:"content,omitempty"` // The complete response; defaults to the chunks joined together
+	Models  []string `json:"models,omitempty"`  // The answer to a "models" request
+	Error   string   `json:"error,omitempty"`
+
+	RequestID string `json:"request_id,omitempty"` // The provider's ID for the request, recorded on the response node
+}
+
+// NewPluginClient creates a client for the provider plugin executable at path
+// The API key, if any, is passed to the plugin in the BONSAI_API_KEY environment variable.
+func NewPluginClient(path string, config Config) *PluginClient {
+	return &PluginClient{path: path, config: config}
+}
+
+// GenerateResponse generates a response using the plugin
+func (c *PluginClient) GenerateResponse(ctx context.Context, prompt string, model string) (string, error) {
+	messages := []Message{
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+	return c.GenerateResponseFromHistory(ctx, messages, model)
+}
+
+// GenerateResponseFromHistory generates a response using conversation history
+func (c *PluginClient) GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error) {
+	return c.StreamResponseFromHistory(ctx, messages, model, nil)
+}
+
+// StreamResponseFromHistory generates a response using conversation history, passing text to
+// onChunk as the plugin sends it, and returns the complete response
+func (c *PluginClient) StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error) {
+	request := PluginRequest{
+		Type:      "generate",
+		Model:     model,
+		Messages:  messages,
+		MaxTokens: c.config.MaxTokens,
+		Stream:    onChunk != nil,
+
+		Temperature: c.config.Temperature,
+		System:      c.config.System,
+	}
+	recordGeneration(ctx, c.GetProviderName(), c.config, c.config.MaxTokens)
+
+	var result strings.Builder
+	defer recordLatency(ctx, time.Now())
+	response, err := c.run(ctx, request, func(chunk string) error {
+		result.WriteString(chunk)
+		if onChunk != nil {
+			return onChunk(chunk)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	recordResponse(ctx, 0, response.RequestID)
+
+	if response.Content != "" {
+		return response.Content, nil
+	}
+	if result.Len() == 0 {
+		return "", fmt.Errorf("no response received from plugin %s", c.GetProviderName())
+	}
+	return result.String(), nil
+}
+
+// GetAvailableModels asks the plugin for the models it serves
+func (c *PluginClient) GetAvailableModels() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginModelsTimeout)
+	defer cancel()
+
+	response, err := c.run(ctx, PluginRequest{Type: "models"}, nil)
+	if err != nil {
+		return nil
+	}
+	return response.Models
+}
+
+// GetProviderName returns the provider name, taken from the plugin's file name
+func (c *PluginClient) GetProviderName() string {
+	return strings.TrimSuffix(filepath.Base(c.path), filepath.Ext(c.path))
+}
+
+// run sends a request to the plugin, passing chunks to onChunk, and returns its final response
+func (c *PluginClient) run(ctx context.Context, request PluginRequest, onChunk StreamHandler) (*PluginResponse, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, c.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = os.Environ()
+	if c.config.APIKey != "" {
+		cmd.Env = append(cmd.Env, "BONSAI_API_KEY="+c.config.APIKey)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin %s: %w", c.GetProviderName(), err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", c.GetProviderName(), err)
+	}
+
+	final := &PluginResponse{}
+	readErr := readPluginResponses(stdout, func(response *PluginResponse) error {
+		if response.Chunk != "" && onChunk != nil {
+			if err := onChunk(response.Chunk); err != nil {
+				return err
+			}
+		}
+		if response.Content != "" || response.Models != nil || response.Error != "" || response.RequestID != "" {
+			final = response
+		}
+		return nil
+	})
+	if readErr != nil {
+		// Stop the plugin rather than waiting for it to notice nobody is reading
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+
+	switch {
+	case readErr != nil:
+		return nil, readErr
+	case final.Error != "":
+		return nil, fmt.Errorf("plugin %s error: %s", c.GetProviderName(), final.Error)
+	case waitErr != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("plugin %s timed out: %w", c.GetProviderName(), ctx.Err())
+	case waitErr != nil:
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", c.GetProviderName(), waitErr, message)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", c.GetProviderName(), waitErr)
+	}
+	return final, nil
+}
+
+// readPluginResponses decodes each line of plugin output, calling onResponse until the output ends
+func readPluginResponses(r io.Reader, onResponse func(*PluginResponse) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var response PluginResponse
+		if err := json.Unmarshal(line, &response); err != nil {
+			return fmt.Errorf("failed to unmarshal plugin output: %w", err)
+		}
+		if err := onResponse(&response); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read plugin output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers is how many requests a Pool runs at once unless told otherwise
+const DefaultWorkers = 4
+
+// Pool bounds how many generation requests run at once, overall and against each provider, so
+// large fan-outs are fast without flooding any one provider. It is safe for concurrent use.
+type Pool struct {
+	workers int
+	limits  map[string]int // Provider name to the most requests sent to it at once
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// NewPool creates a pool running up to workers tasks at once, DefaultWorkers if workers isn't
+// positive, and no more than limits[provider] requests against a provider at once. Providers
+// without a limit are only bounded by the workers.
+func NewPool(workers int, limits map[string]int) *Pool {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &Pool{workers: workers, limits: limits, slots: make(map[string]chan struct{})}
+}
+
+// Acquire waits until the model's provider has room for another request and returns the function
+// that frees the slot again. It fails only if ctx is done first.
+func (p *Pool) Acquire(ctx context.Context, model string) (func(), error) {
+	slots := p.providerSlots(ProviderName(model))
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// providerSlots returns the semaphore limiting requests to a provider, or nil if it has no limit
+func (p *Pool) providerSlots(provider string) chan struct{} {
+	limit := p.limits[provider]
+	if limit <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	slots, ok := p.slots[provider]
+	if !ok {
+		slots = make(chan struct{}, limit)
+		p.slots[provider] = slots
+	}
+	return slots
+}
+
+// Run calls fn for each index below n, running up to workers calls at once (the pool's own number
+// if workers isn't positive), and returns once all have finished. A call's error only counts it as
+// failed in the progress passed to onProgress, which is called after each call finishes and never
+// concurrently; onProgress may be nil.
+func (p *Pool) Run(n, workers int, fn func(i int) error, onProgress func(Progress)) {
+	if workers <= 0 {
+		workers = p.workers
+	}
+
+	progress := Progress{Total: n}
+	started := time.Now()
+	var progressMu sync.Mutex
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				err := fn(i)
+
+				progressMu.Lock()
+				progress.Done++
+				if err != nil {
+					progress.Failed++
+				}
+				progress.Elapsed = time.Since(started)
+				if onProgress != nil {
+					onProgress(progress)
+				}
+				progressMu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Progress is how far a Pool's Run has got
+type Progress struct {
+	Total   int
+	Done    int // Finished, successfully or not
+	Failed  int
+	Elapsed time.Duration
+}
+
+// Bar renders the progress as a bar of the given width followed by the counts, e.g.
+// "[██████░░░░] 6/10 · 1 failed · 12s"
+func (p Progress) Bar(width int) string {
+	filled := 0
+	if p.Total > 0 {
+		filled = width * p.Done / p.Total
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]")
+	fmt.Fprintf(&b, " %d/%d", p.Done, p.Total)
+	if p.Failed > 0 {
+		fmt.Fprintf(&b, " · %d failed", p.Failed)
+	}
+	fmt.Fprintf(&b, " · %s", p.Elapsed.Round(time.Second))
+	return b.String()
+}
+
+// ProviderName returns the name of the provider serving a model as DetectProvider finds it, or an
+// empty string if no provider is known to serve it. It doesn't need the provider to be registered yet.
+func ProviderName(model string) string {
+	provider, _ := DetectProvider(model)
+	return provider
+}
+
+// ParseProviderLimits parses per-provider request limits written as "provider=N" pairs separated by
+// commas, e.g. "anthropic=2,openai=8"
+func ParseProviderLimits(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, limit, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q isn't of the form provider=N", pair)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("the limit for %s must be a whole number of 1 or more", name)
+		}
+		limits[name] = n
+	}
+	return limits, nil
+}
@@ -0,0 +1,177 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider is an LLM provider added with Register, such as an in-house model gateway
+// Models are addressed as "<name>/<model>", e.g. "gateway/llama-3-70b". The built-in providers are
+// registered too, as "openai" and "anthropic", so "anthropic/claude-…" names the provider explicitly.
+type Provider struct {
+	Name      string
+	APIKeyEnv string                              // Optional environment variable holding the provider's API key
+	New       func(config Config) (Client, error) // Creates a client; the model name passed to it has the "<name>/" prefix removed
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		"openai":    {Name: "openai", APIKeyEnv: "OPENAI_API_KEY", New: newExplicitOpenAIClient},
+		"anthropic": {Name: "anthropic", APIKeyEnv: "ANTHROPIC_API_KEY", New: newExplicitAnthropicClient},
+	}
+)
+
+// newExplicitOpenAIClient creates the client for models named "openai/<model>", which are sent to
+// OpenAI as-is when bai doesn't know them
+func newExplicitOpenAIClient(config Config) (Client, error) {
+	config.ForceModel = true
+	return NewOpenAIClient(config)
+}
+
+// newExplicitAnthropicClient creates the client for models named "anthropic/<model>", which are
+// sent to Anthropic as-is when bai doesn't know them
+func newExplicitAnthropicClient(config Config) (Client, error) {
+	config.ForceModel = true
+	return NewAnthropicClient(config)
+}
+
+// Register adds a provider, replacing any earlier provider with the same name
+func Register(provider Provider) error {
+	if provider.Name == "" || strings.Contains(provider.Name, "/") {
+		return fmt.Errorf("invalid provider name %q", provider.Name)
+	}
+	if provider.New == nil {
+		return fmt.Errorf("provider %s has no client constructor", provider.Name)
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	providers[provider.Name] = provider
+	return nil
+}
+
+// ProviderForModel returns the registered provider serving a "<name>/<model>" model and the model
+// name without the provider prefix
+func ProviderForModel(model string) (Provider, string, bool) {
+	name, providerModel, ok := strings.Cut(model, "/")
+	if !ok {
+		return Provider{}, "", false
+	}
+
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[name]
+	return provider, providerModel, ok
+}
+
+// LookupProvider returns the registered provider with the given name
+func LookupProvider(name string) (Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// RegisteredProviders returns the names of all registered providers, sorted
+func RegisteredProviders() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// prefixedClient passes requests to a registered provider's client without the "<name>/" model prefix
+type prefixedClient struct {
+	Client
+	name string
+}
+
+// providerModel strips the provider prefix from a model name
+func (c *prefixedClient) providerModel(model string) string {
+	return strings.TrimPrefix(model, c.name+"/")
+}
+
+// GenerateResponse generates a response using the provider
+func (c *prefixedClient) GenerateResponse(ctx context.Context, prompt string, model string) (string, error) {
+	return c.Client.GenerateResponse(ctx, prompt, c.providerModel(model))
+}
+
+// GenerateResponseFromHistory generates a response using conversation history
+func (c *prefixedClient) GenerateResponseFromHistory(ctx context.Context, messages []Message, model string) (string, error) {
+	return c.Client.GenerateResponseFromHistory(ctx, messages, c.providerModel(model))
+}
+
+// StreamResponseFromHistory streams a response using conversation history
+func (c *prefixedClient) StreamResponseFromHistory(ctx context.Context, messages []Message, model string, onChunk StreamHandler) (string, error) {
+	return c.Client.StreamResponseFromHistory(ctx, messages, c.providerModel(model), onChunk)
+}
+
+// GetAvailableModels returns the provider's models with the "<name>/" prefix bai addresses them by
+func (c *prefixedClient) GetAvailableModels() []string {
+	models := c.Client.GetAvailableModels()
+	prefixed := make([]string, len(models))
+	for i, model := range models {
+		prefixed[i] = c.name + "/" + model
+	}
+	return prefixed
+}
+
+// ProviderRule routes the models whose names start with Prefix to the provider named Provider, so
+// e.g. "llama-3-70b" is sent to a local gateway as "gateway/llama-3-70b"
+type ProviderRule struct {
+	Prefix   string
+	Provider string
+}
+
+// ParseProviderRules parses provider rules written as "prefix=provider" pairs separated by commas,
+// e.g. "llama=gateway,mistral=ollama"
+func ParseProviderRules(value string) ([]ProviderRule, error) {
+	var rules []ProviderRule
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		prefix, provider, ok := strings.Cut(pair, "=")
+		prefix, provider = strings.TrimSpace(prefix), strings.TrimSpace(provider)
+		if !ok || prefix == "" || provider == "" {
+			return nil, fmt.Errorf("%q isn't of the form prefix=provider", pair)
+		}
+		if strings.Contains(prefix, "/") || strings.Contains(provider, "/") {
+			return nil, fmt.Errorf("%q can't contain a slash; models named <provider>/<model> already choose their provider", pair)
+		}
+		rules = append(rules, ProviderRule{Prefix: prefix, Provider: provider})
+	}
+	return rules, nil
+}
+
+// RouteModel returns the "<provider>/<model>" name of a model the rules route to a provider, using
+// the rule with the longest matching prefix. Models already naming their provider and models no
+// rule matches are returned unchanged.
+func RouteModel(model string, rules []ProviderRule) string {
+	if strings.Contains(model, "/") {
+		return model
+	}
+
+	best := -1
+	for i, rule := range rules {
+		if strings.HasPrefix(model, rule.Prefix) && (best < 0 || len(rule.Prefix) > len(rules[best].Prefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return model
+	}
+	return rules[best].Provider + "/" + model
+}
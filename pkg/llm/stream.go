@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StreamHandler receives each piece of text as a streamed response is generated
+// Returning an error stops the stream.
+type StreamHandler func(chunk string) error
+
+type streamHandlerKey struct{}
+
+// WithStreamHandler returns a context asking code that generates responses with it to stream them
+// to onChunk as they arrive, for callers that can't pass the handler along themselves
+func WithStreamHandler(ctx context.Context, onChunk StreamHandler) context.Context {
+	return context.WithValue(ctx, streamHandlerKey{}, onChunk)
+}
+
+// StreamHandlerFrom returns the handler the context asks responses to be streamed to, or nil
+func StreamHandlerFrom(ctx context.Context) StreamHandler {
+	onChunk, _ := ctx.Value(streamHandlerKey{}).(StreamHandler)
+	return onChunk
+}
+
+// readServerSentEvents reads a Server-Sent Events stream, calling onEvent with each event's
+// name and data until the stream ends or onEvent returns an error
+func readServerSentEvents(r io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line dispatches the event collected so far
+		if line == "" {
+			if len(data) > 0 {
+				if err := onEvent(event, strings.Join(data, "\n")); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read response stream: %w", err)
+	}
+	if len(data) > 0 {
+		return onEvent(event, strings.Join(data, "\n"))
+	}
+	return nil
+}
+
+// checkResponseStatus returns an error describing a failed API response
+func checkResponseStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiErr APIError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("API error: %s", apiErr.Message)
+}
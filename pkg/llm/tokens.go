@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// messageOverheadTokens is what each message's role and delimiters add to a request, and
+// replyPrimingTokens what the request adds for the model to start replying
+const (
+	messageOverheadTokens = 4
+	replyPrimingTokens    = 3
+)
+
+// pretokenizer splits text into the pieces a BPE tokenizer like tiktoken encodes separately:
+// contractions, words with a leading space or symbol, groups of up to three digits, runs of
+// punctuation, and whitespace
+var pretokenizer = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\pL\pN]?\pL+|\pN{1,3}| ?[^\s\pL\pN]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// CountTokens estimates how many tokens text encodes to, splitting it the way tiktoken does and
+// estimating each piece: common short words are a single token, longer words a token per few
+// letters, and text in scripts other than Latin about a token per character. It's closer to real
+// tokenizers than EstimateTokens, especially for code, numbers and non-English text.
+func CountTokens(text string) int {
+	tokens := 0
+	for _, piece := range pretokenizer.FindAllString(text, -1) {
+		tokens += pieceTokens(piece)
+	}
+	return tokens
+}
+
+// pieceTokens estimates the tokens of one pretokenized piece
+func pieceTokens(piece string) int {
+	word := strings.TrimSpace(piece)
+	if word == "" {
+		return 1
+	}
+
+	runes := utf8.RuneCountInString(word)
+	if runes != len(word) {
+		// Multi-byte scripts get far fewer characters per token
+		return runes
+	}
+	last, _ := utf8.DecodeLastRuneInString(word)
+	switch {
+	case unicode.IsDigit(last):
+		return 1
+	case unicode.IsLetter(last):
+		// A leading symbol, as in "(word", usually merges into the word
+		if runes <= 7 {
+			return 1
+		}
+		return (runes + 5) / 6
+	default:
+		return (runes + 1) / 2
+	}
+}
+
+// CountHistoryTokens estimates the input tokens of a request sending the messages as history,
+// including the tokens each message's formatting adds
+func CountHistoryTokens(messages []Message) int {
+	tokens := replyPrimingTokens
+	for _, message := range messages {
+		tokens += messageOverheadTokens + CountTokens(message.Content)
+	}
+	return tokens
+}
+
+// GetContextWindow returns how many tokens a model accepts, and false if the model is unknown
+func GetContextWindow(model string) (int, bool) {
+	capabilities, ok := GetCapabilities(model)
+	if !ok || capabilities.ContextWindow == 0 {
+		return 0, false
+	}
+	return capabilities.ContextWindow, true
+}
@@ -0,0 +1,57 @@
+package llm
+
+import "strings"
+
+// ModelPricing holds the price of a model in USD per million tokens
+type ModelPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// modelPricing lists known model prices, keyed by model name prefix
+// Longer prefixes are matched first so e.g. gpt-4o-mini isn't priced as gpt-4o
+var modelPricing = map[string]ModelPricing{
+	"gpt-3.5-turbo":     {0.50, 1.50},
+	"gpt-4":             {30.00, 60.00},
+	"gpt-4-turbo":       {10.00, 30.00},
+	"gpt-4o":            {2.50, 10.00},
+	"gpt-4o-mini":       {0.15, 0.60},
+	"claude-3-haiku":    {0.25, 1.25},
+	"claude-3.5-haiku":  {0.80, 4.00},
+	"claude-3-sonnet":   {3.00, 15.00},
+	"claude-3-5-sonnet": {3.00, 15.00},
+	"claude-3.5-sonnet": {3.00, 15.00},
+	"claude-3-opus":     {15.00, 75.00},
+}
+
+// EstimateTokens gives a rough token count for text, using the common ~4 characters per token heuristic
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// GetModelPricing returns the pricing for a model, and false if the model's price is unknown
+func GetModelPricing(model string) (ModelPricing, bool) {
+	bestPrefix := ""
+	for prefix := range modelPricing {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+	if bestPrefix == "" {
+		return ModelPricing{}, false
+	}
+	return modelPricing[bestPrefix], true
+}
+
+// EstimateCost estimates the cost in USD of a request, and false if the model's price is unknown
+func EstimateCost(model string, inputTokens, outputTokens int) (float64, bool) {
+	pricing, ok := GetModelPricing(model)
+	if !ok {
+		return 0, false
+	}
+	cost := float64(inputTokens)*pricing.InputPerMillion/1e6 + float64(outputTokens)*pricing.OutputPerMillion/1e6
+	return cost, true
+}
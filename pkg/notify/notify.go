@@ -0,0 +1,67 @@
+// Package notify shows desktop notifications.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no way to show notifications was found
+var ErrUnavailable = errors.New("no desktop notifications available: install notify-send on Linux")
+
+// Send shows a desktop notification with a title and message, using osascript on macOS, PowerShell
+// on Windows and notify-send elsewhere
+func Send(title, message string) error {
+	name, args := command(title, message)
+	if name == "" {
+		return ErrUnavailable
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return ErrUnavailable
+	}
+
+	if output, err := exec.Command(path, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// command returns the command showing a notification on this platform and its arguments, or an empty
+// name if there's no display to show it on
+func command(title, message string) (string, []string) {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		// A balloon tip from the notification area, which needs no app registered with Windows
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$icon = New-Object System.Windows.Forms.NotifyIcon
+$icon.Icon = [System.Drawing.SystemIcons]::Information
+$icon.Visible = $true
+$icon.ShowBalloonTip(10000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$icon.Dispose()`, powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	}
+
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return "", nil
+	}
+	return "notify-send", []string{"--app-name=bai", title, message}
+}
+
+// appleScriptString quotes text as an AppleScript string literal
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// powerShellString quotes text as a PowerShell single-quoted string literal
+func powerShellString(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
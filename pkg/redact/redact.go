@@ -0,0 +1,129 @@
+// Package redact scrubs secrets such as API keys, tokens and email addresses from text.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule replaces the text matching a pattern with a [REDACTED:<name>] marker. When the pattern has
+// a capture group, only the text matching the first group is replaced, so a rule like
+// `password=(\S+)` keeps the surrounding context.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// builtinPatterns are the rules every redactor starts with, in the order they're applied. More
+// specific patterns come first so their names are the ones recorded.
+var builtinPatterns = []struct{ name, pattern string }{
+	{"private-key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{"anthropic-key", `\bsk-ant-[A-Za-z0-9_-]{20,}`},
+	{"openai-key", `\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`},
+	{"aws-access-key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{"github-token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`},
+	{"slack-token", `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+	{"stripe-key", `\b[rs]k_(?:live|test)_[0-9A-Za-z]{16,}`},
+	{"google-api-key", `\bAIza[0-9A-Za-z_-]{35}`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+	{"bearer-token", `(?i)\bbearer\s+([A-Za-z0-9._~+/-]{20,}=*)`},
+	{"email", `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`},
+}
+
+// Builtin returns the built-in rules
+func Builtin() []Rule {
+	rules := make([]Rule, len(builtinPatterns))
+	for i, builtin := range builtinPatterns {
+		rules[i] = Rule{Name: builtin.name, Pattern: regexp.MustCompile(builtin.pattern)}
+	}
+	return rules
+}
+
+// IsBuiltin reports whether a rule name belongs to a built-in rule
+func IsBuiltin(name string) bool {
+	for _, builtin := range builtinPatterns {
+		if builtin.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRule compiles a rule from a regular expression in Go's syntax
+func NewRule(name, pattern string) (Rule, error) {
+	if name == "" || strings.ContainsAny(name, " \t\n]") {
+		return Rule{}, fmt.Errorf("invalid rule name %q: names can't be empty or contain spaces or ]", name)
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid pattern for rule %s: %w", name, err)
+	}
+	if compiled.MatchString("") {
+		return Rule{}, fmt.Errorf("the pattern for rule %s matches empty text", name)
+	}
+	return Rule{Name: name, Pattern: compiled}, nil
+}
+
+// markerPrefix starts every marker
+const markerPrefix = "[REDACTED:"
+
+// Marker returns the text that replaces a secret matched by the named rule
+func Marker(name string) string {
+	return markerPrefix + name + "]"
+}
+
+// Redactor applies a set of rules in order
+type Redactor struct {
+	rules []Rule
+}
+
+// New creates a redactor applying the given rules in order
+func New(rules []Rule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+// Redact replaces every secret in text with its rule's marker, returning the scrubbed text and how
+// many secrets each rule replaced. Redacting already redacted text changes nothing.
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	if r == nil {
+		return text, counts
+	}
+
+	for _, rule := range r.rules {
+		matches := rule.Pattern.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, match := range matches {
+			start, end := match[0], match[1]
+			if len(match) >= 4 && match[2] >= 0 {
+				start, end = match[2], match[3]
+			}
+			// Rules like password=(\S+) would otherwise match their own markers again
+			if start == end || strings.HasPrefix(text[start:end], markerPrefix) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(Marker(rule.Name))
+			last = end
+			counts[rule.Name]++
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text, counts
+}
+
+// Total sums the counts returned by Redact
+func Total(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
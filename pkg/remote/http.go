@@ -0,0 +1,211 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// requestTimeout bounds each request to an HTTP remote
+const requestTimeout = 5 * time.Minute
+
+// client is shared by the HTTP remotes
+var client = &http.Client{Timeout: requestTimeout}
+
+// serverRemote is a garden served by 'bai serve' or 'bai visualize'
+type serverRemote struct {
+	endpoint string
+	token    string
+}
+
+// newServer reaches the sync endpoint of the API at u, which may include a reverse proxy base path
+func newServer(u *url.URL, token string) *serverRemote {
+	endpoint := *u
+	endpoint.Path = strings.TrimSuffix(u.Path, "/") + "/api/v1/sync"
+	return &serverRemote{endpoint: endpoint.String(), token: token}
+}
+
+func (r *serverRemote) Nodes(ctx context.Context) ([]*db.Node, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var nodes []*db.Node
+	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", r.endpoint, err)
+	}
+	return nodes, nil
+}
+
+func (r *serverRemote) Put(ctx context.Context, nodes []*db.Node) error {
+	body, err := json.Marshal(nodes)
+	if err != nil {
+		return fmt.Errorf("failed to encode nodes: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (r *serverRemote) Close() error {
+	return nil
+}
+
+// do sends an authenticated request, turning error statuses into errors
+func (r *serverRemote) do(req *http.Request) (*http.Response, error) {
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", r.endpoint, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("%s needs an access token: pass --token or set BONSAI_WEB_TOKEN", r.endpoint)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%s doesn't support sync: update bai on the server", r.endpoint)
+	}
+	return nil, responseError(r.endpoint, resp)
+}
+
+// webDAV stores a snapshot file on a WebDAV server
+type webDAV struct {
+	url      string
+	user     string
+	password string
+}
+
+// newWebDAV reaches the file at a webdav:// (HTTPS) or webdav+http:// URL
+func newWebDAV(u *url.URL) *webDAV {
+	target := *u
+	target.Scheme = "https"
+	if strings.EqualFold(u.Scheme, "webdav+http") {
+		target.Scheme = "http"
+	}
+	target.User = nil
+
+	store := &webDAV{url: target.String()}
+	if u.User != nil {
+		store.user = u.User.Username()
+		store.password, _ = u.User.Password()
+	}
+	return store
+}
+
+func (s *webDAV) get(ctx context.Context) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	s.authorize(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to reach %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", responseError(s.url, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", s.url, err)
+	}
+	return data, etag(resp), nil
+}
+
+func (s *webDAV) put(ctx context.Context, data []byte, version string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	setPrecondition(req, version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrChanged
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError(s.url, resp)
+	}
+	return nil
+}
+
+// authorize adds the URL's credentials to a request
+func (s *webDAV) authorize(req *http.Request) {
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+}
+
+// setPrecondition makes a write fail if the file changed since it was read at version, or was
+// created since it was found missing
+func setPrecondition(req *http.Request, version string) {
+	if version == "" {
+		req.Header.Set("If-None-Match", "*")
+		return
+	}
+	req.Header.Set("If-Match", version)
+}
+
+// etag returns a response's ETag, or a placeholder that never matches when the server sends none
+// so a snapshot that exists is never mistaken for a missing one
+func etag(resp *http.Response) string {
+	if tag := resp.Header.Get("ETag"); tag != "" {
+		return tag
+	}
+	return "*"
+}
+
+// responseError describes an unexpected response, including the start of its body
+func responseError(target string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if message := strings.TrimSpace(string(body)); message != "" {
+		return fmt.Errorf("%s responded %s: %s", target, resp.Status, message)
+	}
+	return errors.New(target + " responded " + resp.Status)
+}
@@ -0,0 +1,169 @@
+// Package remote reaches the places Bonsai gardens are pushed to and pulled from: other database
+// files, bai serve APIs, and JSON snapshots kept on WebDAV servers or in S3 buckets.
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// ErrChanged is returned when a snapshot changed on the remote between reading and writing it
+var ErrChanged = errors.New("the remote changed while syncing; pull and try again")
+
+// Remote is a garden nodes can be pushed to and pulled from
+type Remote interface {
+	// Nodes returns every node in the remote
+	Nodes(ctx context.Context) ([]*db.Node, error)
+	// Put adds the nodes to the remote, overwriting nodes with the same IDs
+	Put(ctx context.Context, nodes []*db.Node) error
+	// Close releases the remote's resources
+	Close() error
+}
+
+// Options configures how remotes are reached
+type Options struct {
+	Token string // Access token for a bai serve API, like BONSAI_WEB_TOKEN
+}
+
+// Open returns the remote at a location, which is one of:
+//
+//   - a path to a Bonsai database file, created if it doesn't exist
+//   - an http:// or https:// URL of a bai serve API
+//   - a webdav:// (HTTPS) or webdav+http:// URL of a snapshot file on a WebDAV server; credentials
+//     can be given in the URL
+//   - an s3://bucket/key URL of a snapshot object, using the AWS_* environment variables
+func Open(location string, opts Options) (Remote, error) {
+	scheme, _, hasScheme := strings.Cut(location, "://")
+	if !hasScheme {
+		return openFile(location)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote %s: %w", location, err)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "file":
+		return openFile(u.Path)
+	case "http", "https":
+		return newServer(u, opts.Token), nil
+	case "webdav", "webdav+https", "webdav+http":
+		return newSnapshot(newWebDAV(u)), nil
+	case "s3":
+		store, err := newS3(u)
+		if err != nil {
+			return nil, err
+		}
+		return newSnapshot(store), nil
+	}
+	return nil, fmt.Errorf("unsupported remote %s: use a database path, a bai serve URL, or a webdav:// or s3:// snapshot URL", location)
+}
+
+// fileRemote is another Bonsai database on this machine, such as one on a shared drive
+type fileRemote struct {
+	db *db.Database
+}
+
+// openFile opens the database at path, creating it if needed
+func openFile(path string) (*fileRemote, error) {
+	database, err := db.NewDatabase(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if err := database.Initialize(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", path, err)
+	}
+	return &fileRemote{db: database}, nil
+}
+
+func (r *fileRemote) Nodes(ctx context.Context) ([]*db.Node, error) {
+	return r.db.GetAllNodes()
+}
+
+func (r *fileRemote) Put(ctx context.Context, nodes []*db.Node) error {
+	return r.db.UpsertNodes(nodes)
+}
+
+func (r *fileRemote) Close() error {
+	return r.db.Close()
+}
+
+// snapshotStore reads and writes a whole snapshot file, using an opaque version such as an ETag
+// to detect concurrent writes. An empty version means the file doesn't exist yet.
+type snapshotStore interface {
+	get(ctx context.Context) (data []byte, version string, err error)
+	put(ctx context.Context, data []byte, version string) error
+}
+
+// snapshotRemote keeps a garden as a JSON list of nodes in a single file, in the format of
+// Session.Export, so it can be stored anywhere files can
+type snapshotRemote struct {
+	store   snapshotStore
+	nodes   []*db.Node
+	version string
+	loaded  bool
+}
+
+func newSnapshot(store snapshotStore) *snapshotRemote {
+	return &snapshotRemote{store: store}
+}
+
+func (r *snapshotRemote) Nodes(ctx context.Context) ([]*db.Node, error) {
+	data, version, err := r.store.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*db.Node
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &nodes); err != nil {
+			return nil, fmt.Errorf("remote snapshot isn't a Bonsai export: %w", err)
+		}
+	}
+	r.nodes, r.version, r.loaded = nodes, version, true
+	return nodes, nil
+}
+
+func (r *snapshotRemote) Put(ctx context.Context, nodes []*db.Node) error {
+	if !r.loaded {
+		if _, err := r.Nodes(ctx); err != nil {
+			return err
+		}
+	}
+
+	index := make(map[string]int, len(r.nodes))
+	for i, node := range r.nodes {
+		index[node.ID] = i
+	}
+	merged := append([]*db.Node{}, r.nodes...)
+	for _, node := range nodes {
+		if i, ok := index[node.ID]; ok {
+			merged[i] = node
+			continue
+		}
+		index[node.ID] = len(merged)
+		merged = append(merged, node)
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := r.store.put(ctx, data, r.version); err != nil {
+		return err
+	}
+	r.nodes, r.loaded = merged, false
+	return nil
+}
+
+func (r *snapshotRemote) Close() error {
+	return nil
+}
@@ -0,0 +1,214 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3Store keeps a snapshot as an object in an S3 bucket, or in S3-compatible storage when
+// AWS_ENDPOINT_URL is set
+type s3Store struct {
+	url          string // Object URL
+	host         string
+	path         string // Escaped object path, as signed
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// newS3 reaches the object at an s3://bucket/key URL, with credentials and region from the
+// standard AWS_* environment variables
+func newS3(u *url.URL) (*s3Store, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 remote %s: use s3://bucket/path/to/garden.json", u)
+	}
+
+	store := &s3Store{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		region:       firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+	}
+	if store.accessKey == "" || store.secretKey == "" {
+		return nil, errors.New("S3 remotes need AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to be set")
+	}
+	if store.region == "" {
+		store.region = "us-east-1"
+	}
+
+	escapedKey := awsEscapePath(key)
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		// S3-compatible services are addressed path-style
+		base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+		if err != nil || base.Host == "" {
+			return nil, fmt.Errorf("invalid AWS_ENDPOINT_URL %s", endpoint)
+		}
+		store.host = base.Host
+		store.path = strings.TrimSuffix(base.EscapedPath(), "/") + "/" + awsEscape(bucket) + "/" + escapedKey
+		store.url = base.Scheme + "://" + store.host + store.path
+		return store, nil
+	}
+
+	store.host = fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, store.region)
+	store.path = "/" + escapedKey
+	store.url = "https://" + store.host + store.path
+	return store, nil
+}
+
+func (s *s3Store) get(ctx context.Context) ([]byte, string, error) {
+	req, err := s.request(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to reach %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", responseError(s.url, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", s.url, err)
+	}
+	return data, etag(resp), nil
+}
+
+func (s *s3Store) put(ctx context.Context, data []byte, version string) error {
+	req, err := s.request(ctx, http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	setPrecondition(req, version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	// S3 answers 409 when a conditional write races another one
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict {
+		return ErrChanged
+	}
+	if resp.StatusCode != http.StatusOK {
+		return responseError(s.url, resp)
+	}
+	return nil
+}
+
+// request builds a request for the object signed with AWS Signature Version 4
+func (s *s3Store) request(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	headers := map[string]string{
+		"host":                 s.host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if s.sessionToken != "" {
+		headers["x-amz-security-token"] = s.sessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+		if name != "host" {
+			req.Header.Set(name, headers[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, s.path, "", canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range []string{day, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKey, scope, signedHeaders, signature))
+	return req, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscapePath escapes each segment of an object key as AWS signatures expect
+func awsEscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// awsEscape percent-encodes everything but unreserved characters
+func awsEscape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package render
+
+import "strings"
+
+// CodeBlock is a fenced code block in Markdown text
+type CodeBlock struct {
+	Language string // First word of the fence's info string, e.g. "go"
+	Info     string // The fence's whole info string, e.g. "go title=main.go"
+	Code     string
+	Caption  string // The last non-blank line of text before the fence, often naming the code
+}
+
+// CodeBlocks returns the fenced code blocks in Markdown text, in order. A block left open at the
+// end of the text runs to the end.
+func CodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	var caption string
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		open, info, ok := parseFence(lines[i])
+		if !ok {
+			if line := strings.TrimSpace(lines[i]); line != "" {
+				caption = line
+			}
+			continue
+		}
+
+		var code []string
+		for i++; i < len(lines) && !closesFence(lines[i], open); i++ {
+			code = append(code, lines[i])
+		}
+
+		block := CodeBlock{Info: info, Code: strings.Join(code, "\n"), Caption: caption}
+		caption = ""
+		if fields := strings.Fields(info); len(fields) > 0 {
+			block.Language = fields[0]
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// parseFence reports whether a line opens a fenced code block, returning the fence and info string
+func parseFence(line string) (fence string, info string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []byte{'`', '~'} {
+		n := 0
+		for n < len(trimmed) && trimmed[n] == marker {
+			n++
+		}
+		if n >= 3 {
+			info = strings.TrimSpace(trimmed[n:])
+			if marker == '`' && strings.Contains(info, "`") {
+				return "", "", false
+			}
+			return trimmed[:n], info, true
+		}
+	}
+	return "", "", false
+}
+
+// closesFence reports whether a line closes a code block opened with the given fence
+func closesFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
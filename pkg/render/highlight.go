@@ -0,0 +1,211 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Colors for highlighted code
+const (
+	keywordColor = "\033[35m"
+	stringColor  = "\033[32m"
+	commentColor = "\033[90m"
+	numberColor  = "\033[33m"
+	plainColor   = "\033[36m"
+)
+
+// syntax describes just enough of a language to highlight it line by line
+type syntax struct {
+	keywords     map[string]bool
+	lineComments []string
+	blockComment [2]string // Opening and closing delimiters; empty if the language has none
+	quotes       string    // Characters that start and end a string
+}
+
+// words builds a keyword set from a space-separated list
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+var (
+	cLike = [2]string{"/*", "*/"}
+
+	goSyntax = &syntax{
+		keywords:     words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota"),
+		lineComments: []string{"//"},
+		blockComment: cLike,
+		quotes:       "\"'`",
+	}
+	pythonSyntax = &syntax{
+		keywords:     words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False self"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	jsSyntax = &syntax{
+		keywords:     words("async await break case catch class const continue debugger default delete do else export extends finally for function if import in instanceof interface let new null of return static super switch this throw true false try type typeof undefined var void while yield"),
+		lineComments: []string{"//"},
+		blockComment: cLike,
+		quotes:       "\"'`",
+	}
+	shellSyntax = &syntax{
+		keywords:     words("if then else elif fi for in do done while until case esac function return local export echo exit set unset"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	rustSyntax = &syntax{
+		keywords:     words("as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while Some None Ok Err"),
+		lineComments: []string{"//"},
+		blockComment: cLike,
+		quotes:       "\"",
+	}
+	cSyntax = &syntax{
+		keywords:     words("auto break case catch char class const continue default delete do double else enum extends extern false final float for if implements import int long namespace new null nullptr package private protected public return short signed sizeof static struct super switch template this throw true try typedef union unsigned using var void volatile while boolean byte String"),
+		lineComments: []string{"//"},
+		blockComment: cLike,
+		quotes:       "\"'",
+	}
+	sqlSyntax = &syntax{
+		keywords:     words("select from where insert into values update set delete create table index drop alter add join left right inner outer on group by order having limit offset and or not null is in as distinct primary key foreign references default union all case when then else end SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE INDEX DROP ALTER ADD JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AND OR NOT NULL IS IN AS DISTINCT PRIMARY KEY FOREIGN REFERENCES DEFAULT UNION ALL CASE WHEN THEN ELSE END"),
+		lineComments: []string{"--"},
+		blockComment: cLike,
+		quotes:       "'\"",
+	}
+	dataSyntax = &syntax{
+		keywords:     words("true false null"),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+)
+
+// syntaxes maps fence languages to their syntax
+var syntaxes = byName(map[*syntax]string{
+	goSyntax:     "go golang",
+	pythonSyntax: "py python python3",
+	jsSyntax:     "js javascript jsx ts typescript tsx",
+	shellSyntax:  "sh bash shell zsh console",
+	rustSyntax:   "rs rust",
+	cSyntax:      "c h cpp c++ java cs csharp kotlin swift",
+	sqlSyntax:    "sql",
+	dataSyntax:   "json yaml yml toml",
+})
+
+// byName indexes syntaxes by each of their space-separated names
+func byName(names map[*syntax]string) map[string]*syntax {
+	index := make(map[string]*syntax)
+	for syn, list := range names {
+		for _, name := range strings.Fields(list) {
+			index[name] = syn
+		}
+	}
+	return index
+}
+
+// Highlight colors the lines of a code block written in the given language. Code in a language
+// it doesn't know is colored uniformly.
+func Highlight(language string, lines []string) []string {
+	syn := syntaxes[strings.ToLower(language)]
+	out := make([]string, len(lines))
+	inBlockComment := false
+	for i, line := range lines {
+		if syn == nil {
+			out[i] = plainColor + line + fullReset
+			continue
+		}
+		out[i], inBlockComment = syn.highlightLine(line, inBlockComment)
+	}
+	return out
+}
+
+// highlightLine colors one line, given whether it starts inside a block comment, and reports
+// whether it ends inside one
+func (syn *syntax) highlightLine(line string, inBlockComment bool) (string, bool) {
+	var b strings.Builder
+	runes := []rune(line)
+
+	for i := 0; i < len(runes); {
+		rest := string(runes[i:])
+
+		if inBlockComment {
+			end := strings.Index(rest, syn.blockComment[1])
+			if end < 0 {
+				b.WriteString(commentColor + rest + fullReset)
+				return b.String(), true
+			}
+			comment := rest[:end+len(syn.blockComment[1])]
+			b.WriteString(commentColor + comment + fullReset)
+			i += len([]rune(comment))
+			inBlockComment = false
+			continue
+		}
+
+		if syn.blockComment[0] != "" && strings.HasPrefix(rest, syn.blockComment[0]) {
+			inBlockComment = true
+			b.WriteString(commentColor + syn.blockComment[0])
+			i += len([]rune(syn.blockComment[0]))
+			b.WriteString(fullReset)
+			continue
+		}
+
+		if syn.startsLineComment(rest) {
+			b.WriteString(commentColor + rest + fullReset)
+			break
+		}
+
+		r := runes[i]
+		switch {
+		case strings.ContainsRune(syn.quotes, r):
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				end = len(runes) - 1
+			}
+			b.WriteString(stringColor + string(runes[i:end+1]) + fullReset)
+			i = end + 1
+
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || unicode.IsLetter(runes[end]) || runes[end] == '.' || runes[end] == '_') {
+				end++
+			}
+			b.WriteString(numberColor + string(runes[i:end]) + fullReset)
+			i = end
+
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			word := string(runes[i:end])
+			if syn.keywords[word] {
+				b.WriteString(bold + keywordColor + word + fullReset)
+			} else {
+				b.WriteString(word)
+			}
+			i = end
+
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String(), inBlockComment
+}
+
+// startsLineComment reports whether text begins with one of the language's line comment markers
+func (syn *syntax) startsLineComment(text string) bool {
+	for _, marker := range syn.lineComments {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Package render formats Markdown for display in a terminal with ANSI escape codes.
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ANSI escape codes used by the renderer. Each style is closed with its own reset code rather
+// than a full reset, so styles nest inside headings and quotes.
+const (
+	bold       = "\033[1m"
+	boldOff    = "\033[22m"
+	italic     = "\033[3m"
+	italicOff  = "\033[23m"
+	underline  = "\033[4m"
+	underOff   = "\033[24m"
+	strike     = "\033[9m"
+	strikeOff  = "\033[29m"
+	gray       = "\033[90m"
+	cyan       = "\033[36m"
+	magenta    = "\033[35m"
+	yellow     = "\033[33m"
+	colorOff   = "\033[39m"
+	fullReset  = "\033[0m"
+	ruleLength = 40
+)
+
+var (
+	heading     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rule        = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	quote       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	bullet      = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	numbered    = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	task        = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	codeSpan    = regexp.MustCompile("`+([^`]+)`+")
+	boldText    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicText  = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*?)\*|(^|\W)_([^_\s][^_]*?)_(\W|$)`)
+	strikeText  = regexp.MustCompile(`~~([^~]+)~~`)
+	link        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	placeholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// Markdown renders Markdown text for a terminal: headings, emphasis, lists, quotes, rules, links
+// and code. Anything it doesn't recognize, such as tables, is left as written.
+func Markdown(text string) string {
+	var out []string
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence, info, ok := parseFence(line); ok {
+			// Collect the block up to the matching closing fence, or the end of the text
+			var code []string
+			for i++; i < len(lines) && !closesFence(lines[i], fence); i++ {
+				code = append(code, lines[i])
+			}
+
+			var language string
+			if fields := strings.Fields(info); len(fields) > 0 {
+				language = fields[0]
+			}
+			out = append(out, renderCodeBlock(language, code)...)
+			continue
+		}
+
+		out = append(out, renderLine(line))
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// renderCodeBlock renders the lines of a fenced code block with syntax highlighting, labeled with
+// its language if it has one
+func renderCodeBlock(language string, code []string) []string {
+	out := make([]string, 0, len(code)+1)
+	if language != "" {
+		out = append(out, gray+"  ┌ "+language+fullReset)
+	}
+	for _, line := range Highlight(language, code) {
+		out = append(out, gray+"  │ "+fullReset+line)
+	}
+	return out
+}
+
+// renderLine renders a single line outside code blocks
+func renderLine(line string) string {
+	if match := heading.FindStringSubmatch(line); match != nil {
+		switch len(match[1]) {
+		case 1:
+			return bold + underline + magenta + inline(match[2]) + fullReset
+		case 2:
+			return bold + cyan + inline(match[2]) + fullReset
+		default:
+			return bold + inline(match[2]) + fullReset
+		}
+	}
+
+	if rule.MatchString(line) {
+		return gray + strings.Repeat("─", ruleLength) + fullReset
+	}
+
+	if match := quote.FindStringSubmatch(line); match != nil {
+		return gray + "│ " + italic + inline(match[1]) + fullReset
+	}
+
+	if match := bullet.FindStringSubmatch(line); match != nil {
+		item := match[2]
+		marker := yellow + "•" + colorOff
+		if taskMatch := task.FindStringSubmatch(item); taskMatch != nil {
+			marker, item = "☐", taskMatch[2]
+			if taskMatch[1] != " " {
+				marker = "☑"
+			}
+		}
+		return match[1] + marker + " " + inline(item)
+	}
+
+	if match := numbered.FindStringSubmatch(line); match != nil {
+		return match[1] + yellow + match[2] + "." + colorOff + " " + inline(match[3])
+	}
+
+	return inline(line)
+}
+
+// inline renders the emphasis, links and code spans within a line
+func inline(text string) string {
+	// Set code spans aside so nothing inside them is treated as Markdown
+	var spans []string
+	text = codeSpan.ReplaceAllStringFunc(text, func(span string) string {
+		spans = append(spans, codeSpan.FindStringSubmatch(span)[1])
+		return "\x00" + strconv.Itoa(len(spans)-1) + "\x00"
+	})
+
+	text = link.ReplaceAllString(text, underline+"$1"+underOff+" "+gray+"($2)"+colorOff)
+	text = boldText.ReplaceAllString(text, bold+"$1$2"+boldOff)
+	text = italicText.ReplaceAllString(text, "$1$3"+italic+"$2$4"+italicOff+"$5")
+	text = strikeText.ReplaceAllString(text, strike+"$1"+strikeOff)
+
+	return placeholder.ReplaceAllStringFunc(text, func(marker string) string {
+		index, _ := strconv.Atoi(placeholder.FindStringSubmatch(marker)[1])
+		return yellow + spans[index] + colorOff
+	})
+}
@@ -0,0 +1,1647 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: bonsai/v1/bonsai.proto
+
+package bonsaiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // "user", "llm", "note" or "system"
+	Parent        *string                `protobuf:"bytes,4,opt,name=parent,proto3,oneof" json:"parent,omitempty"`
+	Model         *string                `protobuf:"bytes,5,opt,name=model,proto3,oneof" json:"model,omitempty"`
+	Metadata      *string                `protobuf:"bytes,6,opt,name=metadata,proto3,oneof" json:"metadata,omitempty"`               // JSON object
+	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix seconds, 0 if unknown
+	VisitedAt     int64                  `protobuf:"varint,8,opt,name=visited_at,json=visitedAt,proto3" json:"visited_at,omitempty"` // Unix seconds, 0 if never visited
+	Author        *string                `protobuf:"bytes,9,opt,name=author,proto3,oneof" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Node) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Node) GetParent() string {
+	if x != nil && x.Parent != nil {
+		return *x.Parent
+	}
+	return ""
+}
+
+func (x *Node) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *Node) GetMetadata() string {
+	if x != nil && x.Metadata != nil {
+		return *x.Metadata
+	}
+	return ""
+}
+
+func (x *Node) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Node) GetVisitedAt() int64 {
+	if x != nil {
+		return x.VisitedAt
+	}
+	return 0
+}
+
+func (x *Node) GetAuthor() string {
+	if x != nil && x.Author != nil {
+		return *x.Author
+	}
+	return ""
+}
+
+type NodeList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeList) Reset() {
+	*x = NodeList{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeList) ProtoMessage() {}
+
+func (x *NodeList) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeList.ProtoReflect.Descriptor instead.
+func (*NodeList) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NodeList) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type PagedNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	ChildCount    int32                  `protobuf:"varint,2,opt,name=child_count,json=childCount,proto3" json:"child_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PagedNode) Reset() {
+	*x = PagedNode{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PagedNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PagedNode) ProtoMessage() {}
+
+func (x *PagedNode) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PagedNode.ProtoReflect.Descriptor instead.
+func (*PagedNode) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PagedNode) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *PagedNode) GetChildCount() int32 {
+	if x != nil {
+		return x.ChildCount
+	}
+	return 0
+}
+
+type NodePage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*PagedNode           `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodePage) Reset() {
+	*x = NodePage{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodePage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodePage) ProtoMessage() {}
+
+func (x *NodePage) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodePage.ProtoReflect.Descriptor instead.
+func (*NodePage) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NodePage) GetNodes() []*PagedNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *NodePage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type TreeSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          *Node                  `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	ChildCount    int32                  `protobuf:"varint,2,opt,name=child_count,json=childCount,proto3" json:"child_count,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	NodeCount     int32                  `protobuf:"varint,4,opt,name=node_count,json=nodeCount,proto3" json:"node_count,omitempty"`
+	LastActivity  int64                  `protobuf:"varint,5,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TreeSummary) Reset() {
+	*x = TreeSummary{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TreeSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeSummary) ProtoMessage() {}
+
+func (x *TreeSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeSummary.ProtoReflect.Descriptor instead.
+func (*TreeSummary) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TreeSummary) GetRoot() *Node {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *TreeSummary) GetChildCount() int32 {
+	if x != nil {
+		return x.ChildCount
+	}
+	return 0
+}
+
+func (x *TreeSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TreeSummary) GetNodeCount() int32 {
+	if x != nil {
+		return x.NodeCount
+	}
+	return 0
+}
+
+func (x *TreeSummary) GetLastActivity() int64 {
+	if x != nil {
+		return x.LastActivity
+	}
+	return 0
+}
+
+type TreePage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trees         []*TreeSummary         `protobuf:"bytes,1,rep,name=trees,proto3" json:"trees,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // Empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TreePage) Reset() {
+	*x = TreePage{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TreePage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreePage) ProtoMessage() {}
+
+func (x *TreePage) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreePage.ProtoReflect.Descriptor instead.
+func (*TreePage) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TreePage) GetTrees() []*TreeSummary {
+	if x != nil {
+		return x.Trees
+	}
+	return nil
+}
+
+func (x *TreePage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// Node IDs in requests may be abbreviated, as with the CLI.
+type GetNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeRequest) Reset() {
+	*x = GetNodeRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeRequest) ProtoMessage() {}
+
+func (x *GetNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CreateNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parent        *string                `protobuf:"bytes,1,opt,name=parent,proto3,oneof" json:"parent,omitempty"` // Omit to start a new tree
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // "user" (default), "llm", "note" or "system"
+	Model         *string                `protobuf:"bytes,4,opt,name=model,proto3,oneof" json:"model,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNodeRequest) Reset() {
+	*x = CreateNodeRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNodeRequest) ProtoMessage() {}
+
+func (x *CreateNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNodeRequest.ProtoReflect.Descriptor instead.
+func (*CreateNodeRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateNodeRequest) GetParent() string {
+	if x != nil && x.Parent != nil {
+		return *x.Parent
+	}
+	return ""
+}
+
+func (x *CreateNodeRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateNodeRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateNodeRequest) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+type UpdateNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNodeRequest) Reset() {
+	*x = UpdateNodeRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNodeRequest) ProtoMessage() {}
+
+func (x *UpdateNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNodeRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNodeRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateNodeRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type DeleteNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	KeepChildren  bool                   `protobuf:"varint,2,opt,name=keep_children,json=keepChildren,proto3" json:"keep_children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNodeRequest) Reset() {
+	*x = DeleteNodeRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNodeRequest) ProtoMessage() {}
+
+func (x *DeleteNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNodeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNodeRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteNodeRequest) GetKeepChildren() bool {
+	if x != nil {
+		return x.KeepChildren
+	}
+	return false
+}
+
+type DeleteNodeResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Deleted        int32                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Reattached     int32                  `protobuf:"varint,2,opt,name=reattached,proto3" json:"reattached,omitempty"`
+	CurrentNode    *string                `protobuf:"bytes,3,opt,name=current_node,json=currentNode,proto3,oneof" json:"current_node,omitempty"`
+	CurrentChanged bool                   `protobuf:"varint,4,opt,name=current_changed,json=currentChanged,proto3" json:"current_changed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteNodeResponse) Reset() {
+	*x = DeleteNodeResponse{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNodeResponse) ProtoMessage() {}
+
+func (x *DeleteNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNodeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteNodeResponse) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteNodeResponse) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *DeleteNodeResponse) GetReattached() int32 {
+	if x != nil {
+		return x.Reattached
+	}
+	return 0
+}
+
+func (x *DeleteNodeResponse) GetCurrentNode() string {
+	if x != nil && x.CurrentNode != nil {
+		return *x.CurrentNode
+	}
+	return ""
+}
+
+func (x *DeleteNodeResponse) GetCurrentChanged() bool {
+	if x != nil {
+		return x.CurrentChanged
+	}
+	return false
+}
+
+type ListChildrenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChildrenRequest) Reset() {
+	*x = ListChildrenRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChildrenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChildrenRequest) ProtoMessage() {}
+
+func (x *ListChildrenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChildrenRequest.ProtoReflect.Descriptor instead.
+func (*ListChildrenRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListChildrenRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ListChildrenRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListChildrenRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetPathRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPathRequest) Reset() {
+	*x = GetPathRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPathRequest) ProtoMessage() {}
+
+func (x *GetPathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPathRequest.ProtoReflect.Descriptor instead.
+func (*GetPathRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetPathRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListTreesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTreesRequest) Reset() {
+	*x = ListTreesRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTreesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTreesRequest) ProtoMessage() {}
+
+func (x *ListTreesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTreesRequest.ProtoReflect.Descriptor instead.
+func (*ListTreesRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTreesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListTreesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetTreeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTreeRequest) Reset() {
+	*x = GetTreeRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTreeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTreeRequest) ProtoMessage() {}
+
+func (x *GetTreeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTreeRequest.ProtoReflect.Descriptor instead.
+func (*GetTreeRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTreeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Snippet       string                 `protobuf:"bytes,2,opt,name=snippet,proto3" json:"snippet,omitempty"` // HTML with matches wrapped in <mark>
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SearchResult) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetCurrentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentRequest) Reset() {
+	*x = GetCurrentRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentRequest) ProtoMessage() {}
+
+func (x *GetCurrentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{18}
+}
+
+type SetCurrentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetCurrentRequest) Reset() {
+	*x = SetCurrentRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetCurrentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCurrentRequest) ProtoMessage() {}
+
+func (x *SetCurrentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCurrentRequest.ProtoReflect.Descriptor instead.
+func (*SetCurrentRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetCurrentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CurrentNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CurrentNode   *string                `protobuf:"bytes,1,opt,name=current_node,json=currentNode,proto3,oneof" json:"current_node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CurrentNode) Reset() {
+	*x = CurrentNode{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrentNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrentNode) ProtoMessage() {}
+
+func (x *CurrentNode) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrentNode.ProtoReflect.Descriptor instead.
+func (*CurrentNode) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CurrentNode) GetCurrentNode() string {
+	if x != nil && x.CurrentNode != nil {
+		return *x.CurrentNode
+	}
+	return ""
+}
+
+type GenerateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parent        string                 `protobuf:"bytes,1,opt,name=parent,proto3" json:"parent,omitempty"`
+	Model         *string                `protobuf:"bytes,2,opt,name=model,proto3,oneof" json:"model,omitempty"` // Defaults to the parent's model
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateRequest) Reset() {
+	*x = GenerateRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRequest) ProtoMessage() {}
+
+func (x *GenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GenerateRequest) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+type GenerateEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*GenerateEvent_Chunk
+	//	*GenerateEvent_Done
+	Event         isGenerateEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateEvent) Reset() {
+	*x = GenerateEvent{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateEvent) ProtoMessage() {}
+
+func (x *GenerateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateEvent.ProtoReflect.Descriptor instead.
+func (*GenerateEvent) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GenerateEvent) GetEvent() isGenerateEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *GenerateEvent) GetChunk() string {
+	if x != nil {
+		if x, ok := x.Event.(*GenerateEvent_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return ""
+}
+
+func (x *GenerateEvent) GetDone() *Node {
+	if x != nil {
+		if x, ok := x.Event.(*GenerateEvent_Done); ok {
+			return x.Done
+		}
+	}
+	return nil
+}
+
+type isGenerateEvent_Event interface {
+	isGenerateEvent_Event()
+}
+
+type GenerateEvent_Chunk struct {
+	Chunk string `protobuf:"bytes,1,opt,name=chunk,proto3,oneof"` // Text as it's generated
+}
+
+type GenerateEvent_Done struct {
+	Done *Node `protobuf:"bytes,2,opt,name=done,proto3,oneof"` // The stored response
+}
+
+func (*GenerateEvent_Chunk) isGenerateEvent_Event() {}
+
+func (*GenerateEvent_Done) isGenerateEvent_Event() {}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{23}
+}
+
+type TreeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                        // node-created, node-updated, node-deleted or current-changed
+	Node          *Node                  `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`                                        // Set for node-created and node-updated
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`                                            // Set for node-deleted
+	CurrentNode   *string                `protobuf:"bytes,4,opt,name=current_node,json=currentNode,proto3,oneof" json:"current_node,omitempty"` // Set for current-changed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TreeEvent) Reset() {
+	*x = TreeEvent{}
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TreeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TreeEvent) ProtoMessage() {}
+
+func (x *TreeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_bonsai_v1_bonsai_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TreeEvent.ProtoReflect.Descriptor instead.
+func (*TreeEvent) Descriptor() ([]byte, []int) {
+	return file_bonsai_v1_bonsai_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TreeEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TreeEvent) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *TreeEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TreeEvent) GetCurrentNode() string {
+	if x != nil && x.CurrentNode != nil {
+		return *x.CurrentNode
+	}
+	return ""
+}
+
+var File_bonsai_v1_bonsai_proto protoreflect.FileDescriptor
+
+const file_bonsai_v1_bonsai_proto_rawDesc = "" +
+	"\n" +
+	"\x16bonsai/v1/bonsai.proto\x12\tbonsai.v1\"\xa5\x02\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1b\n" +
+	"\x06parent\x18\x04 \x01(\tH\x00R\x06parent\x88\x01\x01\x12\x19\n" +
+	"\x05model\x18\x05 \x01(\tH\x01R\x05model\x88\x01\x01\x12\x1f\n" +
+	"\bmetadata\x18\x06 \x01(\tH\x02R\bmetadata\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"visited_at\x18\b \x01(\x03R\tvisitedAt\x12\x1b\n" +
+	"\x06author\x18\t \x01(\tH\x03R\x06author\x88\x01\x01B\t\n" +
+	"\a_parentB\b\n" +
+	"\x06_modelB\v\n" +
+	"\t_metadataB\t\n" +
+	"\a_author\"1\n" +
+	"\bNodeList\x12%\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.bonsai.v1.NodeR\x05nodes\"Q\n" +
+	"\tPagedNode\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.bonsai.v1.NodeR\x04node\x12\x1f\n" +
+	"\vchild_count\x18\x02 \x01(\x05R\n" +
+	"childCount\"W\n" +
+	"\bNodePage\x12*\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x14.bonsai.v1.PagedNodeR\x05nodes\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\xad\x01\n" +
+	"\vTreeSummary\x12#\n" +
+	"\x04root\x18\x01 \x01(\v2\x0f.bonsai.v1.NodeR\x04root\x12\x1f\n" +
+	"\vchild_count\x18\x02 \x01(\x05R\n" +
+	"childCount\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1d\n" +
+	"\n" +
+	"node_count\x18\x04 \x01(\x05R\tnodeCount\x12#\n" +
+	"\rlast_activity\x18\x05 \x01(\x03R\flastActivity\"Y\n" +
+	"\bTreePage\x12,\n" +
+	"\x05trees\x18\x01 \x03(\v2\x16.bonsai.v1.TreeSummaryR\x05trees\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\" \n" +
+	"\x0eGetNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x8e\x01\n" +
+	"\x11CreateNodeRequest\x12\x1b\n" +
+	"\x06parent\x18\x01 \x01(\tH\x00R\x06parent\x88\x01\x01\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x19\n" +
+	"\x05model\x18\x04 \x01(\tH\x01R\x05model\x88\x01\x01B\t\n" +
+	"\a_parentB\b\n" +
+	"\x06_model\"=\n" +
+	"\x11UpdateNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"H\n" +
+	"\x11DeleteNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rkeep_children\x18\x02 \x01(\bR\fkeepChildren\"\xb0\x01\n" +
+	"\x12DeleteNodeResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\x05R\adeleted\x12\x1e\n" +
+	"\n" +
+	"reattached\x18\x02 \x01(\x05R\n" +
+	"reattached\x12&\n" +
+	"\fcurrent_node\x18\x03 \x01(\tH\x00R\vcurrentNode\x88\x01\x01\x12'\n" +
+	"\x0fcurrent_changed\x18\x04 \x01(\bR\x0ecurrentChangedB\x0f\n" +
+	"\r_current_node\"S\n" +
+	"\x13ListChildrenRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\" \n" +
+	"\x0eGetPathRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"@\n" +
+	"\x10ListTreesRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\" \n" +
+	"\x0eGetTreeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\";\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"M\n" +
+	"\fSearchResult\x12#\n" +
+	"\x04node\x18\x01 \x01(\v2\x0f.bonsai.v1.NodeR\x04node\x12\x18\n" +
+	"\asnippet\x18\x02 \x01(\tR\asnippet\"C\n" +
+	"\x0eSearchResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.bonsai.v1.SearchResultR\aresults\"\x13\n" +
+	"\x11GetCurrentRequest\"#\n" +
+	"\x11SetCurrentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"F\n" +
+	"\vCurrentNode\x12&\n" +
+	"\fcurrent_node\x18\x01 \x01(\tH\x00R\vcurrentNode\x88\x01\x01B\x0f\n" +
+	"\r_current_node\"N\n" +
+	"\x0fGenerateRequest\x12\x16\n" +
+	"\x06parent\x18\x01 \x01(\tR\x06parent\x12\x19\n" +
+	"\x05model\x18\x02 \x01(\tH\x00R\x05model\x88\x01\x01B\b\n" +
+	"\x06_model\"W\n" +
+	"\rGenerateEvent\x12\x16\n" +
+	"\x05chunk\x18\x01 \x01(\tH\x00R\x05chunk\x12%\n" +
+	"\x04done\x18\x02 \x01(\v2\x0f.bonsai.v1.NodeH\x00R\x04doneB\a\n" +
+	"\x05event\"\x14\n" +
+	"\x12WatchEventsRequest\"\x8d\x01\n" +
+	"\tTreeEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12#\n" +
+	"\x04node\x18\x02 \x01(\v2\x0f.bonsai.v1.NodeR\x04node\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\x12&\n" +
+	"\fcurrent_node\x18\x04 \x01(\tH\x00R\vcurrentNode\x88\x01\x01B\x0f\n" +
+	"\r_current_node2\x8e\a\n" +
+	"\x06Bonsai\x125\n" +
+	"\aGetNode\x12\x19.bonsai.v1.GetNodeRequest\x1a\x0f.bonsai.v1.Node\x12;\n" +
+	"\n" +
+	"CreateNode\x12\x1c.bonsai.v1.CreateNodeRequest\x1a\x0f.bonsai.v1.Node\x12;\n" +
+	"\n" +
+	"UpdateNode\x12\x1c.bonsai.v1.UpdateNodeRequest\x1a\x0f.bonsai.v1.Node\x12I\n" +
+	"\n" +
+	"DeleteNode\x12\x1c.bonsai.v1.DeleteNodeRequest\x1a\x1d.bonsai.v1.DeleteNodeResponse\x12C\n" +
+	"\fListChildren\x12\x1e.bonsai.v1.ListChildrenRequest\x1a\x13.bonsai.v1.NodePage\x129\n" +
+	"\aGetPath\x12\x19.bonsai.v1.GetPathRequest\x1a\x13.bonsai.v1.NodeList\x12=\n" +
+	"\tListTrees\x12\x1b.bonsai.v1.ListTreesRequest\x1a\x13.bonsai.v1.TreePage\x129\n" +
+	"\aGetTree\x12\x19.bonsai.v1.GetTreeRequest\x1a\x13.bonsai.v1.NodeList\x12=\n" +
+	"\x06Search\x12\x18.bonsai.v1.SearchRequest\x1a\x19.bonsai.v1.SearchResponse\x12B\n" +
+	"\n" +
+	"GetCurrent\x12\x1c.bonsai.v1.GetCurrentRequest\x1a\x16.bonsai.v1.CurrentNode\x12B\n" +
+	"\n" +
+	"SetCurrent\x12\x1c.bonsai.v1.SetCurrentRequest\x1a\x16.bonsai.v1.CurrentNode\x127\n" +
+	"\bGenerate\x12\x1a.bonsai.v1.GenerateRequest\x1a\x0f.bonsai.v1.Node\x12H\n" +
+	"\x0eStreamGenerate\x12\x1a.bonsai.v1.GenerateRequest\x1a\x18.bonsai.v1.GenerateEvent0\x01\x12D\n" +
+	"\vWatchEvents\x12\x1d.bonsai.v1.WatchEventsRequest\x1a\x14.bonsai.v1.TreeEvent0\x01B+Z)github.com/aarose/bonsai/pkg/rpc/bonsaiv1b\x06proto3"
+
+var (
+	file_bonsai_v1_bonsai_proto_rawDescOnce sync.Once
+	file_bonsai_v1_bonsai_proto_rawDescData []byte
+)
+
+func file_bonsai_v1_bonsai_proto_rawDescGZIP() []byte {
+	file_bonsai_v1_bonsai_proto_rawDescOnce.Do(func() {
+		file_bonsai_v1_bonsai_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bonsai_v1_bonsai_proto_rawDesc), len(file_bonsai_v1_bonsai_proto_rawDesc)))
+	})
+	return file_bonsai_v1_bonsai_proto_rawDescData
+}
+
+var file_bonsai_v1_bonsai_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_bonsai_v1_bonsai_proto_goTypes = []any{
+	(*Node)(nil),                // 0: bonsai.v1.Node
+	(*NodeList)(nil),            // 1: bonsai.v1.NodeList
+	(*PagedNode)(nil),           // 2: bonsai.v1.PagedNode
+	(*NodePage)(nil),            // 3: bonsai.v1.NodePage
+	(*TreeSummary)(nil),         // 4: bonsai.v1.TreeSummary
+	(*TreePage)(nil),            // 5: bonsai.v1.TreePage
+	(*GetNodeRequest)(nil),      // 6: bonsai.v1.GetNodeRequest
+	(*CreateNodeRequest)(nil),   // 7: bonsai.v1.CreateNodeRequest
+	(*UpdateNodeRequest)(nil),   // 8: bonsai.v1.UpdateNodeRequest
+	(*DeleteNodeRequest)(nil),   // 9: bonsai.v1.DeleteNodeRequest
+	(*DeleteNodeResponse)(nil),  // 10: bonsai.v1.DeleteNodeResponse
+	(*ListChildrenRequest)(nil), // 11: bonsai.v1.ListChildrenRequest
+	(*GetPathRequest)(nil),      // 12: bonsai.v1.GetPathRequest
+	(*ListTreesRequest)(nil),    // 13: bonsai.v1.ListTreesRequest
+	(*GetTreeRequest)(nil),      // 14: bonsai.v1.GetTreeRequest
+	(*SearchRequest)(nil),       // 15: bonsai.v1.SearchRequest
+	(*SearchResult)(nil),        // 16: bonsai.v1.SearchResult
+	(*SearchResponse)(nil),      // 17: bonsai.v1.SearchResponse
+	(*GetCurrentRequest)(nil),   // 18: bonsai.v1.GetCurrentRequest
+	(*SetCurrentRequest)(nil),   // 19: bonsai.v1.SetCurrentRequest
+	(*CurrentNode)(nil),         // 20: bonsai.v1.CurrentNode
+	(*GenerateRequest)(nil),     // 21: bonsai.v1.GenerateRequest
+	(*GenerateEvent)(nil),       // 22: bonsai.v1.GenerateEvent
+	(*WatchEventsRequest)(nil),  // 23: bonsai.v1.WatchEventsRequest
+	(*TreeEvent)(nil),           // 24: bonsai.v1.TreeEvent
+}
+var file_bonsai_v1_bonsai_proto_depIdxs = []int32{
+	0,  // 0: bonsai.v1.NodeList.nodes:type_name -> bonsai.v1.Node
+	0,  // 1: bonsai.v1.PagedNode.node:type_name -> bonsai.v1.Node
+	2,  // 2: bonsai.v1.NodePage.nodes:type_name -> bonsai.v1.PagedNode
+	0,  // 3: bonsai.v1.TreeSummary.root:type_name -> bonsai.v1.Node
+	4,  // 4: bonsai.v1.TreePage.trees:type_name -> bonsai.v1.TreeSummary
+	0,  // 5: bonsai.v1.SearchResult.node:type_name -> bonsai.v1.Node
+	16, // 6: bonsai.v1.SearchResponse.results:type_name -> bonsai.v1.SearchResult
+	0,  // 7: bonsai.v1.GenerateEvent.done:type_name -> bonsai.v1.Node
+	0,  // 8: bonsai.v1.TreeEvent.node:type_name -> bonsai.v1.Node
+	6,  // 9: bonsai.v1.Bonsai.GetNode:input_type -> bonsai.v1.GetNodeRequest
+	7,  // 10: bonsai.v1.Bonsai.CreateNode:input_type -> bonsai.v1.CreateNodeRequest
+	8,  // 11: bonsai.v1.Bonsai.UpdateNode:input_type -> bonsai.v1.UpdateNodeRequest
+	9,  // 12: bonsai.v1.Bonsai.DeleteNode:input_type -> bonsai.v1.DeleteNodeRequest
+	11, // 13: bonsai.v1.Bonsai.ListChildren:input_type -> bonsai.v1.ListChildrenRequest
+	12, // 14: bonsai.v1.Bonsai.GetPath:input_type -> bonsai.v1.GetPathRequest
+	13, // 15: bonsai.v1.Bonsai.ListTrees:input_type -> bonsai.v1.ListTreesRequest
+	14, // 16: bonsai.v1.Bonsai.GetTree:input_type -> bonsai.v1.GetTreeRequest
+	15, // 17: bonsai.v1.Bonsai.Search:input_type -> bonsai.v1.SearchRequest
+	18, // 18: bonsai.v1.Bonsai.GetCurrent:input_type -> bonsai.v1.GetCurrentRequest
+	19, // 19: bonsai.v1.Bonsai.SetCurrent:input_type -> bonsai.v1.SetCurrentRequest
+	21, // 20: bonsai.v1.Bonsai.Generate:input_type -> bonsai.v1.GenerateRequest
+	21, // 21: bonsai.v1.Bonsai.StreamGenerate:input_type -> bonsai.v1.GenerateRequest
+	23, // 22: bonsai.v1.Bonsai.WatchEvents:input_type -> bonsai.v1.WatchEventsRequest
+	0,  // 23: bonsai.v1.Bonsai.GetNode:output_type -> bonsai.v1.Node
+	0,  // 24: bonsai.v1.Bonsai.CreateNode:output_type -> bonsai.v1.Node
+	0,  // 25: bonsai.v1.Bonsai.UpdateNode:output_type -> bonsai.v1.Node
+	10, // 26: bonsai.v1.Bonsai.DeleteNode:output_type -> bonsai.v1.DeleteNodeResponse
+	3,  // 27: bonsai.v1.Bonsai.ListChildren:output_type -> bonsai.v1.NodePage
+	1,  // 28: bonsai.v1.Bonsai.GetPath:output_type -> bonsai.v1.NodeList
+	5,  // 29: bonsai.v1.Bonsai.ListTrees:output_type -> bonsai.v1.TreePage
+	1,  // 30: bonsai.v1.Bonsai.GetTree:output_type -> bonsai.v1.NodeList
+	17, // 31: bonsai.v1.Bonsai.Search:output_type -> bonsai.v1.SearchResponse
+	20, // 32: bonsai.v1.Bonsai.GetCurrent:output_type -> bonsai.v1.CurrentNode
+	20, // 33: bonsai.v1.Bonsai.SetCurrent:output_type -> bonsai.v1.CurrentNode
+	0,  // 34: bonsai.v1.Bonsai.Generate:output_type -> bonsai.v1.Node
+	22, // 35: bonsai.v1.Bonsai.StreamGenerate:output_type -> bonsai.v1.GenerateEvent
+	24, // 36: bonsai.v1.Bonsai.WatchEvents:output_type -> bonsai.v1.TreeEvent
+	23, // [23:37] is the sub-list for method output_type
+	9,  // [9:23] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_bonsai_v1_bonsai_proto_init() }
+func file_bonsai_v1_bonsai_proto_init() {
+	if File_bonsai_v1_bonsai_proto != nil {
+		return
+	}
+	file_bonsai_v1_bonsai_proto_msgTypes[0].OneofWrappers = []any{}
+	file_bonsai_v1_bonsai_proto_msgTypes[7].OneofWrappers = []any{}
+	file_bonsai_v1_bonsai_proto_msgTypes[10].OneofWrappers = []any{}
+	file_bonsai_v1_bonsai_proto_msgTypes[20].OneofWrappers = []any{}
+	file_bonsai_v1_bonsai_proto_msgTypes[21].OneofWrappers = []any{}
+	file_bonsai_v1_bonsai_proto_msgTypes[22].OneofWrappers = []any{
+		(*GenerateEvent_Chunk)(nil),
+		(*GenerateEvent_Done)(nil),
+	}
+	file_bonsai_v1_bonsai_proto_msgTypes[24].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bonsai_v1_bonsai_proto_rawDesc), len(file_bonsai_v1_bonsai_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   25,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bonsai_v1_bonsai_proto_goTypes,
+		DependencyIndexes: file_bonsai_v1_bonsai_proto_depIdxs,
+		MessageInfos:      file_bonsai_v1_bonsai_proto_msgTypes,
+	}.Build()
+	File_bonsai_v1_bonsai_proto = out.File
+	file_bonsai_v1_bonsai_proto_goTypes = nil
+	file_bonsai_v1_bonsai_proto_depIdxs = nil
+}
@@ -0,0 +1,636 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: bonsai/v1/bonsai.proto
+
+package bonsaiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Bonsai_GetNode_FullMethodName        = "/bonsai.v1.Bonsai/GetNode"
+	Bonsai_CreateNode_FullMethodName     = "/bonsai.v1.Bonsai/CreateNode"
+	Bonsai_UpdateNode_FullMethodName     = "/bonsai.v1.Bonsai/UpdateNode"
+	Bonsai_DeleteNode_FullMethodName     = "/bonsai.v1.Bonsai/DeleteNode"
+	Bonsai_ListChildren_FullMethodName   = "/bonsai.v1.Bonsai/ListChildren"
+	Bonsai_GetPath_FullMethodName        = "/bonsai.v1.Bonsai/GetPath"
+	Bonsai_ListTrees_FullMethodName      = "/bonsai.v1.Bonsai/ListTrees"
+	Bonsai_GetTree_FullMethodName        = "/bonsai.v1.Bonsai/GetTree"
+	Bonsai_Search_FullMethodName         = "/bonsai.v1.Bonsai/Search"
+	Bonsai_GetCurrent_FullMethodName     = "/bonsai.v1.Bonsai/GetCurrent"
+	Bonsai_SetCurrent_FullMethodName     = "/bonsai.v1.Bonsai/SetCurrent"
+	Bonsai_Generate_FullMethodName       = "/bonsai.v1.Bonsai/Generate"
+	Bonsai_StreamGenerate_FullMethodName = "/bonsai.v1.Bonsai/StreamGenerate"
+	Bonsai_WatchEvents_FullMethodName    = "/bonsai.v1.Bonsai/WatchEvents"
+)
+
+// BonsaiClient is the client API for Bonsai service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Bonsai mirrors the REST API served under /api/v1 for programmatic clients.
+type BonsaiClient interface {
+	// Nodes
+	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	CreateNode(ctx context.Context, in *CreateNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	UpdateNode(ctx context.Context, in *UpdateNodeRequest, opts ...grpc.CallOption) (*Node, error)
+	DeleteNode(ctx context.Context, in *DeleteNodeRequest, opts ...grpc.CallOption) (*DeleteNodeResponse, error)
+	ListChildren(ctx context.Context, in *ListChildrenRequest, opts ...grpc.CallOption) (*NodePage, error)
+	GetPath(ctx context.Context, in *GetPathRequest, opts ...grpc.CallOption) (*NodeList, error)
+	// Trees
+	ListTrees(ctx context.Context, in *ListTreesRequest, opts ...grpc.CallOption) (*TreePage, error)
+	GetTree(ctx context.Context, in *GetTreeRequest, opts ...grpc.CallOption) (*NodeList, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Current working node
+	GetCurrent(ctx context.Context, in *GetCurrentRequest, opts ...grpc.CallOption) (*CurrentNode, error)
+	SetCurrent(ctx context.Context, in *SetCurrentRequest, opts ...grpc.CallOption) (*CurrentNode, error)
+	// Generation
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*Node, error)
+	StreamGenerate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateEvent], error)
+	// Live tree changes, including those made from the CLI
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TreeEvent], error)
+}
+
+type bonsaiClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBonsaiClient(cc grpc.ClientConnInterface) BonsaiClient {
+	return &bonsaiClient{cc}
+}
+
+func (c *bonsaiClient) GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, Bonsai_GetNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) CreateNode(ctx context.Context, in *CreateNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, Bonsai_CreateNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) UpdateNode(ctx context.Context, in *UpdateNodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, Bonsai_UpdateNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) DeleteNode(ctx context.Context, in *DeleteNodeRequest, opts ...grpc.CallOption) (*DeleteNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteNodeResponse)
+	err := c.cc.Invoke(ctx, Bonsai_DeleteNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) ListChildren(ctx context.Context, in *ListChildrenRequest, opts ...grpc.CallOption) (*NodePage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodePage)
+	err := c.cc.Invoke(ctx, Bonsai_ListChildren_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) GetPath(ctx context.Context, in *GetPathRequest, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, Bonsai_GetPath_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) ListTrees(ctx context.Context, in *ListTreesRequest, opts ...grpc.CallOption) (*TreePage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TreePage)
+	err := c.cc.Invoke(ctx, Bonsai_ListTrees_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) GetTree(ctx context.Context, in *GetTreeRequest, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, Bonsai_GetTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, Bonsai_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) GetCurrent(ctx context.Context, in *GetCurrentRequest, opts ...grpc.CallOption) (*CurrentNode, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CurrentNode)
+	err := c.cc.Invoke(ctx, Bonsai_GetCurrent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) SetCurrent(ctx context.Context, in *SetCurrentRequest, opts ...grpc.CallOption) (*CurrentNode, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CurrentNode)
+	err := c.cc.Invoke(ctx, Bonsai_SetCurrent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, Bonsai_Generate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bonsaiClient) StreamGenerate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[GenerateEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Bonsai_ServiceDesc.Streams[0], Bonsai_StreamGenerate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GenerateRequest, GenerateEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bonsai_StreamGenerateClient = grpc.ServerStreamingClient[GenerateEvent]
+
+func (c *bonsaiClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TreeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Bonsai_ServiceDesc.Streams[1], Bonsai_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, TreeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bonsai_WatchEventsClient = grpc.ServerStreamingClient[TreeEvent]
+
+// BonsaiServer is the server API for Bonsai service.
+// All implementations must embed UnimplementedBonsaiServer
+// for forward compatibility.
+//
+// Bonsai mirrors the REST API served under /api/v1 for programmatic clients.
+type BonsaiServer interface {
+	// Nodes
+	GetNode(context.Context, *GetNodeRequest) (*Node, error)
+	CreateNode(context.Context, *CreateNodeRequest) (*Node, error)
+	UpdateNode(context.Context, *UpdateNodeRequest) (*Node, error)
+	DeleteNode(context.Context, *DeleteNodeRequest) (*DeleteNodeResponse, error)
+	ListChildren(context.Context, *ListChildrenRequest) (*NodePage, error)
+	GetPath(context.Context, *GetPathRequest) (*NodeList, error)
+	// Trees
+	ListTrees(context.Context, *ListTreesRequest) (*TreePage, error)
+	GetTree(context.Context, *GetTreeRequest) (*NodeList, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Current working node
+	GetCurrent(context.Context, *GetCurrentRequest) (*CurrentNode, error)
+	SetCurrent(context.Context, *SetCurrentRequest) (*CurrentNode, error)
+	// Generation
+	Generate(context.Context, *GenerateRequest) (*Node, error)
+	StreamGenerate(*GenerateRequest, grpc.ServerStreamingServer[GenerateEvent]) error
+	// Live tree changes, including those made from the CLI
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[TreeEvent]) error
+	mustEmbedUnimplementedBonsaiServer()
+}
+
+// UnimplementedBonsaiServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBonsaiServer struct{}
+
+func (UnimplementedBonsaiServer) GetNode(context.Context, *GetNodeRequest) (*Node, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNode not implemented")
+}
+func (UnimplementedBonsaiServer) CreateNode(context.Context, *CreateNodeRequest) (*Node, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateNode not implemented")
+}
+func (UnimplementedBonsaiServer) UpdateNode(context.Context, *UpdateNodeRequest) (*Node, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateNode not implemented")
+}
+func (UnimplementedBonsaiServer) DeleteNode(context.Context, *DeleteNodeRequest) (*DeleteNodeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteNode not implemented")
+}
+func (UnimplementedBonsaiServer) ListChildren(context.Context, *ListChildrenRequest) (*NodePage, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListChildren not implemented")
+}
+func (UnimplementedBonsaiServer) GetPath(context.Context, *GetPathRequest) (*NodeList, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPath not implemented")
+}
+func (UnimplementedBonsaiServer) ListTrees(context.Context, *ListTreesRequest) (*TreePage, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrees not implemented")
+}
+func (UnimplementedBonsaiServer) GetTree(context.Context, *GetTreeRequest) (*NodeList, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTree not implemented")
+}
+func (UnimplementedBonsaiServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedBonsaiServer) GetCurrent(context.Context, *GetCurrentRequest) (*CurrentNode, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCurrent not implemented")
+}
+func (UnimplementedBonsaiServer) SetCurrent(context.Context, *SetCurrentRequest) (*CurrentNode, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetCurrent not implemented")
+}
+func (UnimplementedBonsaiServer) Generate(context.Context, *GenerateRequest) (*Node, error) {
+	return nil, status.Error(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedBonsaiServer) StreamGenerate(*GenerateRequest, grpc.ServerStreamingServer[GenerateEvent]) error {
+	return status.Error(codes.Unimplemented, "method StreamGenerate not implemented")
+}
+func (UnimplementedBonsaiServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[TreeEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedBonsaiServer) mustEmbedUnimplementedBonsaiServer() {}
+func (UnimplementedBonsaiServer) testEmbeddedByValue()                {}
+
+// UnsafeBonsaiServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BonsaiServer will
+// result in compilation errors.
+type UnsafeBonsaiServer interface {
+	mustEmbedUnimplementedBonsaiServer()
+}
+
+func RegisterBonsaiServer(s grpc.ServiceRegistrar, srv BonsaiServer) {
+	// If the following call panics, it indicates UnimplementedBonsaiServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Bonsai_ServiceDesc, srv)
+}
+
+func _Bonsai_GetNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).GetNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_GetNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).GetNode(ctx, req.(*GetNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_CreateNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).CreateNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_CreateNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).CreateNode(ctx, req.(*CreateNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_UpdateNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).UpdateNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_UpdateNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).UpdateNode(ctx, req.(*UpdateNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_DeleteNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).DeleteNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_DeleteNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).DeleteNode(ctx, req.(*DeleteNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_ListChildren_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChildrenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).ListChildren(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_ListChildren_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).ListChildren(ctx, req.(*ListChildrenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_GetPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).GetPath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_GetPath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).GetPath(ctx, req.(*GetPathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_ListTrees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTreesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).ListTrees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_ListTrees_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).ListTrees(ctx, req.(*ListTreesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_GetTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).GetTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_GetTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).GetTree(ctx, req.(*GetTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_GetCurrent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).GetCurrent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_GetCurrent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).GetCurrent(ctx, req.(*GetCurrentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_SetCurrent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCurrentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).SetCurrent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_SetCurrent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).SetCurrent(ctx, req.(*SetCurrentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BonsaiServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Bonsai_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BonsaiServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Bonsai_StreamGenerate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BonsaiServer).StreamGenerate(m, &grpc.GenericServerStream[GenerateRequest, GenerateEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bonsai_StreamGenerateServer = grpc.ServerStreamingServer[GenerateEvent]
+
+func _Bonsai_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BonsaiServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, TreeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Bonsai_WatchEventsServer = grpc.ServerStreamingServer[TreeEvent]
+
+// Bonsai_ServiceDesc is the grpc.ServiceDesc for Bonsai service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Bonsai_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bonsai.v1.Bonsai",
+	HandlerType: (*BonsaiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNode",
+			Handler:    _Bonsai_GetNode_Handler,
+		},
+		{
+			MethodName: "CreateNode",
+			Handler:    _Bonsai_CreateNode_Handler,
+		},
+		{
+			MethodName: "UpdateNode",
+			Handler:    _Bonsai_UpdateNode_Handler,
+		},
+		{
+			MethodName: "DeleteNode",
+			Handler:    _Bonsai_DeleteNode_Handler,
+		},
+		{
+			MethodName: "ListChildren",
+			Handler:    _Bonsai_ListChildren_Handler,
+		},
+		{
+			MethodName: "GetPath",
+			Handler:    _Bonsai_GetPath_Handler,
+		},
+		{
+			MethodName: "ListTrees",
+			Handler:    _Bonsai_ListTrees_Handler,
+		},
+		{
+			MethodName: "GetTree",
+			Handler:    _Bonsai_GetTree_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Bonsai_Search_Handler,
+		},
+		{
+			MethodName: "GetCurrent",
+			Handler:    _Bonsai_GetCurrent_Handler,
+		},
+		{
+			MethodName: "SetCurrent",
+			Handler:    _Bonsai_SetCurrent_Handler,
+		},
+		{
+			MethodName: "Generate",
+			Handler:    _Bonsai_Generate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamGenerate",
+			Handler:       _Bonsai_StreamGenerate_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Bonsai_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "bonsai/v1/bonsai.proto",
+}
@@ -0,0 +1,25 @@
+// Package rpc serves the Bonsai gRPC API defined in proto/bonsai/v1/bonsai.proto, a mirror of the
+// REST API under /api/v1 for programmatic clients. 'bai serve --grpc-port' runs it next to the REST API.
+//
+// Go clients use the generated bonsaiv1 package:
+//
+//	conn, err := grpc.NewClient("localhost:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	if err != nil {
+//		return err
+//	}
+//	defer conn.Close()
+//	client := bonsaiv1.NewBonsaiClient(conn)
+//	trees, err := client.ListTrees(ctx, &bonsaiv1.ListTreesRequest{})
+//
+// Editor plugins that would rather not link a gRPC library can speak JSON-RPC to the same service
+// over a process's stdin and stdout, with ServeJSONRPC, which 'bai rpc --stdio' runs.
+//
+// Clients in other languages can be generated from the same .proto file. After changing it, regenerate
+// the Go code with:
+//
+//	go generate ./pkg/rpc
+//
+// which requires protoc with the protoc-gen-go and protoc-gen-go-grpc plugins on the PATH.
+package rpc
+
+//go:generate protoc --proto_path=../../proto --go_out=. --go_opt=module=github.com/aarose/bonsai/pkg/rpc --go-grpc_out=. --go-grpc_opt=module=github.com/aarose/bonsai/pkg/rpc bonsai/v1/bonsai.proto
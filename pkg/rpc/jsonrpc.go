@@ -0,0 +1,339 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/rpc/bonsaiv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// JSON-RPC 2.0 error codes, with -32800 for cancelled requests as in the Language Server Protocol
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeCancelled      = -32800
+)
+
+// cancelMethod is the notification that cancels a request in progress, given its id
+const cancelMethod = "$/cancelRequest"
+
+var (
+	// jsonParams reads params with either the proto field names, like parent_id, or their camelCase JSON names
+	jsonParams = protojson.UnmarshalOptions{}
+
+	// jsonResults writes results with the proto field names, matching the REST API's snake_case
+	jsonResults = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+// jsonRequest is a JSON-RPC request, or a notification when it has no id
+type jsonRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonResponse is a JSON-RPC response. Its id is null when the request's couldn't be read.
+type jsonResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonError      `json:"error,omitempty"`
+}
+
+// jsonNotification is a notification the server sends while a request streams
+type jsonNotification struct {
+	JSONRPC string       `json:"jsonrpc"`
+	Method  string       `json:"method"`
+	Params  streamParams `json:"params"`
+}
+
+// jsonError is the error of a failed JSON-RPC request. Data holds the gRPC status code name, such as NotFound.
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// streamParams are the params of the notifications a streaming request sends, naming the request
+type streamParams struct {
+	ID    json.RawMessage `json:"id"`
+	Chunk string          `json:"chunk,omitempty"`
+	Event json.RawMessage `json:"event,omitempty"`
+}
+
+// jsonMethod is a method of the JSON-RPC protocol, calling through to the gRPC service
+type jsonMethod struct {
+	call func(ctx context.Context, s *Server, params json.RawMessage, notify func(streamParams)) (proto.Message, error)
+
+	// endless methods stream until they're cancelled, so they're cancelled when the client goes away
+	endless bool
+}
+
+// unary adapts a gRPC method to a JSON-RPC method taking its request as params and returning its response
+func unary[Req, Resp proto.Message](newRequest func() Req, method func(*Server, context.Context, Req) (Resp, error)) jsonMethod {
+	return jsonMethod{call: func(ctx context.Context, s *Server, params json.RawMessage, _ func(streamParams)) (proto.Message, error) {
+		req := newRequest()
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		return method(s, ctx, req)
+	}}
+}
+
+// jsonMethods lists the methods of the JSON-RPC protocol. Each mirrors the gRPC method of the same
+// name, except getBranch, which is GetPath.
+var jsonMethods = map[string]jsonMethod{
+	"getNode":      unary(func() *bonsaiv1.GetNodeRequest { return &bonsaiv1.GetNodeRequest{} }, (*Server).GetNode),
+	"createNode":   unary(func() *bonsaiv1.CreateNodeRequest { return &bonsaiv1.CreateNodeRequest{} }, (*Server).CreateNode),
+	"updateNode":   unary(func() *bonsaiv1.UpdateNodeRequest { return &bonsaiv1.UpdateNodeRequest{} }, (*Server).UpdateNode),
+	"deleteNode":   unary(func() *bonsaiv1.DeleteNodeRequest { return &bonsaiv1.DeleteNodeRequest{} }, (*Server).DeleteNode),
+	"listChildren": unary(func() *bonsaiv1.ListChildrenRequest { return &bonsaiv1.ListChildrenRequest{} }, (*Server).ListChildren),
+	"getBranch":    unary(func() *bonsaiv1.GetPathRequest { return &bonsaiv1.GetPathRequest{} }, (*Server).GetPath),
+	"listTrees":    unary(func() *bonsaiv1.ListTreesRequest { return &bonsaiv1.ListTreesRequest{} }, (*Server).ListTrees),
+	"getTree":      unary(func() *bonsaiv1.GetTreeRequest { return &bonsaiv1.GetTreeRequest{} }, (*Server).GetTree),
+	"search":       unary(func() *bonsaiv1.SearchRequest { return &bonsaiv1.SearchRequest{} }, (*Server).Search),
+	"getCurrent":   unary(func() *bonsaiv1.GetCurrentRequest { return &bonsaiv1.GetCurrentRequest{} }, (*Server).GetCurrent),
+	"setCurrent":   unary(func() *bonsaiv1.SetCurrentRequest { return &bonsaiv1.SetCurrentRequest{} }, (*Server).SetCurrent),
+	"generate":     unary(func() *bonsaiv1.GenerateRequest { return &bonsaiv1.GenerateRequest{} }, (*Server).Generate),
+
+	// Sends a "chunk" notification for each piece of the response as it's generated, then returns the stored node
+	"streamGenerate": {call: func(ctx context.Context, s *Server, params json.RawMessage, notify func(streamParams)) (proto.Message, error) {
+		req := &bonsaiv1.GenerateRequest{}
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		var done *bonsaiv1.Node
+		err := s.StreamGenerate(req, &notifyStream[*bonsaiv1.GenerateEvent]{ctx: ctx, send: func(event *bonsaiv1.GenerateEvent) error {
+			if node := event.GetDone(); node != nil {
+				done = node
+				return nil
+			}
+			notify(streamParams{Chunk: event.GetChunk()})
+			return nil
+		}})
+		return done, err
+	}},
+
+	// Sends an "event" notification for each change to the tree until it's cancelled, then returns nothing
+	"watchEvents": {endless: true, call: func(ctx context.Context, s *Server, params json.RawMessage, notify func(streamParams)) (proto.Message, error) {
+		req := &bonsaiv1.WatchEventsRequest{}
+		if err := decodeParams(params, req); err != nil {
+			return nil, err
+		}
+		err := s.WatchEvents(req, &notifyStream[*bonsaiv1.TreeEvent]{ctx: ctx, send: func(event *bonsaiv1.TreeEvent) error {
+			encoded, err := jsonResults.Marshal(event)
+			if err != nil {
+				return err
+			}
+			notify(streamParams{Event: encoded})
+			return nil
+		}})
+		return nil, err
+	}},
+}
+
+// notifyStream passes the messages a streaming gRPC method sends to a function, so the method can
+// serve a JSON-RPC request. Only Context and Send are implemented.
+type notifyStream[T any] struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(T) error
+}
+
+func (s *notifyStream[T]) Context() context.Context { return s.ctx }
+func (s *notifyStream[T]) Send(message T) error     { return s.send(message) }
+
+// decodeParams reads a request's params into its gRPC request message, accepting missing params
+func decodeParams(params json.RawMessage, req proto.Message) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := jsonParams.Unmarshal(params, req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid params: %v", err)
+	}
+	return nil
+}
+
+// ServeJSONRPC speaks JSON-RPC 2.0 over a pair of streams, such as an editor plugin's pipes to
+// stdin and stdout, with one message per line. It offers the methods of the gRPC service under
+// camelCase names, with params and results in the proto3 JSON encoding of its messages. Requests
+// run concurrently; streamGenerate and watchEvents send notifications carrying the request's id
+// as they go, and any request can be cancelled with a $/cancelRequest notification. ServeJSONRPC
+// returns once r reaches the end or ctx is cancelled, after in-flight requests have finished,
+// cancelling those that only end when cancelled.
+func ServeJSONRPC(ctx context.Context, r io.Reader, w io.Writer, session *bonsai.Session, readOnly bool) error {
+	server := NewServer(session, readOnly)
+	var (
+		writeMu sync.Mutex
+		encoder = json.NewEncoder(w)
+
+		inflightMu sync.Mutex
+		inflight   = make(map[string]context.CancelFunc)
+		endless    = make(map[string]context.CancelFunc)
+		running    sync.WaitGroup
+	)
+	write := func(message interface{}) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = encoder.Encode(message)
+	}
+	fail := func(id json.RawMessage, code int, message string) {
+		write(jsonResponse{JSONRPC: "2.0", ID: id, Error: &jsonError{Code: code, Message: message}})
+	}
+	defer func() {
+		inflightMu.Lock()
+		for _, cancel := range endless {
+			cancel()
+		}
+		inflightMu.Unlock()
+		running.Wait()
+	}()
+
+	reader := bufio.NewReader(r)
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				lines <- line
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read request: %w", err)
+		case line = <-lines:
+		}
+
+		var req jsonRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			fail(nil, codeParseError, fmt.Sprintf("invalid JSON: %v", err))
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			fail(req.ID, codeInvalidRequest, `expected a JSON-RPC 2.0 request with "jsonrpc": "2.0" and a method`)
+			continue
+		}
+
+		if req.Method == cancelMethod {
+			var params struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(req.Params, &params) == nil {
+				inflightMu.Lock()
+				if cancel, ok := inflight[string(params.ID)]; ok {
+					cancel()
+				}
+				inflightMu.Unlock()
+			}
+			continue
+		}
+
+		method, ok := jsonMethods[req.Method]
+		if !ok {
+			if req.ID != nil {
+				fail(req.ID, codeMethodNotFound, fmt.Sprintf("unknown method %q", req.Method))
+			}
+			continue
+		}
+
+		key := string(req.ID)
+		callCtx, cancel := context.WithCancel(ctx)
+		if req.ID != nil {
+			inflightMu.Lock()
+			if _, duplicate := inflight[key]; duplicate {
+				inflightMu.Unlock()
+				cancel()
+				fail(req.ID, codeInvalidRequest, fmt.Sprintf("request %s is already in progress", key))
+				continue
+			}
+			inflight[key] = cancel
+			if method.endless {
+				endless[key] = cancel
+			}
+			inflightMu.Unlock()
+		}
+
+		running.Add(1)
+		go func(req jsonRequest) {
+			defer running.Done()
+			defer func() {
+				cancel()
+				if req.ID != nil {
+					inflightMu.Lock()
+					delete(inflight, key)
+					delete(endless, key)
+					inflightMu.Unlock()
+				}
+			}()
+
+			notify := func(params streamParams) {
+				params.ID = req.ID
+				name := "chunk"
+				if params.Event != nil {
+					name = "event"
+				}
+				write(jsonNotification{JSONRPC: "2.0", Method: name, Params: params})
+			}
+			result, err := method.call(callCtx, server, req.Params, notify)
+			if req.ID == nil {
+				return
+			}
+			if err != nil {
+				write(jsonResponse{JSONRPC: "2.0", ID: req.ID, Error: toJSONError(callCtx, err)})
+				return
+			}
+
+			encoded := json.RawMessage("null")
+			if result != nil && result.ProtoReflect().IsValid() {
+				if encoded, err = jsonResults.Marshal(result); err != nil {
+					fail(req.ID, codeServerError, fmt.Sprintf("failed to encode result: %v", err))
+					return
+				}
+			}
+			write(jsonResponse{JSONRPC: "2.0", ID: req.ID, Result: encoded})
+		}(req)
+	}
+}
+
+// toJSONError maps an error from the gRPC service to a JSON-RPC error
+func toJSONError(ctx context.Context, err error) *jsonError {
+	if ctx.Err() != nil {
+		return &jsonError{Code: codeCancelled, Message: "request cancelled"}
+	}
+	st := status.Convert(err)
+	code := codeServerError
+	if st.Code() == codes.InvalidArgument {
+		code = codeInvalidParams
+	}
+	return &jsonError{Code: code, Message: st.Message(), Data: st.Code().String()}
+}
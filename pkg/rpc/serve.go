@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures a gRPC server started with Serve
+type Options struct {
+	Token    string // When set, every call must send "authorization: Bearer <token>" metadata
+	CertFile string // TLS certificate; serves over TLS when set together with KeyFile
+	KeyFile  string
+	ReadOnly bool // Reject every call that would change the tree
+}
+
+// Serve runs the gRPC service for the session on the listener until ctx is cancelled, then stops
+// gracefully, letting in-flight calls finish
+func Serve(ctx context.Context, listener net.Listener, session *bonsai.Session, opts Options) error {
+	var serverOpts []grpc.ServerOption
+	if opts.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+	if opts.Token != "" {
+		serverOpts = append(serverOpts,
+			grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if err := checkToken(ctx, opts.Token); err != nil {
+					return nil, err
+				}
+				return handler(ctx, req)
+			}),
+			grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				if err := checkToken(stream.Context(), opts.Token); err != nil {
+					return err
+				}
+				return handler(srv, stream)
+			}),
+		)
+	}
+
+	server := grpc.NewServer(serverOpts...)
+	NewServer(session, opts.ReadOnly).Register(server)
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	if err := server.Serve(listener); err != nil {
+		return fmt.Errorf("gRPC server failed: %w", err)
+	}
+	return nil
+}
+
+// checkToken compares the bearer token in a call's metadata with the server's in constant time
+func checkToken(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if presented, ok := strings.CutPrefix(value, "Bearer "); ok && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "send the access token as 'authorization: Bearer <token>' metadata")
+}
@@ -0,0 +1,448 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/hooks"
+	"github.com/aarose/bonsai/pkg/llm"
+	"github.com/aarose/bonsai/pkg/rpc/bonsaiv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultPageSize and maxPageSize bound paginated listings, as in the REST API
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	// defaultSearchLimit is the number of search results returned when no limit is given
+	defaultSearchLimit = 20
+
+	// maxTitleLength is the longest tree title returned by ListTrees
+	maxTitleLength = 80
+)
+
+// Server implements the Bonsai gRPC service on a session
+type Server struct {
+	bonsaiv1.UnimplementedBonsaiServer
+
+	session  *bonsai.Session
+	readOnly bool
+}
+
+// NewServer creates a service working on the session's database. A read-only server rejects every
+// call that would change the tree.
+func NewServer(session *bonsai.Session, readOnly bool) *Server {
+	return &Server{session: session, readOnly: readOnly}
+}
+
+// Register adds the service to a gRPC server
+func (s *Server) Register(server *grpc.Server) {
+	bonsaiv1.RegisterBonsaiServer(server, s)
+}
+
+// db returns the session's database
+func (s *Server) db() *db.Database {
+	return s.session.Database()
+}
+
+// writable returns an error if the server is read-only
+func (s *Server) writable() error {
+	if s.readOnly {
+		return status.Error(codes.PermissionDenied, "the server is read-only")
+	}
+	return nil
+}
+
+// node looks up a node by its full or abbreviated ID
+func (s *Server) node(id string) (*db.Node, error) {
+	node, err := s.session.Node(id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return node, nil
+}
+
+// GetNode returns a single node
+func (s *Server) GetNode(ctx context.Context, req *bonsaiv1.GetNodeRequest) (*bonsaiv1.Node, error) {
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toNode(node), nil
+}
+
+// CreateNode creates a node, starting a new tree when no parent is given
+func (s *Server) CreateNode(ctx context.Context, req *bonsaiv1.CreateNodeRequest) (*bonsaiv1.Node, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	if req.GetContent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	nodeType := req.GetType()
+	if nodeType == "" {
+		nodeType = "user"
+	}
+
+	var (
+		node *db.Node
+		err  error
+	)
+	if req.Parent == nil {
+		if nodeType != "user" {
+			return nil, status.Error(codes.InvalidArgument, "root nodes must have type 'user'")
+		}
+		node, err = s.db().CreateRootNode(req.GetContent(), req.Model)
+	} else {
+		parent, lookupErr := s.node(req.GetParent())
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		if !db.IsNodeType(nodeType) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid node type: %s (must be one of %s)", nodeType, strings.Join(db.NodeTypes, ", "))
+		}
+		node, err = s.db().CreateChildNodeWithType(req.GetContent(), parent.ID, nodeType, req.Model)
+	}
+	if err != nil {
+		return nil, contentError(err, "failed to create node")
+	}
+
+	s.session.Notify(hooks.NodeCreated, node)
+	return toNode(node), nil
+}
+
+// UpdateNode edits a node's content
+func (s *Server) UpdateNode(ctx context.Context, req *bonsaiv1.UpdateNodeRequest) (*bonsaiv1.Node, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db().UpdateNodeContent(node.ID, req.GetContent()); err != nil {
+		return nil, contentError(err, "failed to update node")
+	}
+	return s.GetNode(ctx, &bonsaiv1.GetNodeRequest{Id: node.ID})
+}
+
+// DeleteNode prunes a node and its subtree, or only the node when keep_children is set
+func (s *Server) DeleteNode(ctx context.Context, req *bonsaiv1.DeleteNodeRequest) (*bonsaiv1.DeleteNodeResponse, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.session.Prune(node.ID, req.GetKeepChildren())
+	if result == nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &bonsaiv1.DeleteNodeResponse{
+		Deleted:        int32(result.Deleted),
+		Reattached:     int32(result.Reattached),
+		CurrentChanged: result.CurrentCleared || result.CurrentMovedTo != nil,
+	}
+	if resp.CurrentNode, err = s.db().GetCurrentNode(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return resp, nil
+}
+
+// ListChildren returns a page of a node's direct children
+func (s *Server) ListChildren(ctx context.Context, req *bonsaiv1.ListChildrenRequest) (*bonsaiv1.NodePage, error) {
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageSize(req.GetLimit(), defaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra node to find out whether there's another page
+	nodes, err := s.db().GetChildrenPage(node.ID, req.GetCursor(), limit+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch child nodes: %v", err)
+	}
+	page := &bonsaiv1.NodePage{}
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+		page.NextCursor = nodes[len(nodes)-1].ID
+	}
+
+	counts, err := s.db().CountChildren(nodeIDs(nodes))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count child nodes: %v", err)
+	}
+	for _, child := range nodes {
+		page.Nodes = append(page.Nodes, &bonsaiv1.PagedNode{Node: toNode(child), ChildCount: int32(counts[child.ID])})
+	}
+	return page, nil
+}
+
+// GetPath returns the nodes from the root down to a node, in conversation order
+func (s *Server) GetPath(ctx context.Context, req *bonsaiv1.GetPathRequest) (*bonsaiv1.NodeList, error) {
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.db().GetConversationHistory(node.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get conversation path: %v", err)
+	}
+	return toNodeList(history), nil
+}
+
+// ListTrees returns a page of root nodes with their trees' titles, sizes and last activity
+func (s *Server) ListTrees(ctx context.Context, req *bonsaiv1.ListTreesRequest) (*bonsaiv1.TreePage, error) {
+	limit, err := pageSize(req.GetLimit(), defaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.db().GetRootNodesPage(req.GetCursor(), limit+1)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch root nodes: %v", err)
+	}
+	page := &bonsaiv1.TreePage{}
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+		page.NextCursor = nodes[len(nodes)-1].ID
+	}
+
+	ids := nodeIDs(nodes)
+	counts, err := s.db().CountChildren(ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count child nodes: %v", err)
+	}
+	activity, err := s.db().GetTreeActivityForRoots(ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tree activity: %v", err)
+	}
+
+	for _, root := range nodes {
+		tree := &bonsaiv1.TreeSummary{
+			Root:       toNode(root),
+			ChildCount: int32(counts[root.ID]),
+			Title:      treeTitle(root.Content),
+		}
+		if a, ok := activity[root.ID]; ok {
+			tree.NodeCount = int32(a.NodeCount)
+			tree.LastActivity = a.LastActivity
+		}
+		page.Trees = append(page.Trees, tree)
+	}
+	return page, nil
+}
+
+// GetTree returns a single tree, or the subtree below any node
+func (s *Server) GetTree(ctx context.Context, req *bonsaiv1.GetTreeRequest) (*bonsaiv1.NodeList, error) {
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.db().GetNodeAndAllChildren(node.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch tree: %v", err)
+	}
+	return toNodeList(nodes), nil
+}
+
+// Search runs a full-text search over node content
+func (s *Server) Search(ctx context.Context, req *bonsaiv1.SearchRequest) (*bonsaiv1.SearchResponse, error) {
+	if strings.TrimSpace(req.GetQuery()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	limit, err := pageSize(req.GetLimit(), defaultSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.db().SearchNodes(req.GetQuery(), limit)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to search: %v", err)
+	}
+	resp := &bonsaiv1.SearchResponse{}
+	for _, match := range matches {
+		resp.Results = append(resp.Results, &bonsaiv1.SearchResult{Node: toNode(match.Node), Snippet: highlightSnippet(match.Snippet)})
+	}
+	return resp, nil
+}
+
+// GetCurrent returns the current working node
+func (s *Server) GetCurrent(ctx context.Context, req *bonsaiv1.GetCurrentRequest) (*bonsaiv1.CurrentNode, error) {
+	currentNodeID, err := s.db().GetCurrentNode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current node: %v", err)
+	}
+	return &bonsaiv1.CurrentNode{CurrentNode: currentNodeID}, nil
+}
+
+// SetCurrent checks out a node
+func (s *Server) SetCurrent(ctx context.Context, req *bonsaiv1.SetCurrentRequest) (*bonsaiv1.CurrentNode, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	node, err := s.node(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db().SetCurrentNode(node.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set current node: %v", err)
+	}
+	return &bonsaiv1.CurrentNode{CurrentNode: &node.ID}, nil
+}
+
+// Generate asks the model to respond to the conversation ending at a node, storing the answer as its child
+func (s *Server) Generate(ctx context.Context, req *bonsaiv1.GenerateRequest) (*bonsaiv1.Node, error) {
+	return s.generate(ctx, req, nil)
+}
+
+// StreamGenerate is Generate, sending the response as it's generated and then the stored node
+func (s *Server) StreamGenerate(req *bonsaiv1.GenerateRequest, stream grpc.ServerStreamingServer[bonsaiv1.GenerateEvent]) error {
+	node, err := s.generate(stream.Context(), req, func(chunk string) error {
+		return stream.Send(&bonsaiv1.GenerateEvent{Event: &bonsaiv1.GenerateEvent_Chunk{Chunk: chunk}})
+	})
+	if err != nil {
+		return err
+	}
+	return stream.Send(&bonsaiv1.GenerateEvent{Event: &bonsaiv1.GenerateEvent_Done{Done: node}})
+}
+
+// generate responds to a node with the requested or inherited model, streaming to onChunk if it's set
+func (s *Server) generate(ctx context.Context, req *bonsaiv1.GenerateRequest, onChunk llm.StreamHandler) (*bonsaiv1.Node, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	parent, err := s.node(req.GetParent())
+	if err != nil {
+		return nil, err
+	}
+
+	model := req.GetModel()
+	if model == "" {
+		if model, err = s.session.InheritedModel(parent); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	if model == "" {
+		return nil, status.Error(codes.InvalidArgument, "model is required: neither the branch nor its tree has a model")
+	}
+
+	// Bounded by --timeout or the generate.timeout setting, as in the CLI and the REST API
+	ctx, cancel := context.WithTimeout(ctx, s.session.Timeout())
+	defer cancel()
+	if onChunk != nil {
+		ctx = llm.WithStreamHandler(ctx, onChunk)
+	}
+
+	node, err := s.session.Respond(ctx, parent, model)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return toNode(node), nil
+}
+
+// WatchEvents streams changes to the tree until the client goes away
+func (s *Server) WatchEvents(req *bonsaiv1.WatchEventsRequest, stream grpc.ServerStreamingServer[bonsaiv1.TreeEvent]) error {
+	events, unsubscribe, err := s.db().Subscribe()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to watch for changes: %v", err)
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change := <-events:
+			event := &bonsaiv1.TreeEvent{Type: string(change.Type), Id: change.ID, CurrentNode: change.CurrentNode}
+			if change.Node != nil {
+				event.Node = toNode(change.Node)
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// contentError maps a failure to store content to a status, distinguishing content over the size limit
+func contentError(err error, message string) error {
+	if errors.Is(err, db.ErrContentTooLarge) {
+		return status.Errorf(codes.InvalidArgument, "%s: %v", message, err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+// pageSize validates a requested number of results, returning def when none was requested
+func pageSize(requested int32, def int) (int, error) {
+	if requested == 0 {
+		return def, nil
+	}
+	if requested < 1 || requested > maxPageSize {
+		return 0, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxPageSize)
+	}
+	return int(requested), nil
+}
+
+// nodeIDs returns the IDs of the given nodes
+func nodeIDs(nodes []*db.Node) []string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
+
+// treeTitle derives a tree's title from its root message: the first non-blank line, shortened to maxTitleLength
+func treeTitle(content string) string {
+	title := strings.TrimSpace(content)
+	if line, _, found := strings.Cut(title, "\n"); found {
+		title = strings.TrimSpace(line)
+	}
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength-3]) + "..."
+	}
+	return title
+}
+
+// highlightSnippet escapes a search snippet as HTML and wraps its matches in <mark>, as the REST API does
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer(db.SnippetMatchStart, "<mark>", db.SnippetMatchEnd, "</mark>").Replace(html.EscapeString(snippet))
+}
+
+// toNode converts a database node to its protobuf message
+func toNode(node *db.Node) *bonsaiv1.Node {
+	return &bonsaiv1.Node{
+		Id:        node.ID,
+		Content:   node.Content,
+		Type:      node.Type,
+		Parent:    node.Parent,
+		Model:     node.Model,
+		Metadata:  node.Metadata,
+		CreatedAt: node.CreatedAt,
+		VisitedAt: node.VisitedAt,
+		Author:    node.Author,
+	}
+}
+
+// toNodeList converts database nodes to a protobuf node list
+func toNodeList(nodes []*db.Node) *bonsaiv1.NodeList {
+	list := &bonsaiv1.NodeList{}
+	for _, node := range nodes {
+		list.Nodes = append(list.Nodes, toNode(node))
+	}
+	return list
+}
@@ -0,0 +1,159 @@
+// Package share uploads exported conversations so they can be shared by URL.
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Timeout bounds how long an upload may take
+const Timeout = 30 * time.Second
+
+// GistService is the service name that uploads to GitHub gists
+const GistService = "gist"
+
+// gistsURL is the GitHub API endpoint that creates gists
+const gistsURL = "https://api.github.com/gists"
+
+// ErrNoGitHubToken is returned when uploading a gist without a GitHub token to do it with
+var ErrNoGitHubToken = errors.New("no GitHub token: set GITHUB_TOKEN or GH_TOKEN, or log in with 'gh auth login'")
+
+// Document is a file to share
+type Document struct {
+	Filename    string
+	Description string
+	Content     string
+	Public      bool // Gists are secret unless public; ignored by paste services
+}
+
+// Upload shares the document with the given service and returns its URL. The service is either
+// GistService or the URL of a paste service that accepts the raw text in a POST body and responds
+// with the paste's URL, such as https://paste.rs.
+func Upload(ctx context.Context, service string, doc Document) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	if service == "" || service == GistService {
+		return uploadGist(ctx, doc)
+	}
+	if !strings.HasPrefix(service, "http://") && !strings.HasPrefix(service, "https://") {
+		return "", fmt.Errorf("unknown share service %q: use %q or a paste service URL", service, GistService)
+	}
+	return uploadPaste(ctx, service, doc)
+}
+
+// uploadGist creates a GitHub gist holding the document
+func uploadGist(ctx context.Context, doc Document) (string, error) {
+	token := GitHubToken()
+	if token == "" {
+		return "", ErrNoGitHubToken
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"description": doc.Description,
+		"public":      doc.Public,
+		"files": map[string]interface{}{
+			doc.Filename: map[string]string{"content": doc.Content},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode gist: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gistsURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create gist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read gist response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		var apiError struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiError) == nil && apiError.Message != "" {
+			return "", fmt.Errorf("failed to create gist: %s: %s", resp.Status, apiError.Message)
+		}
+		return "", fmt.Errorf("failed to create gist: %s", resp.Status)
+	}
+
+	var gist struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(data, &gist); err != nil || gist.HTMLURL == "" {
+		return "", errors.New("failed to create gist: response has no URL")
+	}
+	return gist.HTMLURL, nil
+}
+
+// urlPattern finds the first URL in a paste service's response
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// uploadPaste posts the document to a paste service and returns the URL it responds with
+func uploadPaste(ctx context.Context, service string, doc Document) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, service, strings.NewReader(doc.Content))
+	if err != nil {
+		return "", fmt.Errorf("invalid share service URL %s: %w", service, err)
+	}
+	req.Header.Set("Content-Type", "text/markdown; charset=utf-8")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response from %s: %w", service, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to upload to %s: %s", service, resp.Status)
+	}
+
+	if location := resp.Header.Get("Location"); strings.HasPrefix(location, "http") {
+		return location, nil
+	}
+	url := urlPattern.FindString(string(data))
+	if url == "" {
+		return "", fmt.Errorf("%s didn't respond with a URL", service)
+	}
+	return url, nil
+}
+
+// GitHubToken returns a GitHub token from GITHUB_TOKEN or GH_TOKEN, or from the GitHub CLI if
+// it's logged in, or an empty string if there is none
+func GitHubToken() string {
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+
+	output, err := exec.Command("gh", "auth", "token").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/aarose/bonsai/db"
 )
 
 type Node struct {
@@ -190,9 +190,9 @@ func stringPtr(s string) *string {
 
 func main() {
 	var dbPath string
-	
+
 	// Check if custom path provided via command line
-	if len(os.Args) > 1 {
+	if len(os.Args) > 1 && os.Args[1] != "" {
 		dbPath = os.Args[1]
 	} else {
 		// Use same path as CLI tool: ~/.bonsai/bonsai.db
@@ -203,55 +203,19 @@ func main() {
 		dbPath = filepath.Join(homeDir, ".bonsai", "bonsai.db")
 	}
 
-	// Ensure the directory exists
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Fatalf("Failed to create database directory: %v", err)
-	}
-
-	// Open database connection
-	db, err := sql.Open("sqlite", dbPath)
+	// Open the database through the db package, so the schema, search index and content handling match bai's
+	database, err := db.NewDatabase(dbPath)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
-	defer db.Close()
+	defer database.Close()
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	if err := database.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	// Create table if it doesn't exist
-	createNodeTable := `
-	CREATE TABLE IF NOT EXISTS Node (
-		id TEXT PRIMARY KEY,
-		content TEXT NOT NULL,
-		type TEXT NOT NULL CHECK (type IN ('user', 'llm')),
-		parent TEXT,
-		children TEXT DEFAULT '[]',
-		model TEXT
-	);`
-
-	if _, err := db.Exec(createNodeTable); err != nil {
-		log.Fatalf("Failed to create Node table: %v", err)
-	}
-
-	// Clear existing data (optional - comment out if you want to keep existing data)
-	if _, err := db.Exec("DELETE FROM Node"); err != nil {
-		log.Fatalf("Failed to clear existing data: %v", err)
-	}
-
-	// Insert conversation nodes
-	insertSQL := `
-	INSERT INTO Node (id, content, type, parent, children, model)
-	VALUES (?, ?, ?, ?, ?, ?)`
-
-	stmt, err := db.Prepare(insertSQL)
-	if err != nil {
-		log.Fatalf("Failed to prepare statement: %v", err)
-	}
-	defer stmt.Close()
-
+	// Overwrite the sample conversation if it's already there, leaving any other trees alone
+	nodes := make([]*db.Node, 0, len(conversationNodes))
 	for _, node := range conversationNodes {
 		// Convert children slice to JSON string
 		childrenJSON, err := json.Marshal(node.Children)
@@ -259,19 +223,20 @@ func main() {
 			log.Fatalf("Failed to marshal children for node %s: %v", node.ID, err)
 		}
 
-		// Execute insert
-		_, err = stmt.Exec(
-			node.ID,
-			node.Content,
-			node.Type,
-			node.Parent,
-			string(childrenJSON),
-			node.Model,
-		)
-		if err != nil {
-			log.Fatalf("Failed to insert node %s: %v", node.ID, err)
-		}
-
+		nodes = append(nodes, &db.Node{
+			ID:        node.ID,
+			Content:   node.Content,
+			Type:      node.Type,
+			Parent:    node.Parent,
+			Children:  string(childrenJSON),
+			Model:     node.Model,
+			CreatedAt: time.Now().Unix(),
+		})
+	}
+	if err := database.UpsertNodes(nodes); err != nil {
+		log.Fatalf("Failed to insert nodes: %v", err)
+	}
+	for _, node := range nodes {
 		fmt.Printf("Inserted node: %s (%s)\n", node.ID, node.Type)
 	}
 
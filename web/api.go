@@ -0,0 +1,333 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// maxRequestBodyBytes caps the size of JSON request bodies accepted by the API
+const maxRequestBodyBytes = 1 << 20
+
+// createNodeRequest is the body of POST /api/nodes
+type createNodeRequest struct {
+	Parent  *string `json:"parent,omitempty"` // Omit to start a new tree
+	Content string  `json:"content"`
+	Type    string  `json:"type,omitempty"` // "user" (default), "llm", "note" or "system"
+	Model   *string `json:"model,omitempty"`
+}
+
+// updateNodeRequest is the body of PATCH /api/nodes/{id}
+type updateNodeRequest struct {
+	Content *string `json:"content"`
+}
+
+// setCurrentRequest is the body of PUT /api/current
+type setCurrentRequest struct {
+	ID string `json:"id"`
+}
+
+// deleteNodeResponse is returned by DELETE /api/nodes/{id}
+type deleteNodeResponse struct {
+	Deleted        int     `json:"deleted"`
+	Reattached     int     `json:"reattached,omitempty"`
+	CurrentNode    *string `json:"current_node"`
+	CurrentChanged bool    `json:"current_changed"`
+}
+
+// currentNodeResponse is returned by GET and PUT /api/current
+type currentNodeResponse struct {
+	CurrentNode *string `json:"current_node"`
+}
+
+// handleCreateNode creates a new root node, or a child node when a parent is given
+func (s *Server) handleCreateNode(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	var req createNodeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.Content == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+	if req.Type == "" {
+		req.Type = "user"
+	}
+
+	var (
+		node *db.Node
+		err  error
+	)
+	if req.Parent == nil {
+		if req.Type != "user" {
+			http.Error(w, "root nodes must have type 'user'", http.StatusBadRequest)
+			return
+		}
+		node, err = database.CreateRootNode(req.Content, req.Model)
+	} else {
+		parentID, ok := database.resolveNodeID(w, *req.Parent)
+		if !ok {
+			return
+		}
+		if !db.IsNodeType(req.Type) {
+			http.Error(w, fmt.Sprintf("invalid node type: %s (must be one of %s)", req.Type, strings.Join(db.NodeTypes, ", ")), http.StatusBadRequest)
+			return
+		}
+		node, err = database.CreateChildNodeWithType(req.Content, parentID, req.Type, req.Model)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create node: %v", err), contentErrorStatus(err))
+		log.Printf("Error creating node: %v", err)
+		return
+	}
+
+	database.hooks.Fire(hooks.NodeCreated, node)
+	writeJSON(w, http.StatusCreated, node)
+}
+
+// contentErrorStatus is the status for failing to store a node's content: 413 if it's over the size
+// limit, which the client can fix, or else 500
+func contentErrorStatus(err error) int {
+	if errors.Is(err, db.ErrContentTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusInternalServerError
+}
+
+// handleGetNode serves a single node
+func (s *Server) handleGetNode(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	node, err := database.GetNodeByID(nodeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, node)
+}
+
+// handleUpdateNode edits the content of a node
+func (s *Server) handleUpdateNode(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	var req updateNodeRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Content == nil || *req.Content == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := database.UpdateNodeContent(nodeID, *req.Content); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to update node: %v", err), contentErrorStatus(err))
+		log.Printf("Error updating node %s: %v", nodeID, err)
+		return
+	}
+
+	node, err := database.GetNodeByID(nodeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, node)
+}
+
+// handleDeleteNode prunes a node and its subtree, or just the node itself with ?keep_children=true
+func (s *Server) handleDeleteNode(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	keepChildren := r.URL.Query().Get("keep_children") == "true"
+
+	node, err := database.GetNodeByID(nodeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Run hooks once the lock below is released, so they can use bai themselves
+	pruned := false
+	defer func() {
+		if pruned {
+			database.hooks.Fire(hooks.Prune, node)
+		}
+	}()
+
+	// Hold the lock so a CLI prune can't move the current node between the delete and the check below
+	unlock, err := database.Lock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer unlock()
+
+	var resp deleteNodeResponse
+	if keepChildren {
+		resp.Reattached, err = database.DeleteNodeKeepChildren(nodeID)
+		resp.Deleted = 1
+	} else {
+		resp.Deleted, err = database.DeleteNodeAndAllChildren(nodeID)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete node: %v", err), http.StatusInternalServerError)
+		log.Printf("Error deleting node %s: %v", nodeID, err)
+		return
+	}
+	pruned = true
+
+	// Move the current node off anything that was deleted, like 'bai prune' does
+	currentNodeID, err := database.GetCurrentNode()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Deleted node but failed to get current node: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if currentNodeID != nil {
+		if _, err := database.GetNodeByID(*currentNodeID); err != nil {
+			if keepChildren && node.Parent != nil {
+				err = database.SetCurrentNode(*node.Parent)
+				currentNodeID = node.Parent
+			} else {
+				err = database.ClearCurrentNode()
+				currentNodeID = nil
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Deleted node but failed to update current node: %v", err), http.StatusInternalServerError)
+				return
+			}
+			resp.CurrentChanged = true
+		}
+	}
+	resp.CurrentNode = currentNodeID
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGetCurrent serves the current working node ID
+func (s *Server) handleGetCurrent(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	currentNodeID, err := database.GetCurrentNode()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get current node: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, currentNodeResponse{CurrentNode: currentNodeID})
+}
+
+// handleSetCurrent checks out a node, making it the current working node
+func (s *Server) handleSetCurrent(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	var req setCurrentRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	nodeID, ok := database.resolveNodeID(w, req.ID)
+	if !ok {
+		return
+	}
+
+	if err := database.SetCurrentNode(nodeID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set current node: %v", err), http.StatusInternalServerError)
+		log.Printf("Error setting current node: %v", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, currentNodeResponse{CurrentNode: &nodeID})
+}
+
+// handlePath serves the chain of nodes from the root down to a node, so the active branch can be shown as a transcript
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	history, err := database.GetConversationHistory(nodeID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get conversation history: %v", err), http.StatusInternalServerError)
+		log.Printf("Error getting conversation history for %s: %v", nodeID, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+// writable wraps a handler that changes the tree, rejecting its requests when the server is read-only
+func (s *Server) writable(handler http.HandlerFunc) http.HandlerFunc {
+	if !s.opts.ReadOnly {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "The server is read-only", http.StatusForbidden)
+	}
+}
+
+// resolveNodeID expands a full or abbreviated node ID, writing a 404 response if it doesn't match a single node
+func (database *servedDatabase) resolveNodeID(w http.ResponseWriter, idOrPrefix string) (string, bool) {
+	nodeID, err := database.ResolveNodeID(idOrPrefix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return "", false
+	}
+	return nodeID, true
+}
+
+// decodeJSON decodes a JSON request body into v, writing an error response and returning false on failure
+// Requiring a JSON content type also stops other web pages from submitting cross-site form posts.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	return decodeJSONBody(w, r, v, maxRequestBodyBytes)
+}
+
+// decodeJSONBody is decodeJSON for bodies of up to limit bytes
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}, limit int64) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return false
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	}
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding JSON: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// tokenCookieName is the cookie the browser keeps the access token in after opening a tokenized link
+const tokenCookieName = "bonsai_token"
+
+// GenerateToken returns a random access token suitable for Options.Token
+func GenerateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// IsLoopbackHost reports whether host only accepts connections from this machine
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken rejects requests that don't present the server's access token, when one is set
+// The token is accepted as a bearer token, from the cookie, or as a ?token= query parameter. The query
+// parameter sets the cookie and redirects to the same page without it, so links can be shared once and
+// the page's own requests, including the event stream, authenticate with the cookie from then on.
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Tools logging transcripts authenticate with the narrower ingest token instead
+		if isIngestRequest(r) && validIngestToken(s.database(), r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token := r.URL.Query().Get("token"); token != "" && s.validToken(token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookieName,
+				Value:    token,
+				Path:     s.externalBasePath(r) + "/",
+				HttpOnly: true,
+				Secure:   s.isSecureRequest(r),
+				SameSite: http.SameSiteLaxMode,
+			})
+			if r.Method == http.MethodGet {
+				query := r.URL.Query()
+				query.Del("token")
+				redirect := url.URL{Path: redirectPath(s.externalBasePath(r) + r.URL.Path), RawQuery: query.Encode()}
+				http.Redirect(w, r, redirect.String(), http.StatusSeeOther)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && s.validToken(token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie(tokenCookieName); err == nil && s.validToken(cookie.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="bonsai"`)
+		http.Error(w, "Unauthorized: open the link printed by 'bai visualize' or send an Authorization: Bearer header", http.StatusUnauthorized)
+	})
+}
+
+// redirectPath cleans a path to redirect to so it stays on this server: a path starting "//" would
+// otherwise be followed by the browser to another host. A trailing slash is kept.
+func redirectPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// validToken compares a presented token with the server's in constant time
+func (s *Server) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
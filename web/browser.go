@@ -0,0 +1,32 @@
+package web
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens url in the user's default browser
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	// Don't leave a zombie behind once the launcher exits
+	go cmd.Wait()
+	return nil
+}
+
+// NodePage returns the permalink page that opens the visualization focused on a node
+func NodePage(nodeID string) string {
+	return "node/" + nodeID
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipWriters reuses gzip writers between responses, since each holds sizable compression buffers
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+		return gz
+	},
+}
+
+// gzipped wraps a handler whose responses can be large, compressing them for clients that accept gzip
+func gzipped(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			handler(w, r)
+			return
+		}
+
+		gz := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(gz)
+		compressed := &gzipResponseWriter{ResponseWriter: w, gz: gz}
+		defer compressed.close()
+		handler(compressed, r)
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows a gzip response
+func acceptsGzip(r *http.Request) bool {
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses a response's body, leaving responses without one, such as 304s, alone
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+	compressing bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	if status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified {
+		g.Header().Set("Content-Encoding", "gzip")
+		g.Header().Del("Content-Length")
+		g.gz.Reset(g.ResponseWriter)
+		g.compressing = true
+	}
+	g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if !g.compressing {
+		return g.ResponseWriter.Write(b)
+	}
+	return g.gz.Write(b)
+}
+
+// close flushes the end of the compressed body
+func (g *gzipResponseWriter) close() {
+	if g.compressing {
+		if err := g.gz.Close(); err != nil {
+			log.Printf("Error compressing response: %v", err)
+		}
+	}
+}
+
+// streamJSONArray writes a JSON array whose elements each passes to emit, encoding each as it comes
+// so large responses aren't built in memory first. If each fails before emitting anything, the
+// client gets a 500 naming what couldn't be fetched; after that the response has started, so the
+// error can only be logged and the array is left unterminated.
+func streamJSONArray(w http.ResponseWriter, what string, each func(emit func(v interface{}) error) error) {
+	encoder := json.NewEncoder(w)
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", "application/json")
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		}
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte("["))
+		return err
+	}
+
+	err := each(func(v interface{}) error {
+		separator := []byte(",")
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+			separator = nil
+		}
+		if _, err := w.Write(separator); err != nil {
+			return err
+		}
+		return encoder.Encode(v)
+	})
+	if err != nil {
+		if !started {
+			http.Error(w, fmt.Sprintf("Failed to fetch %s: %v", what, err), http.StatusInternalServerError)
+		}
+		log.Printf("Error fetching %s: %v", what, err)
+		return
+	}
+
+	if !started && start() != nil {
+		return
+	}
+	if _, err := w.Write([]byte("]\n")); err != nil {
+		log.Printf("Error writing %s: %v", what, err)
+	}
+}
@@ -0,0 +1,74 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CORSOriginsConfigKey is the 'bai config' setting holding the origins allowed to call the API from other sites
+const CORSOriginsConfigKey = "web.cors_origins"
+
+// ParseOrigins splits a comma-separated list of CORS origins, dropping blanks and trailing slashes
+func ParseOrigins(list string) []string {
+	var origins []string
+	for _, origin := range strings.Split(list, ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// ValidateOrigin checks that origin is "*" or a bare origin such as http://localhost:5173 or chrome-extension://<id>
+func ValidateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid origin %q: must be \"*\" or a scheme and host such as http://localhost:5173", origin)
+	}
+	return nil
+}
+
+// allowCORS lets the configured origins call the API from other sites, answering preflight requests itself
+// Preflight requests never carry credentials, so this has to run before requireToken. The origins are
+// looked up on every request, since switching databases can change the web.cors_origins setting.
+func (s *Server) allowCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origins := s.allowedOrigins()
+		if len(origins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !originAllowed(origins, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed reports whether origin is in the allowed list, or the list allows any origin with "*"
+func originAllowed(allowed []string, origin string) bool {
+	for _, o := range allowed {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
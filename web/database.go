@@ -0,0 +1,196 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+	"github.com/aarose/bonsai/pkg/hooks"
+)
+
+// servedDatabase is a database the server serves, with the session generations run in, its hooks and
+// the requests still using it
+type servedDatabase struct {
+	*db.Database
+	session  *bonsai.Session
+	hooks    *hooks.Dispatcher
+	inflight sync.WaitGroup // Requests that started while this was the current database
+}
+
+// newServedDatabase wraps a database in a session created by newSession, or bonsai.NewSession with
+// the response cache if it's turned on when newSession is nil. Its hooks log failures.
+func newServedDatabase(database *db.Database, newSession func(*db.Database) *bonsai.Session) *servedDatabase {
+	var session *bonsai.Session
+	if newSession != nil {
+		session = newSession(database)
+	} else {
+		session = bonsai.NewSession(database)
+		if enabled, err := database.ResponseCacheEnabled(); err == nil && enabled {
+			session.UseCache(true)
+		}
+	}
+	session.Hooks().OnError = func(event hooks.Event, err error) {
+		log.Printf("Error running hook: %v", err)
+	}
+	return &servedDatabase{Database: database, session: session, hooks: session.Hooks()}
+}
+
+// databaseResponse is returned by GET and PUT /api/db
+type databaseResponse struct {
+	Path string `json:"path"`
+}
+
+// switchDatabaseRequest is the body of PUT /api/db
+type switchDatabaseRequest struct {
+	Path   string `json:"path"`             // Relative to the directory of the database the server started with
+	Create bool   `json:"create,omitempty"` // Create the database if it doesn't exist, instead of failing
+}
+
+// errOutsideDatabaseDir is returned for database paths outside the directory PUT /api/db may open files in
+var errOutsideDatabaseDir = errors.New("database must be in the same directory as the one the server started with")
+
+// database returns the database the server is currently serving
+// Handlers call it once and use the result throughout, so a switch partway through can't split a request
+// across two databases.
+func (s *Server) database() *servedDatabase {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// trackDatabase counts each request against the database current when it arrives, so a switch waits for
+// it to finish before closing that database
+func (s *Server) trackDatabase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		database := s.current
+		database.inflight.Add(1)
+		s.mu.RUnlock()
+		defer database.inflight.Done()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SwitchDatabase makes the server serve the database at path, creating it if it doesn't exist. Open event
+// streams are told about the switch and then ended, so clients reconnect to the new database. The previous
+// database is closed in the background once the requests that started on it have finished. Switching to
+// the path already being served reopens it, rereading its settings.
+func (s *Server) SwitchDatabase(path string) error {
+	database, err := db.NewDatabase(path)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := database.Initialize(); err != nil {
+		database.Close()
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	var origins []string
+	if s.corsFromConfig {
+		if origins, err = readCORSOrigins(database); err != nil {
+			database.Close()
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	previous, previousRetired := s.current, s.retired
+	retired := make(chan struct{})
+	s.current = newServedDatabase(database, s.opts.NewSession)
+	s.retired = retired
+	if s.corsFromConfig {
+		s.corsOrigins = origins
+	}
+	close(s.switched)
+	s.switched = make(chan struct{})
+	s.mu.Unlock()
+
+	// A request counted against an older database may have picked up the previous one after a switch,
+	// so databases are closed in the order they were switched away from
+	go func() {
+		defer close(retired)
+		<-previousRetired
+		previous.inflight.Wait()
+		if err := previous.Close(); err != nil {
+			log.Printf("Error closing %s: %v", previous.GetPath(), err)
+		}
+	}()
+
+	log.Printf("Switched to database %s", path)
+	return nil
+}
+
+// close closes the current database once every database switched away from has been closed
+func (s *Server) close() {
+	s.mu.RLock()
+	current, retired := s.current, s.retired
+	s.mu.RUnlock()
+
+	<-retired
+	if err := current.Close(); err != nil {
+		log.Printf("Error closing %s: %v", current.GetPath(), err)
+	}
+}
+
+// resolveDatabasePath turns a path given to PUT /api/db into an absolute path, refusing any outside the
+// directory of the database the server started with, so clients can't open or create files elsewhere
+func (s *Server) resolveDatabasePath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.databaseDir, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(s.databaseDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errOutsideDatabaseDir
+	}
+	return path, nil
+}
+
+// handleGetDatabase serves the path of the database being served
+func (s *Server) handleGetDatabase(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, databaseResponse{Path: s.database().GetPath()})
+}
+
+// handleSwitchDatabase switches the server to another database file in the same directory without restarting it
+func (s *Server) handleSwitchDatabase(w http.ResponseWriter, r *http.Request) {
+	var req switchDatabaseRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	path, err := s.resolveDatabasePath(req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err) && !req.Create:
+		// A mistyped path would otherwise quietly start an empty garden
+		http.Error(w, fmt.Sprintf("no database at %s (set create to start a new one)", path), http.StatusNotFound)
+		return
+	case err == nil && info.IsDir():
+		http.Error(w, fmt.Sprintf("%s is a directory", path), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.SwitchDatabase(path); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to switch database: %v", err), http.StatusBadRequest)
+		log.Printf("Error switching database: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, databaseResponse{Path: path})
+}
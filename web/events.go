@@ -0,0 +1,79 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// eventKeepAliveInterval is how often an idle event stream sends a comment to keep proxies from closing it
+const eventKeepAliveInterval = 30 * time.Second
+
+// Event is a change to the conversation tree pushed to /api/events subscribers
+type Event = db.Change
+
+// handleEvents streams tree change events to the client using Server-Sent Events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	// Capture the database and its switch signal together, so a switch in between isn't missed
+	s.mu.RLock()
+	database, switched := s.current, s.switched
+	s.mu.RUnlock()
+
+	events, unsubscribe, err := database.Subscribe()
+	if err != nil {
+		log.Printf("Error watching for changes: %v", err)
+		http.Error(w, "failed to watch for changes", http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	controller := http.NewResponseController(w)
+
+	// Event streams stay open far longer than the server's default write timeout
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Error clearing write deadline: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		log.Printf("Error starting event stream: %v", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-switched:
+			// Tell the client to reload everything; it reconnects to the new database on its own
+			fmt.Fprint(w, "event: database-switched\ndata: {}\n\n")
+			controller.Flush()
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding event: %v", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aarose/bonsai/pkg/bonsai"
+)
+
+// exportFormat is a format GET /api/export/{id} can download a node in
+type exportFormat struct {
+	extension   string
+	contentType string
+	write       func(session *bonsai.Session, w io.Writer, nodeID string) error
+}
+
+// exportFormats are the formats of GET /api/export/{id}, as the Go library's exporters write them
+var exportFormats = map[string]exportFormat{
+	// The branch from the root down to the node, as a transcript
+	"markdown": {extension: "md", contentType: "text/markdown; charset=utf-8", write: (*bonsai.Session).ExportMarkdown},
+	// The node and everything below it
+	"json": {extension: "json", contentType: "application/json", write: (*bonsai.Session).Export},
+	"dot":  {extension: "dot", contentType: "text/vnd.graphviz; charset=utf-8", write: (*bonsai.Session).ExportDot},
+}
+
+// handleExport downloads a node's branch as Markdown, or its subtree as JSON or a Graphviz graph
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "json"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		http.Error(w, "format must be markdown, json or dot", http.StatusBadRequest)
+		return
+	}
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	// Exported into a buffer first, so a failure partway is still reported as an error
+	var out bytes.Buffer
+	if err := format.write(bonsai.NewSession(database.Database), &out, nodeID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to export: %v", err), http.StatusInternalServerError)
+		log.Printf("Error exporting %s as %s: %v", nodeID, name, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="bonsai-%s.%s"`, nodeID[:8], format.extension))
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write(out.Bytes())
+}
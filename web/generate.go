@@ -0,0 +1,170 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/llm"
+)
+
+// generateRequest is the body of POST /api/generate
+type generateRequest struct {
+	Parent string `json:"parent"`
+	Model  string `json:"model,omitempty"`  // Defaults to the parent node's model
+	Stream bool   `json:"stream,omitempty"` // Stream the response as Server-Sent Events
+}
+
+// generateChunk is the data of a "chunk" event in a streamed generation
+type generateChunk struct {
+	Text string `json:"text"`
+}
+
+// generateError is the data of an "error" event in a streamed generation
+type generateError struct {
+	Error string `json:"error"`
+}
+
+// generateWarning is the data of a "warning" event in a streamed generation
+type generateWarning struct {
+	Warning string `json:"warning"`
+}
+
+// handleGenerate asks the model to respond to the conversation ending at the parent node and
+// stores the answer as a new child of it, which becomes the current working node
+// With "stream": true the response is sent as Server-Sent Events: a "warning" event for each way the
+// request goes beyond the model's limits, a "chunk" event for each piece of text as it's generated,
+// then a "done" event with the new node or an "error" event.
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	var req generateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	parentID, ok := database.resolveNodeID(w, req.Parent)
+	if !ok {
+		return
+	}
+	parent, err := database.session.Node(parentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Inherit the model from the nearest ancestor, or the tree's default, like the CLI does
+	model := req.Model
+	if model == "" {
+		if model, err = database.session.InheritedModel(parent); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if model == "" {
+		http.Error(w, "model is required: neither the branch nor its tree has a model", http.StatusBadRequest)
+		return
+	}
+
+	warnings, err := database.session.CheckGeneration(parent, model)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, warning := range warnings {
+		log.Printf("Warning generating a response to %s: %s", parentID, warning)
+	}
+
+	// Generation, bounded by --timeout or the generate.timeout setting like the CLI's, outlasts the
+	// server's default write timeout
+	timeout := database.session.Timeout()
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second)); err != nil {
+		log.Printf("Error extending write deadline: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	if req.Stream {
+		s.streamGeneration(ctx, w, database, parent, model, warnings)
+		return
+	}
+
+	node, err := database.respond(ctx, parent, model)
+	if err != nil {
+		http.Error(w, err.Error(), generateErrorStatus(err))
+		log.Printf("Error generating response for %s: %v", parentID, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, node)
+}
+
+// streamGeneration generates a response as Server-Sent Events, storing the complete answer once it's
+// done. Until the first event is sent, failures are answered with an HTTP error status instead.
+func (s *Server) streamGeneration(ctx context.Context, w http.ResponseWriter, database *servedDatabase, parent *db.Node, model string, warnings []string) {
+	controller := http.NewResponseController(w)
+
+	started := false
+	send := func(event string, v interface{}) error {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	for _, warning := range warnings {
+		send("warning", generateWarning{Warning: warning})
+	}
+
+	ctx = llm.WithStreamHandler(ctx, func(chunk string) error {
+		return send("chunk", generateChunk{Text: chunk})
+	})
+	node, err := database.respond(ctx, parent, model)
+	if err != nil {
+		log.Printf("Error generating response for %s: %v", parent.ID, err)
+		if !started {
+			http.Error(w, err.Error(), generateErrorStatus(err))
+			return
+		}
+		send("error", generateError{Error: err.Error()})
+		return
+	}
+	send("done", node)
+}
+
+// respond stores the model's reply to the parent node through the database's session, making it the
+// current working node
+func (database *servedDatabase) respond(ctx context.Context, parent *db.Node, model string) (*db.Node, error) {
+	node, err := database.session.Respond(ctx, parent, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.SetCurrentNode(node.ID); err != nil {
+		log.Printf("Error setting current node to %s: %v", node.ID, err)
+	}
+	return node, nil
+}
+
+// generateErrorStatus returns the HTTP status for a failed generation: a bad request for a model no
+// provider is known to serve, otherwise a bad gateway
+func generateErrorStatus(err error) int {
+	var unknown *llm.UnknownModelError
+	if errors.As(err, &unknown) {
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
+)
+
+// IngestTokenConfigKey is the 'bai config' setting holding the token tools present to POST /api/ingest
+const IngestTokenConfigKey = "ingest.token"
+
+// maxIngestBodyBytes caps the size of transcripts sent to POST /api/ingest, which can be much longer than other requests
+const maxIngestBodyBytes = 16 << 20
+
+// ingestRequest is the body of POST /api/ingest
+type ingestRequest struct {
+	// Messages in OpenAI's chat format; fields other than role and content, such as tool_calls, are ignored
+	Messages []json.RawMessage `json:"messages"`
+	Model    string            `json:"model,omitempty"`  // Model the assistant messages came from
+	Parent   string            `json:"parent,omitempty"` // Node to add the transcript below; omit to add it as a tree of its own
+	Source   string            `json:"source,omitempty"` // Name of the tool sending the transcript, e.g. vscode (default "api")
+}
+
+// ingestResponse is returned by POST /api/ingest
+type ingestResponse struct {
+	Root    string     `json:"root"` // Root of the tree holding the transcript
+	Last    string     `json:"last"` // Node holding the last message, to continue from
+	Created []*db.Node `json:"created"`
+}
+
+// handleIngest stores a chat transcript sent by another tool as a branch of nodes
+func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	if !s.ingestAuthorized(database, w, r) {
+		return
+	}
+
+	var req ingestRequest
+	if !decodeJSONBody(w, r, &req, maxIngestBodyBytes) {
+		return
+	}
+	if len(req.Messages) == 0 {
+		http.Error(w, "messages is required", http.StatusBadRequest)
+		return
+	}
+	messages := make([]bonsai.TranscriptMessage, len(req.Messages))
+	for i, raw := range req.Messages {
+		if err := json.Unmarshal(raw, &messages[i]); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid message %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Parent != "" {
+		parentID, ok := database.resolveNodeID(w, req.Parent)
+		if !ok {
+			return
+		}
+		req.Parent = parentID
+	}
+
+	session := bonsai.NewSession(database.Database)
+	session.Hooks().OnError = database.hooks.OnError
+	result, err := session.IngestTranscript(messages, bonsai.IngestOptions{Parent: req.Parent, Model: req.Model, Source: req.Source})
+	if err != nil {
+		status := contentErrorStatus(err)
+		if errors.Is(err, bonsai.ErrInvalidTranscript) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, fmt.Sprintf("Failed to ingest transcript: %v", err), status)
+		log.Printf("Error ingesting transcript: %v", err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(result.Created) > 0 {
+		status = http.StatusCreated
+	}
+	if result.Created == nil {
+		result.Created = []*db.Node{}
+	}
+	writeJSON(w, status, ingestResponse{Root: result.RootID, Last: result.Last.ID, Created: result.Created})
+}
+
+// ingestAuthorized checks that an ingest request is authenticated, writing an error response if it
+// isn't. Tools present the ingest.token setting as a bearer token, which is good for nothing but
+// ingestion, or the server's access token. With neither token set, ingestion is refused, so a server
+// open to this machine can't be written to by any local program that finds it.
+func (s *Server) ingestAuthorized(database *servedDatabase, w http.ResponseWriter, r *http.Request) bool {
+	if validIngestToken(database, r) {
+		return true
+	}
+	if s.opts.Token != "" {
+		// requireToken let the request through, so it presented the access token
+		return true
+	}
+
+	if token, err := database.GetConfigValue(IngestTokenConfigKey); err == nil && token != nil && *token != "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="bonsai"`)
+		http.Error(w, "Unauthorized: send the ingest.token setting in an Authorization: Bearer header", http.StatusUnauthorized)
+		return false
+	}
+	http.Error(w, "Ingestion needs a token: set one with 'bai config set ingest.token <token>', or start the server with --token", http.StatusForbidden)
+	return false
+}
+
+// isIngestRequest reports whether a request is for POST /api/ingest, under either API prefix
+func isIngestRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && (r.URL.Path == apiPrefix+"/ingest" || r.URL.Path == legacyAPIPrefix+"/ingest")
+}
+
+// validIngestToken reports whether a request presents the database's ingest.token setting as a bearer token
+func validIngestToken(database *servedDatabase, r *http.Request) bool {
+	presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || presented == "" {
+		return false
+	}
+	token, err := database.GetConfigValue(IngestTokenConfigKey)
+	if err != nil || token == nil || *token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(*token)) == 1
+}
@@ -0,0 +1,254 @@
+package web
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// apiVersion is the version reported in the OpenAPI document; it changes only with incompatible API changes
+const apiVersion = "1"
+
+// pathParamPattern matches the {name} wildcards in a route path
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// handleOpenAPI serves an OpenAPI 3 description of the API, generated from the route table
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPIDocument(s.externalBasePath(r)+apiPrefix))
+}
+
+// openAPIDocument describes every route in apiRoutes, with schemas derived from their Go request and response types
+func (s *Server) openAPIDocument(serverURL string) map[string]interface{} {
+	schemas := &schemaBuilder{components: make(map[string]interface{})}
+	paths := make(map[string]interface{})
+
+	for _, rt := range s.apiRoutes() {
+		operation := map[string]interface{}{
+			"operationId": operationID(rt.method, rt.path),
+			"summary":     rt.summary,
+			"responses":   routeResponses(rt, schemas),
+		}
+
+		var parameters []interface{}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(rt.path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        match[1],
+				"in":          "path",
+				"required":    true,
+				"description": "Node ID, which may be abbreviated",
+				"schema":      map[string]interface{}{"type": "string"},
+			})
+		}
+		for _, param := range rt.query {
+			parameters = append(parameters, map[string]interface{}{
+				"name":        param.name,
+				"in":          "query",
+				"required":    param.required,
+				"description": param.description,
+				"schema":      map[string]interface{}{"type": param.typ},
+			})
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		if rt.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schema(reflect.TypeOf(rt.request))},
+				},
+			}
+		}
+
+		item, ok := paths[rt.path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = operation
+	}
+
+	components := map[string]interface{}{"schemas": schemas.components}
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Bonsai API",
+			"description": "Read and grow bonsai conversation trees.",
+			"version":     apiVersion,
+		},
+		"servers":    []interface{}{map[string]interface{}{"url": serverURL}},
+		"paths":      paths,
+		"components": components,
+	}
+	if s.opts.Token != "" {
+		components["securitySchemes"] = map[string]interface{}{
+			"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+		}
+		doc["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+	}
+	return doc
+}
+
+// routeResponses describes a route's successful responses, plus the plain text body every error has
+func routeResponses(rt route, schemas *schemaBuilder) map[string]interface{} {
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	responses := map[string]interface{}{
+		"default": map[string]interface{}{
+			"description": "Error",
+			"content": map[string]interface{}{
+				"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+
+	addContent := func(status int, mediaType string, schema interface{}) {
+		key := strconv.Itoa(status)
+		response, ok := responses[key].(map[string]interface{})
+		if !ok {
+			response = map[string]interface{}{"description": http.StatusText(status), "content": map[string]interface{}{}}
+			responses[key] = response
+		}
+		response["content"].(map[string]interface{})[mediaType] = map[string]interface{}{"schema": schema}
+	}
+
+	if rt.response != nil {
+		addContent(status, "application/json", schemas.schema(reflect.TypeOf(rt.response)))
+	}
+	if rt.media != "" {
+		addContent(status, rt.media, map[string]interface{}{"type": "string"})
+	}
+	if rt.events {
+		addContent(http.StatusOK, "text/event-stream", map[string]interface{}{"type": "string"})
+	}
+	return responses
+}
+
+// operationID derives an operation ID such as getNodesByIdChildren from a route's method and path
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if match := pathParamPattern.FindStringSubmatch(segment); match != nil {
+			id += "By" + exportedName(match[1])
+		} else {
+			// File names such as snapshot.svg become SnapshotSvg
+			for _, part := range strings.Split(segment, ".") {
+				id += exportedName(part)
+			}
+		}
+	}
+	return id
+}
+
+// exportedName capitalizes the first letter of a name
+func exportedName(name string) string {
+	runes := []rune(name)
+	if len(runes) == 0 {
+		return name
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// schemaBuilder converts Go types to OpenAPI schemas, collecting named struct types as shared components
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+// schema returns the schema for a type, referring to named structs by component
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schema(t.Elem())
+		if t.Elem().Kind() != reflect.Struct {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := exportedName(t.Name())
+		if _, ok := b.components[name]; !ok {
+			b.components[name] = nil // Reserve the name first in case the type refers to itself
+			b.components[name] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema describes a struct's JSON fields, flattening embedded structs the way encoding/json does
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	// Fields of embedded pointers are all left out when the pointer is nil, so none of them are required
+	var addFields func(t reflect.Type, optional bool)
+	addFields = func(t reflect.Type, optional bool) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+
+			fieldType := field.Type
+			if field.Anonymous && name == "" {
+				embeddedOptional := optional
+				if fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+					embeddedOptional = true
+				}
+				if fieldType.Kind() == reflect.Struct {
+					addFields(fieldType, embeddedOptional)
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			properties[name] = b.schema(fieldType)
+			if !optional && !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t, false)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
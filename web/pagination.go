@@ -0,0 +1,240 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+const (
+	// defaultPageSize is the number of nodes returned by paginated endpoints when no limit is given
+	defaultPageSize = 100
+
+	// maxPageSize is the largest limit accepted by paginated endpoints
+	maxPageSize = 1000
+
+	// maxTitleLength is the longest tree title returned by /api/roots, in characters
+	maxTitleLength = 80
+)
+
+// pagedNode is a node in a paginated listing, with its child count so clients can load children lazily
+type pagedNode struct {
+	*db.Node
+	ChildCount int `json:"child_count"`
+}
+
+// nodePage is a page of nodes returned by the paginated endpoints
+type nodePage struct {
+	Nodes      []*pagedNode `json:"nodes"`
+	NextCursor string       `json:"next_cursor,omitempty"` // Pass as ?cursor= to get the next page; omitted on the last page
+}
+
+// treeSummary is a root node in the /api/roots listing, describing its whole tree for a tree switcher
+type treeSummary struct {
+	pagedNode
+	Title        string `json:"title"`
+	NodeCount    int    `json:"node_count"`
+	MaxDepth     int    `json:"max_depth"`
+	Branches     int    `json:"branches"`
+	LastActivity int64  `json:"last_activity"` // Unix seconds of the newest node, 0 if no node has a timestamp
+}
+
+// treePage is a page of trees returned by /api/roots
+type treePage struct {
+	Trees      []*treeSummary `json:"nodes"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Pass as ?cursor= to get the next page; omitted on the last page
+}
+
+// handleRoots serves a page of root nodes, each with its tree's title, size, shape and last activity
+func (s *Server) handleRoots(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	cursor, limit, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra node to find out whether there's another page
+	nodes, err := database.GetRootNodesPage(cursor, limit+1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch root nodes: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching root nodes: %v", err)
+		return
+	}
+
+	page := treePage{Trees: []*treeSummary{}}
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+		page.NextCursor = nodes[len(nodes)-1].ID
+	}
+
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	counts, err := database.CountChildren(ids)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count child nodes: %v", err), http.StatusInternalServerError)
+		log.Printf("Error counting child nodes: %v", err)
+		return
+	}
+	activity, err := database.GetTreeActivityForRoots(ids)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get tree activity: %v", err), http.StatusInternalServerError)
+		log.Printf("Error getting tree activity: %v", err)
+		return
+	}
+
+	for _, node := range nodes {
+		tree := &treeSummary{
+			pagedNode: pagedNode{Node: node, ChildCount: counts[node.ID]},
+			Title:     treeTitle(node.Content),
+		}
+		if a, ok := activity[node.ID]; ok {
+			tree.NodeCount = a.NodeCount
+			tree.MaxDepth = a.MaxDepth
+			tree.Branches = a.Branches
+			tree.LastActivity = a.LastActivity
+		}
+		page.Trees = append(page.Trees, tree)
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// treeTitle derives a tree's title from its root message: the first non-blank line, shortened to maxTitleLength
+func treeTitle(content string) string {
+	title := strings.TrimSpace(content)
+	if line, _, found := strings.Cut(title, "\n"); found {
+		title = strings.TrimSpace(line)
+	}
+
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength-3]) + "..."
+	}
+	return title
+}
+
+// handleChildren serves a page of a node's direct children
+func (s *Server) handleChildren(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra node to find out whether there's another page
+	nodes, err := database.GetChildrenPage(nodeID, cursor, limit+1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch child nodes: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching children of %s: %v", nodeID, err)
+		return
+	}
+
+	writeNodePage(w, database, nodes, limit)
+}
+
+// handleSubtree serves a single tree, or the subtree below any node, in the same format as /api/tree
+func (s *Server) handleSubtree(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	format, ok := treeFormat(w, r)
+	if !ok {
+		return
+	}
+	asOf, ok := treeAsOf(w, r)
+	if !ok {
+		return
+	}
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if treeUnchanged(w, r, database) {
+		return
+	}
+
+	var nodes []*db.Node
+	var err error
+	if asOf.IsZero() {
+		nodes, err = database.GetNodeAndAllChildren(nodeID)
+	} else {
+		nodes, err = database.GetTreeAsOf(nodeID, asOf)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching subtree of %s: %v", nodeID, err)
+		return
+	}
+	if len(nodes) == 0 {
+		http.Error(w, fmt.Sprintf("Node %s didn't exist yet at %s", nodeID, asOf.UTC().Format(time.RFC3339)), http.StatusNotFound)
+		return
+	}
+
+	marks, err := loadTreeMarks(database)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching subtree of %s: %v", nodeID, err)
+		return
+	}
+
+	if format == treeFormatNested {
+		writeJSON(w, http.StatusOK, nestTree(nodes, marks))
+		return
+	}
+	marked := make([]*SubtreeNode, len(nodes))
+	for i, node := range nodes {
+		marked[i] = &SubtreeNode{Node: node, NodeMarks: marks.of(node)}
+	}
+	writeJSON(w, http.StatusOK, marked)
+}
+
+// writeNodePage writes up to limit nodes with their child counts, plus a cursor if more nodes were fetched
+func writeNodePage(w http.ResponseWriter, database *servedDatabase, nodes []*db.Node, limit int) {
+	page := nodePage{Nodes: []*pagedNode{}}
+	if len(nodes) > limit {
+		nodes = nodes[:limit]
+		page.NextCursor = nodes[len(nodes)-1].ID
+	}
+
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	counts, err := database.CountChildren(ids)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count child nodes: %v", err), http.StatusInternalServerError)
+		log.Printf("Error counting child nodes: %v", err)
+		return
+	}
+
+	for _, node := range nodes {
+		page.Nodes = append(page.Nodes, &pagedNode{Node: node, ChildCount: counts[node.ID]})
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parsePagination reads the cursor and limit query parameters, writing a 400 response if they're invalid
+func parsePagination(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	limit := defaultPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			http.Error(w, fmt.Sprintf("limit must be a number between 1 and %d", maxPageSize), http.StatusBadRequest)
+			return "", 0, false
+		}
+		limit = parsed
+	}
+
+	return r.URL.Query().Get("cursor"), limit, true
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+// normalizeBasePath cleans up a base path so it's either empty or starts with a slash and has no trailing slash
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// externalBasePath returns the path prefix the browser sees the app under
+// A trusted reverse proxy that strips its own prefix before forwarding can report it with X-Forwarded-Prefix.
+func (s *Server) externalBasePath(r *http.Request) string {
+	if s.opts.TrustProxy {
+		if prefix := r.Header.Get("X-Forwarded-Prefix"); strings.HasPrefix(prefix, "/") {
+			return normalizeBasePath(prefix)
+		}
+	}
+	return s.opts.BasePath
+}
+
+// isSecureRequest reports whether the browser reached the server over HTTPS, either directly or through a trusted proxy
+func (s *Server) isSecureRequest(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return s.opts.TrustProxy && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// mountAtBasePath serves handler under the configured base path, for proxies that forward the full path
+func (s *Server) mountAtBasePath(handler http.Handler) http.Handler {
+	if s.opts.BasePath == "" {
+		return handler
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(s.opts.BasePath+"/", http.StripPrefix(s.opts.BasePath, handler))
+	mux.Handle(s.opts.BasePath, http.RedirectHandler(s.opts.BasePath+"/", http.StatusMovedPermanently))
+	return mux
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/aarose/bonsai/db"
+)
+
+const (
+	// apiPrefix is where the current version of the API is served
+	apiPrefix = "/api/v1"
+
+	// legacyAPIPrefix serves the same routes unversioned, for clients written before /api/v1
+	legacyAPIPrefix = "/api"
+)
+
+// route is an API endpoint, described well enough to generate its OpenAPI operation
+type route struct {
+	method   string
+	path     string // Relative to the API prefix, e.g. "/nodes/{id}"
+	summary  string
+	handler  http.HandlerFunc
+	query    []queryParam
+	request  interface{} // Example of the JSON request body type; nil if the route takes no body
+	response interface{} // Example of the JSON response body type; nil if the route has none
+	media    string      // Media type of a successful response that isn't JSON, such as an image
+	status   int         // Status of a successful JSON response; defaults to 200
+	events   bool        // The route can respond with a Server-Sent Events stream
+}
+
+// queryParam is a query string parameter accepted by a route
+type queryParam struct {
+	name        string
+	description string
+	typ         string // OpenAPI type: "string", "integer" or "boolean"
+	required    bool
+}
+
+// paginationParams are the query parameters accepted by every paginated route
+var paginationParams = []queryParam{
+	{name: "cursor", description: "next_cursor from the previous page", typ: "string"},
+	{name: "limit", description: "Nodes per page, 1 to 1000 (default 100)", typ: "integer"},
+}
+
+// apiRoutes lists every API endpoint, in the order they're documented
+func (s *Server) apiRoutes() []route {
+	return []route{
+		{method: "GET", path: "/health", summary: "Server status", handler: s.handleHealth, response: healthResponse{}},
+
+		// Whole trees and paginated access for large databases
+		{method: "GET", path: "/tree", summary: "All nodes", handler: gzipped(s.handleTreeData), query: []queryParam{treeFormatParam, treeAsOfParam}, response: []*TreeNode{}},
+		{method: "GET", path: "/tree/{id}", summary: "One tree, or the subtree below any node", handler: gzipped(s.handleSubtree), query: []queryParam{treeFormatParam, treeAsOfParam}, response: []*SubtreeNode{}},
+		{method: "GET", path: "/tree/{id}/snapshot.svg", summary: "An SVG image of one tree, or the subtree below any node", handler: gzipped(s.handleSnapshot), media: "image/svg+xml"},
+		{method: "GET", path: "/roots", summary: "Root nodes with their trees' titles, sizes and activity, paginated", handler: s.handleRoots, query: paginationParams, response: treePage{}},
+		{method: "GET", path: "/nodes/{id}/children", summary: "A node's direct children, paginated", handler: s.handleChildren, query: paginationParams, response: nodePage{}},
+		{method: "GET", path: "/timeline", summary: "Nodes across every tree in the order they were created, paginated, with how many were created each day", handler: gzipped(s.handleTimeline), query: timelineParams, response: timelinePage{}},
+
+		{method: "GET", path: "/export/{id}", summary: "Download a node's branch as a Markdown transcript, or its subtree as JSON or a Graphviz graph", handler: gzipped(s.handleExport), query: []queryParam{
+			{name: "format", description: "markdown for the branch from the root down to the node, or json (default) or dot for the node and everything below it", typ: "string"},
+		}, media: "application/octet-stream"},
+
+		// Reading and modifying individual nodes
+		{method: "POST", path: "/nodes", summary: "Create a node; omit parent to start a new tree", handler: s.writable(s.handleCreateNode), request: createNodeRequest{}, response: &db.Node{}, status: http.StatusCreated},
+		{method: "GET", path: "/nodes/{id}", summary: "A single node", handler: s.handleGetNode, response: &db.Node{}},
+		{method: "PATCH", path: "/nodes/{id}", summary: "Edit a node's content", handler: s.writable(s.handleUpdateNode), request: updateNodeRequest{}, response: &db.Node{}},
+		{method: "DELETE", path: "/nodes/{id}", summary: "Prune a node and its subtree", handler: s.writable(s.handleDeleteNode), query: []queryParam{
+			{name: "keep_children", description: "Delete only this node and reattach its children to its parent", typ: "boolean"},
+		}, response: deleteNodeResponse{}},
+
+		// Generate an LLM response as a child of a node
+		{method: "POST", path: "/generate", summary: "Generate an LLM response under a node; with stream set, the response is a stream of chunk, done and error events", handler: s.writable(s.handleGenerate), request: generateRequest{}, response: &db.Node{}, status: http.StatusCreated, events: true},
+
+		// Current working node (checkout)
+		{method: "GET", path: "/current", summary: "The current working node", handler: s.handleGetCurrent, response: currentNodeResponse{}},
+		{method: "PUT", path: "/current", summary: "Check out a node", handler: s.writable(s.handleSetCurrent), request: setCurrentRequest{}, response: currentNodeResponse{}},
+		{method: "GET", path: "/path/{id}", summary: "The nodes from the root down to a node, in conversation order", handler: s.handlePath, response: []*db.Node{}},
+
+		// Full-text search
+		{method: "GET", path: "/search", summary: "Full-text search over node content", handler: s.handleSearch, query: []queryParam{
+			{name: "q", description: "Words to search for; every word must appear, matching as a prefix", typ: "string", required: true},
+			{name: "limit", description: "Maximum number of results, 1 to 1000 (default 20)", typ: "integer"},
+		}, response: []*searchResult{}},
+
+		// Logging conversations held in other tools
+		{method: "POST", path: "/ingest", summary: "Store a chat transcript in OpenAI's format as a branch of nodes, reusing the part already stored; needs the ingest.token setting or the access token as a bearer token", handler: s.writable(s.handleIngest), request: ingestRequest{}, response: ingestResponse{}, status: http.StatusCreated},
+
+		// Syncing whole gardens between machines with 'bai push' and 'bai pull'
+		{method: "GET", path: "/sync", summary: "Every node with its metadata", handler: gzipped(s.handleSyncNodes), response: []*db.Node{}},
+		{method: "POST", path: "/sync", summary: "Store nodes, overwriting nodes with the same IDs", handler: s.writable(s.handleSyncPut), request: []*db.Node{}, response: syncResponse{}},
+
+		// Switching the served database without restarting the server
+		{method: "GET", path: "/db", summary: "The database file being served", handler: s.handleGetDatabase, response: databaseResponse{}},
+		{method: "PUT", path: "/db", summary: "Switch to another database file; open event streams get a database-switched event and end", handler: s.writable(s.handleSwitchDatabase), request: switchDatabaseRequest{}, response: databaseResponse{}},
+
+		// Live tree updates, including changes made from the CLI
+		{method: "GET", path: "/events", summary: "Stream of node-created, node-updated, node-deleted and current-changed events", handler: s.handleEvents, events: true},
+	}
+}
+
+// registerAPI serves every API route under both the versioned and the legacy prefix, plus the OpenAPI document
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	for _, rt := range s.apiRoutes() {
+		mux.HandleFunc(rt.method+" "+apiPrefix+rt.path, rt.handler)
+		mux.HandleFunc(rt.method+" "+legacyAPIPrefix+rt.path, rt.handler)
+	}
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", s.handleOpenAPI)
+}
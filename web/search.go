@@ -0,0 +1,60 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// defaultSearchLimit is the number of results returned by /api/search when no limit is given
+const defaultSearchLimit = 20
+
+// searchResult is a node matching a search, with an HTML excerpt whose matches are wrapped in <mark>
+type searchResult struct {
+	*db.Node
+	Snippet string `json:"snippet"`
+}
+
+// handleSearch serves a full-text search over node content
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			http.Error(w, fmt.Sprintf("limit must be a number between 1 and %d", maxPageSize), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	matches, err := database.SearchNodes(query, limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to search: %v", err), http.StatusInternalServerError)
+		log.Printf("Error searching for %q: %v", query, err)
+		return
+	}
+
+	// The snippet is escaped so it can be inserted into the page as HTML
+	highlighter := strings.NewReplacer(db.SnippetMatchStart, "<mark>", db.SnippetMatchEnd, "</mark>")
+	results := []*searchResult{}
+	for _, match := range matches {
+		results = append(results, &searchResult{
+			Node:    match.Node,
+			Snippet: highlighter.Replace(html.EscapeString(match.Snippet)),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
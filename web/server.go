@@ -1,63 +1,235 @@
 package web
 
 import (
+	"bytes"
+	"context"
 	"embed"
-	"encoding/json"
 	"fmt"
+	"html"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aarose/bonsai/db"
+	"github.com/aarose/bonsai/pkg/bonsai"
 )
 
 //go:embed index.html
 var content embed.FS
 
+// shutdownGrace is how much longer than a generation may take in-flight requests get to finish on shutdown
+const shutdownGrace = 5 * time.Second
+
+// DefaultHost is the interface the server listens on unless told otherwise, keeping it private to this machine
+const DefaultHost = "127.0.0.1"
+
+// Options configures the web server
+type Options struct {
+	Host        string // Interface to listen on; defaults to DefaultHost
+	Port        int
+	Token       string // When set, every request must present this access token
+	CertFile    string // TLS certificate; serves HTTPS when set together with KeyFile
+	KeyFile     string
+	BasePath    string          // Path prefix to serve under, e.g. "/bonsai" behind a reverse proxy
+	TrustProxy  bool            // Honor X-Forwarded-Proto and X-Forwarded-Prefix from a reverse proxy
+	ReadOnly    bool            // Reject every request that would change the tree
+	CORSOrigins []string        // Other sites allowed to call the API, or "*" for any; nil uses the web.cors_origins setting
+	Headless    bool            // Serve only the API, without the visualization pages
+	OpenBrowser bool            // Open the browser once the server is listening
+	OpenPage    string          // Page to show first, e.g. "" for the tree or NodePage(id)
+	Reload      <-chan struct{} // Each value received reopens the database being served and rereads its settings
+
+	// Creates the session responses are generated in for each database served, e.g. with a profile
+	// or provider limits; nil uses bonsai.NewSession
+	NewSession func(database *db.Database) *bonsai.Session
+}
+
 // Server represents the web visualization server
 type Server struct {
-	db   *db.Database
-	port int
+	opts     Options
+	shutdown chan struct{} // Closed when the server starts shutting down, ending open event streams
+
+	// The database can be switched while the server runs, see SwitchDatabase
+	mu             sync.RWMutex
+	current        *servedDatabase
+	switched       chan struct{} // Closed when the database is switched, ending event streams on the old one
+	retired        chan struct{} // Closed once the last database switched away from has been closed
+	databaseDir    string        // PUT /api/db only opens databases in this directory
+	corsOrigins    []string
+	corsFromConfig bool // Whether corsOrigins come from the web.cors_origins setting, reread on a switch
 }
 
 // NewServer creates a new web server instance
-func NewServer(database *db.Database, port int) *Server {
+func NewServer(database *db.Database, opts Options) *Server {
+	if opts.Host == "" {
+		opts.Host = DefaultHost
+	}
+	opts.BasePath = normalizeBasePath(opts.BasePath)
+
+	databaseDir, err := filepath.Abs(filepath.Dir(database.GetPath()))
+	if err != nil {
+		databaseDir = filepath.Dir(database.GetPath())
+	}
+	retired := make(chan struct{})
+	close(retired)
+
 	return &Server{
-		db:   database,
-		port: port,
+		opts:           opts,
+		shutdown:       make(chan struct{}),
+		current:        newServedDatabase(database, opts.NewSession),
+		switched:       make(chan struct{}),
+		retired:        retired,
+		databaseDir:    databaseDir,
+		corsOrigins:    opts.CORSOrigins,
+		corsFromConfig: opts.CORSOrigins == nil,
+	}
+}
+
+// allowedOrigins returns the origins currently allowed to call the API from other sites
+func (s *Server) allowedOrigins() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.corsOrigins
+}
+
+// loadCORSOrigins rereads the web.cors_origins setting from the current database, unless origins were given
+// on the command line
+func (s *Server) loadCORSOrigins() error {
+	if !s.corsFromConfig {
+		return nil
+	}
+	origins, err := readCORSOrigins(s.database().Database)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.corsOrigins = origins
+	s.mu.Unlock()
+	return nil
+}
+
+// readCORSOrigins returns the origins saved in a database's web.cors_origins setting
+func readCORSOrigins(database *db.Database) ([]string, error) {
+	value, err := database.GetConfigValue(CORSOriginsConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s setting: %w", CORSOriginsConfigKey, err)
 	}
+	if value == nil {
+		return nil, nil
+	}
+	return ParseOrigins(*value), nil
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
+// URL returns the address to open a page of the visualization at, including the access token for the browser if one is set
+// The page is relative to the root of the visualization, e.g. "" for the tree or NodePage(id).
+func (s *Server) URL(page string) string {
+	host := s.opts.Host
+	if ip := net.ParseIP(host); host == DefaultHost || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	scheme := "http"
+	if s.opts.CertFile != "" {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s/%s", scheme, net.JoinHostPort(host, strconv.Itoa(s.opts.Port)), s.opts.BasePath, page)
+	// API clients send the token as a header instead, so only links for the browser carry it
+	if s.opts.Token != "" && !s.opts.Headless {
+		url += "?token=" + s.opts.Token
+	}
+	return url
+}
+
+// Start runs the HTTP server until ctx is cancelled, then shuts it down gracefully, letting in-flight
+// requests finish before returning
+func (s *Server) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 
-	// Serve the main HTML page
-	mux.HandleFunc("/", s.handleIndex)
+	if !s.opts.Headless {
+		// Serve the main HTML page
+		mux.HandleFunc("/", s.handleIndex)
 
-	// API endpoint to get tree data
-	mux.HandleFunc("/api/tree", s.handleTreeData)
+		// Permalinks that open the visualization focused on a node
+		mux.HandleFunc("GET /node/{id}", s.handleIndex)
+	}
 
-	// Health check endpoint
-	mux.HandleFunc("/api/health", s.handleHealth)
+	// JSON API, documented at /api/v1/openapi.json
+	s.registerAPI(mux)
 
 	server := &http.Server{
-		Addr:           fmt.Sprintf(":%d", s.port),
-		Handler:        mux,
+		Addr:           net.JoinHostPort(s.opts.Host, strconv.Itoa(s.opts.Port)),
+		Handler:        s.mountAtBasePath(s.allowCORS(s.requireToken(s.trackDatabase(mux)))),
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		IdleTimeout:    120 * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
 
-	fmt.Printf("🌳 Bonsai visualization server starting...\n")
-	fmt.Printf("📱 Open your browser to: http://localhost:%d\n", s.port)
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", server.Addr, err)
+	}
+
+	if s.opts.Headless {
+		fmt.Printf("🌳 Bonsai API server listening on %s\n", s.URL(apiPrefix[1:]+"/"))
+		fmt.Printf("📜 OpenAPI document: %s\n", s.URL(apiPrefix[1:]+"/openapi.json"))
+	} else {
+		fmt.Printf("🌳 Bonsai visualization server starting...\n")
+		fmt.Printf("📱 Open your browser to: %s\n", s.URL(s.opts.OpenPage))
+	}
 	fmt.Printf("💡 Press Ctrl+C to stop the server\n\n")
 
-	return server.ListenAndServe()
+	if s.opts.OpenBrowser {
+		if err := OpenBrowser(s.URL(s.opts.OpenPage)); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	// Event streams never go idle, so end them explicitly or Shutdown would wait for them forever
+	server.RegisterOnShutdown(func() {
+		close(s.shutdown)
+	})
+
+	// Reopen the database on request, e.g. on SIGHUP, without dropping connections
+	go func() {
+		for {
+			select {
+			case <-s.opts.Reload:
+			case <-ctx.Done():
+				return
+			}
+			if err := s.SwitchDatabase(s.database().GetPath()); err != nil {
+				log.Printf("Error reloading database: %v", err)
+			}
+		}
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if s.opts.CertFile != "" {
+			serverErr <- server.ServeTLS(listener, s.opts.CertFile, s.opts.KeyFile)
+		} else {
+			serverErr <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// In-flight requests include LLM generations, which may take as long as the session allows
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.database().session.Timeout()+shutdownGrace)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
 }
 
 // handleIndex serves the main HTML page
@@ -75,6 +247,12 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The page uses relative URLs, so point them at the base path the browser sees
+	if basePath := s.externalBasePath(r); basePath != "" {
+		baseTag := fmt.Sprintf(`<base href="%s/">`, html.EscapeString(basePath))
+		htmlContent = bytes.Replace(htmlContent, []byte(`<base href="/">`), []byte(baseTag), 1)
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	w.Header().Set("Pragma", "no-cache")
@@ -82,80 +260,107 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Write(htmlContent)
 }
 
-// handleTreeData serves the conversation tree data as JSON
+// handleTreeData serves every node as JSON, as a flat list streamed as it's read or nested with ?format=nested
 func (s *Server) handleTreeData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	format, ok := treeFormat(w, r)
+	if !ok {
+		return
+	}
+	asOf, ok := treeAsOf(w, r)
+	if !ok {
+		return
+	}
+
+	database := s.database()
+	if treeUnchanged(w, r, database) {
+		return
+	}
 
-	// Get all nodes from database
-	nodes, err := s.getAllNodes()
+	marks, err := loadTreeMarks(database)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
 		log.Printf("Error fetching tree data: %v", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-
-	if err := json.NewEncoder(w).Encode(nodes); err != nil {
-		http.Error(w, "Failed to encode JSON", http.StatusInternalServerError)
-		log.Printf("Error encoding JSON: %v", err)
+	var nodes []*db.Node
+	if !asOf.IsZero() {
+		nodes, err = database.GetTreeAsOf("", asOf)
+	} else if format == treeFormatNested {
+		nodes, err = database.GetAllNodes()
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching tree data: %v", err)
 		return
 	}
+	if format == treeFormatNested {
+		writeJSON(w, http.StatusOK, nestTree(nodes, marks))
+		return
+	}
+
+	// Nodes are encoded as they're read, so large gardens aren't held in memory
+	streamJSONArray(w, "tree data", func(emit func(v interface{}) error) error {
+		if asOf.IsZero() {
+			return database.EachNode(func(node *db.Node) error {
+				return emit(newTreeNode(node, marks))
+			})
+		}
+		for _, node := range nodes {
+			if err := emit(newTreeNode(node, marks)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// healthCheckTimeout bounds the database queries made by /api/health
+const healthCheckTimeout = 5 * time.Second
+
+// healthResponse is returned by /api/health
+type healthResponse struct {
+	Status    string `json:"status"` // "ok", or "unavailable" when the database can't be queried
+	Timestamp int64  `json:"timestamp"`
+	Database  string `json:"database"`
+	ReadOnly  bool   `json:"read_only"`
+	Error     string `json:"error,omitempty"`
+	*db.Stats        // Omitted when the database is unavailable
 }
 
 // handleHealth provides a simple health check endpoint
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	response := map[string]interface{}{
-		"status":    "ok",
-		"timestamp": time.Now().Unix(),
-		"database":  s.db.GetPath(),
+	response := healthResponse{
+		Status:    "ok",
+		Timestamp: time.Now().Unix(),
+		Database:  database.GetPath(),
+		ReadOnly:  s.opts.ReadOnly,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode JSON", http.StatusInternalServerError)
-		return
-	}
-}
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
 
-// getAllNodes retrieves all nodes from the database
-func (s *Server) getAllNodes() ([]*TreeNode, error) {
-	query := `
-		SELECT id, content, type, parent, children, model
-		FROM Node
-		ORDER BY id
-	`
-
-	rows, err := s.db.GetConnection().Query(query)
+	status := http.StatusOK
+	stats, err := database.GetStats(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query nodes: %w", err)
-	}
-	defer rows.Close()
-
-	var nodes []*TreeNode
-	for rows.Next() {
-		node := &TreeNode{}
-		err := rows.Scan(&node.ID, &node.Content, &node.Type, &node.Parent, &node.Children, &node.Model)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan node: %w", err)
-		}
-		nodes = append(nodes, node)
+		status = http.StatusServiceUnavailable
+		response.Status = "unavailable"
+		response.Error = err.Error()
+		log.Printf("Health check failed: %v", err)
 	}
+	response.Stats = stats
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over rows: %w", err)
-	}
-
-	return nodes, nil
+	writeJSON(w, status, response)
 }
 
 // TreeNode represents a node in the conversation tree for JSON serialization
@@ -166,25 +371,31 @@ type TreeNode struct {
 	Parent   *string `json:"parent,omitempty"`
 	Children string  `json:"children"`
 	Model    *string `json:"model,omitempty"`
+	Author   *string `json:"author,omitempty"`
+	NodeMarks
 }
 
-// StartVisualizationServer is a convenience function to start the server
-func StartVisualizationServer(dbPath string, port int) error {
+// StartVisualizationServer is a convenience function to run the server until ctx is cancelled
+func StartVisualizationServer(ctx context.Context, dbPath string, opts Options) error {
 	// Create database connection
 	database, err := db.NewDatabase(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer database.Close()
 
 	// Initialize database (create tables if they don't exist)
 	if err := database.Initialize(); err != nil {
+		database.Close()
 		return fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	// Create and start server
-	server := NewServer(database, port)
-	return server.Start()
+	// Create and start server, falling back to the configured CORS origins when none were given on the command line
+	server := NewServer(database, opts)
+	defer server.close()
+	if err := server.loadCORSOrigins(); err != nil {
+		return err
+	}
+	return server.Start(ctx)
 }
 
 // GetDefaultDatabasePath returns the default database path used by the CLI
@@ -196,23 +407,23 @@ func GetDefaultDatabasePath() (string, error) {
 	return filepath.Join(homeDir, ".bonsai", "bonsai.db"), nil
 }
 
-// FindAvailablePort finds an available port starting from the given port
-func FindAvailablePort(startPort int) int {
+// FindAvailablePort finds an available port on host starting from the given port
+func FindAvailablePort(host string, startPort int) int {
 	for port := startPort; port < startPort+100; port++ {
-		if isPortAvailable(port) {
+		if isPortAvailable(host, port) {
 			return port
 		}
 	}
 	return startPort // fallback to original port if none found
 }
 
-// isPortAvailable checks if a port is available
-func isPortAvailable(port int) bool {
-	address := fmt.Sprintf(":%d", port)
+// isPortAvailable checks if a port is available on host
+func isPortAvailable(host string, port int) bool {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		return false // Port is not available
 	}
 	listener.Close()
 	return true // Port is available
-}
\ No newline at end of file
+}
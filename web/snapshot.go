@@ -0,0 +1,157 @@
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Layout of tree snapshots, matching the look of the page's tree
+const (
+	snapshotLevelWidth = 180 // Horizontal distance between a node and its children
+	snapshotRowHeight  = 36  // Vertical distance between neighbouring leaves
+	snapshotRadius     = 8
+	snapshotMargin     = 24
+	snapshotLabelChars = 22  // Labels are cut to this many characters
+	snapshotCharWidth  = 7   // Rough width of a label character, for sizing the image
+	snapshotTitleChars = 280 // Tooltips are cut to this many characters
+)
+
+// snapshotColors are the fill and stroke of each node type, as on the page
+var snapshotColors = map[string][2]string{
+	"user":   {"#e74c3c", "#c0392b"},
+	"llm":    {"#27ae60", "#229954"},
+	"note":   {"#f1c40f", "#b7950b"},
+	"system": {"#8e44ad", "#6c3483"},
+}
+
+// snapshotNode is a node placed in a snapshot
+type snapshotNode struct {
+	*NestedTreeNode
+	x, y float64
+}
+
+// handleSnapshot serves an SVG image of a tree, or the subtree below any node, laid out like the page
+// draws it, for embedding in documents
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	nodeID, ok := database.resolveNodeID(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if treeUnchanged(w, r, database) {
+		return
+	}
+
+	nodes, err := database.GetNodeAndAllChildren(nodeID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching subtree of %s: %v", nodeID, err)
+		return
+	}
+
+	marks, err := loadTreeMarks(database)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch tree data: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching subtree of %s: %v", nodeID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if err := writeSnapshot(w, nestTree(nodes, marks)[0]); err != nil {
+		log.Printf("Error writing snapshot of %s: %v", nodeID, err)
+	}
+}
+
+// layoutSnapshot places a tree with its root on the left and each depth in a column to the right of
+// the one before. Leaves take a row each, and a parent sits halfway between its first and last child.
+// It returns the placed nodes, parents before children, and the image's width and height.
+func layoutSnapshot(root *NestedTreeNode) ([]*snapshotNode, map[string]*snapshotNode, float64, float64) {
+	var placed []*snapshotNode
+	byID := make(map[string]*snapshotNode)
+	rows, maxDepth := 0, 0
+
+	var place func(node *NestedTreeNode, depth int) float64
+	place = func(node *NestedTreeNode, depth int) float64 {
+		placedNode := &snapshotNode{NestedTreeNode: node, x: float64(snapshotMargin + depth*snapshotLevelWidth)}
+		placed = append(placed, placedNode)
+		byID[node.ID] = placedNode
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+
+		if len(node.Children) == 0 {
+			placedNode.y = float64(snapshotMargin + rows*snapshotRowHeight)
+			rows++
+			return placedNode.y
+		}
+		first := place(node.Children[0], depth+1)
+		last := first
+		for _, child := range node.Children[1:] {
+			last = place(child, depth+1)
+		}
+		placedNode.y = (first + last) / 2
+		return placedNode.y
+	}
+	place(root, 0)
+
+	labelWidth := snapshotRadius + 6 + (snapshotLabelChars+3)*snapshotCharWidth
+	width := float64(2*snapshotMargin + maxDepth*snapshotLevelWidth + labelWidth)
+	height := float64(2*snapshotMargin + (rows-1)*snapshotRowHeight)
+	return placed, byID, width, height
+}
+
+// writeSnapshot writes an SVG image of the tree below root
+func writeSnapshot(w http.ResponseWriter, root *NestedTreeNode) error {
+	nodes, byID, width, height := layoutSnapshot(root)
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(out, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	// Links first, so nodes are drawn over them
+	for _, node := range nodes {
+		if node.Parent == nil || node.ID == root.ID {
+			continue
+		}
+		parent := byID[*node.Parent]
+		middle := (parent.x + node.x) / 2
+		fmt.Fprintf(out, `<path d="M%.1f,%.1f C%.1f,%.1f %.1f,%.1f %.1f,%.1f" fill="none" stroke="#7f8c8d" stroke-width="2"/>`+"\n",
+			parent.x, parent.y, middle, parent.y, middle, node.y, node.x, node.y)
+	}
+
+	for _, node := range nodes {
+		colors, ok := snapshotColors[node.Type]
+		if !ok {
+			colors = [2]string{"#3498db", "#2980b9"}
+		}
+		stroke, strokeWidth := colors[1], 2
+		if node.IsCurrent {
+			stroke, strokeWidth = "#f1c40f", 5
+		}
+		dash := ""
+		if node.Type == "note" {
+			dash = ` stroke-dasharray="3 2"`
+		}
+		text := strings.Join(strings.Fields(node.Content), " ")
+
+		fmt.Fprintf(out, `<g><title>%s: %s</title>`, node.Type, html.EscapeString(truncateRunes(text, snapshotTitleChars)))
+		fmt.Fprintf(out, `<circle cx="%.1f" cy="%.1f" r="%d" fill="%s" stroke="%s" stroke-width="%d"%s/>`, node.x, node.y, snapshotRadius, colors[0], stroke, strokeWidth, dash)
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" dy=".35em" fill="#2c3e50">%s</text></g>`+"\n", node.x+snapshotRadius+6, node.y, html.EscapeString(truncateRunes(text, snapshotLabelChars)))
+	}
+
+	fmt.Fprintln(out, `</svg>`)
+	return out.Flush()
+}
+
+// truncateRunes cuts text to at most n characters, marking the cut with an ellipsis
+func truncateRunes(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// maxSyncBodyBytes caps the size of the nodes pushed in one POST /api/sync, which can be a whole garden
+const maxSyncBodyBytes = 512 << 20
+
+// syncResponse is returned by POST /api/sync
+type syncResponse struct {
+	Stored int `json:"stored"`
+}
+
+// handleSyncNodes serves every node with its metadata, for 'bai pull'
+func (s *Server) handleSyncNodes(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	streamJSONArray(w, "nodes for sync", func(emit func(v interface{}) error) error {
+		return database.EachNode(func(node *db.Node) error {
+			return emit(node)
+		})
+	})
+}
+
+// handleSyncPut stores nodes pushed with 'bai push', overwriting nodes with the same IDs
+func (s *Server) handleSyncPut(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	var nodes []*db.Node
+	if !decodeJSONBody(w, r, &nodes, maxSyncBodyBytes) {
+		return
+	}
+
+	if err := database.UpsertNodes(nodes); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to store nodes: %v", err), http.StatusBadRequest)
+		log.Printf("Error storing pushed nodes: %v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, syncResponse{Stored: len(nodes)})
+}
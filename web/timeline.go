@@ -0,0 +1,107 @@
+package web
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// timelineParams are the query parameters accepted by /api/timeline
+var timelineParams = append([]queryParam{
+	{name: "since", description: "Only nodes created at or after this time, in RFC 3339 or Unix seconds", typ: "string"},
+	{name: "until", description: "Only nodes created before this time, in RFC 3339 or Unix seconds", typ: "string"},
+	{name: "type", description: "Only nodes of this type: user, llm, note or system", typ: "string"},
+	{name: "model", description: "Only nodes whose model matches this glob, e.g. claude*", typ: "string"},
+	{name: "tz", description: "IANA time zone the days are counted in, e.g. Europe/Paris (default: the server's)", typ: "string"},
+}, paginationParams...)
+
+// timelinePage is a page of /api/timeline
+type timelinePage struct {
+	Days       []*db.ActivityDay `json:"days"`  // Nodes created on each day of the whole range, oldest first
+	Nodes      []*db.Node        `json:"nodes"` // A page of the nodes, oldest first
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// handleTimeline serves the nodes of every tree in the order they were created, with how many were
+// created each day for drawing activity heatmaps
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	database := s.database()
+	query := r.URL.Query()
+
+	filter := db.NodeFilter{Type: query.Get("type"), Model: query.Get("model")}
+	if filter.Type != "" && !db.IsNodeType(filter.Type) {
+		http.Error(w, fmt.Sprintf("type must be one of %s", strings.Join(db.NodeTypes, ", ")), http.StatusBadRequest)
+		return
+	}
+	for _, bound := range []struct {
+		name string
+		time *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		t, ok := parseTimeParam(value)
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s must be a time in RFC 3339, such as 2024-01-01T12:00:00Z, or Unix seconds", bound.name), http.StatusBadRequest)
+			return
+		}
+		*bound.time = t
+	}
+
+	loc := time.Local
+	if tz := query.Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			http.Error(w, fmt.Sprintf("Unknown time zone %q", tz), http.StatusBadRequest)
+			return
+		}
+	}
+
+	cursor, limit, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+	var afterCreated int64
+	var afterID string
+	if cursor != "" {
+		created, id, found := strings.Cut(cursor, ":")
+		var err error
+		if afterCreated, err = strconv.ParseInt(created, 10, 64); !found || err != nil {
+			http.Error(w, "cursor must be a next_cursor returned by /api/timeline", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+
+	days, err := database.GetActivityByDay(filter, loc)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count activity: %v", err), http.StatusInternalServerError)
+		log.Printf("Error counting activity: %v", err)
+		return
+	}
+
+	// Fetch one extra node to find out whether there's another page
+	nodes, err := database.GetTimelinePage(filter, afterCreated, afterID, limit+1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch timeline: %v", err), http.StatusInternalServerError)
+		log.Printf("Error fetching timeline: %v", err)
+		return
+	}
+
+	page := timelinePage{Days: days, Nodes: nodes}
+	if len(nodes) > limit {
+		page.Nodes = nodes[:limit]
+		last := page.Nodes[limit-1]
+		page.NextCursor = fmt.Sprintf("%d:%s", last.CreatedAt, last.ID)
+	}
+	if page.Nodes == nil {
+		page.Nodes = []*db.Node{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
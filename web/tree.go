@@ -0,0 +1,256 @@
+package web
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarose/bonsai/db"
+)
+
+// Formats the tree endpoints can respond in, chosen with ?format=
+const (
+	treeFormatFlat   = "flat"   // A list of nodes pointing at their parents
+	treeFormatNested = "nested" // The top nodes, each with its descendants nested below it
+)
+
+// treeFormatParam is the query parameter choosing the format of a tree endpoint's response
+var treeFormatParam = queryParam{
+	name:        "format",
+	description: "flat (default) for a list of nodes, or nested for the top nodes with their children nested in a children array",
+	typ:         "string",
+}
+
+// treeAsOfParam is the query parameter asking a tree endpoint for the tree as it stood at a time
+var treeAsOfParam = queryParam{
+	name:        "as_of",
+	description: "A time in RFC 3339 or Unix seconds; only the nodes created by then are returned. Edits and prunes since aren't undone.",
+	typ:         "string",
+}
+
+// NodeMarks are what the page styles a node by, served with each node by the tree endpoints
+type NodeMarks struct {
+	IsCurrent bool `json:"is_current"` // The node is the current working node
+	IsPinned  bool `json:"is_pinned"`  // The node is pinned to a tree
+}
+
+// NestedTreeNode is a node with its children nested below it, served by the tree endpoints with ?format=nested
+type NestedTreeNode struct {
+	ID        string  `json:"id"`
+	Content   string  `json:"content"`
+	Type      string  `json:"type"`
+	Parent    *string `json:"parent,omitempty"`
+	Model     *string `json:"model,omitempty"`
+	Author    *string `json:"author,omitempty"`
+	CreatedAt int64   `json:"created_at,omitempty"`
+	NodeMarks
+	Children []*NestedTreeNode `json:"children"` // Oldest first
+}
+
+// SubtreeNode is a node as served by /api/tree/{id}: all of its columns, and its marks
+type SubtreeNode struct {
+	*db.Node
+	NodeMarks
+}
+
+// treeMarks holds what's needed to mark the nodes of a tree response
+type treeMarks struct {
+	current string
+	pinned  map[string]bool
+}
+
+// loadTreeMarks reads the current node and every pinned node
+func loadTreeMarks(database *servedDatabase) (*treeMarks, error) {
+	marks := &treeMarks{pinned: make(map[string]bool)}
+	current, err := database.GetCurrentNode()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		marks.current = *current
+	}
+
+	roots, err := database.GetRootNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		ids, _ := root.GetMetadata()[db.PinnedMetadataKey].([]interface{})
+		for _, id := range ids {
+			if id, ok := id.(string); ok {
+				marks.pinned[id] = true
+			}
+		}
+	}
+	return marks, nil
+}
+
+// of returns a node's marks
+func (m *treeMarks) of(node *db.Node) NodeMarks {
+	return NodeMarks{IsCurrent: node.ID == m.current, IsPinned: m.pinned[node.ID]}
+}
+
+// treeFormat reads the format a tree endpoint should respond in, writing an error if it's unknown
+func treeFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", treeFormatFlat:
+		return treeFormatFlat, true
+	case treeFormatNested:
+		return treeFormatNested, true
+	default:
+		http.Error(w, "format must be flat or nested", http.StatusBadRequest)
+		return "", false
+	}
+}
+
+// treeAsOf reads the time a tree endpoint should show the tree as of, writing an error if it's
+// malformed. It's zero if the endpoint should show the tree as it is now.
+func treeAsOf(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	value := r.URL.Query().Get("as_of")
+	if value == "" {
+		return time.Time{}, true
+	}
+	t, ok := parseTimeParam(value)
+	if !ok {
+		http.Error(w, "as_of must be a time in RFC 3339, such as 2024-01-01T12:00:00Z, or Unix seconds", http.StatusBadRequest)
+	}
+	return t, ok
+}
+
+// parseTimeParam reads a time passed in a query parameter, in RFC 3339 or Unix seconds
+func parseTimeParam(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	return time.Time{}, false
+}
+
+// treeUnchanged sets the validators of a tree response, the ETag and Last-Modified headers, from the
+// revision of the database's nodes. It reports whether the client's copy is still current, having
+// answered 304 Not Modified if so. Responses are marked no-cache rather than no-store, so browsers
+// keep them and revalidate with If-None-Match or If-Modified-Since.
+func treeUnchanged(w http.ResponseWriter, r *http.Request, database *servedDatabase) bool {
+	revision, err := database.Revision()
+	if err != nil {
+		// Serve the tree without validators rather than fail over them
+		log.Printf("Error reading revision: %v", err)
+		return false
+	}
+
+	current, err := database.GetCurrentNode()
+	if err != nil {
+		log.Printf("Error reading current node: %v", err)
+		return false
+	}
+
+	// The path tells apart databases that may have reached the same revision, and nodes are marked
+	// with whether they're current
+	state := fnv.New32a()
+	state.Write([]byte(database.GetPath()))
+	if current != nil {
+		state.Write([]byte{0})
+		state.Write([]byte(*current))
+	}
+	etag := fmt.Sprintf(`W/"%d-%08x"`, revision.Seq, state.Sum32())
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+	if revision.ChangedAt > 0 {
+		w.Header().Set("Last-Modified", time.Unix(revision.ChangedAt, 0).UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since when both are sent
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if !etagMatches(match, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || revision.ChangedAt == 0 || revision.ChangedAt > since.Unix() {
+			return false
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, comparing weakly
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// newTreeNode converts a node to the flat form served by /api/tree
+func newTreeNode(node *db.Node, marks *treeMarks) *TreeNode {
+	return &TreeNode{
+		ID:        node.ID,
+		Content:   node.Content,
+		Type:      node.Type,
+		Parent:    node.Parent,
+		Children:  node.Children,
+		Model:     node.Model,
+		Author:    node.Author,
+		NodeMarks: marks.of(node),
+	}
+}
+
+// nestTree builds the nested form of nodes from their parent pointers, ignoring the stored children
+// lists. Nodes whose parent isn't among them are returned as the top nodes, oldest first, so a subtree
+// nests below the node it was fetched for.
+func nestTree(nodes []*db.Node, marks *treeMarks) []*NestedTreeNode {
+	byID := make(map[string]*NestedTreeNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = &NestedTreeNode{
+			ID:        node.ID,
+			Content:   node.Content,
+			Type:      node.Type,
+			Parent:    node.Parent,
+			Model:     node.Model,
+			Author:    node.Author,
+			CreatedAt: node.CreatedAt,
+			NodeMarks: marks.of(node),
+			Children:  []*NestedTreeNode{},
+		}
+	}
+
+	top := []*NestedTreeNode{}
+	for _, node := range nodes {
+		nested := byID[node.ID]
+		if node.Parent != nil {
+			if parent, ok := byID[*node.Parent]; ok {
+				parent.Children = append(parent.Children, nested)
+				continue
+			}
+		}
+		top = append(top, nested)
+	}
+
+	sortNested(top)
+	for _, nested := range byID {
+		sortNested(nested.Children)
+	}
+	return top
+}
+
+// sortNested orders sibling nodes oldest first, by ID for nodes created at the same time
+func sortNested(siblings []*NestedTreeNode) {
+	sort.Slice(siblings, func(i, j int) bool {
+		if siblings[i].CreatedAt != siblings[j].CreatedAt {
+			return siblings[i].CreatedAt < siblings[j].CreatedAt
+		}
+		return siblings[i].ID < siblings[j].ID
+	})
+}